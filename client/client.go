@@ -0,0 +1,216 @@
+// Package client is a Go client for the Bitterlink HTTP API.
+//
+//	c := client.New("https://ping.example.com", os.Getenv("BITTERLINK_API_KEY"))
+//	check, err := c.CreateCheck(ctx, client.CreateCheckRequest{Name: "nightly-backup", ExpectedInterval: 86400})
+//	...
+//	err = c.Ping(ctx, check.UUID)
+//
+// Request and response types are shared with the server, so they always match
+// what the API accepts and returns.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/models"
+	httptransport "bitterlink/core/internal/transport/http"
+)
+
+// Types shared with the server.
+type (
+	Check              = models.Check
+	CreateCheckRequest = httptransport.CreateCheckRequest
+)
+
+var (
+	// ErrCheckNotFound is returned for unknown checks, including checks owned by
+	// another account (the API does not tell them apart).
+	ErrCheckNotFound = errors.New("check not found")
+	// ErrUnauthorized is returned when the API key is missing, invalid or inactive.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// APIError is a non-2xx response from the API. It unwraps to ErrCheckNotFound
+// or ErrUnauthorized where applicable, so callers can use errors.Is.
+type APIError struct {
+	StatusCode int
+	Message    string // the "error" field of the response body
+	Details    string // the "details" field, when present
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("bitterlink: %d %s", e.StatusCode, e.Message)
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrCheckNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// temporary reports whether retrying the request may succeed.
+func (e *APIError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client calls the API with one API key. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+
+	pingRetries int
+	pingBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces the default HTTP client (10s timeout).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithPingRetries sets how many times Ping retries a transient failure and the
+// initial backoff, which doubles after every attempt. Defaults to 3 and 500ms.
+func WithPingRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.pingRetries = retries
+		c.pingBackoff = backoff
+	}
+}
+
+// New creates a client for the API at baseURL (e.g. "https://ping.example.com").
+func New(baseURL, apiKey string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		apiKey:      apiKey,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		pingRetries: 3,
+		pingBackoff: 500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CreateCheck creates a check and returns it with its generated ID and UUID.
+func (c *Client) CreateCheck(ctx context.Context, req CreateCheckRequest) (*Check, error) {
+	var check Check
+	if err := c.do(ctx, http.MethodPost, "/api/v1/checks", req, &check); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// ListChecks returns all checks of the account.
+func (c *Client) ListChecks(ctx context.Context) ([]Check, error) {
+	var checks []Check
+	if err := c.do(ctx, http.MethodGet, "/api/v1/checks", nil, &checks); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// DeleteCheck deletes a check by its numeric ID.
+func (c *Client) DeleteCheck(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/checks/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+// Ping sends a success heartbeat for the check with the given UUID. Network
+// errors, 429 and 5xx responses are retried with exponential backoff; other
+// errors (e.g. ErrCheckNotFound) are returned immediately.
+func (c *Client) Ping(ctx context.Context, uuid string) error {
+	backoff := c.pingBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.do(ctx, http.MethodGet, "/api/v1/ping/"+uuid, nil, nil)
+		if err == nil || attempt >= c.pingRetries || !isTransient(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransient reports whether err is worth retrying.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.temporary()
+	}
+	// Anything else failed before a response arrived (connection refused, reset, timeout)
+	return true
+}
+
+// do sends a JSON request and decodes a JSON response into out (if non-nil).
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("bitterlink: encoding request: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("bitterlink: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("bitterlink: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var errBody struct {
+			Error   string `json:"error"`
+			Details string `json:"details"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errBody) == nil && errBody.Error != "" {
+			apiErr.Message, apiErr.Details = errBody.Error, errBody.Details
+		}
+		return apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("bitterlink: decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+const checksUsage = `Usage:
+  core checks list [--status=down] [--user=email] [--json]
+  core checks set-status --uuid=UUID --status=paused|up [--json]
+`
+
+// statusesSettableFromCLI are the statuses an operator may force a check into.
+var statusesSettableFromCLI = map[string]bool{"paused": true, "up": true}
+
+// runChecksCommand implements `core checks ...` and returns the process exit code.
+func runChecksCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, checksUsage)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "list":
+		return runChecksList(ctx, args[1:])
+	case "set-status":
+		return runChecksSetStatus(ctx, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown checks subcommand %q\n\n%s", args[0], checksUsage)
+		return 2
+	}
+}
+
+func runChecksList(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("checks list", flag.ContinueOnError)
+	status := flags.String("status", "down", "only list checks with this status")
+	userEmail := flags.String("user", "", "only list checks owned by the user with this email")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	databasePool, err := bootstrap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: database initialization failed: %v\n", err)
+		return 1
+	}
+	defer databasePool.Close()
+
+	var userID int64 // 0 lists all users
+	if *userEmail != "" {
+		user, err := repository.NewMySQLUserRepository(databasePool).FindByEmail(ctx, *userEmail)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: looking up user %s: %v\n", *userEmail, err)
+			return 1
+		}
+		userID = user.ID
+	}
+
+	checks, err := newCheckRepository(databasePool).ListByStatus(ctx, *status, userID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: listing checks: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		return printJSON(checks)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "UUID\tNAME\tUSER ID\tSTATUS\tLAST PING AGE")
+	for _, check := range checks {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", check.UUID, check.Name, check.UserID, check.Status, lastPingAge(check))
+	}
+	tw.Flush()
+	return 0
+}
+
+func runChecksSetStatus(ctx context.Context, args []string) int {
+	flags := flag.NewFlagSet("checks set-status", flag.ContinueOnError)
+	checkUUID := flags.String("uuid", "", "UUID of the check to change (required)")
+	status := flags.String("status", "", "new status: paused or up (required)")
+	asJSON := flags.Bool("json", false, "print the updated check as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *checkUUID == "" || !statusesSettableFromCLI[*status] {
+		fmt.Fprint(os.Stderr, "error: --uuid and --status=paused|up are required\n\n"+checksUsage)
+		return 2
+	}
+
+	databasePool, err := bootstrap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: database initialization failed: %v\n", err)
+		return 1
+	}
+	defer databasePool.Close()
+
+	checkRepo := newCheckRepository(databasePool)
+	if err := checkRepo.SetStatus(ctx, *checkUUID, *status, models.EventSourceCLI); err != nil {
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			fmt.Fprintf(os.Stderr, "error: no active check with UUID %s\n", *checkUUID)
+		} else {
+			fmt.Fprintf(os.Stderr, "error: setting status: %v\n", err)
+		}
+		return 1
+	}
+
+	check, err := checkRepo.FindByUUID(ctx, *checkUUID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: reloading check: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		return printJSON(check)
+	}
+	fmt.Printf("Check %s (%s) is now %s\n", check.UUID, check.Name, check.Status)
+	return 0
+}
+
+// lastPingAge renders how long ago the check last pinged, for table output.
+func lastPingAge(check models.Check) string {
+	if !check.LastPingAt.Valid {
+		return "never"
+	}
+	return time.Since(check.LastPingAt.Time).Truncate(time.Second).String()
+}
+
+// printJSON writes v to stdout as indented JSON and returns the exit code.
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "error: encoding JSON: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bitterlink/core/internal/export"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+// runExportCommand implements `core export`, writing a per-account JSON dump.
+func runExportCommand(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	userEmail := flags.String("user", "", "export the account with this email")
+	all := flags.Bool("all", false, "export every account")
+	out := flags.String("out", "-", "output file, or - for stdout")
+	pingsDays := flags.Int("pings-days", 0, "include pings from the last N days (0 = no pings)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if (*userEmail == "") == !*all {
+		fmt.Fprintln(os.Stderr, "error: exactly one of --user or --all is required")
+		return 2
+	}
+
+	databasePool, err := bootstrap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: database initialization failed: %v\n", err)
+		return 1
+	}
+	defer databasePool.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	exporter := &export.Exporter{CheckRepo: newCheckRepository(databasePool)}
+	if *pingsDays > 0 {
+		exporter.Options.PingsSince = time.Now().UTC().AddDate(0, 0, -*pingsDays)
+	}
+	userRepo := repository.NewMySQLUserRepository(databasePool)
+
+	dw := export.NewWriter(w)
+	err = dw.Begin()
+	if err == nil {
+		if *all {
+			err = userRepo.Each(ctx, func(user *models.User) error {
+				return exporter.Account(ctx, dw, user)
+			})
+		} else {
+			var user *models.User
+			if user, err = userRepo.FindByEmail(ctx, *userEmail); err == nil {
+				err = exporter.Account(ctx, dw, user)
+			}
+		}
+	}
+	if err == nil {
+		err = dw.End()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: export failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runImportCommand implements `core import`, restoring a dump into empty accounts.
+func runImportCommand(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := flags.String("in", "-", "dump file, or - for stdin")
+	userEmail := flags.String("user", "", "restore into this account instead of the one matching the exported email")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	databasePool, err := bootstrap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: database initialization failed: %v\n", err)
+		return 1
+	}
+	defer databasePool.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+
+	userRepo := repository.NewMySQLUserRepository(databasePool)
+	importer := &export.Importer{
+		CheckRepo: newCheckRepository(databasePool),
+		Resolve: func(ctx context.Context, exported *models.User) (*models.User, error) {
+			if *userEmail != "" {
+				return userRepo.FindByEmail(ctx, *userEmail)
+			}
+			return userRepo.FindByEmail(ctx, exported.Email)
+		},
+	}
+
+	stats, err := importer.Import(ctx, r)
+	fmt.Printf("Restored %d accounts, %d checks, %d dependencies, %d events, %d pings\n", stats.Accounts, stats.Checks, stats.Dependencies, stats.Events, stats.Pings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: import failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const healthcheckTimeout = 2 * time.Second
+
+// runHealthcheckCommand implements `core healthcheck` for container probes in
+// images without curl. It only asks the running server for /health: no logging
+// setup, no database. Exits 0 on HTTP 200, 1 otherwise.
+func runHealthcheckCommand(args []string) int {
+	// Pick up SERVER_PORT / SERVER_SOCKET from .env like the server does, quietly
+	_ = godotenv.Load()
+
+	client := &http.Client{Timeout: healthcheckTimeout}
+	url := "http://127.0.0.1:" + serverPort() + "/health"
+
+	if socketPath := os.Getenv("SERVER_SOCKET"); socketPath != "" {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		}
+		url = "http://unix/health" // Host is ignored by the unix dialer
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck failed: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "healthcheck failed: %s returned %s\n", url, resp.Status)
+		return 1
+	}
+	return 0
+}
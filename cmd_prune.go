@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bitterlink/core/internal/agency"
+	"bitterlink/core/internal/db"
+)
+
+// runPruneCommand implements `core prune`, a one-shot, rate-limited cleanup.
+func runPruneCommand(args []string) int {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	pingsOlderThan := flags.String("pings-older-than", "", "delete pings older than this (e.g. 90d)")
+	purgeDeletedOlderThan := flags.String("purge-deleted-checks-older-than", "", "permanently delete checks soft-deleted longer ago than this (e.g. 30d)")
+	batchSize := flags.Int("batch-size", 5000, "rows per DELETE batch (checks per batch when purging)")
+	pause := flags.Duration("sleep", 500*time.Millisecond, "pause between batches, to limit replication lag")
+	maxLag := flags.Duration("max-replica-lag", 0, "wait between batches while a replica in REPLICA_DSNS is further behind (default REPLICATION_MAX_LAG, or 10s)")
+	dryRun := flags.Bool("dry-run", false, "only print how many rows would be deleted")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *pingsOlderThan == "" && *purgeDeletedOlderThan == "" {
+		fmt.Fprintln(os.Stderr, "error: nothing to do, pass --pings-older-than and/or --purge-deleted-checks-older-than")
+		return 2
+	}
+	if *batchSize <= 0 {
+		fmt.Fprintln(os.Stderr, "error: --batch-size must be positive")
+		return 2
+	}
+
+	now := time.Now().UTC()
+	var pingCutoff, purgeCutoff time.Time
+	for _, opt := range []struct {
+		value  string
+		cutoff *time.Time
+		name   string
+	}{
+		{*pingsOlderThan, &pingCutoff, "--pings-older-than"},
+		{*purgeDeletedOlderThan, &purgeCutoff, "--purge-deleted-checks-older-than"},
+	} {
+		if opt.value == "" {
+			continue
+		}
+		age, err := agency.ParseDuration(opt.value)
+		if err != nil || age <= 0 {
+			fmt.Fprintf(os.Stderr, "error: %s: invalid duration %q\n", opt.name, opt.value)
+			return 2
+		}
+		*opt.cutoff = now.Add(-age)
+	}
+
+	databasePool, err := bootstrap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: database initialization failed: %v\n", err)
+		return 1
+	}
+	defer databasePool.Close()
+	checkRepo := newCheckRepository(databasePool)
+
+	// Ctrl-C stops the loop between batches; the batch in flight runs on its own
+	// context so it completes and the summary stays accurate.
+	interrupted, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	batchCtx := context.Background()
+
+	if *dryRun {
+		if !pingCutoff.IsZero() {
+			count, err := checkRepo.CountPingsOlderThan(batchCtx, pingCutoff)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				return 1
+			}
+			fmt.Printf("pings older than %s: %d\n", pingCutoff.Format(time.RFC3339), count)
+		}
+		if !purgeCutoff.IsZero() {
+			count, err := checkRepo.CountDeletedChecksOlderThan(batchCtx, purgeCutoff)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				return 1
+			}
+			fmt.Printf("checks soft-deleted before %s: %d\n", purgeCutoff.Format(time.RFC3339), count)
+		}
+		return 0
+	}
+
+	throttleConfig := lagThrottleConfig()
+	if *maxLag > 0 {
+		throttleConfig.MaxLag = *maxLag
+	}
+	throttle := db.NewLagThrottle(connectReplicas(), throttleConfig)
+
+	exitCode := 0
+	if !pingCutoff.IsZero() {
+		total, err := pruneInBatches(interrupted, "pings", *batchSize, *pause, throttle, func(limit int) (int64, error) {
+			return checkRepo.DeletePingsOlderThan(batchCtx, pingCutoff, limit)
+		})
+		fmt.Printf("deleted %d pings\n", total)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			exitCode = 1
+		}
+	}
+	if !purgeCutoff.IsZero() && interrupted.Err() == nil && exitCode == 0 {
+		total, err := pruneInBatches(interrupted, "deleted checks", *batchSize, *pause, throttle, func(limit int) (int64, error) {
+			return checkRepo.PurgeDeletedChecks(batchCtx, purgeCutoff, limit)
+		})
+		fmt.Printf("purged %d deleted checks\n", total)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			exitCode = 1
+		}
+	}
+	if interrupted.Err() != nil {
+		fmt.Println("interrupted, stopped after the current batch")
+	}
+	return exitCode
+}
+
+// pruneInBatches calls deleteBatch until it deletes fewer than batchSize rows,
+// the context is cancelled, or it fails, sleeping pause between batches and
+// then waiting for lagging replicas. Returns the total number of rows deleted.
+func pruneInBatches(ctx context.Context, what string, batchSize int, pause time.Duration, throttle *db.LagThrottle, deleteBatch func(limit int) (int64, error)) (int64, error) {
+	var total int64
+	for batch := 1; ; batch++ {
+		deleted, err := deleteBatch(batchSize)
+		total += deleted
+		if err != nil {
+			return total, fmt.Errorf("pruning %s (batch %d): %w", what, batch, err)
+		}
+		fmt.Printf("%s batch %d: deleted %d (total %d)\n", what, batch, deleted, total)
+		if deleted < int64(batchSize) {
+			return total, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, nil
+		case <-time.After(pause):
+		}
+		if throttle.Wait(ctx) != nil {
+			return total, nil
+		}
+	}
+}
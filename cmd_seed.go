@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// seedMaxPingsPerCheck keeps fast-interval checks from generating huge histories.
+	seedMaxPingsPerCheck = 200
+	// seedPasswordHash is a placeholder; seeded users log in with API keys only.
+	seedPasswordHash = "$2y$10$seeded.user.password.hash.placeholder"
+	// seedBaseTime is the default "now" of seeded data. Fixed rather than the
+	// wall clock so a seed generates the same timestamps on every run.
+	seedBaseTime = "2024-01-01T00:00:00Z"
+)
+
+// seedTables are truncated by --wipe, children first.
+var seedTables = []string{
+	"check_events", "notifications_log", "check_notification_channel", "pings",
+	"notification_channels", "checks", "api_keys", "users",
+}
+
+var (
+	seedIntervals    = []uint32{60, 300, 900, 3600, 86400}
+	seedGracePeriods = []uint32{0, 30, 60, 300}
+	seedStatuses     = []string{models.StatusNew, models.StatusUp, models.StatusDown, models.StatusPaused}
+	seedUserAgents   = []string{"curl/8.5.0", "Wget/1.21.4", "python-requests/2.31.0", "cron-agent/1.0"}
+)
+
+// runSeedCommand implements `core seed`, generating realistic development data.
+func runSeedCommand(args []string) int {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	numUsers := flags.Int("users", 5, "number of users to create")
+	checksPerUser := flags.Int("checks", 20, "number of checks per user")
+	days := flags.Int("days", 7, "how many days of ping history to generate")
+	seed := flags.Int64("seed", 1, "random seed; the same seed produces the same data")
+	baseTime := flags.String("base-time", seedBaseTime, "RFC 3339 time the generated history ends at")
+	wipe := flags.Bool("wipe", false, "truncate seeded tables first (asks for confirmation)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *numUsers <= 0 || *checksPerUser <= 0 || *days <= 0 {
+		fmt.Fprintln(os.Stderr, "error: --users, --checks and --days must be positive")
+		return 2
+	}
+	now, err := time.Parse(time.RFC3339, *baseTime)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: --base-time: %v\n", err)
+		return 2
+	}
+
+	databasePool, err := bootstrap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: database initialization failed: %v\n", err)
+		return 1
+	}
+	defer databasePool.Close()
+
+	// Checked after bootstrap so APP_ENV from .env is honored too
+	if strings.EqualFold(os.Getenv("APP_ENV"), "production") {
+		fmt.Fprintln(os.Stderr, "error: refusing to seed when APP_ENV=production")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *wipe {
+		if !confirm(fmt.Sprintf("This will TRUNCATE %s. Type 'yes' to continue: ", strings.Join(seedTables, ", "))) {
+			fmt.Fprintln(os.Stderr, "aborted")
+			return 1
+		}
+		if err := wipeTables(ctx, databasePool); err != nil {
+			fmt.Fprintf(os.Stderr, "error: wiping tables: %v\n", err)
+			return 1
+		}
+	}
+
+	s := &seeder{
+		rng:       rand.New(rand.NewSource(*seed)),
+		seed:      *seed,
+		now:       now.UTC().Truncate(time.Second),
+		days:      *days,
+		userRepo:  repository.NewMySQLUserRepository(databasePool),
+		keyRepo:   repository.NewMySQLAPIKeyRepository(databasePool),
+		checkRepo: newCheckRepository(databasePool),
+	}
+	for i := 0; i < *numUsers; i++ {
+		if err := s.seedUser(ctx, i, *checksPerUser); err != nil {
+			fmt.Fprintf(os.Stderr, "error: seeding user %d: %v\n", i+1, err)
+			return 1
+		}
+	}
+
+	fmt.Printf("Seeded %d users, %d checks, %d pings (seed %d)\n", s.users, s.checks, s.pings, *seed)
+	return 0
+}
+
+// seeder generates data from a single RNG so a given --seed is reproducible.
+type seeder struct {
+	rng  *rand.Rand
+	seed int64
+	now  time.Time
+	days int
+
+	userRepo  repository.UserRepository
+	keyRepo   repository.APIKeyRepository
+	checkRepo repository.CheckRepository
+
+	users, checks, pings int
+}
+
+func (s *seeder) seedUser(ctx context.Context, index int, numChecks int) error {
+	user := &models.User{
+		Name:            fmt.Sprintf("Seed User %d", index+1),
+		Email:           fmt.Sprintf("seed%d-user%d@example.test", s.seed, index+1),
+		PasswordHash:    seedPasswordHash,
+		EmailVerifiedAt: sql.NullTime{Time: s.now, Valid: true},
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+	s.users++
+
+	key := &models.APIKey{
+		UserID:   user.ID,
+		KeyValue: fmt.Sprintf("akey_seed%d_user%d_%08x", s.seed, index+1, s.rng.Uint32()),
+		Label:    sql.NullString{String: "Seeded key", Valid: true},
+		IsActive: true,
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return err
+	}
+	fmt.Printf("User %s: API key %s\n", user.Email, key.KeyValue)
+
+	for i := 0; i < numChecks; i++ {
+		if err := s.seedCheck(ctx, user.ID, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *seeder) seedCheck(ctx context.Context, userID int64, index int) error {
+	checkUUID, err := uuid.NewRandomFromReader(s.rng)
+	if err != nil {
+		return err
+	}
+	check := &models.Check{
+		UserID:           userID,
+		UUID:             checkUUID.String(),
+		Name:             fmt.Sprintf("Seed check %d", index+1),
+		ExpectedInterval: seedIntervals[s.rng.Intn(len(seedIntervals))],
+		GracePeriod:      seedGracePeriods[s.rng.Intn(len(seedGracePeriods))],
+		Status:           seedStatuses[index%len(seedStatuses)], // Cycle so every status is represented
+		IsEnabled:        s.rng.Intn(10) != 0,                   // ~10% disabled
+	}
+	if s.rng.Intn(2) == 0 {
+		check.Description = sql.NullString{String: "Generated by the seed command", Valid: true}
+	}
+	if err := s.checkRepo.Create(ctx, check); err != nil {
+		return err
+	}
+	s.checks++
+
+	if check.Status != models.StatusNew {
+		if err := s.seedPings(ctx, check); err != nil {
+			return err
+		}
+	}
+
+	// ~5% soft-deleted, after their history exists
+	if s.rng.Intn(20) == 0 {
+		return s.checkRepo.Delete(ctx, check.ID, check.UserID)
+	}
+	return nil
+}
+
+// seedPings generates history ending at a point consistent with the check's status.
+func (s *seeder) seedPings(ctx context.Context, check *models.Check) error {
+	interval := time.Duration(check.ExpectedInterval) * time.Second
+	jitter := func() time.Duration { return time.Duration(s.rng.Int63n(int64(interval)/10 + 1)) }
+
+	var last time.Time
+	switch check.Status {
+	case models.StatusDown: // Overdue past the grace period
+		last = s.now.Add(-interval - time.Duration(check.GracePeriod)*time.Second - interval - jitter())
+	case models.StatusPaused: // Went quiet a while ago
+		last = s.now.Add(-time.Duration(s.rng.Intn(s.days*24)+1) * time.Hour)
+	default: // Up: pinged within the current interval
+		last = s.now.Add(-jitter())
+	}
+
+	oldest := s.now.AddDate(0, 0, -s.days)
+	receivedAt := last
+	for i := 0; i < seedMaxPingsPerCheck && receivedAt.After(oldest); i++ {
+		ping := &models.Ping{
+			CheckID:    check.ID,
+			ReceivedAt: receivedAt,
+			SourceIP:   sql.NullString{String: fmt.Sprintf("10.0.%d.%d", s.rng.Intn(256), s.rng.Intn(254)+1), Valid: true},
+			UserAgent:  sql.NullString{String: seedUserAgents[s.rng.Intn(len(seedUserAgents))], Valid: true},
+			CreatedAt:  receivedAt,
+		}
+		if s.rng.Intn(4) == 0 {
+			ping.Payload = sql.NullString{String: fmt.Sprintf(`{"exit_code": 0, "duration_ms": %d}`, s.rng.Intn(60000)), Valid: true}
+		}
+		if err := s.checkRepo.ImportPing(ctx, ping); err != nil {
+			return err
+		}
+		s.pings++
+		receivedAt = receivedAt.Add(-interval + jitter() - interval/20)
+	}
+	return nil
+}
+
+// confirm asks an interactive yes/no question on stdin.
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(answer) == "yes"
+}
+
+// wipeTables truncates seedTables on a single connection with foreign key checks off.
+func wipeTables(ctx context.Context, databasePool *sql.DB) error {
+	conn, err := databasePool.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "SET FOREIGN_KEY_CHECKS = 1")
+
+	for _, table := range seedTables {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE "+table); err != nil {
+			return fmt.Errorf("truncating %s: %w", table, err)
+		}
+	}
+	return nil
+}
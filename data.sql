@@ -31,11 +31,11 @@ INSERT INTO pings (check_id, received_at, source_ip, user_agent, payload, create
 
 
 -- Sample Notification Channels
-INSERT INTO notification_channels (id, user_id, type, value, label, is_verified, verification_token, is_enabled, deleted_at, created_at, updated_at) VALUES
-(1, 1, 'email', 'alice@example.com', 'Primary Email', TRUE, NULL, TRUE, NULL, '2025-04-01 09:00:00', '2025-04-01 10:00:00'), -- User 1, Email, Verified
-(2, 1, 'webhook', 'https://hooks.example.com/T123/B456/C789', 'Team Alert Hook', FALSE, 'verify_hook_abc123', TRUE, NULL, '2025-04-01 14:30:00', '2025-04-01 14:30:00'), -- User 1, Webhook, Unverified
-(3, 2, 'webhook', 'https://hooks.slack.com/services/X1/Y2/Z3', 'Bob Slack', TRUE, NULL, TRUE, NULL, '2025-04-01 11:10:00', '2025-04-01 11:15:00'), -- User 2, Slack (as webhook), Verified
-(4, 2, 'email', 'bob.secondary@example.net', 'Secondary Email', FALSE, 'verify_email_def456', FALSE, NULL, '2025-04-01 16:00:00', '2025-04-01 16:05:00'); -- User 2, Email, Unverified, Disabled
+INSERT INTO notification_channels (id, user_id, type, value, config, label, is_verified, verification_token, is_enabled, deleted_at, created_at, updated_at) VALUES
+(1, 1, 'email', 'alice@example.com', '{"address": "alice@example.com"}', 'Primary Email', TRUE, NULL, TRUE, NULL, '2025-04-01 09:00:00', '2025-04-01 10:00:00'), -- User 1, Email, Verified
+(2, 1, 'webhook', 'https://hooks.example.com/T123/B456/C789', '{"url": "https://hooks.example.com/T123/B456/C789"}', 'Team Alert Hook', FALSE, 'verify_hook_abc123', TRUE, NULL, '2025-04-01 14:30:00', '2025-04-01 14:30:00'), -- User 1, Webhook, Unverified
+(3, 2, 'slack', 'https://hooks.slack.com/services/X1/Y2/Z3', '{"webhook_url": "https://hooks.slack.com/services/X1/Y2/Z3"}', 'Bob Slack', TRUE, NULL, TRUE, NULL, '2025-04-01 11:10:00', '2025-04-01 11:15:00'), -- User 2, Slack, Verified
+(4, 2, 'email', 'bob.secondary@example.net', '{"address": "bob.secondary@example.net"}', 'Secondary Email', FALSE, 'verify_email_def456', FALSE, NULL, '2025-04-01 16:00:00', '2025-04-01 16:05:00'); -- User 2, Email, Unverified, Disabled
 
 -- Sample Assignments of Channels to Checks
 INSERT INTO check_notification_channel (check_id, notification_channel_id) VALUES
@@ -0,0 +1,128 @@
+// Package actionlink signs the one-click action links put in alerts, such as
+// "mute this check for 4 hours" in a down notification. A link is only good for
+// one action on one check, until it expires; it carries no credentials, so
+// whoever holds it (anyone the alert was forwarded to) can apply that action and
+// nothing else.
+//
+// A token is <payload>.<signature>, both base64url without padding. The payload
+// is "<check UUID>|<action>|<expiry, unix seconds>|<nonce>" and the signature is
+// an HMAC-SHA256 of it. The nonce lets single-use actions (see SingleUse) be
+// refused the second time; the repository remembers the nonces used.
+package actionlink
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions a link can carry.
+const (
+	// ActionMute silences the check's late and down notifications for one of
+	// MuteDurations, chosen on the confirmation page.
+	ActionMute = "mute"
+)
+
+// MuteDurations are the mute lengths offered, the first being the default.
+var MuteDurations = []time.Duration{4 * time.Hour, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour}
+
+// SingleUse reports whether a link for action may only be applied once. A mute
+// counts from the moment it is applied, so a second click would extend it.
+func SingleUse(action string) bool {
+	return action == ActionMute
+}
+
+// Verification failures. Both mean the link is refused.
+var (
+	ErrInvalid = errors.New("invalid action link")
+	ErrExpired = errors.New("action link expired")
+)
+
+// Token is a verified link's content.
+type Token struct {
+	CheckUUID string
+	Action    string // Action* constants
+	Expires   time.Time
+	Nonce     string
+}
+
+// Signer signs and verifies action links with one server secret. Rotating the
+// secret invalidates every link signed with the old one.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer. secret must not be empty.
+func NewSigner(secret string) (*Signer, error) {
+	if secret == "" {
+		return nil, errors.New("action link secret is empty")
+	}
+	return &Signer{secret: []byte(secret)}, nil
+}
+
+// Sign returns a token for action on the check with checkUUID, valid until
+// expires.
+func (s *Signer) Sign(checkUUID, action string, expires time.Time) (string, error) {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating action link nonce: %w", err)
+	}
+	payload := strings.Join([]string{
+		checkUUID, action, strconv.FormatInt(expires.Unix(), 10), base64.RawURLEncoding.EncodeToString(nonce),
+	}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + s.sign(payload), nil
+}
+
+func (s *Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// URL returns the link for action on checkUUID under baseURL (the instance's
+// public base URL), valid until expires.
+func (s *Signer) URL(baseURL, checkUUID, action string, expires time.Time) (string, error) {
+	token, err := s.Sign(checkUUID, action, expires)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(baseURL, "/") + "/actions/" + token, nil
+}
+
+// Verify checks token at time now and returns what it grants.
+func (s *Signer) Verify(token string, now time.Time) (Token, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Token{}, ErrInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Token{}, ErrInvalid
+	}
+	payload := string(raw)
+	// Compare before looking at the payload, so a forged one can't be told apart
+	// from any other bad signature
+	if !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return Token{}, ErrInvalid
+	}
+	fields := strings.Split(payload, "|")
+	if len(fields) != 4 || !slices.Contains([]string{ActionMute}, fields[1]) {
+		return Token{}, ErrInvalid
+	}
+	expUnix, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return Token{}, ErrInvalid
+	}
+	t := Token{CheckUUID: fields[0], Action: fields[1], Expires: time.Unix(expUnix, 0).UTC(), Nonce: fields[3]}
+	if !now.Before(t.Expires) {
+		return Token{}, ErrExpired
+	}
+	return t, nil
+}
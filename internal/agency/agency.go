@@ -2,7 +2,10 @@
 package agency
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // IsNumeric Checks if input s is int or float.
@@ -23,3 +26,85 @@ func Min(a, b int) int {
 	}
 	return b
 }
+
+// ParseDuration extends time.ParseDuration with a "d" (24h day) unit, so
+// retention settings can be written as "90d" or "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	if days, rest, found := strings.Cut(s, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total := time.Duration(n) * 24 * time.Hour
+		if rest != "" {
+			extra, err := time.ParseDuration(rest)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			total += extra
+		}
+		return total, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// isoDurationUnits are the units of ParseISODuration, in the order they must
+// appear. Years and months are left out: their length depends on the date.
+var isoDurationUnits = []struct {
+	unit   byte
+	inTime bool // After the "T"
+	size   time.Duration
+}{
+	{'W', false, 7 * 24 * time.Hour},
+	{'D', false, 24 * time.Hour},
+	{'H', true, time.Hour},
+	{'M', true, time.Minute},
+	{'S', true, time.Second},
+}
+
+// ParseISODuration parses an ISO 8601 duration with whole numbers of weeks,
+// days, hours, minutes and seconds, e.g. "P1D", "PT90M" or "P1DT12H".
+func ParseISODuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid ISO 8601 duration %q", s)
+	rest, ok := strings.CutPrefix(s, "P")
+	if !ok || rest == "" || rest == "T" {
+		return 0, invalid
+	}
+	var total time.Duration
+	next, inTime := 0, false
+	for rest != "" {
+		if rest[0] == 'T' && !inTime {
+			inTime, rest = true, rest[1:]
+			if rest == "" {
+				return 0, invalid
+			}
+			continue
+		}
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		if digits == 0 || digits == len(rest) {
+			return 0, invalid
+		}
+		n, err := strconv.ParseInt(rest[:digits], 10, 64)
+		if err != nil {
+			return 0, invalid
+		}
+		unit := rest[digits]
+		rest = rest[digits+1:]
+		for next < len(isoDurationUnits) && (isoDurationUnits[next].unit != unit || isoDurationUnits[next].inTime != inTime) {
+			next++
+		}
+		if next == len(isoDurationUnits) {
+			if unit == 'Y' || (unit == 'M' && !inTime) {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q: years and months have no fixed length, use days", s)
+			}
+			return 0, invalid
+		}
+		size := isoDurationUnits[next].size
+		if n > int64((1<<63-1-total)/size) {
+			return 0, invalid
+		}
+		total += time.Duration(n) * size
+		next++
+	}
+	return total, nil
+}
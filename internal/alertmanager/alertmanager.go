@@ -0,0 +1,248 @@
+// Package alertmanager delivers down and recovery notifications to Prometheus
+// Alertmanager, for checks with an 'alertmanager' notification channel. A check
+// going down posts a firing alert to the channel's /api/v2/alerts; its recovery
+// posts the same alert (same labels) with endsAt set, so Alertmanager does the
+// deduplication, grouping, silencing and routing. Late and slow notices are
+// not sent.
+//
+// Notifications raised together, such as a worker batch going down, are posted
+// in one request per channel (see Dispatcher.DispatchBatch).
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/version"
+)
+
+// AlertName is the alertname label of every alert.
+const AlertName = "BitterlinkCheckDown"
+
+// Config configures the Dispatcher. Zero values get the defaults noted.
+type Config struct {
+	Timeout time.Duration // Per request, default 10s
+	// SelfHosts are this instance's host names; channels pointing at them are
+	// refused, as for forward URLs.
+	SelfHosts []string
+	// Disabled is set when the 'alertmanager' type is turned off on this
+	// instance (NOTIFY_CHANNEL_TYPES): channels created before then are skipped
+	// and every notification goes straight to Next.
+	Disabled bool
+}
+
+// Store is the part of the channel repository the Dispatcher needs.
+type Store interface {
+	ListChannelTargets(ctx context.Context, kind string, checkIDs []int64) ([]models.ChannelTarget, error)
+	LogNotification(ctx context.Context, checkID, channelID int64, kind string, deliveryErr error) error
+}
+
+// Dispatcher posts alerts to the Alertmanager channels of the notified checks,
+// then passes every notification on to Next.
+type Dispatcher struct {
+	Next   notify.Dispatcher
+	store  Store
+	config Config
+	client *http.Client
+}
+
+// New creates a Dispatcher in front of next (nil: notify.LogDispatcher).
+func New(next notify.Dispatcher, store Store, cfg Config) *Dispatcher {
+	if next == nil {
+		next = notify.LogDispatcher{}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Dispatcher{
+		Next:   next,
+		store:  store,
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse // Alertmanager doesn't redirect its API
+			},
+		},
+	}
+}
+
+// Dispatch implements notify.Dispatcher.
+func (d *Dispatcher) Dispatch(ctx context.Context, n notify.Notification) error {
+	return d.DispatchBatch(ctx, []notify.Notification{n})[0]
+}
+
+// DispatchBatch implements notify.BatchDispatcher: one POST per channel for
+// all of ns. A notification fails, to be redelivered by the worker, when a
+// channel of its check failed with a network error or a 5xx; Alertmanager
+// deduplicates the alerts other channels then receive twice. A 4xx is the
+// channel's own problem (a wrong URL or credentials) and is only recorded in
+// notifications_log: retrying wouldn't help.
+func (d *Dispatcher) DispatchBatch(ctx context.Context, ns []notify.Notification) []error {
+	if d.config.Disabled {
+		return notify.DispatchAll(ctx, d.Next, ns)
+	}
+	errs := make([]error, len(ns))
+
+	// 1. Find the Alertmanager channels of the checks concerned
+	var checkIDs []int64
+	for _, n := range ns {
+		if n.Kind == notify.KindDown || n.Kind == notify.KindUp {
+			checkIDs = append(checkIDs, n.CheckID)
+		}
+	}
+	targets, err := d.store.ListChannelTargets(ctx, models.ChannelAlertmanager, checkIDs)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	// 2. Post each channel's alerts in one request
+	byChannel := make(map[int64][]models.ChannelTarget)
+	var channelOrder []int64
+	for _, t := range targets {
+		if _, seen := byChannel[t.ChannelID]; !seen {
+			channelOrder = append(channelOrder, t.ChannelID)
+		}
+		byChannel[t.ChannelID] = append(byChannel[t.ChannelID], t)
+	}
+	failedChecks := make(map[int64]error)
+	now := time.Now().UTC()
+	for _, channelID := range channelOrder {
+		channelTargets := byChannel[channelID]
+		var alerts []Alert
+		var sent []notify.Notification // Aligned with alerts
+		for _, n := range ns {
+			for _, t := range channelTargets {
+				if t.CheckID == n.CheckID && (n.Kind == notify.KindDown || n.Kind == notify.KindUp) {
+					alerts = append(alerts, newAlert(n, t, now))
+					sent = append(sent, n)
+				}
+			}
+		}
+		retry, err := d.post(ctx, channelTargets[0].Value, alerts)
+		if err != nil {
+			log.Printf("WARN: Posting %d alerts to Alertmanager channel %d failed (retry: %t): %v", len(alerts), channelID, retry, err)
+		}
+		for _, n := range sent {
+			if logErr := d.store.LogNotification(ctx, n.CheckID, channelID, n.Kind, err); logErr != nil {
+				log.Printf("WARN: Failed to log notification of check ID %d to channel %d: %v", n.CheckID, channelID, logErr)
+			}
+			if err != nil && retry {
+				failedChecks[n.CheckID] = fmt.Errorf("alertmanager channel %d: %w", channelID, err)
+			}
+		}
+	}
+
+	// 3. Pass the delivered ones on
+	var delivered []notify.Notification
+	var deliveredIndex []int // delivered[i] is ns[deliveredIndex[i]]
+	for i, n := range ns {
+		if err := failedChecks[n.CheckID]; err != nil {
+			errs[i] = err
+			continue
+		}
+		delivered = append(delivered, n)
+		deliveredIndex = append(deliveredIndex, i)
+	}
+	for i, err := range notify.DispatchAll(ctx, d.Next, delivered) {
+		errs[deliveredIndex[i]] = err
+	}
+	return errs
+}
+
+// TestChannel verifies a new channel pointing at channelURL by posting an empty
+// list of alerts, which Alertmanager accepts without changing anything. Any
+// failure, retryable or not, fails the test.
+func (d *Dispatcher) TestChannel(ctx context.Context, channelURL string) error {
+	_, err := d.post(ctx, channelURL, []Alert{})
+	return err
+}
+
+// Alert is one alert in Alertmanager's POST /api/v2/alerts body.
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    *time.Time        `json:"startsAt,omitempty"`
+	EndsAt      *time.Time        `json:"endsAt,omitempty"`
+}
+
+// newAlert builds n's alert for target: firing for a down notification,
+// resolved for a recovery. Both carry the same labels, which is how
+// Alertmanager matches them; the severity label is the check's, so changing it
+// during an outage leaves the firing alert to Alertmanager's resolve_timeout.
+func newAlert(n notify.Notification, target models.ChannelTarget, now time.Time) Alert {
+	alert := Alert{
+		Labels: map[string]string{
+			"alertname": AlertName,
+			"check":     target.CheckName,
+			"uuid":      target.CheckUUID,
+			"severity":  target.CheckSeverity,
+		},
+		Annotations: map[string]string{
+			"summary": fmt.Sprintf("Check %q is down", target.CheckName),
+		},
+	}
+	if n.Kind == notify.KindUp {
+		alert.EndsAt = &now
+	} else {
+		alert.StartsAt = &now
+	}
+	return alert
+}
+
+// post sends alerts to the Alertmanager at channelURL. Credentials in the URL
+// are sent as basic auth. retry reports whether a later attempt may succeed.
+func (d *Dispatcher) post(ctx context.Context, channelURL string, alerts []Alert) (retry bool, err error) {
+	if err := forward.ValidateURL(channelURL, d.config.SelfHosts); err != nil {
+		return false, err
+	}
+	target, err := url.Parse(channelURL)
+	if err != nil {
+		return false, fmt.Errorf("invalid Alertmanager URL: %w", err)
+	}
+	user := target.User
+	target.User = nil
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/api/v2/alerts"
+
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return false, fmt.Errorf("encoding alerts: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bitterlink-alertmanager/"+version.Version)
+	if user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("alertmanager returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("alertmanager returned %s", resp.Status)
+	}
+}
@@ -0,0 +1,79 @@
+package alertmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+)
+
+// fakeStore hands out fixed targets and counts lookups and logged deliveries.
+type fakeStore struct {
+	targets []models.ChannelTarget
+	lookups int
+	logged  int
+}
+
+func (s *fakeStore) ListChannelTargets(ctx context.Context, kind string, checkIDs []int64) ([]models.ChannelTarget, error) {
+	s.lookups++
+	return s.targets, nil
+}
+
+func (s *fakeStore) LogNotification(ctx context.Context, checkID, channelID int64, kind string, deliveryErr error) error {
+	s.logged++
+	return nil
+}
+
+// recordingDispatcher stands in for the rest of the chain.
+type recordingDispatcher struct {
+	got []notify.Notification
+}
+
+func (r *recordingDispatcher) Dispatch(ctx context.Context, n notify.Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+func TestDispatchBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		disabled  bool
+		wantPosts int32
+	}{
+		{name: "enabled", wantPosts: 1},
+		{name: "type disabled", disabled: true, wantPosts: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				posts.Add(1)
+			}))
+			defer server.Close()
+
+			store := &fakeStore{targets: []models.ChannelTarget{{ChannelID: 3, Value: server.URL, CheckID: 42, CheckUUID: "uuid-42"}}}
+			next := &recordingDispatcher{}
+			d := New(next, store, Config{Disabled: tt.disabled})
+
+			ns := []notify.Notification{{Kind: notify.KindDown, CheckID: 42, CheckUUID: "uuid-42"}}
+			for i, err := range d.DispatchBatch(context.Background(), ns) {
+				if err != nil {
+					t.Errorf("notification %d: %v", i, err)
+				}
+			}
+			if got := posts.Load(); got != tt.wantPosts {
+				t.Errorf("posts = %d, want %d", got, tt.wantPosts)
+			}
+			if tt.disabled && (store.lookups != 0 || store.logged != 0) {
+				t.Errorf("disabled dispatcher looked up %d times and logged %d deliveries, want none", store.lookups, store.logged)
+			}
+			if len(next.got) != 1 || next.got[0].CheckID != 42 {
+				t.Errorf("next got %v, want the notification of check 42", next.got)
+			}
+		})
+	}
+}
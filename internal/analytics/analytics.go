@@ -0,0 +1,86 @@
+// Package analytics copies ping events to a separate analytics database for
+// long-term aggregation, keeping that load off the primary.
+//
+// Everything here is best effort. A MetricsSink is told about a ping only after
+// the primary has committed it, and nothing it does (a full buffer, a dead
+// analytics server) can fail or slow down the ping.
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+// PingEvent is one recorded ping as the analytics database sees it.
+type PingEvent struct {
+	CheckUUID    string
+	Kind         string // models.PingKind*
+	ReceivedAt   time.Time
+	PayloadBytes int
+}
+
+// MetricsSink receives ping events after they are recorded. PingRecorded is
+// called on the ping's request path, so implementations must not block and have
+// no way to report errors; they drop events rather than wait.
+type MetricsSink interface {
+	PingRecorded(event PingEvent)
+}
+
+// NopSink discards events. It is the sink when no analytics database is set up.
+type NopSink struct{}
+
+// PingRecorded implements MetricsSink.
+func (NopSink) PingRecorded(PingEvent) {}
+
+// sinkingRepository passes successfully recorded pings on to a MetricsSink.
+type sinkingRepository struct {
+	repository.CheckRepository
+	sink MetricsSink
+}
+
+// WrapCheckRepository returns repo with RecordPing and RecordPingsBatch also
+// reporting each recorded ping to sink, after the primary transaction commits.
+// Every transport records pings through the repository, so this covers them all.
+func WrapCheckRepository(repo repository.CheckRepository, sink MetricsSink) repository.CheckRepository {
+	return &sinkingRepository{CheckRepository: repo, sink: sink}
+}
+
+// RecordPing implements repository.CheckRepository.
+func (r *sinkingRepository) RecordPing(ctx context.Context, ping repository.PingRecord) error {
+	if err := r.CheckRepository.RecordPing(ctx, ping); err != nil {
+		return err
+	}
+	r.sink.PingRecorded(eventFor(ping, time.Now()))
+	return nil
+}
+
+// RecordPingsBatch implements repository.CheckRepository.
+func (r *sinkingRepository) RecordPingsBatch(ctx context.Context, userID int64, pings []repository.PingRecord) ([]error, error) {
+	results, err := r.CheckRepository.RecordPingsBatch(ctx, userID, pings)
+	if err != nil {
+		return results, err
+	}
+	now := time.Now()
+	for i, result := range results {
+		if result == nil {
+			r.sink.PingRecorded(eventFor(pings[i], now))
+		}
+	}
+	return results, nil
+}
+
+func eventFor(ping repository.PingRecord, receivedAt time.Time) PingEvent {
+	kind := ping.Kind
+	if kind == "" {
+		kind = models.PingKindSuccess
+	}
+	return PingEvent{
+		CheckUUID:    ping.UUID,
+		Kind:         kind,
+		ReceivedAt:   receivedAt.UTC(),
+		PayloadBytes: len(ping.Payload),
+	}
+}
@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// MySQLSinkConfig configures MySQLSink. Zero values get the defaults noted.
+type MySQLSinkConfig struct {
+	BufferSize    int           // Events held before new ones are dropped, default 10000
+	BatchSize     int           // Rows per INSERT, default 500
+	FlushInterval time.Duration // Longest an event waits for its batch, default 5s
+	WriteTimeout  time.Duration // Per INSERT, default 10s
+}
+
+// MySQLSink appends ping events to the analytics database's ping_events table
+// (migrations/analytics). Events are buffered in memory and written in batches
+// by a single goroutine; if the buffer fills up because the analytics database
+// is slow or down, new events are dropped and counted.
+type MySQLSink struct {
+	db      *sql.DB
+	config  MySQLSinkConfig
+	events  chan PingEvent
+	dropped atomic.Int64
+}
+
+// NewMySQLSink creates a sink writing to analyticsDB. Call Start to run it.
+func NewMySQLSink(analyticsDB *sql.DB, cfg MySQLSinkConfig) *MySQLSink {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	return &MySQLSink{db: analyticsDB, config: cfg, events: make(chan PingEvent, cfg.BufferSize)}
+}
+
+// PingRecorded implements MetricsSink. It never blocks.
+func (s *MySQLSink) PingRecorded(event PingEvent) {
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Start writes buffered events until ctx is cancelled, then makes one last
+// attempt to write what is still buffered.
+func (s *MySQLSink) Start(ctx context.Context) {
+	log.Printf("INFO: Analytics sink started (batch %d, flush every %s).", s.config.BatchSize, s.config.FlushInterval)
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]PingEvent, 0, s.config.BatchSize)
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) >= s.config.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		case <-ctx.Done():
+			// Write out what is buffered, giving up at the first failure so a dead
+			// analytics server can't hold up shutdown for every remaining batch
+			for {
+				for len(s.events) > 0 && len(batch) < s.config.BatchSize {
+					batch = append(batch, <-s.events)
+				}
+				if len(batch) == 0 || s.flush(batch) != nil {
+					break
+				}
+				batch = batch[:0]
+			}
+			if pending := len(s.events); pending > 0 {
+				log.Printf("WARN: Analytics sink stopped with %d events unwritten.", pending)
+			}
+			log.Println("INFO: Analytics sink stopped.")
+			return
+		}
+	}
+}
+
+// flush writes batch, logging (not retrying) a failure. Losing a batch is
+// acceptable; the primary keeps every ping.
+func (s *MySQLSink) flush(batch []PingEvent) error {
+	if dropped := s.dropped.Swap(0); dropped > 0 {
+		log.Printf("WARN: Analytics sink buffer full, dropped %d ping events.", dropped)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	// The pings' own contexts are long gone, and shutdown has cancelled ctx;
+	// each write gets its own deadline instead
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteTimeout)
+	defer cancel()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?),", len(batch)), ",")
+	args := make([]any, 0, len(batch)*4)
+	for _, event := range batch {
+		args = append(args, event.CheckUUID, event.Kind, event.ReceivedAt, event.PayloadBytes)
+	}
+	query := `INSERT INTO ping_events (check_uuid, kind, received_at, payload_bytes) VALUES ` + placeholders
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		log.Printf("WARN: Analytics sink failed to write %d ping events: %v", len(batch), err)
+		return fmt.Errorf("inserting ping_events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package blackout is the global notification kill-switch. During a major
+// incident, when everything goes down at once, an admin turns it on (PUT
+// /api/v1/admin/notification-blackout) and no notification goes out for any
+// check until it is turned off. Statuses still change and are recorded as
+// usual; suppressed notifications are logged, not sent later. When it is
+// turned off with send_summary, one worker sends a single summary of the
+// checks whose status changed meanwhile.
+//
+// The switch lives in the database (notification_blackouts), so every worker
+// instance sees it within Config.RefreshInterval.
+package blackout
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+)
+
+// Config configures the Dispatcher. Zero values get the defaults noted.
+type Config struct {
+	RefreshInterval time.Duration // How long the switch's state is reused, default 10s
+	SummaryLimit    int           // Checks listed in a summary, default 100
+}
+
+// Store is the part of the check repository the Dispatcher needs.
+type Store interface {
+	ActiveBlackout(ctx context.Context) (*models.NotificationBlackout, error)
+	ClaimBlackoutSummary(ctx context.Context) (*models.NotificationBlackout, error)
+	ListBlackoutChanges(ctx context.Context, b *models.NotificationBlackout, limit int) ([]models.BlackoutChange, error)
+}
+
+// Dispatcher drops notifications while a blackout is on, and passes them on to
+// Next otherwise. It goes in front of every other dispatcher, so nothing gets
+// past it.
+type Dispatcher struct {
+	Next   notify.Dispatcher
+	store  Store
+	config Config
+
+	mu        sync.Mutex
+	active    *models.NotificationBlackout // nil when off
+	checkedAt time.Time
+}
+
+// New creates a Dispatcher in front of next (nil: notify.LogDispatcher).
+func New(next notify.Dispatcher, store Store, cfg Config) *Dispatcher {
+	if next == nil {
+		next = notify.LogDispatcher{}
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 10 * time.Second
+	}
+	if cfg.SummaryLimit <= 0 {
+		cfg.SummaryLimit = 100
+	}
+	return &Dispatcher{Next: next, store: store, config: cfg}
+}
+
+// Dispatch implements notify.Dispatcher.
+func (d *Dispatcher) Dispatch(ctx context.Context, n notify.Notification) error {
+	if d.suppress(ctx, n) {
+		return nil
+	}
+	return d.Next.Dispatch(ctx, n)
+}
+
+// DispatchBatch implements notify.BatchDispatcher.
+func (d *Dispatcher) DispatchBatch(ctx context.Context, ns []notify.Notification) []error {
+	if len(ns) > 0 && d.blackout(ctx) != nil {
+		for _, n := range ns {
+			d.suppress(ctx, n)
+		}
+		return make([]error, len(ns))
+	}
+	return notify.DispatchAll(ctx, d.Next, ns)
+}
+
+// suppress reports whether n is dropped by a blackout, logging it if so. The
+// notification counts as handed off: pending and outbox entries are cleared,
+// not redelivered after the blackout.
+func (d *Dispatcher) suppress(ctx context.Context, n notify.Notification) bool {
+	b := d.blackout(ctx)
+	if b == nil {
+		return false
+	}
+	log.Printf("INFO: Notification blackout %d: not sending '%s' notification for check ID %d (cycle %s)", b.ID, n.Kind, n.CheckID, n.CycleID)
+	metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:blackout")
+	return true
+}
+
+// blackout returns the blackout that is on, nil when none is, reading the
+// switch again once RefreshInterval has passed. When it can't be read the last
+// known state holds: an unreachable database doesn't end a blackout.
+func (d *Dispatcher) blackout(ctx context.Context) *models.NotificationBlackout {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if time.Since(d.checkedAt) < d.config.RefreshInterval {
+		return d.active
+	}
+	b, err := d.store.ActiveBlackout(ctx)
+	if err != nil {
+		log.Printf("WARN: Failed to read the notification blackout switch, keeping it %s: %v", onOff(d.active), err)
+		return d.active
+	}
+	switch {
+	case b != nil && d.active == nil:
+		log.Printf("WARN: Notification blackout %d on since %s (user %d, reason %q): no notifications are sent",
+			b.ID, b.StartedAt.Format(time.RFC3339), b.StartedBy, b.Reason.String)
+	case b == nil && d.active != nil:
+		log.Printf("INFO: Notification blackout %d over, sending notifications again", d.active.ID)
+	}
+	d.active, d.checkedAt = b, time.Now()
+	return b
+}
+
+func onOff(b *models.NotificationBlackout) string {
+	if b == nil {
+		return "off"
+	}
+	return "on"
+}
+
+// Start reads the switch every RefreshInterval, so its changes are logged even
+// while nothing is notified, and sends the summaries of ended blackouts. It
+// returns when ctx is cancelled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		d.blackout(ctx)
+		if err := d.sendSummary(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("ERROR: Failed to send notification blackout summary: %v", err)
+		}
+	}
+}
+
+// sendSummary sends the summary of one ended blackout that asked for it, if
+// any. It is claimed first, so of several workers only one sends it, and a
+// failure to list the changes after the claim is not retried.
+func (d *Dispatcher) sendSummary(ctx context.Context) error {
+	b, err := d.store.ClaimBlackoutSummary(ctx)
+	if err != nil || b == nil {
+		return err
+	}
+	changes, err := d.store.ListBlackoutChanges(ctx, b, d.config.SummaryLimit+1)
+	if err != nil {
+		return fmt.Errorf("listing the changes of blackout %d: %w", b.ID, err)
+	}
+	log.Printf("INFO: %s", Summary(b, changes, d.config.SummaryLimit))
+	metrics.Incr(metrics.NotificationsDispatched, "kind:blackout_summary", "outcome:ok")
+	return nil
+}
+
+// Summary describes b and the status changes during it in one line, listing up
+// to limit checks.
+func Summary(b *models.NotificationBlackout, changes []models.BlackoutChange, limit int) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Notification blackout %d summary (%s to %s): ", b.ID,
+		b.StartedAt.Format(time.RFC3339), b.EndedAt.Time.Format(time.RFC3339))
+	if len(changes) == 0 {
+		s.WriteString("no check changed status")
+		return s.String()
+	}
+	if len(changes) > limit {
+		fmt.Fprintf(&s, "more than %d checks changed status: ", limit)
+	} else {
+		fmt.Fprintf(&s, "%d checks changed status: ", len(changes))
+	}
+	for i, change := range changes[:min(len(changes), limit)] {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+		fmt.Fprintf(&s, "%q (%s) %s -> %s", change.Name, change.CheckUUID, change.FromStatus, change.ToStatus)
+	}
+	return s.String()
+}
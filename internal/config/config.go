@@ -3,6 +3,9 @@ package config
 
 import (
 	"log"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,4 +17,79 @@ func LoadEnv() {
 	} else {
 		log.Printf("INFO: Loaded configuration from .env file.")
 	}
-}
\ No newline at end of file
+}
+
+// GetString returns the environment variable named by key, or def if it is unset or empty.
+func GetString(key, def string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// GetInt returns the environment variable named by key parsed as an int.
+// Unset values return def; unparsable values log a warning and return def.
+func GetInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("WARN: %s=%q is not a valid integer, using default %d", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// GetBool returns the environment variable named by key parsed as a bool
+// (accepts 1/0, true/false, etc. as understood by strconv.ParseBool).
+// Unset values return def; unparsable values log a warning and return def.
+func GetBool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("WARN: %s=%q is not a valid boolean, using default %t", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// GetFloat returns the environment variable named by key parsed as a float64.
+// Unset values return def; unparsable values log a warning and return def.
+func GetFloat(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("WARN: %s=%q is not a valid number, using default %g", key, value, def)
+		return def
+	}
+	return parsed
+}
+
+// GetDuration returns the environment variable named by key parsed as a
+// duration, e.g. "30s" or "2m"; a bare integer is taken as seconds, like the
+// *_SECONDS settings. Unset values return def; unparsable values log a warning
+// and return def.
+func GetDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("WARN: %s=%q is not a valid duration, using default %s", key, value, def)
+		return def
+	}
+	return parsed
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// The primary pool (ConnectDB) dials through a circuit breaker. While MySQL is
+// down every request would otherwise wait out a full connection attempt, tying
+// up goroutines and connections; once Threshold dials in a row fail with a
+// connection error the breaker opens, and until the database answers again:
+//
+//  1. Dials fail at once with ErrCircuitOpen.
+//  2. CircuitReady reports false, so HTTP and gRPC requests are refused with
+//     503 / UNAVAILABLE before they reach the database, and the timeout
+//     checker skips its ticks.
+//  3. MonitorCircuit probes the database every ProbeInterval (half-open); the
+//     first answer closes the breaker.
+//
+// Only dials are watched, and only connection errors count, so a bad query
+// can't open the breaker. Each opening and closing is logged once.
+
+// ErrCircuitOpen is returned instead of dialing while the breaker is open.
+var ErrCircuitOpen = errors.New("database circuit breaker is open")
+
+// Circuit states, as reported on /health/ready.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open" // A probe is in flight
+)
+
+// CircuitConfig configures the breaker. Zero values get the defaults noted.
+type CircuitConfig struct {
+	Threshold     int           // Consecutive failed dials that open the breaker, default 5
+	ProbeInterval time.Duration // Between probes while open, default 5s
+}
+
+// circuit is the breaker of the primary pool.
+var circuit = struct {
+	mu       sync.Mutex
+	config   CircuitConfig
+	state    string
+	failures int
+	openedAt time.Time
+	probeAt  time.Time // Next probe, while open
+}{config: CircuitConfig{Threshold: 5, ProbeInterval: 5 * time.Second}, state: CircuitClosed}
+
+// ConfigureCircuit sets the breaker's thresholds. Call it before ConnectDB.
+func ConfigureCircuit(cfg CircuitConfig) {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = 5
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = 5 * time.Second
+	}
+	circuit.mu.Lock()
+	circuit.config = cfg
+	circuit.mu.Unlock()
+}
+
+// CircuitState returns the breaker's state (Circuit*) and, unless it is
+// closed, since when it has been open.
+func CircuitState() (state string, openedAt time.Time) {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+	return circuit.state, circuit.openedAt
+}
+
+// CircuitReady reports whether requests should go to the database. When not,
+// retryAfter is how long until the next probe, at least a second.
+func CircuitReady() (ready bool, retryAfter time.Duration) {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+	if circuit.state == CircuitClosed {
+		return true, 0
+	}
+	return false, max(time.Until(circuit.probeAt), time.Second)
+}
+
+// IsConnectionError reports whether err, from a dial, means the database
+// couldn't be reached or took no more connections, as opposed to refusing
+// this one (bad credentials, unknown database).
+func IsConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1040, // ER_CON_COUNT_ERROR, too many connections
+			1053, // ER_SERVER_SHUTDOWN
+			1129, // ER_HOST_IS_BLOCKED
+			1203: // ER_TOO_MANY_USER_CONNECTIONS
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// record updates the breaker with the outcome of a dial or probe: whether the
+// database failed to answer, and with what.
+func record(failed bool, err error) {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+	if !failed {
+		if circuit.state != CircuitClosed {
+			log.Printf("INFO: Database reachable again, circuit breaker closed after %s open", time.Since(circuit.openedAt).Round(time.Second))
+		}
+		circuit.state, circuit.failures, circuit.openedAt = CircuitClosed, 0, time.Time{}
+		return
+	}
+	circuit.failures++
+	switch circuit.state {
+	case CircuitClosed:
+		if circuit.failures < circuit.config.Threshold {
+			return
+		}
+		log.Printf("WARN: Database circuit breaker open after %d failed connection attempts, failing requests fast until it answers again: %v",
+			circuit.failures, err)
+		circuit.state, circuit.openedAt = CircuitOpen, time.Now()
+		circuit.probeAt = time.Now().Add(circuit.config.ProbeInterval)
+	case CircuitHalfOpen:
+		circuit.state = CircuitOpen
+		circuit.probeAt = time.Now().Add(circuit.config.ProbeInterval)
+	}
+}
+
+// allowDial reports whether a dial may go ahead: not while the breaker is open.
+// Half-open, the probe needs to dial.
+func allowDial() bool {
+	circuit.mu.Lock()
+	defer circuit.mu.Unlock()
+	return circuit.state != CircuitOpen
+}
+
+// circuitConnector dials through the breaker.
+type circuitConnector struct {
+	driver.Connector
+}
+
+func (c circuitConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if !allowDial() {
+		return nil, ErrCircuitOpen
+	}
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return nil, err // The caller went away, which says nothing about the database
+	}
+	record(err != nil && IsConnectionError(err), err)
+	return conn, err
+}
+
+// MonitorCircuit probes dbPool every ProbeInterval while the breaker is open,
+// until ctx is cancelled. A probe that gets any answer closes it.
+func MonitorCircuit(ctx context.Context, dbPool *sql.DB) {
+	circuit.mu.Lock()
+	interval := circuit.config.ProbeInterval
+	circuit.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		circuit.mu.Lock()
+		due := circuit.state == CircuitOpen
+		if due {
+			circuit.state = CircuitHalfOpen
+		}
+		circuit.mu.Unlock()
+		if !due {
+			continue
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, interval)
+		err := dbPool.PingContext(probeCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		// A timeout waiting for a pooled connection is no answer either
+		failed := IsConnectionError(err) || errors.Is(err, context.DeadlineExceeded)
+		if failed {
+			log.Printf("DEBUG: Database circuit breaker probe failed: %v", err)
+		}
+		record(failed, err)
+	}
+}
@@ -4,58 +4,126 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 const MaxOpenMySQLConnections = 25
 const MaxIdleMySQLConnections = MaxOpenMySQLConnections
 const MySQLConnectionMaxLifetime = 10 * time.Minute
 
-func ConnectDB() (*sql.DB, error) {
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
+// settings are the connection parameters read from the environment.
+type settings struct {
+	user, password, host, port, name string
+}
+
+// loadSettings reads DB_* variables, falling back to local development defaults.
+// When warn is set, each defaulted variable is logged.
+func loadSettings(warn bool) settings {
+	s := settings{
+		user:     os.Getenv("DB_USER"),
+		password: os.Getenv("DB_PASSWORD"),
+		host:     os.Getenv("DB_HOST"),
+		port:     os.Getenv("DB_PORT"),
+		name:     os.Getenv("DB_NAME"),
+	}
+	warnf := func(format string, args ...any) {
+		if warn {
+			log.Printf(format, args...)
+		}
+	}
 
 	// --- Provide Defaults (Optional, useful for local dev) ---
-	if dbUser == "" {
-		dbUser = "admin" // Replace with your local user if needed
-		log.Println("WARN: DB_USER not set, using default 'admin'")
+	if s.user == "" {
+		s.user = "admin" // Replace with your local user if needed
+		warnf("WARN: DB_USER not set, using default 'admin'")
 	}
-	if dbPassword == "" {
-		dbPassword = "a"
-		log.Println("WARN: DB_PASSWORD not set, using default (CHANGE THIS)")
+	if s.password == "" {
+		s.password = "a"
+		warnf("WARN: DB_PASSWORD not set, using default (CHANGE THIS)")
 	}
-	if dbHost == "" {
-		dbHost = "127.0.0.1" 
-		log.Println("WARN: DB_HOST not set, using default '127.0.0.1'")
+	if s.host == "" {
+		s.host = "127.0.0.1"
+		warnf("WARN: DB_HOST not set, using default '127.0.0.1'")
 	}
-	if dbPort == "" {
-		dbPort = "3306"
-		log.Println("WARN: DB_PORT not set, using default '3306'")
+	if s.port == "" {
+		s.port = "3306"
+		warnf("WARN: DB_PORT not set, using default '3306'")
 	}
-	if dbName == "" {
-		dbName = "ping" 
-		log.Println("WARN: DB_NAME not set, using default 'ping'")
+	if s.name == "" {
+		s.name = "ping"
+		warnf("WARN: DB_NAME not set, using default 'ping'")
 	}
+	return s
+}
+
+// Target describes the configured database as user@host:port/name, without the
+// password, for logging.
+func Target() string {
+	s := loadSettings(false)
+	return fmt.Sprintf("%s@%s:%s/%s", s.user, s.host, s.port, s.name)
+}
+
+func ConnectDB() (*sql.DB, error) {
+	s := loadSettings(true)
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+		s.user, s.password, s.host, s.port, s.name)
 
-	dbPool, err := sql.Open("mysql", dsn)
+	dbPool, err := open(dsn, MaxOpenMySQLConnections, MaxIdleMySQLConnections, true)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("INFO: Database connection pool established successfully.")
+	return dbPool, nil
+}
+
+// MaxOpenAnalyticsConnections is small on purpose: analytics writes are batched
+// by a single goroutine and must never compete with the primary for resources.
+const MaxOpenAnalyticsConnections = 4
+
+// ConnectAnalyticsDB opens the optional analytics database from a full
+// go-sql-driver DSN (ANALYTICS_DB_DSN). It is a separate server or schema from
+// the primary and only sees best-effort writes.
+func ConnectAnalyticsDB(dsn string) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid analytics DSN: %w", err)
+	}
+	cfg.ParseTime = true
+
+	dbPool, err := open(cfg.FormatDSN(), MaxOpenAnalyticsConnections, MaxOpenAnalyticsConnections, false)
+	if err != nil {
+		return nil, fmt.Errorf("analytics database: %w", err)
+	}
+	log.Printf("INFO: Analytics database connection pool established (%s@%s/%s).", cfg.User, cfg.Addr, cfg.DBName)
+	return dbPool, nil
+}
+
+// open creates a pool with the shared settings and checks it can connect.
+// withCircuit dials it through the circuit breaker (circuit.go).
+func open(dsn string, maxOpen, maxIdle int, withCircuit bool) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	var connector driver.Connector
+	if err == nil {
+		connector, err = mysql.NewConnector(cfg)
+	}
 	if err != nil {
 		log.Printf("ERROR: Failed to prepare database connection pool: %v", err)
 		return nil, fmt.Errorf("failed to prepare database connection pool: %w", err)
 	}
+	if withCircuit {
+		connector = circuitConnector{connector}
+	}
+	dbPool := sql.OpenDB(connector)
 
-	dbPool.SetMaxOpenConns(MaxOpenMySQLConnections)
-	dbPool.SetMaxIdleConns(MaxIdleMySQLConnections)
+	dbPool.SetMaxOpenConns(maxOpen)
+	dbPool.SetMaxIdleConns(maxIdle)
 	dbPool.SetConnMaxLifetime(MySQLConnectionMaxLifetime)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -63,14 +131,11 @@ func ConnectDB() (*sql.DB, error) {
 
 	err = dbPool.PingContext(ctx)
 	if err != nil {
-		err := dbPool.Close()
-		if err != nil {
-			return nil, err
+		if closeErr := dbPool.Close(); closeErr != nil {
+			return nil, closeErr
 		}
 		log.Printf("ERROR: Failed to connect to database: %v", err)
 		return nil, fmt.Errorf("database connection failed: %w", err)
 	}
-
-	log.Println("INFO: Database connection pool established successfully.")
 	return dbPool, nil
-}
\ No newline at end of file
+}
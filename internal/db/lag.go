@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MaxOpenReplicaConnections: a replica is only asked for its lag, one query at
+// a time.
+const MaxOpenReplicaConnections = 1
+
+// ConnectReplicaDB opens a read replica from a full go-sql-driver DSN (one of
+// REPLICA_DSNS), only to watch its replication lag. The account needs the
+// REPLICATION CLIENT privilege.
+func ConnectReplicaDB(dsn string) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replica DSN: %w", err)
+	}
+	dbPool, err := open(cfg.FormatDSN(), MaxOpenReplicaConnections, MaxOpenReplicaConnections, false)
+	if err != nil {
+		return nil, fmt.Errorf("replica %s: %w", cfg.Addr, err)
+	}
+	log.Printf("INFO: Replica connection established (%s@%s) for replication lag checks.", cfg.User, cfg.Addr)
+	return dbPool, nil
+}
+
+// ReplicationLag returns how far replica is behind its source
+// (Seconds_Behind_Source, Seconds_Behind_Master before MySQL 8.0.22). ok is
+// false when the lag is unknown: replication is stopped or broken, or the
+// server is not a replica.
+func ReplicationLag(ctx context.Context, replica *sql.DB) (lag time.Duration, ok bool, err error) {
+	// SHOW SLAVE STATUS is gone from MySQL 8.4; SHOW REPLICA STATUS is new in
+	// 8.0.22. Try the new one first.
+	rows, err := replica.QueryContext(ctx, `SHOW REPLICA STATUS`)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1064 { // Syntax error: an older server
+		rows, err = replica.QueryContext(ctx, `SHOW SLAVE STATUS`)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("reading replica status: %w", err)
+	}
+	defer rows.Close()
+
+	// The statement returns dozens of columns, which differ between versions
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, false, fmt.Errorf("reading replica status columns: %w", err)
+	}
+	if !rows.Next() {
+		return 0, false, rows.Err() // Not a replica
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, false, fmt.Errorf("scanning replica status: %w", err)
+	}
+	for i, column := range columns {
+		if !strings.EqualFold(column, "Seconds_Behind_Source") && !strings.EqualFold(column, "Seconds_Behind_Master") {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, false, nil // Replication not running
+		}
+		var seconds int64
+		if _, err := fmt.Sscan(values[i].String, &seconds); err != nil {
+			return 0, false, fmt.Errorf("parsing %s %q: %w", column, values[i].String, err)
+		}
+		return time.Duration(seconds) * time.Second, true, nil
+	}
+	return 0, false, errors.New("replica status has no Seconds_Behind_Source column")
+}
+
+// LagThrottleConfig configures a LagThrottle. Zero values get the defaults noted.
+type LagThrottleConfig struct {
+	MaxLag        time.Duration // Wait while a replica is further behind, default 10s
+	CheckInterval time.Duration // How long a reading is trusted before asking again, default 5s
+}
+
+// LagThrottle holds back batched deletes while a read replica lags, so pruning
+// doesn't leave replicas serving stale reads. A replica whose lag is unknown,
+// or that can't be reached, doesn't hold anything back: it is logged, and the
+// batches go on at the pace their pause sets. Not safe for concurrent use;
+// each deleting loop gets its own.
+type LagThrottle struct {
+	replicas  []*sql.DB
+	config    LagThrottleConfig
+	checkedAt time.Time // Of the last reading within MaxLag
+}
+
+// NewLagThrottle creates a throttle watching replicas. With no replicas it
+// never waits.
+func NewLagThrottle(replicas []*sql.DB, cfg LagThrottleConfig) *LagThrottle {
+	if cfg.MaxLag <= 0 {
+		cfg.MaxLag = 10 * time.Second
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Second
+	}
+	return &LagThrottle{replicas: replicas, config: cfg}
+}
+
+// Wait returns once every replica is within MaxLag, checking again every
+// CheckInterval while one isn't, or with ctx's error when it is cancelled
+// first. Call it between batches. A nil throttle never waits.
+func (t *LagThrottle) Wait(ctx context.Context) error {
+	if t == nil || len(t.replicas) == 0 || time.Since(t.checkedAt) < t.config.CheckInterval {
+		return nil
+	}
+	throttled := false
+	for {
+		lag := t.maxLag(ctx)
+		metrics.Default().Gauge(metrics.DBReplicationLag, lag.Seconds())
+		if lag <= t.config.MaxLag {
+			if throttled {
+				log.Printf("INFO: Replication lag down to %s, resuming deletes", lag)
+			}
+			t.checkedAt = time.Now()
+			return nil
+		}
+		if !throttled {
+			log.Printf("WARN: Replication lag %s exceeds %s, pausing deletes until replicas catch up", lag, t.config.MaxLag)
+			throttled = true
+		}
+		select {
+		case <-time.After(t.config.CheckInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// maxLag returns the greatest known lag among the replicas.
+func (t *LagThrottle) maxLag(ctx context.Context) time.Duration {
+	var worst time.Duration
+	for i, replica := range t.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		lag, ok, err := ReplicationLag(checkCtx, replica)
+		cancel()
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				log.Printf("WARN: Replication lag check of replica %d failed, not throttling on it: %v", i+1, err)
+			}
+		case !ok:
+			log.Printf("WARN: Replica %d reports no replication lag (replication stopped?), not throttling on it", i+1)
+		default:
+			worst = max(worst, lag)
+		}
+	}
+	return worst
+}
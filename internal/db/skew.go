@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// SkewConfig configures MonitorSkew. Zero values get the defaults noted.
+type SkewConfig struct {
+	Interval      time.Duration // Between probes, default 1h
+	WarnThreshold time.Duration // Skew logged as a warning and reported on /health, default 2s
+}
+
+// skewState is the latest measurement, read by /health, metrics and the worker.
+var skewState struct {
+	mu         sync.Mutex
+	skew       time.Duration
+	measuredAt time.Time
+	threshold  time.Duration
+}
+
+// ClockFunc reads a clock. Tests substitute fakes for both sides of MeasureSkew.
+type ClockFunc func(ctx context.Context) (time.Time, error)
+
+// DatabaseClock reads the database server's clock. It asks for a Unix timestamp
+// rather than UTC_TIMESTAMP() so the DSN's loc setting can't shift the result.
+func DatabaseClock(dbPool *sql.DB) ClockFunc {
+	return func(ctx context.Context) (time.Time, error) {
+		var seconds float64
+		if err := dbPool.QueryRowContext(ctx, `SELECT UNIX_TIMESTAMP(NOW(6))`).Scan(&seconds); err != nil {
+			return time.Time{}, fmt.Errorf("reading database clock: %w", err)
+		}
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(frac*1e9)), nil
+	}
+}
+
+// MeasureSkew returns how far the database clock (dbNow) is ahead of ours (now),
+// negative when it is behind. The database reading is assumed to be taken half
+// way through the round trip, which is also returned; a long round trip makes
+// the measurement correspondingly less precise.
+func MeasureSkew(ctx context.Context, dbNow ClockFunc, now func() time.Time) (skew, rtt time.Duration, err error) {
+	before := now()
+	dbTime, err := dbNow(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	after := now()
+	rtt = after.Sub(before)
+	midpoint := before.Add(rtt / 2)
+	return dbTime.Sub(midpoint), rtt, nil
+}
+
+// MonitorSkew measures the skew between the database clock and ours right away
+// and then every cfg.Interval until ctx is cancelled, keeping the result for
+// Skew and logging a warning whenever it exceeds cfg.WarnThreshold.
+//
+// The timeout checker compares timestamps inside the database, but anything
+// that compares a database timestamp with time.Now() here (API responses,
+// imports) is off by the skew, which otherwise goes unnoticed.
+func MonitorSkew(ctx context.Context, dbPool *sql.DB, cfg SkewConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.WarnThreshold <= 0 {
+		cfg.WarnThreshold = 2 * time.Second
+	}
+	skewState.mu.Lock()
+	skewState.threshold = cfg.WarnThreshold
+	skewState.mu.Unlock()
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		probeSkew(ctx, DatabaseClock(dbPool), cfg.WarnThreshold)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func probeSkew(ctx context.Context, dbNow ClockFunc, threshold time.Duration) {
+	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	skew, rtt, err := MeasureSkew(probeCtx, dbNow, time.Now)
+	if err != nil {
+		log.Printf("WARN: Clock skew probe failed: %v", err)
+		return
+	}
+
+	skewState.mu.Lock()
+	skewState.skew, skewState.measuredAt = skew, time.Now()
+	skewState.mu.Unlock()
+
+	if skew.Abs() > threshold {
+		log.Printf("WARN: Database clock is %s %s ours (threshold %s, round trip %s); check NTP on both hosts",
+			skew.Abs().Round(time.Millisecond), aheadOrBehind(skew), threshold, rtt.Round(time.Millisecond))
+	} else {
+		log.Printf("INFO: Database clock skew %s (round trip %s)", skew.Round(time.Millisecond), rtt.Round(time.Millisecond))
+	}
+}
+
+func aheadOrBehind(skew time.Duration) string {
+	if skew < 0 {
+		return "behind"
+	}
+	return "ahead of"
+}
+
+// Skew returns the latest measured skew (database clock minus ours) and when
+// it was measured; measuredAt is zero until the first successful probe.
+// exceeded reports whether it is beyond the configured warning threshold.
+func Skew() (skew time.Duration, measuredAt time.Time, exceeded bool) {
+	skewState.mu.Lock()
+	defer skewState.mu.Unlock()
+	if skewState.measuredAt.IsZero() {
+		return 0, time.Time{}, false
+	}
+	return skewState.skew, skewState.measuredAt, skewState.skew.Abs() > skewState.threshold
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// steppingClock returns start, then start+step, and so on, standing in for
+// time.Now on our side of a probe.
+func steppingClock(start time.Time, step time.Duration) func() time.Time {
+	next := start
+	return func() time.Time {
+		t := next
+		next = next.Add(step)
+		return t
+	}
+}
+
+func TestMeasureSkew(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		dbTime   time.Time
+		wantSkew time.Duration
+	}{
+		{name: "in step", dbTime: base.Add(50 * time.Millisecond), wantSkew: 0},
+		{name: "database ahead", dbTime: base.Add(3*time.Second + 50*time.Millisecond), wantSkew: 3 * time.Second},
+		{name: "database behind", dbTime: base.Add(-2*time.Second + 50*time.Millisecond), wantSkew: -2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbNow := func(context.Context) (time.Time, error) { return tt.dbTime, nil }
+			skew, rtt, err := MeasureSkew(context.Background(), dbNow, steppingClock(base, 100*time.Millisecond))
+			if err != nil {
+				t.Fatalf("MeasureSkew: %v", err)
+			}
+			if skew != tt.wantSkew {
+				t.Errorf("skew = %s, want %s", skew, tt.wantSkew)
+			}
+			if rtt != 100*time.Millisecond {
+				t.Errorf("rtt = %s, want 100ms", rtt)
+			}
+		})
+	}
+}
+
+func TestMeasureSkewClockError(t *testing.T) {
+	wantErr := errors.New("connection refused")
+	dbNow := func(context.Context) (time.Time, error) { return time.Time{}, wantErr }
+	if _, _, err := MeasureSkew(context.Background(), dbNow, time.Now); !errors.Is(err, wantErr) {
+		t.Fatalf("err = %v, want %v", err, wantErr)
+	}
+}
+
+func TestDatabaseClock(t *testing.T) {
+	dbPool, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer dbPool.Close()
+	mock.ExpectQuery(`SELECT UNIX_TIMESTAMP\(NOW\(6\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"ts"}).AddRow(1709294400.25))
+
+	got, err := DatabaseClock(dbPool)(context.Background())
+	if err != nil {
+		t.Fatalf("DatabaseClock: %v", err)
+	}
+	want := time.Unix(1709294400, 250_000_000)
+	if !got.Equal(want) {
+		t.Errorf("clock = %s, want %s", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestProbeSkew feeds the probe a database clock a fixed distance from ours
+// and checks what Skew reports against a 2s threshold.
+func TestProbeSkew(t *testing.T) {
+	tests := []struct {
+		name         string
+		offset       time.Duration
+		wantExceeded bool
+	}{
+		{name: "within threshold", offset: 500 * time.Millisecond},
+		{name: "ahead beyond threshold", offset: 10 * time.Second, wantExceeded: true},
+		{name: "behind beyond threshold", offset: -10 * time.Second, wantExceeded: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skewState.mu.Lock()
+			skewState.skew, skewState.measuredAt, skewState.threshold = 0, time.Time{}, 2*time.Second
+			skewState.mu.Unlock()
+			if _, measuredAt, _ := Skew(); !measuredAt.IsZero() {
+				t.Fatalf("measuredAt = %s before any probe, want zero", measuredAt)
+			}
+
+			dbNow := func(context.Context) (time.Time, error) { return time.Now().Add(tt.offset), nil }
+			probeSkew(context.Background(), dbNow, 2*time.Second)
+
+			skew, measuredAt, exceeded := Skew()
+			if measuredAt.IsZero() {
+				t.Fatal("measuredAt is zero after a successful probe")
+			}
+			// The real clock moves during the probe, so allow some slack.
+			if diff := (skew - tt.offset).Abs(); diff > 100*time.Millisecond {
+				t.Errorf("skew = %s, want about %s", skew, tt.offset)
+			}
+			if exceeded != tt.wantExceeded {
+				t.Errorf("exceeded = %t, want %t", exceeded, tt.wantExceeded)
+			}
+		})
+	}
+}
+
+func TestProbeSkewFailureKeepsLastMeasurement(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	skewState.mu.Lock()
+	skewState.skew, skewState.measuredAt, skewState.threshold = 3*time.Second, at, 2*time.Second
+	skewState.mu.Unlock()
+
+	dbNow := func(context.Context) (time.Time, error) { return time.Time{}, errors.New("timeout") }
+	probeSkew(context.Background(), dbNow, 2*time.Second)
+
+	skew, measuredAt, exceeded := Skew()
+	if skew != 3*time.Second || !measuredAt.Equal(at) || !exceeded {
+		t.Errorf("Skew() = %s, %s, %t; want the earlier 3s measurement, exceeded", skew, measuredAt, exceeded)
+	}
+}
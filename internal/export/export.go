@@ -0,0 +1,209 @@
+// Package export writes and restores per-account data dumps.
+//
+// A dump is a single JSON document:
+//
+//	{
+//	  "format": "bitterlink-export", "version": 1, "exported_at": "...",
+//	  "accounts": [
+//	    {"user": {...}, "api_keys": [...], "checks": [{"check": {...}, "depends_on": [...], "events": [...], "pings": [...]}, ...]},
+//	    ...
+//	  ]
+//	}
+//
+// It is written incrementally: checks are streamed from the database and each
+// check's pings are encoded as they are read, so the exporter never holds a large
+// account (or a long ping history) in memory. The importer reads it back one
+// check at a time. api_keys (metadata only, never key values) is only present
+// when the Exporter has an APIKeyRepo, and the importer skips it. depends_on
+// lists the UUIDs of the check's dependencies and is left out when it has none.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+const (
+	Format  = "bitterlink-export"
+	Version = 1
+
+	// eventsPerCheck bounds how much event history is exported per check.
+	eventsPerCheck = 500
+)
+
+// Header is the envelope written before the accounts array.
+type Header struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// CheckEntry is a check together with its history.
+type CheckEntry struct {
+	Check     models.Check        `json:"check"`
+	DependsOn []string            `json:"depends_on,omitempty"` // UUIDs of the checks it depends on
+	Events    []models.CheckEvent `json:"events"`
+	Pings     []models.Ping       `json:"pings"`
+}
+
+// Options controls what is exported.
+type Options struct {
+	// PingsSince includes pings received at or after this time. Zero exports no pings.
+	PingsSince time.Time
+}
+
+// Exporter writes dumps from the repositories.
+type Exporter struct {
+	CheckRepo  repository.CheckRepository
+	APIKeyRepo repository.APIKeyRepository // Optional, adds api_keys
+	Options    Options
+}
+
+// Writer streams a dump document to an io.Writer.
+// Call Begin once, Account for each user, then End.
+type Writer struct {
+	w            io.Writer
+	enc          *json.Encoder
+	wroteAccount bool
+}
+
+// NewWriter creates a Writer on w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, enc: json.NewEncoder(w)}
+}
+
+// Begin writes the document header and opens the accounts array.
+func (dw *Writer) Begin() error {
+	header, err := json.Marshal(Header{Format: Format, Version: Version, ExportedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	// Splice the accounts array into the header object: {"format":...,"accounts":[
+	_, err = fmt.Fprintf(dw.w, "%s,\"accounts\":[", header[:len(header)-1])
+	return err
+}
+
+// End closes the accounts array and the document.
+func (dw *Writer) End() error {
+	_, err := io.WriteString(dw.w, "]}\n")
+	return err
+}
+
+// Account writes one user and all of their checks.
+func (e *Exporter) Account(ctx context.Context, dw *Writer, user *models.User) error {
+	if dw.wroteAccount {
+		if _, err := io.WriteString(dw.w, ","); err != nil {
+			return err
+		}
+	}
+	dw.wroteAccount = true
+
+	if _, err := io.WriteString(dw.w, `{"user":`); err != nil {
+		return err
+	}
+	if err := dw.enc.Encode(user); err != nil {
+		return err
+	}
+	if e.APIKeyRepo != nil {
+		keys, err := e.APIKeyRepo.ListByUserID(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("listing API keys of user %d: %w", user.ID, err)
+		}
+		if _, err := io.WriteString(dw.w, `,"api_keys":`); err != nil {
+			return err
+		}
+		if err := dw.enc.Encode(keys); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(dw.w, `,"checks":[`); err != nil {
+		return err
+	}
+
+	first := true
+	err := e.CheckRepo.EachByUserID(ctx, user.ID, func(check models.Check) error {
+		if !first {
+			if _, err := io.WriteString(dw.w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		return e.writeCheckEntry(ctx, dw, check)
+	})
+	if err != nil {
+		return fmt.Errorf("exporting checks of user %d: %w", user.ID, err)
+	}
+
+	_, err = io.WriteString(dw.w, "]}")
+	return err
+}
+
+// writeCheckEntry writes one check with its history, in the CheckEntry layout.
+// Events are bounded by eventsPerCheck and loaded up front; pings are unbounded
+// and encoded as they are read.
+func (e *Exporter) writeCheckEntry(ctx context.Context, dw *Writer, check models.Check) error {
+	events, err := e.CheckRepo.ListEventsByCheckID(ctx, check.ID, eventsPerCheck)
+	if err != nil {
+		return fmt.Errorf("listing events of check %d: %w", check.ID, err)
+	}
+	if events == nil {
+		events = []models.CheckEvent{}
+	}
+	dependencies, err := e.CheckRepo.ListDependencies(ctx, check.ID)
+	if err != nil {
+		return fmt.Errorf("listing dependencies of check %d: %w", check.ID, err)
+	}
+
+	if _, err := io.WriteString(dw.w, `{"check":`); err != nil {
+		return err
+	}
+	if err := dw.enc.Encode(check); err != nil {
+		return err
+	}
+	if len(dependencies) > 0 {
+		dependsOn := make([]string, len(dependencies))
+		for i, dependency := range dependencies {
+			dependsOn[i] = dependency.UUID
+		}
+		if _, err := io.WriteString(dw.w, `,"depends_on":`); err != nil {
+			return err
+		}
+		if err := dw.enc.Encode(dependsOn); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(dw.w, `,"events":`); err != nil {
+		return err
+	}
+	if err := dw.enc.Encode(events); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(dw.w, `,"pings":[`); err != nil {
+		return err
+	}
+
+	if !e.Options.PingsSince.IsZero() {
+		firstPing := true
+		err = e.CheckRepo.EachPingSince(ctx, check.ID, e.Options.PingsSince, func(ping models.Ping) error {
+			if !firstPing {
+				if _, err := io.WriteString(dw.w, ","); err != nil {
+					return err
+				}
+			}
+			firstPing = false
+			return dw.enc.Encode(ping)
+		})
+		if err != nil {
+			return fmt.Errorf("listing pings of check %d: %w", check.ID, err)
+		}
+	}
+
+	_, err = io.WriteString(dw.w, "]}")
+	return err
+}
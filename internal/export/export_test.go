@@ -0,0 +1,339 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+// memRepo is the part of repository.CheckRepository that export and import
+// use, in memory. Events are listed newest first, as ListEventsByCheckID does.
+type memRepo struct {
+	repository.CheckRepository
+	nextID       int64
+	checks       []models.Check
+	pings        []models.Ping
+	events       []models.CheckEvent
+	dependencies map[int64][]int64
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{nextID: 100, dependencies: map[int64][]int64{}}
+}
+
+func (r *memRepo) id() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *memRepo) Create(_ context.Context, check *models.Check) error {
+	check.ID = r.id()
+	r.checks = append(r.checks, *check)
+	return nil
+}
+
+func (r *memRepo) ListByUserID(_ context.Context, userID int64) ([]models.Check, error) {
+	var checks []models.Check
+	for _, check := range r.checks {
+		if check.UserID == userID {
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
+func (r *memRepo) EachByUserID(ctx context.Context, userID int64, fn func(models.Check) error) error {
+	checks, _ := r.ListByUserID(ctx, userID)
+	for _, check := range checks {
+		if err := fn(check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memRepo) ListEventsByCheckID(_ context.Context, checkID int64, limit int) ([]models.CheckEvent, error) {
+	var events []models.CheckEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		if r.events[i].CheckID == checkID {
+			events = append(events, r.events[i])
+		}
+	}
+	return events, nil
+}
+
+func (r *memRepo) EachPingSince(_ context.Context, checkID int64, since time.Time, fn func(models.Ping) error) error {
+	for _, ping := range r.pings {
+		if ping.CheckID == checkID && !ping.ReceivedAt.Before(since) {
+			if err := fn(ping); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memRepo) ListDependencies(_ context.Context, checkID int64) ([]models.Check, error) {
+	var checks []models.Check
+	for _, id := range r.dependencies[checkID] {
+		for _, check := range r.checks {
+			if check.ID == id {
+				checks = append(checks, check)
+			}
+		}
+	}
+	return checks, nil
+}
+
+func (r *memRepo) AddDependency(_ context.Context, _, checkID, dependsOnID int64) error {
+	r.dependencies[checkID] = append(r.dependencies[checkID], dependsOnID)
+	return nil
+}
+
+func (r *memRepo) ImportPing(_ context.Context, ping *models.Ping) error {
+	ping.ID = r.id()
+	r.pings = append(r.pings, *ping)
+	return nil
+}
+
+func (r *memRepo) ImportEvent(_ context.Context, event *models.CheckEvent) error {
+	event.ID = r.id()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// accountContents is what a repository holds for one account, keyed by check
+// UUID instead of row IDs so two copies can be compared.
+type accountContents struct {
+	Checks       map[string]models.Check
+	Pings        map[string][]models.Ping
+	Events       map[string][]models.CheckEvent
+	Dependencies map[string][]string
+}
+
+func contentsOf(r *memRepo, userID int64) accountContents {
+	contents := accountContents{
+		Checks:       map[string]models.Check{},
+		Pings:        map[string][]models.Ping{},
+		Events:       map[string][]models.CheckEvent{},
+		Dependencies: map[string][]string{},
+	}
+	uuids := map[int64]string{}
+	pingTimes := map[int64]time.Time{}
+	for _, check := range r.checks {
+		if check.UserID == userID {
+			uuids[check.ID] = check.UUID
+		}
+	}
+	for _, check := range r.checks {
+		if uuid, ok := uuids[check.ID]; ok {
+			check.ID, check.UserID = 0, 0
+			contents.Checks[uuid] = check
+		}
+	}
+	for id, uuid := range uuids {
+		for _, dependsOn := range r.dependencies[id] {
+			contents.Dependencies[uuid] = append(contents.Dependencies[uuid], uuids[dependsOn])
+		}
+		sort.Strings(contents.Dependencies[uuid])
+	}
+	for _, ping := range r.pings {
+		if uuid, ok := uuids[ping.CheckID]; ok {
+			pingTimes[ping.ID] = ping.ReceivedAt
+			ping.ID, ping.CheckID = 0, 0
+			contents.Pings[uuid] = append(contents.Pings[uuid], ping)
+		}
+	}
+	for _, event := range r.events {
+		if uuid, ok := uuids[event.CheckID]; ok {
+			// A ping link is compared by the time of the ping it points at
+			if event.PingID.Valid {
+				event.PingID = sql.NullInt64{Int64: pingTimes[event.PingID.Int64].Unix(), Valid: true}
+			}
+			event.ID, event.CheckID = 0, 0
+			contents.Events[uuid] = append(contents.Events[uuid], event)
+		}
+	}
+	return contents
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	source := newMemRepo()
+	db := models.Check{UserID: 1, UUID: "uuid-db", Name: "database backup", ExpectedInterval: 86400, GracePeriod: 600,
+		Status: models.StatusUp, IsEnabled: true, Description: sql.NullString{String: "nightly", Valid: true},
+		LastPingAt: sql.NullTime{Time: at, Valid: true}, CreatedAt: at, UpdatedAt: at}
+	report := models.Check{UserID: 1, UUID: "uuid-report", Name: "report", ExpectedInterval: 3600, Status: models.StatusNew,
+		CreatedAt: at, UpdatedAt: at}
+	other := models.Check{UserID: 2, UUID: "uuid-other", Name: "someone else's", ExpectedInterval: 60}
+	for _, check := range []*models.Check{&db, &report, &other} {
+		source.Create(ctx, check)
+	}
+	source.AddDependency(ctx, 1, report.ID, db.ID)
+	ping := models.Ping{CheckID: db.ID, Kind: models.PingKindSuccess, ReceivedAt: at, CreatedAt: at,
+		Payload: sql.NullString{String: "ok", Valid: true}}
+	source.ImportPing(ctx, &ping)
+	source.ImportPing(ctx, &models.Ping{CheckID: db.ID, Kind: models.PingKindSuccess, ReceivedAt: at.Add(-48 * time.Hour)}) // Before PingsSince
+	source.ImportEvent(ctx, &models.CheckEvent{CheckID: db.ID, Type: models.EventCheckCreated, Source: models.EventSourceAPI, CreatedAt: at})
+	source.ImportEvent(ctx, &models.CheckEvent{CheckID: db.ID, Type: models.EventStatusChanged, Source: models.EventSourcePing,
+		FromStatus: sql.NullString{String: models.StatusNew, Valid: true}, ToStatus: sql.NullString{String: models.StatusUp, Valid: true},
+		PingID: sql.NullInt64{Int64: ping.ID, Valid: true}, CreatedAt: at})
+
+	// Export the account
+	var dump bytes.Buffer
+	exporter := Exporter{CheckRepo: source, Options: Options{PingsSince: at.Add(-24 * time.Hour)}}
+	dw := NewWriter(&dump)
+	if err := dw.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Account(ctx, dw, &models.User{ID: 1, Email: "ops@example.com"}); err != nil {
+		t.Fatalf("Account: %v", err)
+	}
+	if err := dw.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Import into a wiped database, under another user ID
+	target := newMemRepo()
+	target.nextID = 500
+	importer := Importer{CheckRepo: target, Resolve: func(context.Context, *models.User) (*models.User, error) {
+		return &models.User{ID: 9, Email: "ops@example.com"}, nil
+	}}
+	stats, err := importer.Import(ctx, &dump)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if want := (Stats{Accounts: 1, Checks: 2, Dependencies: 1, Events: 2, Pings: 1}); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+
+	// Everything but the row IDs and the old ping survives
+	want := contentsOf(source, 1)
+	want.Pings["uuid-db"] = want.Pings["uuid-db"][:1]
+	if got := contentsOf(target, 9); !reflect.DeepEqual(got, want) {
+		t.Errorf("imported account differs from the exported one:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestImportIntoNonEmptyAccount(t *testing.T) {
+	ctx := context.Background()
+	source := newMemRepo()
+	source.Create(ctx, &models.Check{UserID: 1, UUID: "uuid-a", Name: "a"})
+	var dump bytes.Buffer
+	dw := NewWriter(&dump)
+	dw.Begin()
+	(&Exporter{CheckRepo: source}).Account(ctx, dw, &models.User{ID: 1, Email: "ops@example.com"})
+	dw.End()
+
+	target := newMemRepo()
+	target.Create(ctx, &models.Check{UserID: 9, UUID: "uuid-existing", Name: "existing"})
+	importer := Importer{CheckRepo: target, Resolve: func(context.Context, *models.User) (*models.User, error) {
+		return &models.User{ID: 9, Email: "ops@example.com"}, nil
+	}}
+	if _, err := importer.Import(ctx, &dump); !errors.Is(err, ErrAccountNotEmpty) {
+		t.Fatalf("Import = %v, want ErrAccountNotEmpty", err)
+	}
+}
+
+// generatedRepo makes up count checks of user 7 as they are asked for, the
+// way rows come off a cursor: ListByUserID builds the whole slice,
+// EachByUserID one check at a time. They have no history.
+type generatedRepo struct {
+	*memRepo
+	count int
+}
+
+func (r generatedRepo) check(i int) models.Check {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Second)
+	return models.Check{
+		ID:               int64(i + 1),
+		UUID:             fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+		UserID:           7,
+		Name:             fmt.Sprintf("Check %d", i),
+		Description:      sql.NullString{String: "Nightly database backup", Valid: true},
+		ExpectedInterval: 3600,
+		GracePeriod:      300,
+		Status:           models.StatusUp,
+		IsEnabled:        true,
+		LastPingAt:       sql.NullTime{Time: at, Valid: true},
+		CreatedAt:        at,
+		UpdatedAt:        at,
+	}
+}
+
+func (r generatedRepo) ListByUserID(context.Context, int64) ([]models.Check, error) {
+	checks := make([]models.Check, 0, r.count)
+	for i := range r.count {
+		checks = append(checks, r.check(i))
+	}
+	return checks, nil
+}
+
+func (r generatedRepo) EachByUserID(_ context.Context, _ int64, fn func(models.Check) error) error {
+	for i := range r.count {
+		if err := fn(r.check(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// largestWrite discards what is written, keeping the largest single write.
+type largestWrite struct{ max int }
+
+func (w *largestWrite) Write(p []byte) (int, error) {
+	w.max = max(w.max, len(p))
+	return len(p), nil
+}
+
+// BenchmarkAccountExport compares exporting an account of 20k checks buffered
+// (every entry collected, then encoded as one document, as before streaming)
+// with Exporter.Account. max-write-B is the largest chunk of the document held
+// at once.
+func BenchmarkAccountExport(b *testing.B) {
+	repo := generatedRepo{memRepo: newMemRepo(), count: 20_000}
+	user := &models.User{ID: 7, Email: "ops@example.com"}
+	ctx := context.Background()
+
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		w := &largestWrite{}
+		for range b.N {
+			checks, _ := repo.ListByUserID(ctx, user.ID)
+			entries := make([]CheckEntry, 0, len(checks))
+			for _, check := range checks {
+				events, _ := repo.ListEventsByCheckID(ctx, check.ID, eventsPerCheck)
+				entries = append(entries, CheckEntry{Check: check, Events: events, Pings: []models.Ping{}})
+			}
+			doc := map[string]any{"user": user, "checks": entries}
+			if err := json.NewEncoder(w).Encode(doc); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(w.max), "max-write-B")
+	})
+	b.Run("streamed", func(b *testing.B) {
+		b.ReportAllocs()
+		w := &largestWrite{}
+		exporter := &Exporter{CheckRepo: repo}
+		for range b.N {
+			dw := NewWriter(w)
+			if err := exporter.Account(ctx, dw, user); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(w.max), "max-write-B")
+	})
+}
@@ -0,0 +1,249 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+// ErrAccountNotEmpty is returned when the import target already has checks.
+var ErrAccountNotEmpty = errors.New("target account already has checks")
+
+// Stats counts what an import restored.
+type Stats struct {
+	Accounts     int
+	Checks       int
+	Dependencies int
+	Events       int
+	Pings        int
+}
+
+// Importer restores dumps into existing, empty accounts. Row IDs are remapped
+// to the target database; check UUIDs are preserved so ping URLs keep working.
+// Dependencies are restored once all of an account's checks are, since a check
+// may depend on one that comes later in the dump.
+type Importer struct {
+	CheckRepo repository.CheckRepository
+	// Resolve maps the exported user onto the account to restore into.
+	Resolve func(ctx context.Context, exported *models.User) (*models.User, error)
+}
+
+// Import reads a dump from r, restoring one check at a time.
+func (im *Importer) Import(ctx context.Context, r io.Reader) (Stats, error) {
+	var stats Stats
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return stats, err
+	}
+	var header Header
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return stats, err
+		}
+		switch key {
+		case "format":
+			err = dec.Decode(&header.Format)
+		case "version":
+			err = dec.Decode(&header.Version)
+		case "accounts":
+			if header.Format != Format || header.Version != Version {
+				return stats, fmt.Errorf("unsupported dump format %q version %d", header.Format, header.Version)
+			}
+			err = im.importAccounts(ctx, dec, &stats)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return stats, err
+		}
+	}
+	return stats, expectDelim(dec, '}')
+}
+
+func (im *Importer) importAccounts(ctx context.Context, dec *json.Decoder, stats *Stats) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := im.importAccount(ctx, dec, stats); err != nil {
+			return err
+		}
+		stats.Accounts++
+	}
+	return expectDelim(dec, ']')
+}
+
+func (im *Importer) importAccount(ctx context.Context, dec *json.Decoder, stats *Stats) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	var target *models.User
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "user":
+			var exported models.User
+			if err := dec.Decode(&exported); err != nil {
+				return err
+			}
+			if target, err = im.resolveEmptyAccount(ctx, &exported); err != nil {
+				return err
+			}
+		case "checks":
+			if target == nil {
+				return errors.New(`malformed dump: "checks" before "user"`)
+			}
+			if err := im.importChecks(ctx, dec, target.ID, stats); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func (im *Importer) resolveEmptyAccount(ctx context.Context, exported *models.User) (*models.User, error) {
+	target, err := im.Resolve(ctx, exported)
+	if err != nil {
+		return nil, fmt.Errorf("resolving target account for %s: %w", exported.Email, err)
+	}
+	existing, err := im.CheckRepo.ListByUserID(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%s: %w", target.Email, ErrAccountNotEmpty)
+	}
+	return target, nil
+}
+
+// importedChecks maps an account's exported checks onto the restored ones.
+type importedChecks struct {
+	ids       map[int64]int64    // Exported check ID to restored ID
+	byUUID    map[string]int64   // UUID to restored ID
+	dependsOn map[int64][]string // Restored ID to the UUIDs it depends on
+}
+
+func (im *Importer) importChecks(ctx context.Context, dec *json.Decoder, userID int64, stats *Stats) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	imported := importedChecks{ids: map[int64]int64{}, byUUID: map[string]int64{}, dependsOn: map[int64][]string{}}
+	for dec.More() {
+		var entry CheckEntry
+		if err := dec.Decode(&entry); err != nil {
+			return err
+		}
+		if err := im.importCheck(ctx, &entry, userID, &imported, stats); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return err
+	}
+
+	// Dependencies on checks missing from the dump (deleted before the export)
+	// are dropped
+	for checkID, uuids := range imported.dependsOn {
+		for _, uuid := range uuids {
+			dependsOnID, ok := imported.byUUID[uuid]
+			if !ok {
+				continue
+			}
+			if err := im.CheckRepo.AddDependency(ctx, userID, checkID, dependsOnID); err != nil {
+				return fmt.Errorf("restoring dependency on check %s: %w", uuid, err)
+			}
+			stats.Dependencies++
+		}
+	}
+	return nil
+}
+
+func (im *Importer) importCheck(ctx context.Context, entry *CheckEntry, userID int64, imported *importedChecks, stats *Stats) error {
+	check := entry.Check
+	check.ID = 0
+	check.UserID = userID
+	if err := im.CheckRepo.Create(ctx, &check); err != nil {
+		return fmt.Errorf("restoring check %s: %w", check.UUID, err)
+	}
+	stats.Checks++
+	imported.ids[entry.Check.ID] = check.ID
+	imported.byUUID[check.UUID] = check.ID
+	if len(entry.DependsOn) > 0 {
+		imported.dependsOn[check.ID] = entry.DependsOn
+	}
+
+	// Pings first, so events can be pointed at the remapped ping IDs
+	pingIDs := make(map[int64]int64, len(entry.Pings))
+	for _, ping := range entry.Pings {
+		oldID := ping.ID
+		ping.ID = 0
+		ping.CheckID = check.ID
+		if err := im.CheckRepo.ImportPing(ctx, &ping); err != nil {
+			return fmt.Errorf("restoring ping of check %s: %w", check.UUID, err)
+		}
+		pingIDs[oldID] = ping.ID
+		stats.Pings++
+	}
+
+	// Events are exported newest first; restore oldest first to keep their order
+	for i := len(entry.Events) - 1; i >= 0; i-- {
+		event := entry.Events[i]
+		event.ID = 0
+		event.CheckID = check.ID
+		if event.PingID.Valid {
+			// Drop links to pings outside the exported window
+			newID, ok := pingIDs[event.PingID.Int64]
+			event.PingID = sql.NullInt64{Int64: newID, Valid: ok}
+		}
+		if event.RelatedCheckID.Valid {
+			// Likewise for checks not restored (yet): only earlier ones are known
+			newID, ok := imported.ids[event.RelatedCheckID.Int64]
+			event.RelatedCheckID = sql.NullInt64{Int64: newID, Valid: ok}
+		}
+		if err := im.CheckRepo.ImportEvent(ctx, &event); err != nil {
+			return fmt.Errorf("restoring event of check %s: %w", check.UUID, err)
+		}
+		stats.Events++
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("malformed dump: expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+func nextKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("malformed dump: expected object key, got %v", tok)
+	}
+	return key, nil
+}
@@ -0,0 +1,253 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job states.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobReady   = "ready"
+	JobFailed  = "failed"
+)
+
+var (
+	// ErrJobNotFound is returned for unknown job IDs and other users' jobs.
+	ErrJobNotFound = errors.New("export job not found")
+	// ErrJobExpired is returned for jobs whose artifact has been removed.
+	ErrJobExpired = errors.New("export job expired")
+	// ErrQueueFull is returned by Submit when too many exports are pending.
+	ErrQueueFull = errors.New("too many exports pending")
+)
+
+// JobsConfig configures the JobManager. Zero values get the defaults noted.
+type JobsConfig struct {
+	Dir       string        // Where artifacts are written, default <tmp>/bitterlink-exports
+	TTL       time.Duration // How long a finished artifact can be fetched, default 24h
+	Workers   int           // Concurrent exports, default 1
+	QueueSize int           // Pending exports before Submit refuses, default 10
+}
+
+// BuildFunc writes an export document to w.
+type BuildFunc func(ctx context.Context, w io.Writer) error
+
+// Job is a snapshot of an asynchronous export.
+type Job struct {
+	ID          string     `json:"id"`
+	UserID      int64      `json:"-"`
+	Status      string     `json:"status"` // Job*
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Set once ready or failed
+	path        string
+	build       BuildFunc
+}
+
+// JobManager builds exports in the background and keeps the artifacts on local
+// disk until they expire. Job state lives in memory: with several API replicas
+// the follow-up GET must reach the instance that took the request, and a restart
+// forgets all jobs (their files are removed on the next start).
+type JobManager struct {
+	config JobsConfig
+	queue  chan *Job
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewJobManager creates a manager. Call Start to run its workers.
+func NewJobManager(cfg JobsConfig) *JobManager {
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(os.TempDir(), "bitterlink-exports")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10
+	}
+	return &JobManager{
+		config: cfg,
+		queue:  make(chan *Job, cfg.QueueSize),
+		jobs:   make(map[string]*Job),
+	}
+}
+
+// Submit queues an export for userID and returns its pending job.
+func (m *JobManager) Submit(userID int64, build BuildFunc) (Job, error) {
+	job := &Job{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Status:    JobPending,
+		CreatedAt: time.Now().UTC(),
+		build:     build,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case m.queue <- job:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[job.ID] = job
+	return *job, nil
+}
+
+// Get returns userID's job and, once it is ready, the artifact's path.
+func (m *JobManager) Get(id string, userID int64) (Job, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || job.UserID != userID {
+		return Job{}, "", ErrJobNotFound
+	}
+	if job.ExpiresAt != nil && !time.Now().Before(*job.ExpiresAt) {
+		return *job, "", ErrJobExpired
+	}
+	if job.Status != JobReady {
+		return *job, "", nil
+	}
+	return *job, job.path, nil
+}
+
+// Start runs the workers and the expiry sweep until ctx is cancelled. Leftover
+// artifacts from a previous run are removed first.
+func (m *JobManager) Start(ctx context.Context) {
+	if err := os.MkdirAll(m.config.Dir, 0o700); err != nil {
+		log.Printf("ERROR: Account export directory %s unusable, async exports will fail: %v", m.config.Dir, err)
+	}
+	m.removeLeftovers()
+	log.Printf("INFO: Account export jobs started (%d workers, artifacts kept %s in %s)", m.config.Workers, m.config.TTL, m.config.Dir)
+
+	var wg sync.WaitGroup
+	for i := 0; i < m.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case job := <-m.queue:
+					m.run(ctx, job)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.sweep(time.Now())
+		case <-ctx.Done():
+			wg.Wait()
+			log.Println("INFO: Account export jobs stopped.")
+			return
+		}
+	}
+}
+
+// run builds one job's artifact.
+func (m *JobManager) run(ctx context.Context, job *Job) {
+	build := job.build
+	m.setState(job, JobRunning, "", "")
+	path := filepath.Join(m.config.Dir, job.ID+".json")
+	err := m.write(ctx, path, build)
+	if err != nil {
+		os.Remove(path)
+		if ctx.Err() != nil {
+			m.setState(job, JobFailed, "", "server shutting down")
+			return
+		}
+		log.Printf("ERROR: Account export %s for user %d failed: %v", job.ID, job.UserID, err)
+		m.setState(job, JobFailed, "", "export failed")
+		return
+	}
+	log.Printf("INFO: Account export %s for user %d ready", job.ID, job.UserID)
+	m.setState(job, JobReady, path, "")
+}
+
+func (m *JobManager) write(ctx context.Context, path string, build BuildFunc) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating artifact: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	if err := build(ctx, w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writing artifact: %w", err)
+	}
+	return f.Close()
+}
+
+func (m *JobManager) setState(job *Job, status, path, errMsg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.Status, job.path, job.Error = status, path, errMsg
+	job.build = nil // Past pending, the closure is no longer needed
+	if status == JobReady || status == JobFailed {
+		now := time.Now().UTC()
+		expires := now.Add(m.config.TTL)
+		job.CompletedAt, job.ExpiresAt = &now, &expires
+	}
+}
+
+// removeLeftovers deletes artifacts written before a restart, which no job
+// refers to any more. Only files named like artifacts are touched, in case Dir
+// is shared.
+func (m *JobManager) removeLeftovers() {
+	paths, _ := filepath.Glob(filepath.Join(m.config.Dir, "*.json"))
+	for _, path := range paths {
+		if uuid.Validate(strings.TrimSuffix(filepath.Base(path), ".json")) != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Printf("WARN: Failed to remove old export %s: %v", path, err)
+		}
+	}
+}
+
+// sweep deletes the artifacts of expired jobs. The jobs themselves are
+// forgotten one TTL later, so until then Get still reports them as expired.
+func (m *JobManager) sweep(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, job := range m.jobs {
+		if job.ExpiresAt == nil || now.Before(*job.ExpiresAt) {
+			continue
+		}
+		if job.path != "" {
+			if err := os.Remove(job.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("WARN: Failed to remove expired export %s: %v", job.path, err)
+				continue
+			}
+			job.path = ""
+		}
+		if now.Sub(*job.ExpiresAt) >= m.config.TTL {
+			delete(m.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package forward mirrors recorded pings to a per-check downstream URL, e.g. the
+// monitoring system being migrated away from.
+//
+// Forwarding is strictly best effort and never on the ping's request path: the
+// ping handler enqueues after the ping is recorded and replies straight away. A
+// full queue drops the forward; a failed one is counted on the check
+// (forward_failures) instead of being reported to the pinger.
+package forward
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/version"
+)
+
+// LoopHeader is set on every forwarded request. Pings arriving with it are
+// recorded but not forwarded again, which breaks loops between two instances
+// that forward to each other.
+const LoopHeader = "X-Bitterlink-Forwarded"
+
+// ErrLoop is returned for forward URLs that point at this instance.
+var ErrLoop = errors.New("forward URL points at this instance")
+
+// Config configures the Forwarder. Zero values get the defaults noted.
+type Config struct {
+	Timeout     time.Duration // Per attempt, default 5s
+	MaxAttempts int           // Including the first, default 3
+	RetryDelay  time.Duration // Before the second attempt, doubled after each, default 1s
+	QueueSize   int           // Pending forwards before new ones are dropped, default 1000
+	Workers     int           // Concurrent forwards, default 4
+	// SelfHosts are this instance's host or host:port names (e.g. from
+	// PUBLIC_BASE_URL). Forward URLs pointing at them are refused.
+	SelfHosts []string
+}
+
+// Ping is a recorded ping to forward.
+type Ping struct {
+	UUID    string
+	Kind    string // models.PingKind*
+	Method  string // http.MethodGet or http.MethodPost, as the ping arrived
+	Payload []byte // Sent as the POST body
+}
+
+// Store is the part of the check repository the Forwarder needs.
+type Store interface {
+	FindByUUID(ctx context.Context, uuid string) (*models.Check, error)
+	RecordForwardResult(ctx context.Context, checkID int64, forwardErr error) error
+}
+
+// Forwarder sends queued pings to their checks' forward_url.
+type Forwarder struct {
+	store  Store
+	config Config
+	client *http.Client
+	queue  chan Ping
+
+	dropMu  sync.Mutex
+	dropped int // Total forwards dropped on a full queue
+}
+
+// New creates a Forwarder. Call Start to run its workers.
+func New(store Store, cfg Config) *Forwarder {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	f := &Forwarder{
+		store:  store,
+		config: cfg,
+		queue:  make(chan Ping, cfg.QueueSize),
+	}
+	f.client = &http.Client{
+		Timeout: cfg.Timeout,
+		// A redirect could lead back here, so every hop gets the same check
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return errors.New("stopped after 3 redirects")
+			}
+			return f.checkTarget(req.URL)
+		},
+	}
+	return f
+}
+
+// Enqueue queues p for forwarding without blocking. When the queue is full the
+// forward is dropped: a slow downstream must not back up ping ingestion.
+func (f *Forwarder) Enqueue(p Ping) {
+	select {
+	case f.queue <- p:
+	default:
+		f.dropMu.Lock()
+		f.dropped++
+		dropped := f.dropped
+		f.dropMu.Unlock()
+		// Warn on the first drop and then every 100th, not once per ping
+		if dropped == 1 || dropped%100 == 0 {
+			log.Printf("WARN: Ping forward queue full (%d), dropped %d forwards so far", f.config.QueueSize, dropped)
+		}
+	}
+}
+
+// Start runs the workers until ctx is cancelled. Forwards still queued then are
+// dropped.
+func (f *Forwarder) Start(ctx context.Context) {
+	log.Printf("INFO: Ping forwarder started (%d workers, timeout %s, %d attempts)", f.config.Workers, f.config.Timeout, f.config.MaxAttempts)
+	var wg sync.WaitGroup
+	for i := 0; i < f.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case p := <-f.queue:
+					f.forward(ctx, p)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if pending := len(f.queue); pending > 0 {
+		log.Printf("WARN: Ping forwarder stopped with %d forwards still queued", pending)
+	}
+	log.Println("INFO: Ping forwarder stopped.")
+}
+
+// forward looks up p's check and, if it has a forward_url, sends p there and
+// records the outcome on the check.
+func (f *Forwarder) forward(ctx context.Context, p Ping) {
+	// 1. Only checks with a forward URL are forwarded
+	check, err := f.store.FindByUUID(ctx, p.UUID)
+	if err != nil {
+		log.Printf("WARN: Ping forwarder could not load check %s: %v", p.UUID, err)
+		return
+	}
+	if !check.ForwardURL.Valid || check.ForwardURL.String == "" {
+		return
+	}
+
+	// 2. Send, retrying transient failures
+	sendErr := f.send(ctx, check.ForwardURL.String, p)
+	if sendErr != nil {
+		if ctx.Err() != nil {
+			return // Shutting down, not the downstream's fault
+		}
+		log.Printf("WARN: Forwarding ping for check ID %d failed: %v", check.ID, sendErr)
+	}
+
+	// 3. Surface the result on the check
+	if err := f.store.RecordForwardResult(ctx, check.ID, sendErr); err != nil {
+		log.Printf("ERROR: Failed to record forward result for check ID %d: %v", check.ID, err)
+	}
+}
+
+// send delivers p to forwardURL with up to MaxAttempts attempts. Network errors,
+// 429 and 5xx responses are retried; any other non-2xx is final.
+func (f *Forwarder) send(ctx context.Context, forwardURL string, p Ping) error {
+	target, err := TargetURL(forwardURL, p.Kind)
+	if err != nil {
+		return err
+	}
+	if err := f.checkTarget(target); err != nil {
+		return err // Set directly in the database, or PUBLIC_BASE_URL changed since
+	}
+
+	delay := f.config.RetryDelay
+	var lastErr error
+	for attempt := 1; attempt <= f.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+		retry, err := f.attempt(ctx, target, p)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// attempt makes one request. retry reports whether a later attempt may succeed.
+func (f *Forwarder) attempt(ctx context.Context, target *url.URL, p Ping) (retry bool, err error) {
+	method := http.MethodGet
+	var body io.Reader
+	if p.Method == http.MethodPost {
+		method = http.MethodPost
+		body = bytes.NewReader(p.Payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return false, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("User-Agent", "bitterlink-forwarder/"+version.Version)
+	req.Header.Set(LoopHeader, "1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrLoop) {
+			return false, err // Redirected back here
+		}
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("downstream returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("downstream returned %s", resp.Status)
+	}
+}
+
+// TargetURL is the URL a ping of kind is forwarded to: forwardURL itself for a
+// success, with /start or /fail appended for those signals, the convention
+// shared by healthchecks.io-style ping endpoints.
+func TargetURL(forwardURL, kind string) (*url.URL, error) {
+	target, err := url.Parse(forwardURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward URL: %w", err)
+	}
+	if kind == models.PingKindStart || kind == models.PingKindFail {
+		target.Path = strings.TrimRight(target.Path, "/") + "/" + kind
+	}
+	return target, nil
+}
+
+// ValidateURL checks a forward URL before it is stored: absolute http(s), and not
+// pointing at any of selfHosts.
+func ValidateURL(raw string, selfHosts []string) error {
+	target, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid forward URL: %w", err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return errors.New("forward URL must be http or https")
+	}
+	if target.Host == "" {
+		return errors.New("forward URL must be absolute")
+	}
+	return checkHost(target, selfHosts)
+}
+
+func (f *Forwarder) checkTarget(target *url.URL) error {
+	return checkHost(target, f.config.SelfHosts)
+}
+
+// checkHost returns ErrLoop when target's host (and port, when self has one)
+// matches one of selfHosts. Hostnames compare case-insensitively.
+func checkHost(target *url.URL, selfHosts []string) error {
+	host, port := target.Hostname(), target.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[target.Scheme]
+	}
+	for _, self := range selfHosts {
+		selfHost, selfPort, err := net.SplitHostPort(self)
+		if err != nil {
+			selfHost, selfPort = self, "" // No port: any port on that host is us
+		}
+		if strings.EqualFold(host, selfHost) && (selfPort == "" || selfPort == port) {
+			return ErrLoop
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+)
+
+// Error log deduplication. When a dependency goes away every request and worker
+// tick fails the same way, and logging each failure buries everything else.
+// Errorf collapses repeats instead: the first line of a class (its format
+// string) is logged as is, repeats within the window are only counted, and a
+// sweep once per window logs one summary line per class with the count and the
+// most recent message. A class that stayed quiet for a whole window is closed
+// with an INFO line saying how many times it occurred in total.
+//
+// This is not sampling: every line is either logged or counted, and every class
+// that was suppressed at all is reported as having stopped.
+
+// dedupClass is one format string's current run of errors.
+type dedupClass struct {
+	count      int64 // Occurrences since the first was logged
+	suppressed int64 // Occurrences since the last line written for the class
+	first      time.Time
+	lastSeen   time.Time
+	last       string // Most recent message
+}
+
+var dedup struct {
+	mu      sync.Mutex
+	window  time.Duration // 0 until StartDedup, i.e. log every line
+	classes map[string]*dedupClass
+}
+
+// StartDedup turns on deduplication for Errorf with the given window and runs
+// the sweep until ctx is cancelled, then writes the outstanding summaries.
+// Without it (CLI commands, window <= 0) Errorf logs every line.
+func StartDedup(ctx context.Context, window time.Duration) {
+	if window <= 0 {
+		return
+	}
+	dedup.mu.Lock()
+	dedup.window = window
+	dedup.classes = make(map[string]*dedupClass)
+	dedup.mu.Unlock()
+	log.Printf("INFO: Repeated error log lines are collapsed per %s", window)
+
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sweepDedup(time.Now(), false)
+		case <-ctx.Done():
+			sweepDedup(time.Now(), true)
+			return
+		}
+	}
+}
+
+// Errorf logs an ERROR line, collapsing repeats of the same format (see
+// StartDedup). Suppressed lines are counted in metrics.LogLinesSuppressed.
+func Errorf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	now := time.Now()
+
+	dedup.mu.Lock()
+	if dedup.window <= 0 {
+		dedup.mu.Unlock()
+		log.Output(2, "ERROR: "+msg)
+		return
+	}
+	class, seen := dedup.classes[format]
+	if !seen {
+		dedup.classes[format] = &dedupClass{count: 1, first: now, lastSeen: now, last: msg}
+		dedup.mu.Unlock()
+		log.Output(2, "ERROR: "+msg)
+		return
+	}
+	class.count++
+	class.suppressed++
+	class.lastSeen, class.last = now, msg
+	dedup.mu.Unlock()
+	metrics.Incr(metrics.LogLinesSuppressed)
+}
+
+// sweepDedup writes the summary of every class with suppressed lines and closes
+// the classes that were quiet for a window. The final sweep at shutdown only
+// writes summaries: an error that is still happening hasn't stopped.
+func sweepDedup(now time.Time, final bool) {
+	var lines []string
+	dedup.mu.Lock()
+	for format, class := range dedup.classes {
+		if class.suppressed > 0 {
+			lines = append(lines, fmt.Sprintf("ERROR: %s [repeated %d more times in the last %s]", class.last, class.suppressed, dedup.window))
+			class.suppressed = 0
+		}
+		if final || now.Sub(class.lastSeen) < dedup.window {
+			continue
+		}
+		// A one-off error was logged in full already; only a run gets a recovery line
+		if class.count > 1 {
+			lines = append(lines, fmt.Sprintf("INFO: Errors stopped after %d occurrences since %s: %s", class.count, class.first.UTC().Format(time.RFC3339), class.last))
+		}
+		delete(dedup.classes, format)
+	}
+	dedup.mu.Unlock()
+
+	for _, line := range lines {
+		log.Print(line)
+	}
+}
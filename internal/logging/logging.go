@@ -2,13 +2,21 @@
 package logging
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// probeInterval is how often the log directory is test-written, so a full disk is
+// noticed even while nothing is being logged.
+const probeInterval = 30 * time.Second
+
 func SetupLogging() {
 	logDirectory := "logs"
 	logFilename := "ping_app.log"
@@ -28,16 +36,93 @@ func SetupLogging() {
 		Filename:   logFilePath,
 		MaxSize:    logMaxSizeMB, // megabytes
 		MaxBackups: logMaxBackups,
-		MaxAge:     logMaxAgeDays, // days
+		MaxAge:     logMaxAgeDays,   // days
 		Compress:   compressRotated, // Enable compression
-		LocalTime:  true, // Use local time zone for timestamps in backup filenames
+		LocalTime:  true,            // Use local time zone for timestamps in backup filenames
 	}
 
-	log.SetOutput(lumberjackLogger)
+	log.SetOutput(&trackingWriter{w: lumberjackLogger})
 
 	// Optional: Configure standard log flags (add date, time, file/line number)
 	log.SetFlags(log.LstdFlags | log.Lshortfile | log.Lmicroseconds)
 
 	log.Println("INFO: Logging configured successfully. Output directed to:", logFilePath)
 
-}
\ No newline at end of file
+	go probeLoop(filepath.Join(logDirectory, ".write-probe"))
+}
+
+// writerHealth records the outcome of the latest log writes and probes.
+var writerHealth struct {
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastFailure time.Time
+}
+
+// Healthy reports whether the log writer is working: false once a write or probe
+// has failed and nothing has succeeded since. Before SetupLogging it is true.
+func Healthy() bool {
+	writerHealth.mu.Lock()
+	defer writerHealth.mu.Unlock()
+	return !writerHealth.lastFailure.After(writerHealth.lastSuccess)
+}
+
+// LastSuccess returns when a log write or probe last succeeded (zero if never).
+func LastSuccess() time.Time {
+	writerHealth.mu.Lock()
+	defer writerHealth.mu.Unlock()
+	return writerHealth.lastSuccess
+}
+
+// recordWrite updates writerHealth after a write attempt. The first failure after
+// a success is reported on stderr, since the log itself may be what is failing.
+func recordWrite(err error) {
+	now := time.Now()
+	writerHealth.mu.Lock()
+	defer writerHealth.mu.Unlock()
+	if err == nil {
+		writerHealth.lastSuccess = now
+		return
+	}
+	if !writerHealth.lastFailure.After(writerHealth.lastSuccess) {
+		fmt.Fprintf(os.Stderr, "ERROR: Log writer failing: %v\n", err)
+	}
+	writerHealth.lastFailure = now
+}
+
+// trackingWriter records whether writes to the log file succeed.
+type trackingWriter struct {
+	w io.Writer
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	recordWrite(err)
+	return n, err
+}
+
+// probeLoop test-writes a small file next to the log every probeInterval. It
+// goes to its own file so the log isn't filled with probe lines.
+func probeLoop(path string) {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		recordWrite(probeWrite(path))
+	}
+}
+
+func probeWrite(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(time.Now().UTC().Format(time.RFC3339Nano) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	// Sync, so a full disk surfaces here rather than on some later flush
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
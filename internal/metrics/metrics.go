@@ -0,0 +1,121 @@
+// Package metrics records operational counters and timers.
+//
+// Callers use the package-level functions; which backend receives them is chosen
+// once at startup with SetDefault (see METRICS_BACKEND). Until then, and whenever
+// metrics are off, the no-op backend is used, so instrumented code paths cost a
+// single interface call. StatsD pushes metrics to an agent; Prometheus keeps them
+// for scraping.
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metric names. Every backend emits the same set, so dashboards can move between
+// them without renaming.
+const (
+	// HTTPRequests counts API requests. Tags: method, route, status.
+	HTTPRequests = "http.requests"
+	// HTTPRequestDuration times API requests. Tags: method, route, status.
+	HTTPRequestDuration = "http.request_duration"
+	// PingsIngested counts received pings. Tags: kind, result (ok, duplicate, not_found, inactive, spooled, error).
+	PingsIngested = "pings.ingested"
+	// WorkerStatusChanges counts checks moved by the timeout checker. Tags: to_status.
+	WorkerStatusChanges = "worker.status_changes"
+	// NotificationsDispatched counts notification hand-offs. Tags: kind, outcome (ok, error, suppressed, muted, blackout).
+	NotificationsDispatched = "notifications.dispatched"
+	// WebhookDeliveries counts account webhook events. Tags: event, outcome (ok, error, dropped, breaker_open).
+	WebhookDeliveries = "webhooks.deliveries"
+	// PingsRateLimited counts pings refused by the per-IP limiter. Untagged.
+	PingsRateLimited = "pings.rate_limited"
+	// PingPayloadBytes is a histogram of the size of ping bodies as received,
+	// before truncation. Untagged.
+	PingPayloadBytes = "pings.payload_bytes"
+	// PingPayloadsTruncated counts ping bodies cut to PING_MAX_PAYLOAD_BYTES. Untagged.
+	PingPayloadsTruncated = "pings.payloads_truncated"
+	// DBCircuitRejected counts requests refused while the database circuit
+	// breaker is open. Tags: transport (http, grpc).
+	DBCircuitRejected = "db.circuit_rejected"
+	// LogLinesSuppressed counts ERROR lines collapsed by logging.Errorf.
+	LogLinesSuppressed = "log.lines_suppressed"
+
+	// Runtime and process gauges, sampled by CollectRuntime.
+	RuntimeGoroutines     = "runtime.goroutines"
+	RuntimeHeapAllocBytes = "runtime.heap_alloc_bytes"
+	RuntimeHeapSysBytes   = "runtime.heap_sys_bytes"
+	RuntimeGCPause        = "runtime.gc_pause" // Timer, one per collection
+	ProcessOpenFDs        = "process.open_fds"
+	DBPoolOpen            = "db.pool.open"
+	DBPoolInUse           = "db.pool.in_use"
+	DBPoolIdle            = "db.pool.idle"
+	DBPoolWaitCount       = "db.pool.wait_count"           // Cumulative
+	DBPoolWaitDuration    = "db.pool.wait_duration"        // Cumulative, milliseconds
+	DBClockSkew           = "db.clock_skew_ms"             // Database clock minus ours, see db.MonitorSkew
+	DBReplicationLag      = "db.replication_lag_seconds"   // Worst replica, sampled by db.LagThrottle while deleting
+	DBCircuitOpen         = "db.circuit_open"              // 1 while the circuit breaker is open or probing, see db.CircuitState
+	HTTPStreamsOpen       = "http.streams_open"            // Streamed list and export responses in progress
+	PingSpoolDepth        = "ping_spool.depth"             // Pings waiting in the spool, see spool.Spool.Depth
+	OutboxBacklog         = "notifications.outbox_backlog" // Rows in the notification outbox at its consumer's last poll
+)
+
+// Metrics is a metrics backend. Tags are "key:value" strings. Implementations
+// must be safe for concurrent use.
+type Metrics interface {
+	// Count adds value to a counter.
+	Count(name string, value int64, tags ...string)
+	// Timing records one duration.
+	Timing(name string, d time.Duration, tags ...string)
+	// Gauge sets the current value of a gauge.
+	Gauge(name string, value float64, tags ...string)
+	// Histogram records one sample of a distribution, such as a size.
+	Histogram(name string, value float64, tags ...string)
+	// Close flushes anything buffered.
+	Close() error
+}
+
+// Nop discards everything. It is the default.
+type Nop struct{}
+
+func (Nop) Count(string, int64, ...string)          {}
+func (Nop) Timing(string, time.Duration, ...string) {}
+func (Nop) Gauge(string, float64, ...string)        {}
+func (Nop) Histogram(string, float64, ...string)    {}
+func (Nop) Close() error                            { return nil }
+
+// holder keeps atomic.Value storing a single concrete type.
+type holder struct{ m Metrics }
+
+var current atomic.Value
+
+func init() {
+	current.Store(holder{Nop{}})
+}
+
+// SetDefault makes m the backend used by the package-level functions.
+func SetDefault(m Metrics) {
+	if m == nil {
+		m = Nop{}
+	}
+	current.Store(holder{m})
+}
+
+// Default returns the backend in use.
+func Default() Metrics {
+	return current.Load().(holder).m
+}
+
+// Incr adds one to a counter on the default backend.
+func Incr(name string, tags ...string) {
+	Default().Count(name, 1, tags...)
+}
+
+// Timing records d on the default backend.
+func Timing(name string, d time.Duration, tags ...string) {
+	Default().Timing(name, d, tags...)
+}
+
+// Histogram records one sample on the default backend.
+func Histogram(name string, value float64, tags ...string) {
+	Default().Histogram(name, value, tags...)
+}
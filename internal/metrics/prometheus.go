@@ -0,0 +1,234 @@
+package metrics
+
+import (
+	"bytes"
+	"database/sql"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimingBuckets are the histogram buckets of timers, in seconds.
+var DefaultTimingBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// DefaultHistogramBuckets are the buckets of histograms without their own in
+// PrometheusConfig.Buckets. Histograms record sizes, so these are bytes.
+var DefaultHistogramBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// PrometheusConfig configures the Prometheus backend.
+type PrometheusConfig struct {
+	Namespace string // prepended to every metric name with "_", e.g. "bitterlink"
+	// Buckets maps histogram names (e.g. PingPayloadBytes) to their upper bounds,
+	// ascending. Others get DefaultHistogramBuckets.
+	Buckets map[string][]float64
+	// DBPool, when set, has its Stats() exported with each scrape.
+	DBPool *sql.DB
+}
+
+// Prometheus keeps metrics in memory and serves them in the Prometheus text
+// format. Counters get a _total suffix and timers become histograms in seconds
+// named <name>_seconds. "key:value" tags become labels.
+//
+// There is no collector goroutine: the runtime, process and pool gauges and the
+// RegisterGaugeFunc gauges are sampled as each scrape comes in, the same set
+// CollectRuntime sends to push backends.
+type Prometheus struct {
+	config PrometheusConfig
+
+	mu       sync.Mutex
+	families map[string]*promFamily // By exposed name
+
+	scrapeMu  sync.Mutex
+	lastNumGC uint32
+}
+
+type promFamily struct {
+	kind   string // counter, gauge or histogram
+	bounds []float64
+	series map[string]*promSeries // By formatted labels
+}
+
+type promSeries struct {
+	value   float64  // Counters and gauges
+	buckets []uint64 // Histograms: observations per bound, not cumulative
+	count   uint64
+	sum     float64
+}
+
+// NewPrometheus creates the backend. Serve it with its ServeHTTP.
+func NewPrometheus(cfg PrometheusConfig) *Prometheus {
+	return &Prometheus{config: cfg, families: make(map[string]*promFamily)}
+}
+
+func (p *Prometheus) Count(name string, value int64, tags ...string) {
+	p.series(p.name(name)+"_total", "counter", nil, tags, func(s *promSeries) { s.value += float64(value) })
+}
+
+func (p *Prometheus) Timing(name string, d time.Duration, tags ...string) {
+	p.observe(p.name(name)+"_seconds", DefaultTimingBuckets, d.Seconds(), tags)
+}
+
+func (p *Prometheus) Gauge(name string, value float64, tags ...string) {
+	p.series(p.name(name), "gauge", nil, tags, func(s *promSeries) { s.value = value })
+}
+
+func (p *Prometheus) Histogram(name string, value float64, tags ...string) {
+	bounds, ok := p.config.Buckets[name]
+	if !ok {
+		bounds = DefaultHistogramBuckets
+	}
+	p.observe(p.name(name), bounds, value, tags)
+}
+
+// Close has nothing to flush.
+func (p *Prometheus) Close() error { return nil }
+
+func (p *Prometheus) observe(name string, bounds []float64, value float64, tags []string) {
+	p.series(name, "histogram", bounds, tags, func(s *promSeries) {
+		// Above the last bound only counts towards +Inf, that is count
+		if i, _ := slices.BinarySearch(bounds, value); i < len(bounds) {
+			s.buckets[i]++
+		}
+		s.count++
+		s.sum += value
+	})
+}
+
+// series applies update to the series of name with tags, creating it first.
+// A name already used with another kind keeps its first one.
+func (p *Prometheus) series(name, kind string, bounds []float64, tags []string, update func(*promSeries)) {
+	labels := formatLabels(tags)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	family := p.families[name]
+	if family == nil {
+		family = &promFamily{kind: kind, bounds: bounds, series: make(map[string]*promSeries)}
+		p.families[name] = family
+	}
+	if family.kind != kind {
+		return
+	}
+	s := family.series[labels]
+	if s == nil {
+		s = &promSeries{buckets: make([]uint64, len(family.bounds))}
+		family.series[labels] = s
+	}
+	update(s)
+}
+
+// name turns a metric name such as "pings.payload_bytes" into
+// "bitterlink_pings_payload_bytes".
+func (p *Prometheus) name(name string) string {
+	if p.config.Namespace != "" {
+		name = p.config.Namespace + "_" + name
+	}
+	return sanitizeName(name)
+}
+
+// sanitizeName replaces what a Prometheus name can't hold with underscores.
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// formatLabels turns "key:value" tags into key="value" pairs sorted by key. A
+// tag without a colon becomes a label with an empty value.
+func formatLabels(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, ":")
+		pairs = append(pairs, sanitizeName(key)+`="`+escapeLabel(value)+`"`)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string { return labelEscaper.Replace(value) }
+
+// ServeHTTP samples the gauges and writes every metric in the text format.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.scrapeMu.Lock()
+	p.lastNumGC = collectRuntimeOnce(p, p.config.DBPool, p.lastNumGC)
+	p.scrapeMu.Unlock()
+
+	var buf bytes.Buffer
+	p.write(&buf)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// write formats all families, sorted by name and labels so scrapes diff cleanly.
+func (p *Prometheus) write(buf *bytes.Buffer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.families))
+	for name := range p.families {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		family := p.families[name]
+		buf.WriteString("# TYPE " + name + " " + family.kind + "\n")
+		labelSets := make([]string, 0, len(family.series))
+		for labels := range family.series {
+			labelSets = append(labelSets, labels)
+		}
+		slices.Sort(labelSets)
+		for _, labels := range labelSets {
+			s := family.series[labels]
+			if family.kind != "histogram" {
+				writeSample(buf, name, labels, "", s.value)
+				continue
+			}
+			cumulative := uint64(0)
+			for i, bound := range family.bounds {
+				cumulative += s.buckets[i]
+				writeSample(buf, name+"_bucket", labels, `le="`+formatFloat(bound)+`"`, float64(cumulative))
+			}
+			writeSample(buf, name+"_bucket", labels, `le="+Inf"`, float64(s.count))
+			writeSample(buf, name+"_sum", labels, "", s.sum)
+			writeSample(buf, name+"_count", labels, "", float64(s.count))
+		}
+	}
+}
+
+func writeSample(buf *bytes.Buffer, name, labels, extra string, value float64) {
+	buf.WriteString(name)
+	if labels != "" || extra != "" {
+		buf.WriteByte('{')
+		buf.WriteString(labels)
+		if labels != "" && extra != "" {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(extra)
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(value))
+	buf.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape fetches p's exposition.
+func scrape(t *testing.T, p *Prometheus) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestPrometheusExposition(t *testing.T) {
+	p := NewPrometheus(PrometheusConfig{
+		Namespace: "bitterlink",
+		Buckets:   map[string][]float64{PingPayloadBytes: {100, 1000}},
+	})
+	p.Count(PingsIngested, 1, "kind:success", "result:ok")
+	p.Count(PingsIngested, 2, "result:ok", "kind:success") // Same series, tags in another order
+	p.Count(PingsIngested, 1, "kind:fail", "result:ok")
+	p.Gauge(DBCircuitOpen, 1)
+	p.Gauge(DBCircuitOpen, 0)
+	p.Histogram(PingPayloadBytes, 100) // On a bound: counted in it
+	p.Histogram(PingPayloadBytes, 512)
+	p.Histogram(PingPayloadBytes, 5000) // Only in +Inf
+	p.Timing(HTTPRequestDuration, 20*time.Millisecond, "route:/ping/:uuid")
+
+	got := scrape(t, p)
+	for _, want := range []string{
+		"# TYPE bitterlink_pings_ingested_total counter\n" +
+			`bitterlink_pings_ingested_total{kind="fail",result="ok"} 1` + "\n" +
+			`bitterlink_pings_ingested_total{kind="success",result="ok"} 3` + "\n",
+		"# TYPE bitterlink_db_circuit_open gauge\nbitterlink_db_circuit_open 0\n",
+		"# TYPE bitterlink_pings_payload_bytes histogram\n" +
+			`bitterlink_pings_payload_bytes_bucket{le="100"} 1` + "\n" +
+			`bitterlink_pings_payload_bytes_bucket{le="1000"} 2` + "\n" +
+			`bitterlink_pings_payload_bytes_bucket{le="+Inf"} 3` + "\n" +
+			"bitterlink_pings_payload_bytes_sum 5612\n" +
+			"bitterlink_pings_payload_bytes_count 3\n",
+		`bitterlink_http_request_duration_seconds_bucket{route="/ping/:uuid",le="0.01"} 0` + "\n" +
+			`bitterlink_http_request_duration_seconds_bucket{route="/ping/:uuid",le="0.025"} 1` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("exposition missing\n%s\ngot\n%s", want, got)
+		}
+	}
+}
+
+// Runtime and registered gauges are read on each scrape, with no collector
+// goroutine running.
+func TestPrometheusSamplesGaugesOnScrape(t *testing.T) {
+	depth := 3.0
+	RegisterGaugeFunc(PingSpoolDepth, func() float64 { return depth })
+	t.Cleanup(func() {
+		gaugeFuncs.mu.Lock()
+		delete(gaugeFuncs.fns, PingSpoolDepth)
+		gaugeFuncs.mu.Unlock()
+	})
+	p := NewPrometheus(PrometheusConfig{Namespace: "bitterlink"})
+
+	got := scrape(t, p)
+	for _, want := range []string{"bitterlink_ping_spool_depth 3\n", "bitterlink_runtime_goroutines "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("first scrape missing %q:\n%s", want, got)
+		}
+	}
+	depth = 0
+	if got := scrape(t, p); !strings.Contains(got, "bitterlink_ping_spool_depth 0\n") {
+		t.Errorf("second scrape kept the old depth:\n%s", got)
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	p := NewPrometheus(PrometheusConfig{})
+	p.Count("webhooks.deliveries", 1, `event:say "hi"\now`, "flag")
+	want := `webhooks_deliveries_total{event="say \"hi\"\\now",flag=""} 1`
+	if got := scrape(t, p); !strings.Contains(got, want) {
+		t.Errorf("exposition missing %s:\n%s", want, got)
+	}
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// gaugeFuncs are application gauges registered with RegisterGaugeFunc.
+var gaugeFuncs struct {
+	mu  sync.Mutex
+	fns map[string]func() float64
+}
+
+// RegisterGaugeFunc adds an application gauge (e.g. open SSE connections or a
+// buffer depth) that CollectRuntime samples on every tick, and the Prometheus
+// backend on every scrape. fn must be cheap and non-blocking: read a counter,
+// don't query the database. Registering a name again replaces the previous fn.
+func RegisterGaugeFunc(name string, fn func() float64) {
+	gaugeFuncs.mu.Lock()
+	defer gaugeFuncs.mu.Unlock()
+	if gaugeFuncs.fns == nil {
+		gaugeFuncs.fns = make(map[string]func() float64)
+	}
+	gaugeFuncs.fns[name] = fn
+}
+
+// CollectRuntime samples Go runtime, process and connection pool gauges, plus the
+// registered application gauges, every interval until ctx is cancelled. dbPool
+// may be nil. Only dbPool.Stats() is read, which never touches the database.
+func CollectRuntime(ctx context.Context, interval time.Duration, dbPool *sql.DB) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastNumGC uint32
+	for {
+		select {
+		case <-ticker.C:
+			lastNumGC = collectRuntimeOnce(Default(), dbPool, lastNumGC)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// collectRuntimeOnce emits one sample of every gauge and the GC pauses since the
+// collection numbered lastNumGC. Returns the current collection count.
+func collectRuntimeOnce(m Metrics, dbPool *sql.DB, lastNumGC uint32) uint32 {
+	// 1. Go runtime
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	m.Gauge(RuntimeGoroutines, float64(runtime.NumGoroutine()))
+	m.Gauge(RuntimeHeapAllocBytes, float64(mem.HeapAlloc))
+	m.Gauge(RuntimeHeapSysBytes, float64(mem.HeapSys))
+
+	// 2. GC pauses since the last tick. PauseNs is a ring of the 256 most recent,
+	// so a burst of more collections than that only reports the newest.
+	newGCs := mem.NumGC - lastNumGC
+	if lastNumGC == 0 || newGCs > uint32(len(mem.PauseNs)) {
+		newGCs = min(mem.NumGC, uint32(len(mem.PauseNs)))
+	}
+	for i := uint32(0); i < newGCs; i++ {
+		pause := mem.PauseNs[(mem.NumGC-i+uint32(len(mem.PauseNs))-1)%uint32(len(mem.PauseNs))]
+		m.Timing(RuntimeGCPause, time.Duration(pause))
+	}
+
+	// 3. Process
+	if fds, ok := openFDs(); ok {
+		m.Gauge(ProcessOpenFDs, float64(fds))
+	}
+
+	// 4. Connection pool
+	if dbPool != nil {
+		stats := dbPool.Stats()
+		m.Gauge(DBPoolOpen, float64(stats.OpenConnections))
+		m.Gauge(DBPoolInUse, float64(stats.InUse))
+		m.Gauge(DBPoolIdle, float64(stats.Idle))
+		m.Gauge(DBPoolWaitCount, float64(stats.WaitCount))
+		m.Gauge(DBPoolWaitDuration, float64(stats.WaitDuration.Milliseconds()))
+	}
+
+	// 5. Application gauges, in a stable order
+	gaugeFuncs.mu.Lock()
+	names := make([]string, 0, len(gaugeFuncs.fns))
+	for name := range gaugeFuncs.fns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fns := make([]func() float64, len(names))
+	for i, name := range names {
+		fns[i] = gaugeFuncs.fns[name]
+	}
+	gaugeFuncs.mu.Unlock()
+	for i, name := range names {
+		m.Gauge(name, fns[i]())
+	}
+
+	return mem.NumGC
+}
+
+// openFDs counts this process's open file descriptors. Only available where
+// /proc/self/fd exists (Linux); elsewhere ok is false.
+func openFDs() (int, bool) {
+	dir, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	defer dir.Close()
+	entries, err := dir.Readdirnames(-1)
+	if err != nil {
+		return 0, false
+	}
+	return len(entries) - 1, true // Minus the descriptor opened to read the directory
+}
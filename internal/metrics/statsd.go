@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPacketBytes keeps each datagram within a typical 1500-byte MTU after
+// IP/UDP headers, so packets aren't fragmented (and silently lost).
+const maxPacketBytes = 1432
+
+// StatsDConfig configures the StatsD/DogStatsD backend.
+type StatsDConfig struct {
+	Addr   string   // host:port of the agent
+	Prefix string   // prepended to every metric name, e.g. "bitterlink."
+	Tags   []string // global "key:value" tags added to every metric
+	// SampleRates maps metric names to the fraction of events sent (0 < rate < 1).
+	// Meant for the hottest counters; unlisted metrics are always sent.
+	SampleRates map[string]float64
+	// FlushInterval bounds how long a line waits in the buffer. Defaults to 1s.
+	FlushInterval time.Duration
+}
+
+// StatsD sends metrics over UDP in the DogStatsD line format
+// (name:value|type|@rate|#tag:value,...). Lines are buffered and sent in
+// MTU-sized packets, flushed when full and every FlushInterval.
+type StatsD struct {
+	conn   net.Conn
+	config StatsDConfig
+	tags   string // global tags, preformatted
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewStatsD dials the agent and starts the flush loop. UDP is connectionless, so
+// this only fails on an unresolvable address; a missing agent just drops metrics.
+func NewStatsD(cfg StatsDConfig) (*StatsD, error) {
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("dialing statsd at %s: %w", cfg.Addr, err)
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	s := &StatsD{
+		conn:   conn,
+		config: cfg,
+		tags:   strings.Join(cfg.Tags, ","),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+// Count implements Metrics.
+func (s *StatsD) Count(name string, value int64, tags ...string) {
+	s.record(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Timing implements Metrics. Durations are sent in milliseconds.
+func (s *StatsD) Timing(name string, d time.Duration, tags ...string) {
+	s.record(name, strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Gauge implements Metrics.
+func (s *StatsD) Gauge(name string, value float64, tags ...string) {
+	s.record(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+// Histogram implements Metrics, with the DogStatsD histogram type. The agent
+// (or statsd_exporter, for Prometheus) aggregates the samples into buckets.
+func (s *StatsD) Histogram(name string, value float64, tags ...string) {
+	s.record(name, strconv.FormatFloat(value, 'f', -1, 64), "h", tags)
+}
+
+// Close stops the flush loop, sends what is buffered and closes the socket.
+func (s *StatsD) Close() error {
+	close(s.stop)
+	<-s.done
+	s.mu.Lock()
+	s.flushLocked()
+	s.mu.Unlock()
+	return s.conn.Close()
+}
+
+// record formats one line and appends it to the buffer, applying sampling.
+func (s *StatsD) record(name, value, metricType string, tags []string) {
+	rate, sampled := s.config.SampleRates[name]
+	if sampled && rand.Float64() >= rate {
+		return
+	}
+
+	var line strings.Builder
+	line.WriteString(s.config.Prefix)
+	line.WriteString(name)
+	line.WriteByte(':')
+	line.WriteString(value)
+	line.WriteByte('|')
+	line.WriteString(metricType)
+	if sampled {
+		line.WriteString("|@")
+		line.WriteString(strconv.FormatFloat(rate, 'f', -1, 64))
+	}
+	if s.tags != "" || len(tags) > 0 {
+		line.WriteString("|#")
+		line.WriteString(s.tags)
+		if s.tags != "" && len(tags) > 0 {
+			line.WriteByte(',')
+		}
+		line.WriteString(strings.Join(tags, ","))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Send the buffer first if this line would overflow the packet
+	if s.buf.Len() > 0 && s.buf.Len()+1+line.Len() > maxPacketBytes {
+		s.flushLocked()
+	}
+	if s.buf.Len() > 0 {
+		s.buf.WriteByte('\n')
+	}
+	s.buf.WriteString(line.String())
+}
+
+func (s *StatsD) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			s.flushLocked()
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// flushLocked sends the buffer as one datagram. Callers hold s.mu.
+func (s *StatsD) flushLocked() {
+	if s.buf.Len() == 0 {
+		return
+	}
+	if _, err := s.conn.Write(s.buf.Bytes()); err != nil {
+		// Metrics are best effort; an agent that isn't listening shows up here
+		log.Printf("DEBUG: Failed to send %d bytes of metrics: %v", s.buf.Len(), err)
+	}
+	s.buf.Reset()
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listen opens a local UDP listener standing in for the agent.
+func listen(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readLines reads one datagram and splits it into lines.
+func readLines(t *testing.T, conn net.PacketConn) []string {
+	t.Helper()
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("reading packet: %v", err)
+	}
+	if n > maxPacketBytes {
+		t.Errorf("packet of %d bytes, want at most %d", n, maxPacketBytes)
+	}
+	return strings.Split(string(buf[:n]), "\n")
+}
+
+func TestStatsDLines(t *testing.T) {
+	agent := listen(t)
+	s, err := NewStatsD(StatsDConfig{
+		Addr:          agent.LocalAddr().String(),
+		Prefix:        "bitterlink.",
+		Tags:          []string{"env:test", "role:api"},
+		SampleRates:   map[string]float64{"pings.received": 0.999999},
+		FlushInterval: time.Hour, // Only Close flushes
+	})
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+
+	s.Count("checks.down", 2, "severity:critical")
+	s.Timing("ping.duration", 1500*time.Microsecond)
+	s.Gauge("db.clock_skew_seconds", -0.25)
+	s.Histogram("ping.payload_bytes", 512)
+	s.Count("pings.received", 1)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := []string{
+		"bitterlink.checks.down:2|c|#env:test,role:api,severity:critical",
+		"bitterlink.ping.duration:1.5|ms|#env:test,role:api",
+		"bitterlink.db.clock_skew_seconds:-0.25|g|#env:test,role:api",
+		"bitterlink.ping.payload_bytes:512|h|#env:test,role:api",
+		"bitterlink.pings.received:1|c|@0.999999|#env:test,role:api",
+	}
+	if got := readLines(t, agent); !slices.Equal(got, want) {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestStatsDWithoutTags(t *testing.T) {
+	agent := listen(t)
+	s, err := NewStatsD(StatsDConfig{Addr: agent.LocalAddr().String(), FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+	s.Count("pings.received", 1)
+	s.Count("pings.received", 1, "kind:fail")
+	s.Close()
+
+	want := []string{"pings.received:1|c", "pings.received:1|c|#kind:fail"}
+	if got := readLines(t, agent); !slices.Equal(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
+
+// Lines that don't fit a packet go out in the next one.
+func TestStatsDSplitsPackets(t *testing.T) {
+	agent := listen(t)
+	s, err := NewStatsD(StatsDConfig{Addr: agent.LocalAddr().String(), Prefix: "bitterlink.", FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+	const sent = 100 // About 3.5KB of lines
+	for range sent {
+		s.Count("pings.received", 1, "check:0123456789abcdef")
+	}
+	s.Close()
+
+	got := 0
+	for got < sent {
+		for _, line := range readLines(t, agent) {
+			if line != "bitterlink.pings.received:1|c|#check:0123456789abcdef" {
+				t.Fatalf("line = %q", line)
+			}
+			got++
+		}
+	}
+	if got != sent {
+		t.Errorf("received %d lines, want %d", got, sent)
+	}
+}
+
+// The flush loop sends buffered lines without waiting for Close.
+func TestStatsDFlushInterval(t *testing.T) {
+	agent := listen(t)
+	s, err := NewStatsD(StatsDConfig{Addr: agent.LocalAddr().String(), FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewStatsD: %v", err)
+	}
+	defer s.Close()
+	s.Gauge("ping_spool.depth", 3)
+
+	if got := readLines(t, agent); !slices.Equal(got, []string{"ping_spool.depth:3|g"}) {
+		t.Errorf("lines = %q", got)
+	}
+}
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"bitterlink/core/internal/agency"
-	//"context"
+	"bitterlink/core/internal/models"
+	"context"
 	"database/sql"
 	"errors" // Import errors package
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -16,6 +18,8 @@ import (
 
 const UserIDKey = "userID" // Key to store/retrieve user ID from Gin context
 
+const ScopeKey = "apiKeyScope" // Key to store/retrieve the API key's scope from Gin context
+
 // APIKeyAuthMiddleware creates a Gin middleware handler for API key authentication.
 // It requires a database connection pool to validate keys.
 func APIKeyAuthMiddleware(db *sql.DB) gin.HandlerFunc {
@@ -54,49 +58,79 @@ func APIKeyAuthMiddleware(db *sql.DB) gin.HandlerFunc {
 		}
 
 		// 2. Validate the key against the database
-		// IMPORTANT SECURITY NOTE: In production, you should HASH API keys in the database
-		// and compare hashes, not plaintext keys. This example uses plaintext for simplicity.
-		var userID int
-		var isActive bool
-
-		query := "SELECT user_id, is_active FROM api_keys WHERE key_value = ? LIMIT 1"
-		err := db.QueryRowContext(c.Request.Context(), query, strings.TrimSpace(apiKey)).Scan(&userID, &isActive)
+		userID, scope, err := ResolveAPIKeyScope(c.Request.Context(), db, apiKey)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				// Key not found
+			switch {
+			case errors.Is(err, ErrInvalidAPIKey):
 				log.Printf("WARN: Invalid API key presented via Bearer token: %s...", apiKey[:agency.Min(len(apiKey), 10)]) // Log prefix only
 				c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="Invalid API key"`)
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 					"error": "Invalid API key",
 				})
-				return
+			case errors.Is(err, ErrInactiveAPIKey):
+				// 3. The key exists but is not active
+				log.Printf("WARN: Inactive API key presented for user %d", userID)
+				c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="API key is inactive"`)
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "API key is inactive",
+				})
+			default:
+				// Other database error
+				log.Printf("ERROR: Database error during API key validation: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Could not validate API key",
+				})
 			}
-			// Other database error
-			log.Printf("ERROR: Database error during API key validation: %v", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": "Could not validate API key",
-			})
 			return
 		}
 
-		// 3. Check if the key is active
-		if !isActive {
-			log.Printf("WARN: Inactive API key presented for user %d", userID)
-			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token", error_description="API key is inactive"`)
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "API key is inactive",
-			})
-			return
-		}
-
-		// 4. Store User ID in context for downsteam handlers
+		// 4. Store User ID (and the key's scope) in context for downsteam handlers
 		c.Set(UserIDKey, userID)
+		c.Set(ScopeKey, scope)
 		log.Printf("INFO: API key validated successfully for user %d", userID)
 		// 5. Call the next handler in the chain
 		c.Next()
 	}
 }
 
+var (
+	// ErrInvalidAPIKey means no API key with that value exists.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrInactiveAPIKey means the key exists but has been deactivated.
+	ErrInactiveAPIKey = errors.New("API key is inactive")
+)
+
+// ResolveAPIKey looks up the user an API key belongs to. It is shared by the HTTP
+// middleware and the gRPC interceptor so both accept exactly the same keys.
+// For an inactive key the owner's ID is returned along with ErrInactiveAPIKey.
+func ResolveAPIKey(ctx context.Context, db *sql.DB, apiKey string) (int, error) {
+	userID, _, err := ResolveAPIKeyScope(ctx, db, apiKey)
+	return userID, err
+}
+
+// ResolveAPIKeyScope is ResolveAPIKey that also returns the key's scope
+// (models.ScopeUser or models.ScopeAdmin).
+func ResolveAPIKeyScope(ctx context.Context, db *sql.DB, apiKey string) (int, string, error) {
+	// IMPORTANT SECURITY NOTE: In production, you should HASH API keys in the database
+	// and compare hashes, not plaintext keys. This example uses plaintext for simplicity.
+	var userID int
+	var scope string
+	var isActive bool
+
+	query := "SELECT user_id, scope, is_active FROM api_keys WHERE key_value = ? LIMIT 1"
+	err := db.QueryRowContext(ctx, query, strings.TrimSpace(apiKey)).Scan(&userID, &scope, &isActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", ErrInvalidAPIKey
+		}
+		return 0, "", fmt.Errorf("querying API key: %w", err)
+	}
+	if !isActive {
+		return userID, scope, ErrInactiveAPIKey
+	}
+	return userID, scope, nil
+}
+
 // GetUserIDFromContext retrieves the user ID stored in the Gin context by the middleware.
 // Returns the user ID and true if found, otherwise 0 and false.
 func GetUserIDFromContext(c *gin.Context) (int, bool) {
@@ -111,3 +145,48 @@ func GetUserIDFromContext(c *gin.Context) (int, bool) {
 	}
 	return userID, true
 }
+
+// RequireAdminScope only lets through requests authenticated with an admin-scoped
+// API key. It runs after APIKeyAuthMiddleware; identities asserted by a trusted
+// gateway header carry no scope and are refused as well.
+func RequireAdminScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, _ := c.Get(ScopeKey)
+		if scope != models.ScopeAdmin {
+			userID, _ := GetUserIDFromContext(c)
+			log.Printf("WARN: User %d denied access to admin endpoint %s", userID, c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Admin scope required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HCAPIKeyMiddleware authenticates the healthchecks.io-compatible API, which
+// sends the key in X-Api-Key rather than as a Bearer token. Keys are the same
+// API keys, resolved with ResolveAPIKey; errors use that API's {"error": ...} shape.
+func HCAPIKeyMiddleware(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := strings.TrimSpace(c.GetHeader("X-Api-Key"))
+		if apiKey == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing api key"})
+			return
+		}
+		userID, err := ResolveAPIKey(c.Request.Context(), db, apiKey)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrInvalidAPIKey), errors.Is(err, ErrInactiveAPIKey):
+				log.Printf("WARN: Rejected X-Api-Key for healthchecks.io API: %v", err)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "wrong api key"})
+			default:
+				log.Printf("ERROR: Database error during API key validation: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "could not validate api key"})
+			}
+			return
+		}
+		c.Set(UserIDKey, userID)
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DBCircuitMiddleware refuses requests with 503 and Retry-After while the
+// database circuit breaker is open, instead of letting each one wait for a
+// connection that won't come. It goes before anything that queries the
+// database, authentication included.
+func DBCircuitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ready, retryAfter := db.CircuitReady()
+		if ready {
+			c.Next()
+			return
+		}
+		metrics.Incr(metrics.DBCircuitRejected, "transport:http")
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Database unavailable, try again later",
+		})
+	}
+}
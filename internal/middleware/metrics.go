@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware counts and times every request. Requests are tagged with the
+// route pattern rather than the path, so per-check URLs don't explode the number
+// of series.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		tags := []string{
+			"method:" + c.Request.Method,
+			"route:" + route,
+			"status:" + strconv.Itoa(c.Writer.Status()),
+		}
+		metrics.Incr(metrics.HTTPRequests, tags...)
+		metrics.Timing(metrics.HTTPRequestDuration, time.Since(start), tags...)
+	}
+}
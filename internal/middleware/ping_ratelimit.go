@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"container/list"
+	"hash/maphash"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPLimiterConfig configures an IPLimiter. Zero values get the defaults noted.
+type IPLimiterConfig struct {
+	// Rate is the sustained pings per second allowed from one IP, default 10.
+	// One NAT gateway can front a whole fleet of cron jobs, so it is far above
+	// what a single host needs.
+	Rate float64
+	// Burst is how many pings one IP may send at once, default 300: jobs
+	// scheduled on the same minute all ping together
+	Burst int
+	// MaxIPs bounds the IPs tracked, default 100000. Beyond it the least
+	// recently seen one is forgotten, and starts over with a full bucket.
+	MaxIPs int
+}
+
+// ipLimiterShards splits the key space so concurrent pings from different IPs
+// rarely wait on the same lock.
+const ipLimiterShards = 16
+
+// IPLimiter is an in-memory token bucket per source IP, for the ping routes.
+// Unlike RateLimiter it runs before authentication and is never shared between
+// instances: its job is to stop one broken or abusive host from costing a
+// database lookup per request, not to enforce a quota.
+type IPLimiter struct {
+	rate   float64
+	burst  float64
+	seed   maphash.Seed
+	shards [ipLimiterShards]ipLimiterShard
+}
+
+// ipLimiterShard is an LRU of buckets, most recently seen at the front.
+type ipLimiterShard struct {
+	mu      sync.Mutex
+	maxIPs  int
+	buckets map[string]*list.Element
+	lru     list.List
+}
+
+// ipBucket is one IP's token bucket.
+type ipBucket struct {
+	ip     string
+	tokens float64
+	last   time.Time
+}
+
+// NewIPLimiter creates a per-IP limiter.
+func NewIPLimiter(cfg IPLimiterConfig) *IPLimiter {
+	if cfg.Rate <= 0 {
+		cfg.Rate = 10
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 300
+	}
+	if cfg.MaxIPs <= 0 {
+		cfg.MaxIPs = 100000
+	}
+	l := &IPLimiter{rate: cfg.Rate, burst: float64(cfg.Burst), seed: maphash.MakeSeed()}
+	for i := range l.shards {
+		l.shards[i].maxIPs = max(cfg.MaxIPs/ipLimiterShards, 1)
+		l.shards[i].buckets = make(map[string]*list.Element)
+	}
+	return l
+}
+
+// Allow takes a token from ip's bucket at time now. When it is empty it returns
+// false and how long until the next token.
+func (l *IPLimiter) Allow(ip string, now time.Time) (bool, time.Duration) {
+	s := &l.shards[maphash.String(l.seed, ip)%ipLimiterShards]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 1. Find the bucket, refilled for the time since it was last used
+	var b *ipBucket
+	if e, ok := s.buckets[ip]; ok {
+		s.lru.MoveToFront(e)
+		b = e.Value.(*ipBucket)
+		b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	} else {
+		if s.lru.Len() >= s.maxIPs {
+			oldest := s.lru.Back()
+			delete(s.buckets, oldest.Value.(*ipBucket).ip)
+			s.lru.Remove(oldest)
+		}
+		b = &ipBucket{ip: ip, tokens: l.burst, last: now}
+		s.buckets[ip] = s.lru.PushFront(b)
+	}
+
+	// 2. Take a token
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// IPRateLimitMiddleware rejects requests from an IP over limiter's rate with
+// 429 and Retry-After. The IP is c.ClientIP(), as stored with the ping. Accepted
+// requests get no headers, keeping the ping path as it was.
+func IPRateLimitMiddleware(limiter *IPLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		ok, retryAfter := limiter.Allow(ip, time.Now())
+		if ok {
+			c.Next()
+			return
+		}
+		// Counted rather than logged: a flood would flood the log too
+		metrics.Incr(metrics.PingsRateLimited)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "Rate limit exceeded",
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitResult is the outcome of a single RateLimiter.Allow call.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int           // Requests allowed per window
+	Remaining  int           // Requests left in the current window
+	ResetAfter time.Duration // Until the current window ends
+}
+
+// RateLimiter counts requests per key in fixed windows. Implementations must be
+// safe for concurrent use.
+//
+// The in-memory limiter only sees the requests of its own instance; behind a load
+// balancer use the Redis limiter so the limit is enforced across all of them.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string) (RateLimitResult, error)
+}
+
+// RateLimitMiddleware rejects requests over the limit with 429. It must run after
+// APIKeyAuthMiddleware so requests are limited per user; unauthenticated requests
+// fall back to the client IP.
+//
+// If the limiter fails (e.g. Redis is unreachable) the request is let through:
+// an outage of the limit store shouldn't take ping ingestion down with it.
+func RateLimitMiddleware(limiter RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. Pick the bucket key
+		key := "ip:" + c.ClientIP()
+		if userID, ok := GetUserIDFromContext(c); ok {
+			key = "user:" + strconv.Itoa(userID)
+		}
+
+		// 2. Count the request
+		result, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			log.Printf("ERROR: Rate limiter failed for %s, allowing request: %v", key, err)
+			c.Next()
+			return
+		}
+
+		// 3. Report the budget and reject if it's used up
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		resetSeconds := strconv.Itoa(int((result.ResetAfter + time.Second - 1) / time.Second)) // Round up
+		c.Header("X-RateLimit-Reset", resetSeconds)
+		if !result.Allowed {
+			log.Printf("WARN: Rate limit exceeded for %s", key)
+			c.Header("Retry-After", resetSeconds)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Rate limit exceeded",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// memoryWindow is the counter of one key in the in-memory limiter.
+type memoryWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryRateLimiter is a fixed-window limiter kept in process memory. It is the
+// default and is only correct for single-instance deployments.
+type MemoryRateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*memoryWindow
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimiter allows limit requests per key every window.
+func NewMemoryRateLimiter(limit int, window time.Duration) *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*memoryWindow),
+	}
+}
+
+// Allow implements RateLimiter.
+func (l *MemoryRateLimiter) Allow(_ context.Context, key string) (RateLimitResult, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows now and then so idle keys don't accumulate
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, w := range l.windows {
+			if !now.Before(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &memoryWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+	w.count++
+
+	return RateLimitResult{
+		Allowed:    w.count <= l.limit,
+		Limit:      l.limit,
+		Remaining:  max(l.limit-w.count, 0),
+		ResetAfter: w.resetAt.Sub(now),
+	}, nil
+}
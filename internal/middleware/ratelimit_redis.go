@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisRateLimitKeyPrefix namespaces limiter keys in a Redis shared with other apps.
+const redisRateLimitKeyPrefix = "bitterlink:ratelimit:"
+
+// fixedWindowScript increments the key's counter, starting the window's expiry on
+// the first request, and returns the count and the milliseconds left in the window.
+// Running it as a script keeps INCR and PEXPIRE atomic, so a crash between the two
+// can't leave a counter that never expires.
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// RedisRateLimiter is a fixed-window limiter stored in Redis, so every instance
+// behind a load balancer shares the same counters.
+type RedisRateLimiter struct {
+	client redis.Scripter
+	limit  int
+	window time.Duration
+}
+
+// NewRedisRateLimiter allows limit requests per key every window, counted in client.
+func NewRedisRateLimiter(client redis.Scripter, limit int, window time.Duration) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client, limit: limit, window: window}
+}
+
+// Allow implements RateLimiter.
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string) (RateLimitResult, error) {
+	values, err := fixedWindowScript.Run(ctx, l.client, []string{redisRateLimitKeyPrefix + key}, l.window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("running rate limit script: %w", err)
+	}
+	if len(values) != 2 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+	count, ttl := int(values[0]), time.Duration(values[1])*time.Millisecond
+
+	return RateLimitResult{
+		Allowed:    count <= l.limit,
+		Limit:      l.limit,
+		Remaining:  max(l.limit-count, 0),
+		ResetAfter: ttl,
+	}, nil
+}
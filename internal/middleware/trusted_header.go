@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedHeaderConfig configures TrustedHeaderAuthMiddleware.
+type TrustedHeaderConfig struct {
+	// Header carries the authenticated user's ID, e.g. X-User-ID.
+	Header string
+	// TrustedProxies are the gateway addresses allowed to set Header. Matched
+	// against the TCP peer address, never against X-Forwarded-For.
+	TrustedProxies []netip.Prefix
+}
+
+// ParseTrustedProxies parses a comma-separated list of IPs and CIDRs.
+func ParseTrustedProxies(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// TrustedHeaderAuthMiddleware accepts the user ID set by an authenticating gateway
+// in cfg.Header, for requests whose peer address is one of cfg.TrustedProxies.
+// Every other request goes through fallback (normally APIKeyAuthMiddleware), so
+// clients that bypass the gateway still need an API key. The header is ignored,
+// not trusted, when it arrives from anywhere else.
+func TrustedHeaderAuthMiddleware(cfg TrustedHeaderConfig, fallback gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(cfg.Header)
+		if value == "" {
+			fallback(c)
+			return
+		}
+
+		// 1. Only the gateway may assert identities. RemoteIP is the socket peer;
+		// ClientIP would honour X-Forwarded-For, which the caller controls.
+		peer, err := netip.ParseAddr(c.RemoteIP())
+		if err != nil || !isTrustedProxy(peer.Unmap(), cfg.TrustedProxies) {
+			log.Printf("WARN: Ignoring %s header from untrusted address %s", cfg.Header, c.RemoteIP())
+			fallback(c)
+			return
+		}
+
+		// 2. The gateway vouches for the user; reject values it shouldn't send
+		userID, err := strconv.Atoi(value)
+		if err != nil || userID <= 0 {
+			log.Printf("WARN: Invalid %s header %q from trusted proxy %s", cfg.Header, value, peer)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid user header",
+			})
+			return
+		}
+
+		// 3. Same context key as the API key middleware, so handlers can't tell the difference
+		c.Set(UserIDKey, userID)
+		c.Next()
+	}
+}
+
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
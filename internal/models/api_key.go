@@ -0,0 +1,26 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// APIKey represents an API key used to authenticate against the API.
+// It maps to the `api_keys` table in the database.
+type APIKey struct {
+	ID        int64          `json:"id"`
+	UserID    int64          `json:"user_id"`
+	KeyValue  string         `json:"-"` // Secret, never serialized
+	Label     sql.NullString `json:"label"`
+	Scope     string         `json:"scope"` // ScopeUser or ScopeAdmin
+	IsActive  bool           `json:"is_active"`
+	DeletedAt sql.NullTime   `json:"-"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// API key scopes.
+const (
+	ScopeUser  = "user"  // The owner's own checks
+	ScopeAdmin = "admin" // Additionally the instance-wide /api/v1/admin endpoints
+)
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// NotificationBlackout is a period during which no notifications are sent,
+// for any check, turned on and off by an admin during a major incident. It
+// maps to the `notification_blackouts` table.
+type NotificationBlackout struct {
+	ID        int64          `json:"id"`
+	Reason    sql.NullString `json:"reason"`
+	StartedBy int64          `json:"started_by"`
+	StartedAt time.Time      `json:"started_at"`
+	EndedBy   sql.NullInt64  `json:"ended_by"`
+	EndedAt   sql.NullTime   `json:"ended_at"` // NULL while active
+	// SendSummary asks for a summary of the status changes during the
+	// blackout once it ends, sent by the worker at SummarySentAt
+	SendSummary   bool         `json:"send_summary"`
+	SummarySentAt sql.NullTime `json:"summary_sent_at"`
+
+	FirstEventID int64 `json:"-"` // check_events after this and up to LastEventID
+	LastEventID  int64 `json:"-"`
+}
+
+// Active reports whether notifications are still held back.
+func (b *NotificationBlackout) Active() bool {
+	return !b.EndedAt.Valid
+}
+
+// BlackoutChange is one check whose status changed during a blackout.
+type BlackoutChange struct {
+	CheckID    int64  `json:"check_id"`
+	CheckUUID  string `json:"check_uuid"`
+	Name       string `json:"name"`
+	UserID     int64  `json:"user_id"`
+	FromStatus string `json:"from_status"` // Before its first change
+	ToStatus   string `json:"to_status"`   // After its last change
+	Changes    int    `json:"changes"`
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Notification channel kinds (notification_channels.type).
+const (
+	ChannelEmail        = "email"
+	ChannelWebhook      = "webhook"
+	ChannelSlack        = "slack"        // A Slack incoming webhook
+	ChannelAlertmanager = "alertmanager" // value is the Alertmanager base URL, see migration 0022
+)
+
+// ChannelTypes are the types a channel can be created with through the API.
+// Alertmanager channels are provisioned outside it; the API still reads,
+// updates and deletes them.
+var ChannelTypes = []string{ChannelWebhook, ChannelEmail, ChannelSlack}
+
+// IsValidChannelType reports whether channelType is one of ChannelTypes.
+func IsValidChannelType(channelType string) bool {
+	return slices.Contains(ChannelTypes, channelType)
+}
+
+// ChannelConfig is a channel's type-specific settings, stored as a JSON object
+// in notification_channels.config; a nil map is NULL. Each type has a single
+// key naming its destination (ChannelDestinationKey), which is copied to
+// notification_channels.value on save for delivery and verification.
+type ChannelConfig map[string]string
+
+// channelDestinationKeys maps a channel type to the config key of its destination.
+var channelDestinationKeys = map[string]string{
+	ChannelEmail:        "address",
+	ChannelWebhook:      "url",
+	ChannelSlack:        "webhook_url",
+	ChannelAlertmanager: "url",
+}
+
+// ChannelDestinationKey returns the config key holding the destination of a
+// channel of channelType, "" for an unknown type.
+func ChannelDestinationKey(channelType string) string {
+	return channelDestinationKeys[channelType]
+}
+
+// Destination returns the destination c holds for a channel of channelType.
+func (c ChannelConfig) Destination(channelType string) string {
+	return c[ChannelDestinationKey(channelType)]
+}
+
+// Value implements driver.Valuer: a JSON object, or NULL for a nil or empty map.
+func (c ChannelConfig) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]string(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner for the JSON column. NULL scans as nil.
+func (c *ChannelConfig) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ChannelConfig", src)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return fmt.Errorf("decoding channel config: %w", err)
+	}
+	*c = decoded
+	return nil
+}
+
+// ChannelTarget is one enabled channel of one check, as a delivery needs it:
+// the channel's value and the check's name, UUID and severity.
+type ChannelTarget struct {
+	ChannelID     int64
+	Value         string
+	CheckID       int64
+	CheckName     string
+	CheckUUID     string
+	CheckSeverity string // Severity* constant, at least the association's min_severity
+}
+
+// Channel verification states, see NotificationChannel.VerificationState.
+const (
+	ChannelVerified            = "verified"
+	ChannelPendingVerification = "pending_verification" // Not sent alerts yet
+)
+
+// NotificationChannel is one of an account's notification channels.
+type NotificationChannel struct {
+	ID                 int64          `json:"id"`
+	UserID             int64          `json:"user_id"`
+	Type               string         `json:"type"`   // Channel* constants
+	Value              string         `json:"value"`  // Destination from Config: address or URL; the API redacts URL passwords
+	Config             ChannelConfig  `json:"config"` // Type-specific settings, as redacted as Value
+	Label              sql.NullString `json:"label"`
+	IsEnabled          bool           `json:"is_enabled"`
+	IsVerified         bool           `json:"is_verified"`          // Only verified channels get alerts
+	VerificationSentAt sql.NullTime   `json:"verification_sent_at"` // Last verification attempt
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+// VerificationState is ChannelVerified or ChannelPendingVerification.
+func (ch NotificationChannel) VerificationState() string {
+	if ch.IsVerified {
+		return ChannelVerified
+	}
+	return ChannelPendingVerification
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChannelConfigRoundTrip(t *testing.T) {
+	config := ChannelConfig{"webhook_url": "https://hooks.slack.com/services/T1/B2/C3"}
+	stored, err := config.Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+	var scanned ChannelConfig
+	if err := scanned.Scan([]byte(stored.(string))); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if !reflect.DeepEqual(scanned, config) {
+		t.Errorf("round trip = %v, want %v", scanned, config)
+	}
+	if scanned.Destination(ChannelSlack) != config["webhook_url"] {
+		t.Errorf("Destination = %q", scanned.Destination(ChannelSlack))
+	}
+}
+
+func TestChannelConfigNull(t *testing.T) {
+	if v, err := (ChannelConfig{}).Value(); v != nil || err != nil {
+		t.Errorf("empty config Value() = %v, %v; want NULL", v, err)
+	}
+	config := ChannelConfig{"url": "x"}
+	if err := config.Scan(nil); err != nil || config != nil {
+		t.Errorf("Scan(NULL) = %v, config %v; want nil", err, config)
+	}
+	if err := config.Scan(`["not", "an", "object"]`); err == nil {
+		t.Error("Scan of a JSON array succeeded")
+	}
+}
+
+func TestIsValidChannelType(t *testing.T) {
+	for _, channelType := range []string{ChannelWebhook, ChannelEmail, ChannelSlack} {
+		if !IsValidChannelType(channelType) {
+			t.Errorf("IsValidChannelType(%q) = false", channelType)
+		}
+	}
+	for _, channelType := range []string{ChannelAlertmanager, "sms", ""} {
+		if IsValidChannelType(channelType) {
+			t.Errorf("IsValidChannelType(%q) = true", channelType)
+		}
+	}
+}
@@ -2,21 +2,314 @@ package models
 
 import (
 	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Check statuses (the checks.status ENUM).
+//
+// Status and IsEnabled are independent:
+//   - IsEnabled=false means the check exists but monitoring is off. The worker
+//     never evaluates it and no alerts are sent. By default pings are still
+//     recorded but don't change its status, which stays whatever it was when
+//     disabled (see repository.Config.InactivePingPolicy).
+//   - StatusLate means the check missed its expected interval but is still
+//     within its grace period. The worker moves it on to StatusDown once the
+//     grace period runs out; a success ping brings it back to StatusUp.
+//   - StatusPaused is a transient state set by the user (or an operator). The
+//     worker only evaluates 'up' checks so paused ones never go down, and a
+//     ping does not un-pause it.
+const (
+	StatusNew    = "new"
+	StatusUp     = "up"
+	StatusLate   = "late"
+	StatusDown   = "down"
+	StatusPaused = "paused"
+)
+
+// IsValidStatus reports whether status is one of the Status* constants.
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusNew, StatusUp, StatusLate, StatusDown, StatusPaused:
+		return true
+	}
+	return false
+}
+
+// Check severities (the checks.severity ENUM), lowest first. A check's severity
+// goes out with its notifications, and a channel attached to it only gets them
+// when the severity is at least the association's min_severity.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning" // The default
+	SeverityCritical = "critical"
+)
+
+// Severities are the Severity* constants in ascending order.
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+
+// IsValidSeverity reports whether severity is one of the Severity* constants.
+func IsValidSeverity(severity string) bool {
+	return slices.Contains(Severities, severity)
+}
+
 // Check represents the data structure for a monitored check.
 type Check struct {
-	ID               int64          `json:"id"`
-	UserID           int64          `json:"user_id"` // Or omit from JSON if not needed client-side
-	UUID             string         `json:"uuid"`    // Public ID
-	Name             string         `json:"name"`
-	Description      sql.NullString `json:"description"`       // Handles NULL TEXT
-	ExpectedInterval uint32         `json:"expected_interval"` // Assuming INT UNSIGNED
-	GracePeriod      uint32         `json:"grace_period"`      // Assuming INT UNSIGNED
-	LastPingAt       sql.NullTime   `json:"last_ping_at"`      // Handles NULL TIMESTAMP
-	Status           string         `json:"status"`            // ENUM maps nicely to string
-	IsEnabled        bool           `json:"is_enabled"`
-	CreatedAt        time.Time      `json:"created_at"` // Assumes parseTime=True in DSN
-	UpdatedAt        time.Time      `json:"updated_at"`
+	ID                    int64          `json:"id"`
+	UserID                int64          `json:"user_id"` // Or omit from JSON if not needed client-side
+	UUID                  string         `json:"uuid"`    // Public ID
+	Name                  string         `json:"name"`
+	Description           sql.NullString `json:"description"`            // Handles NULL TEXT
+	ExpectedInterval      uint32         `json:"expected_interval"`      // Assuming INT UNSIGNED
+	GracePeriod           uint32         `json:"grace_period"`           // Assuming INT UNSIGNED
+	LastPingAt            sql.NullTime   `json:"last_ping_at"`           // Handles NULL TIMESTAMP
+	Status                string         `json:"status"`                 // ENUM maps nicely to string, see Status* constants
+	IsEnabled             bool           `json:"is_enabled"`             // false = monitoring off, see Status* constants
+	NotifyLate            bool           `json:"notify_late"`            // also send a warning when the check goes 'late'
+	Severity              string         `json:"severity"`               // Sent with its notifications, see Severity* constants
+	RecoveryStabilization uint32         `json:"recovery_stabilization"` // seconds 'up' before the recovery notification, 0 = next worker tick
+	Color                 sql.NullString `json:"color"`                  // Dashboard only: #rrggbb, see NormalizeCheckColor
+	Icon                  sql.NullString `json:"icon"`                   // Dashboard only: one of CheckIcons
+	Metadata              CheckMetadata  `json:"metadata"`               // Owner's key-value labels, null when unset
+	ForwardURL            sql.NullString `json:"forward_url"`            // Pings are mirrored here, see internal/forward
+	ForwardFailures       uint32         `json:"forward_failures"`       // Consecutive failed forwards, reset by a success
+	ForwardLastError      sql.NullString `json:"forward_last_error"`     // Why the most recent failed forward failed
+	ForwardFailedAt       sql.NullTime   `json:"forward_failed_at"`      // When the most recent forward failed
+	PingsHistoryLimit     sql.NullInt32  `json:"pings_history_limit"`    // Keep only this many newest pings, NULL = no per-check limit
+	RequireSignedPings    bool           `json:"require_signed_pings"`   // Refuse pings without a valid signature, see internal/pingsig
+	PingResponseCode      sql.NullInt32  `json:"ping_response_code"`     // Status for successful pings, one of PingResponseCodes; NULL = 200
+	PingResponseBody      sql.NullString `json:"ping_response_body"`     // Plain-text body for successful pings, NULL = {"status":"ok"}
+	MaxDuration           sql.NullInt32  `json:"max_duration"`           // Seconds a run (start to success ping) may take, NULL = no limit
+	WarmupPings           uint32         `json:"warmup_pings"`           // Successful pings in a row a 'new' check needs to go 'up', see StatusAfterWarmup
+	WarmupSuccesses       uint32         `json:"warmup_successes"`       // Counted so far, while the check is 'new'
+	MutedUntil            sql.NullTime   `json:"muted_until"`            // Late and down notifications are silenced until then
+	TotalPings            uint64         `json:"total_pings"`            // Pings in the history, see repository.ReconcilePingCounters
+	PingsThisWeek         uint32         `json:"pings_this_week"`        // Of those, received in the last 7 days
+	LastFailureAt         sql.NullTime   `json:"last_failure_at"`        // Newest fail ping
+	CreatedAt             time.Time      `json:"created_at"`             // Assumes parseTime=True in DSN
+	UpdatedAt             time.Time      `json:"updated_at"`
+}
+
+// CheckIcons are the icon names a check may use. Color and icon are presentation
+// only; nothing in monitoring or alerting looks at them.
+var CheckIcons = []string{
+	"bell", "box", "clock", "cloud", "code", "cpu", "database", "globe",
+	"key", "lock", "mail", "server", "shield", "terminal", "zap",
+}
+
+// IsValidCheckIcon reports whether icon is one of CheckIcons.
+func IsValidCheckIcon(icon string) bool {
+	return slices.Contains(CheckIcons, icon)
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// NormalizeCheckColor validates a hex color code (#rgb or #rrggbb) and returns it
+// in the stored form, lowercase #rrggbb. ok is false for anything else.
+func NormalizeCheckColor(color string) (normalized string, ok bool) {
+	if !hexColor.MatchString(color) {
+		return "", false
+	}
+	color = strings.ToLower(color)
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color, true
+}
+
+// Bounds for Check.PingsHistoryLimit. Below the minimum a check's history is too
+// short to be useful; above the maximum, time-based pruning is the better fit.
+const (
+	MinPingsHistoryLimit = 10
+	MaxPingsHistoryLimit = 10000
+)
+
+// TimingBounds are the instance's limits on a check's expected_interval and
+// grace_period, in seconds, enforced by Check.Validate. A zero maximum means no
+// maximum. Very short intervals hammer the worker and notifications; very long
+// ones effectively never alert and are usually a typo.
+type TimingBounds struct {
+	MinExpectedInterval uint32
+	MaxExpectedInterval uint32
+	MinGracePeriod      uint32
+	MaxGracePeriod      uint32
+}
+
+// FieldErrors maps a field's JSON name to what is wrong with it.
+type FieldErrors map[string]string
+
+// Error lists the fields in name order, e.g. "expected_interval must be ...".
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + " " + e[field]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate checks c's expected_interval, grace_period and max_duration against bounds,
+// that its severity (if set) is a Severity* constant and its metadata within the
+// CheckMetadata limits. It
+// returns nil, or FieldErrors stating the allowed range of each field that is
+// outside it. expected_interval must be positive whatever the bounds.
+func (c *Check) Validate(bounds TimingBounds) error {
+	errs := FieldErrors{}
+	if msg, ok := inRange(c.ExpectedInterval, max(bounds.MinExpectedInterval, 1), bounds.MaxExpectedInterval); !ok {
+		errs["expected_interval"] = msg
+	}
+	if msg, ok := inRange(c.GracePeriod, bounds.MinGracePeriod, bounds.MaxGracePeriod); !ok {
+		errs["grace_period"] = msg
+	}
+	// A run may legitimately take longer than the interval, but not longer than
+	// any interval the instance allows
+	if c.MaxDuration.Valid {
+		if msg, ok := inRange(uint32(max(c.MaxDuration.Int32, 0)), 1, bounds.MaxExpectedInterval); !ok {
+			errs["max_duration"] = msg
+		}
+	}
+	if c.WarmupPings > MaxWarmupPings {
+		errs["warmup_pings"] = fmt.Sprintf("must be between 1 and %d", MaxWarmupPings)
+	}
+	if msg := c.Metadata.validate(); msg != "" {
+		errs["metadata"] = msg
+	}
+	if c.Severity != "" && !IsValidSeverity(c.Severity) {
+		errs["severity"] = "must be one of " + strings.Join(Severities, ", ")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// inRange reports whether value is within [lo, hi] (hi 0 = unbounded), and if
+// not, a message stating the range.
+func inRange(value, lo, hi uint32) (string, bool) {
+	switch {
+	case hi > 0 && (value < lo || value > hi):
+		return fmt.Sprintf("must be between %d and %d seconds on this instance", lo, hi), false
+	case value < lo:
+		return fmt.Sprintf("must be at least %d seconds on this instance", lo), false
+	}
+	return "", true
+}
+
+// DescriptionRules are the instance's limits on check descriptions, applied by
+// every API that accepts one.
+type DescriptionRules struct {
+	MaxLength int  // In characters (runes), after cleaning; 0 = unlimited
+	StripHTML bool // Remove tags so dashboards can render descriptions as-is
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Clean sanitizes a description for storage: control characters other than
+// newline and tab are removed (CRLF becomes LF), and with StripHTML so are HTML
+// tags. ok is false when the cleaned text is longer than MaxLength.
+func (r DescriptionRules) Clean(description string) (cleaned string, ok bool) {
+	description = strings.ReplaceAll(description, "\r\n", "\n")
+	description = strings.Map(func(ch rune) rune {
+		if ch == '\n' || ch == '\t' || !unicode.IsControl(ch) {
+			return ch
+		}
+		return -1
+	}, description)
+	if r.StripHTML {
+		description = htmlTag.ReplaceAllString(description, "")
+	}
+	if r.MaxLength > 0 && utf8.RuneCountInString(description) > r.MaxLength {
+		return "", false
+	}
+	return description, true
+}
+
+// PingResponseCodes are the statuses a check may answer successful pings with.
+var PingResponseCodes = []int{200, 201, 204}
+
+// MaxPingResponseBodyLength caps Check.PingResponseBody, in characters (runes).
+// It is meant for a short token like "OK", not a document.
+const MaxPingResponseBodyLength = 100
+
+// PingResponse is how a check answers successful pings, as stored in its
+// PingResponseCode and PingResponseBody, plus the check's name and interval for
+// the page shown to browsers.
+type PingResponse struct {
+	Code             sql.NullInt32
+	Body             sql.NullString
+	CheckName        string
+	ExpectedInterval uint32
+}
+
+// IsValidPingResponseCode reports whether code is one of PingResponseCodes.
+func IsValidPingResponseCode(code int) bool {
+	return slices.Contains(PingResponseCodes, code)
+}
+
+// IsPlainTextPingResponse reports whether body may be used as a ping response:
+// valid UTF-8, no control characters and at most MaxPingResponseBodyLength long.
+func IsPlainTextPingResponse(body string) bool {
+	if !utf8.ValidString(body) || utf8.RuneCountInString(body) > MaxPingResponseBodyLength {
+		return false
+	}
+	return strings.IndexFunc(body, unicode.IsControl) < 0
+}
+
+// IsMonitored reports whether the timeout worker evaluates this check and may alert on it.
+func (c *Check) IsMonitored() bool {
+	return c.IsEnabled && c.Status != StatusPaused
+}
+
+// MaxWarmupPings caps Check.WarmupPings.
+const MaxWarmupPings = 100
+
+// StatusAfterWarmup holds a 'new' check in its warmup: given newStatus, the
+// StatusAfterPing result for a ping of kind, it returns the status the check
+// actually moves to and its new warmup_successes, which is back to 0 once the
+// check leaves 'new' (a resumed check may land in 'new' again). Until warmupPings successful
+// pings in a row have arrived the check stays 'new', and the worker doesn't
+// alert on it; a fail ping counts as flapping during setup and starts the
+// count over rather than taking the check down. warmupPings 0 or 1 means no
+// warmup. Checks that aren't 'new' are unaffected.
+func StatusAfterWarmup(currentStatus, newStatus, kind string, warmupPings, successes uint32) (string, uint32) {
+	if currentStatus != StatusNew || newStatus == StatusNew || warmupPings <= 1 {
+		return newStatus, successes
+	}
+	if kind == PingKindFail {
+		return StatusNew, 0
+	}
+	if successes+1 < warmupPings {
+		return StatusNew, successes + 1
+	}
+	return newStatus, 0
+}
+
+// StatusAfterPing returns the status a check moves to when it receives a ping of
+// the given kind. Only enabled, non-paused checks change status: a success ping
+// flips 'new', 'late' or 'down' to 'up', and a fail ping flips any of them to 'down'.
+// Start pings never change status. Disabled checks keep whatever status they had.
+func StatusAfterPing(currentStatus string, kind string, isEnabled bool) string {
+	if !isEnabled || currentStatus == StatusPaused {
+		return currentStatus
+	}
+	switch kind {
+	case PingKindFail:
+		return StatusDown
+	case PingKindStart:
+		return currentStatus
+	default:
+		if currentStatus == StatusDown || currentStatus == StatusNew || currentStatus == StatusLate {
+			return StatusUp
+		}
+		return currentStatus
+	}
 }
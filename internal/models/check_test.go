@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+func TestStatusAfterPing(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		kind      string
+		isEnabled bool
+		want      string
+	}{
+		{name: "new check comes up", current: StatusNew, kind: PingKindSuccess, isEnabled: true, want: StatusUp},
+		{name: "down check recovers", current: StatusDown, kind: PingKindSuccess, isEnabled: true, want: StatusUp},
+		{name: "late check catches up", current: StatusLate, kind: PingKindSuccess, isEnabled: true, want: StatusUp},
+		{name: "up stays up", current: StatusUp, kind: PingKindSuccess, isEnabled: true, want: StatusUp},
+		{name: "fail takes it down", current: StatusUp, kind: PingKindFail, isEnabled: true, want: StatusDown},
+		{name: "start changes nothing", current: StatusDown, kind: PingKindStart, isEnabled: true, want: StatusDown},
+		{name: "paused stays paused", current: StatusPaused, kind: PingKindSuccess, isEnabled: true, want: StatusPaused},
+		{name: "paused ignores fail", current: StatusPaused, kind: PingKindFail, isEnabled: true, want: StatusPaused},
+		{name: "disabled keeps down", current: StatusDown, kind: PingKindSuccess, isEnabled: false, want: StatusDown},
+		{name: "disabled keeps new", current: StatusNew, kind: PingKindSuccess, isEnabled: false, want: StatusNew},
+		{name: "disabled ignores fail", current: StatusUp, kind: PingKindFail, isEnabled: false, want: StatusUp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusAfterPing(tt.current, tt.kind, tt.isEnabled); got != tt.want {
+				t.Errorf("StatusAfterPing(%q, %q, %v) = %q, want %q", tt.current, tt.kind, tt.isEnabled, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMonitored(t *testing.T) {
+	tests := []struct {
+		status    string
+		isEnabled bool
+		want      bool
+	}{
+		{StatusUp, true, true},
+		{StatusDown, true, true},
+		{StatusNew, true, true},
+		{StatusPaused, true, false},
+		{StatusUp, false, false},
+		{StatusPaused, false, false},
+	}
+	for _, tt := range tests {
+		check := Check{Status: tt.status, IsEnabled: tt.isEnabled}
+		if got := check.IsMonitored(); got != tt.want {
+			t.Errorf("IsMonitored() with status %q, enabled %v = %v, want %v", tt.status, tt.isEnabled, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Event types recorded in check_events.
+const (
+	EventStatusChanged = "status_changed"
+	EventCheckCreated  = "created"
+	EventCheckUpdated  = "updated"  // Settings saved through Update, see repository.CheckRepository
+	EventCheckEnabled  = "enabled"  // Monitoring turned back on, see Check.IsEnabled
+	EventCheckDisabled = "disabled" // Monitoring turned off
+	EventCheckDeleted  = "deleted"  // Soft-deleted
+	EventCheckSlow     = "slow"     // A run took longer than max_duration, linked to its success ping
+	// A notification was not sent because a dependency was down, see RelatedCheckID
+	EventNotificationSuppressed = "notification_suppressed"
+	EventCheckMuted             = "muted"              // Late and down notifications silenced, see Check.MutedUntil
+	EventNotificationMuted      = "notification_muted" // A notification was not sent because the check was muted
+)
+
+// Event types recorded in account_events, one per bulk action.
+const (
+	EventChecksPaused   = "checks_paused"  // Checks paused at once, e.g. every check of the account
+	EventChecksResumed  = "checks_resumed" // Paused checks resumed at once
+	EventChecksEnabled  = "checks_enabled"
+	EventChecksDisabled = "checks_disabled"
+	EventChecksDeleted  = "checks_deleted"
+)
+
+// Event sources, i.e. which part of the system caused the event.
+const (
+	EventSourcePing   = "ping"
+	EventSourceWorker = "worker"
+	EventSourceAPI    = "api"
+	EventSourceCLI    = "cli"
+	// EventSourceEmailLink is a signed action link from an alert, see internal/actionlink
+	EventSourceEmailLink = "email-link"
+)
+
+// CheckEvent is a single entry in a check's history.
+// It maps to the `check_events` table in the database.
+type CheckEvent struct {
+	ID         int64          `json:"id"`
+	CheckID    int64          `json:"check_id"`
+	Type       string         `json:"type"`
+	FromStatus sql.NullString `json:"from_status"`
+	ToStatus   sql.NullString `json:"to_status"`
+	Source     string         `json:"source"`
+	PingID     sql.NullInt64  `json:"ping_id"`  // Ping that triggered the event, if any
+	CycleID    sql.NullString `json:"cycle_id"` // Worker detection cycle that triggered the event, if any
+	// RelatedCheckID is the other check the event is about, if any, e.g. the
+	// dependency that suppressed a notification
+	RelatedCheckID sql.NullInt64 `json:"related_check_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// FeedEvent is a CheckEvent as the event feed hands it out, with its place in
+// the feed. Seq follows commit order, unlike ID; see migration 0031.
+type FeedEvent struct {
+	Seq int64 `json:"seq"`
+	CheckEvent
+}
+
+// AccountEvent is an account-level history entry for an action on many checks
+// at once. It maps to the `account_events` table; the per-check transitions are
+// recorded as CheckEvents as usual.
+type AccountEvent struct {
+	ID             int64     `json:"id"`
+	UserID         int64     `json:"user_id"`
+	Type           string    `json:"type"`
+	Source         string    `json:"source"`
+	ChecksAffected int64     `json:"checks_affected"`
+	CreatedAt      time.Time `json:"created_at"`
+}
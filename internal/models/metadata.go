@@ -0,0 +1,82 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// CheckMetadata is a check's free-form key-value labels (team=payments,
+// runbook=https://...), for the owner's own tooling. Nothing in monitoring or
+// alerting looks at them. It is stored as a JSON object in checks.metadata; a
+// nil map is NULL.
+type CheckMetadata map[string]string
+
+// Limits on CheckMetadata, enforced by Check.Validate. Keys are restricted to a
+// safe alphabet so they can be used in a JSON path (see
+// repository.ListByMetadata) without escaping.
+const (
+	MaxMetadataKeys        = 32
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 1024 // In characters (runes)
+)
+
+var metadataKey = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// IsValidMetadataKey reports whether key may name a metadata entry: 1 to
+// MaxMetadataKeyLength letters, digits, '_', '.' or '-'.
+func IsValidMetadataKey(key string) bool {
+	return len(key) <= MaxMetadataKeyLength && metadataKey.MatchString(key)
+}
+
+// validate returns what is wrong with m, or "".
+func (m CheckMetadata) validate() string {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Sprintf("must have at most %d keys", MaxMetadataKeys)
+	}
+	for key, value := range m {
+		if !IsValidMetadataKey(key) {
+			return fmt.Sprintf("key %q must be 1 to %d letters, digits, '_', '.' or '-'", key, MaxMetadataKeyLength)
+		}
+		if !utf8.ValidString(value) || utf8.RuneCountInString(value) > MaxMetadataValueLength {
+			return fmt.Sprintf("value of %q must be at most %d characters", key, MaxMetadataValueLength)
+		}
+	}
+	return ""
+}
+
+// Value implements driver.Valuer: a JSON object, or NULL for a nil or empty map.
+func (m CheckMetadata) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner for the JSON column. NULL scans as nil.
+func (m *CheckMetadata) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into CheckMetadata", src)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return fmt.Errorf("decoding check metadata: %w", err)
+	}
+	*m = decoded
+	return nil
+}
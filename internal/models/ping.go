@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Ping kinds (the pings.kind ENUM), i.e. which signal the pinger sent.
+const (
+	PingKindSuccess = "success" // The job completed (plain /ping/{uuid})
+	PingKindStart   = "start"   // The job started; doesn't affect status
+	PingKindFail    = "fail"    // The job reported failure; the check goes down
+)
+
+// IsValidPingKind reports whether kind is one of the PingKind* constants.
+func IsValidPingKind(kind string) bool {
+	return kind == PingKindSuccess || kind == PingKindStart || kind == PingKindFail
+}
+
+// MaxRunIDLength is the longest run ID accepted (the pings.run_id column).
+const MaxRunIDLength = 64
+
+// IsValidRunID reports whether rid can be stored as a run ID: 1 to
+// MaxRunIDLength ASCII letters, digits, '-' and '_', which covers ULIDs and
+// UUIDs.
+func IsValidRunID(rid string) bool {
+	if rid == "" || len(rid) > MaxRunIDLength {
+		return false
+	}
+	for i := 0; i < len(rid); i++ {
+		c := rid[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidClientPingID reports whether id can be stored as a client ping ID
+// (X-Ping-ID, the pings.client_ping_id column). The rules are those of run IDs.
+func IsValidClientPingID(id string) bool {
+	return IsValidRunID(id)
+}
+
+// Ping represents a single heartbeat received for a check.
+// It maps to the `pings` table in the database.
+type Ping struct {
+	ID         int64          `json:"id"`
+	CheckID    int64          `json:"check_id"`
+	Kind       string         `json:"kind"`
+	ReceivedAt time.Time      `json:"received_at"`
+	SourceIP   sql.NullString `json:"source_ip"`
+	UserAgent  sql.NullString `json:"user_agent"`
+	DurationMs sql.NullInt64  `json:"duration_ms"` // Run time for success/fail pings that followed a start ping
+	RunID      sql.NullString `json:"rid"`         // Client-generated run ID, when the ping carried one
+	Payload    sql.NullString `json:"payload"`     // Always the decompressed payload
+	CreatedAt  time.Time      `json:"created_at"`
+}
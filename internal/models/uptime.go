@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// UptimeRatio returns the fraction of [since, now] a check spent outside 'down',
+// given its status_changed events in that window (oldest first) and its current
+// status. With no events the check has been in its current status all along.
+func UptimeRatio(events []CheckEvent, currentStatus string, since, now time.Time) float64 {
+	total := now.Sub(since)
+	if total <= 0 {
+		return 1
+	}
+
+	status := currentStatus
+	for _, event := range events {
+		if event.Type == EventStatusChanged && event.FromStatus.Valid {
+			status = event.FromStatus.String // status at the start of the window
+			break
+		}
+	}
+
+	var down time.Duration
+	cursor := since
+	for _, event := range events {
+		if event.Type != EventStatusChanged || !event.ToStatus.Valid {
+			continue
+		}
+		if status == StatusDown {
+			down += event.CreatedAt.Sub(cursor)
+		}
+		status, cursor = event.ToStatus.String, event.CreatedAt
+	}
+	if status == StatusDown {
+		down += now.Sub(cursor)
+	}
+	return 1 - float64(down)/float64(total)
+}
@@ -62,3 +62,13 @@ func (u *User) IsDeleted() bool {
 func (u *User) GetName() string {
 	return u.Name
 }
+
+// UserWebhook is a user's account-wide event webhook and its delivery state
+// (the webhook_* columns of `users`).
+type UserWebhook struct {
+	UserID    int64          `json:"-"`
+	URL       string         `json:"url"`
+	Failures  uint32         `json:"failures"` // Consecutive failed deliveries
+	LastError sql.NullString `json:"last_error,omitempty"`
+	FailedAt  sql.NullTime   `json:"failed_at,omitempty"`
+}
@@ -0,0 +1,76 @@
+// Package notify delivers alerts about check state changes raised by the worker.
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Notification kinds.
+const (
+	// KindLate is the low-severity warning sent when a check misses its expected
+	// interval but is still within its grace period. Opt-in per check (notify_late).
+	KindLate = "late"
+	// KindDown is the alert sent when a check is past its grace period.
+	KindDown = "down"
+	// KindUp is the recovery notice for a check that came back from 'down'. It is
+	// deferred until the check has stayed up for its recovery_stabilization.
+	KindUp = "up"
+	// KindSlow is the "ran slow" notice for a run that succeeded but took longer
+	// than the check's max_duration. Sent once per streak of slow runs.
+	KindSlow = "slow"
+)
+
+// Notification describes one alert about one check.
+type Notification struct {
+	Kind      string // Kind* constants
+	CheckID   int64
+	CheckUUID string
+	Severity  string // the check's severity (models.Severity*), read when the notification is raised
+	CycleID   string // worker cycle that raised it, matches the check event
+}
+
+// Dispatcher hands notifications off for delivery. Implementations must be safe
+// for concurrent use.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, n Notification) error
+}
+
+// BatchDispatcher is implemented by dispatchers that deliver several
+// notifications raised together (one worker batch going down, say) in fewer
+// requests. DispatchBatch returns one error per notification, nil for those
+// handed off.
+type BatchDispatcher interface {
+	Dispatcher
+	DispatchBatch(ctx context.Context, ns []Notification) []error
+}
+
+// DispatchAll hands ns to d in one DispatchBatch when d is a BatchDispatcher,
+// one Dispatch at a time otherwise, and returns one error per notification.
+func DispatchAll(ctx context.Context, d Dispatcher, ns []Notification) []error {
+	if batch, ok := d.(BatchDispatcher); ok {
+		return batch.DispatchBatch(ctx, ns)
+	}
+	errs := make([]error, len(ns))
+	for i, n := range ns {
+		errs[i] = d.Dispatch(ctx, n)
+	}
+	return errs
+}
+
+// BacklogReporter is implemented by dispatchers that queue notifications for
+// asynchronous delivery. Backlog is the number accepted but not yet delivered;
+// a growing backlog means alerts are late even though the worker is running.
+type BacklogReporter interface {
+	Backlog() int
+}
+
+// LogDispatcher only logs notifications. It is the default until delivery
+// channels are configured.
+type LogDispatcher struct{}
+
+// Dispatch logs n.
+func (LogDispatcher) Dispatch(_ context.Context, n Notification) error {
+	log.Printf("INFO: Dispatched '%s' notification task for check ID %d, severity %s (cycle %s)", n.Kind, n.CheckID, n.Severity, n.CycleID)
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package pingsig signs ping URLs with an expiring HMAC, so a leaked ping URL
+// stops working once its signature expires.
+//
+// A signed URL is the plain ping URL plus ?exp=<unix seconds>&sig=<signature>.
+// The signature covers the check UUID and the expiry, not the signal, so the
+// same query string is valid on /ping/{uuid}, /ping/{uuid}/start and /fail.
+package pingsig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query parameters carrying the signature.
+const (
+	ParamSignature = "sig"
+	ParamExpires   = "exp"
+)
+
+// Verification failures. All of them mean the ping is refused.
+var (
+	ErrMissing = errors.New("ping signature required")
+	ErrInvalid = errors.New("invalid ping signature")
+	ErrExpired = errors.New("ping signature expired")
+)
+
+// Signer signs and verifies ping URLs with one server secret. Rotating the secret
+// invalidates every URL signed with the old one.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner creates a signer. secret must not be empty.
+func NewSigner(secret string) (*Signer, error) {
+	if secret == "" {
+		return nil, errors.New("ping signing secret is empty")
+	}
+	return &Signer{secret: []byte(secret)}, nil
+}
+
+// Sign returns the signature of uuid valid until expires.
+func (s *Signer) Sign(uuid string, expires time.Time) string {
+	return s.sign(uuid, strconv.FormatInt(expires.Unix(), 10))
+}
+
+func (s *Signer) sign(uuid, exp string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(uuid + ":" + exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL returns the signed ping URL of uuid under baseURL (the instance's
+// public base URL), valid until expires.
+func (s *Signer) SignedURL(baseURL, uuid string, expires time.Time) string {
+	query := url.Values{}
+	query.Set(ParamExpires, strconv.FormatInt(expires.Unix(), 10))
+	query.Set(ParamSignature, s.Sign(uuid, expires))
+	return strings.TrimRight(baseURL, "/") + "/api/v1/ping/" + uuid + "?" + query.Encode()
+}
+
+// Verify checks sig and exp, as taken from a ping's query string, for uuid at
+// time now. It returns ErrMissing when both are empty.
+func (s *Signer) Verify(uuid, sig, exp string, now time.Time) error {
+	if sig == "" && exp == "" {
+		return ErrMissing
+	}
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalid
+	}
+	// Compare before looking at the expiry, so a forged exp can't be told apart
+	// from any other bad signature
+	if !hmac.Equal([]byte(sig), []byte(s.sign(uuid, exp))) {
+		return ErrInvalid
+	}
+	if !now.Before(time.Unix(expUnix, 0)) {
+		return ErrExpired
+	}
+	return nil
+}
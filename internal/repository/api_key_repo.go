@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"bitterlink/core/internal/models"
+)
+
+// mysqlAPIKeyRepository implements APIKeyRepository using a MySQL database
+type mysqlAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAPIKeyRepository creates a new repository instance
+func NewMySQLAPIKeyRepository(dbPool *sql.DB) APIKeyRepository {
+	return &mysqlAPIKeyRepository{db: dbPool}
+}
+
+// Create inserts a new API key, setting the generated ID back onto key.
+func (r *mysqlAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if key == nil {
+		return errors.New("can not create nil API key")
+	}
+	if key.UserID <= 0 {
+		return errors.New("UserID is required to create an API key")
+	}
+	if key.KeyValue == "" {
+		return errors.New("KeyValue is required to create an API key")
+	}
+
+	if key.Scope == "" {
+		key.Scope = models.ScopeUser
+	}
+
+	query := `
+        INSERT INTO api_keys (user_id, key_value, label, scope, is_active, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+	result, err := r.db.ExecContext(ctx, query, key.UserID, key.KeyValue, key.Label, key.Scope, key.IsActive)
+	if err != nil {
+		log.Printf("ERROR: Failed to insert API key for user %d: %v", key.UserID, err)
+		return fmt.Errorf("database error creating API key: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve new API key ID after insert: %w", err)
+	}
+	key.ID = id
+	return nil
+}
+
+// ListByUserID returns a user's non-deleted API keys, oldest first. KeyValue is
+// left empty: callers only ever need the metadata.
+func (r *mysqlAPIKeyRepository) ListByUserID(ctx context.Context, userID int64) ([]models.APIKey, error) {
+	query := `
+        SELECT id, user_id, label, scope, is_active, created_at, updated_at
+        FROM api_keys
+        WHERE user_id = ? AND deleted_at IS NULL
+        ORDER BY id ASC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("ERROR: Failed to list API keys for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error querying API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Label, &key.Scope, &key.IsActive, &key.CreatedAt, &key.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/models"
+)
+
+// Batch lookups across many checks in one round trip, for read paths that would
+// otherwise query once per check (the GraphQL resolvers).
+
+// CheckFilter narrows ListChecksPage. Zero values mean "no filter".
+type CheckFilter struct {
+	Status  string
+	UUIDs   []string
+	AfterID int64 // keyset cursor: only checks with a larger ID
+	Limit   int
+}
+
+// ListChecksPage returns one page of a user's checks ordered by ID.
+func (r *mysqlCheckRepository) ListChecksPage(ctx context.Context, userID int64, filter CheckFilter) ([]models.Check, error) {
+	conditions := []string{"user_id = ?", "deleted_at IS NULL", "id > ?"}
+	args := []any{userID, filter.AfterID}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if len(filter.UUIDs) > 0 {
+		conditions = append(conditions, "uuid IN ("+placeholders(len(filter.UUIDs))+")")
+		for _, uuid := range filter.UUIDs {
+			args = append(args, uuid)
+		}
+	}
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY id ASC
+		LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("ERROR: ListChecksPage - Query failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error querying checks: %w", err)
+	}
+	defer rows.Close()
+	return scanCheckRows(rows)
+}
+
+// IterateAllChecks walks every non-deleted check of every user in ID order,
+// calling fn with up to batchSize checks at a time. Each batch is its own
+// keyset-paginated query (id > last seen ID), so no connection is held between
+// batches and checks created while iterating are picked up if their ID is
+// higher. Iteration stops at the first error from fn or when ctx is cancelled.
+// For reindexing, bulk migrations and backfills.
+func (r *mysqlCheckRepository) IterateAllChecks(ctx context.Context, batchSize int, fn func([]models.Check) error) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE id > ? AND deleted_at IS NULL
+		ORDER BY id ASC
+		LIMIT ?`
+
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return canceledErr(ctx, err)
+		}
+		rows, err := r.db.QueryContext(ctx, query, afterID, batchSize)
+		if err != nil {
+			logQueryError(ctx, "IterateAllChecks - Query failed after ID %d: %v", afterID, err)
+			return canceledErr(ctx, fmt.Errorf("error querying checks: %w", err))
+		}
+		checks, err := scanCheckRows(rows)
+		rows.Close()
+		if err != nil {
+			return canceledErr(ctx, err)
+		}
+		if len(checks) == 0 {
+			return nil
+		}
+		if err := fn(checks); err != nil {
+			return err
+		}
+		if len(checks) < batchSize {
+			return nil
+		}
+		afterID = checks[len(checks)-1].ID
+	}
+}
+
+// ListPingsByCheckIDs returns up to limitPerCheck of the most recent pings of
+// each check, newest first, keyed by check ID. Checks without pings are absent.
+func (r *mysqlCheckRepository) ListPingsByCheckIDs(ctx context.Context, checkIDs []int64, limitPerCheck int) (map[int64][]models.Ping, error) {
+	pings := make(map[int64][]models.Ping, len(checkIDs))
+	if len(checkIDs) == 0 {
+		return pings, nil
+	}
+	// One LIMITed subquery per check, UNIONed: a per-group limit without window
+	// functions, so it also runs on MySQL 5.7.
+	subquery := `(SELECT ` + pingColumns + ` FROM pings WHERE check_id = ? ORDER BY received_at DESC, id DESC LIMIT ?)`
+	rows, err := r.db.QueryContext(ctx, unionPerCheck(subquery, len(checkIDs)), perCheckArgs(checkIDs, limitPerCheck)...)
+	if err != nil {
+		log.Printf("ERROR: ListPingsByCheckIDs - Query failed for %d checks: %v", len(checkIDs), err)
+		return nil, fmt.Errorf("error querying pings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ping models.Ping
+		if err := scanPing(rows, &ping); err != nil {
+			return nil, err
+		}
+		pings[ping.CheckID] = append(pings[ping.CheckID], ping)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ping results: %w", err)
+	}
+	return pings, nil
+}
+
+// ListEventsByCheckIDs is ListEventsByCheckID for several checks at once.
+func (r *mysqlCheckRepository) ListEventsByCheckIDs(ctx context.Context, checkIDs []int64, limitPerCheck int) (map[int64][]models.CheckEvent, error) {
+	if len(checkIDs) == 0 {
+		return map[int64][]models.CheckEvent{}, nil
+	}
+	subquery := `(SELECT ` + eventColumns + ` FROM check_events WHERE check_id = ? ORDER BY id DESC LIMIT ?)`
+	rows, err := r.db.QueryContext(ctx, unionPerCheck(subquery, len(checkIDs)), perCheckArgs(checkIDs, limitPerCheck)...)
+	if err != nil {
+		log.Printf("ERROR: ListEventsByCheckIDs - Query failed for %d checks: %v", len(checkIDs), err)
+		return nil, fmt.Errorf("error querying events: %w", err)
+	}
+	defer rows.Close()
+	return scanEventsByCheck(rows, len(checkIDs))
+}
+
+// ListEventsSince returns each check's events created at or after since, oldest
+// first, keyed by check ID.
+func (r *mysqlCheckRepository) ListEventsSince(ctx context.Context, checkIDs []int64, since time.Time) (map[int64][]models.CheckEvent, error) {
+	if len(checkIDs) == 0 {
+		return map[int64][]models.CheckEvent{}, nil
+	}
+	args := make([]any, 0, len(checkIDs)+1)
+	for _, id := range checkIDs {
+		args = append(args, id)
+	}
+	args = append(args, since)
+	query := `SELECT ` + eventColumns + `
+		FROM check_events
+		WHERE check_id IN (` + placeholders(len(checkIDs)) + `) AND created_at >= ?
+		ORDER BY check_id, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("ERROR: ListEventsSince - Query failed for %d checks: %v", len(checkIDs), err)
+		return nil, fmt.Errorf("error querying events: %w", err)
+	}
+	defer rows.Close()
+	return scanEventsByCheck(rows, len(checkIDs))
+}
+
+// scanEventsByCheck drains rows selected with eventColumns into a map keyed by check ID.
+func scanEventsByCheck(rows *sql.Rows, sizeHint int) (map[int64][]models.CheckEvent, error) {
+	events := make(map[int64][]models.CheckEvent, sizeHint)
+	for rows.Next() {
+		var event models.CheckEvent
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		events[event.CheckID] = append(events[event.CheckID], event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating event results: %w", err)
+	}
+	return events, nil
+}
+
+// placeholders returns "?, ?, ..." with n placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// unionPerCheck repeats a parenthesised per-check subquery n times with UNION ALL.
+func unionPerCheck(subquery string, n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = subquery
+	}
+	return strings.Join(parts, " UNION ALL ")
+}
+
+// perCheckArgs expands to check_id, limit pairs matching unionPerCheck.
+func perCheckArgs(checkIDs []int64, limit int) []any {
+	args := make([]any, 0, 2*len(checkIDs))
+	for _, id := range checkIDs {
+		args = append(args, id, limit)
+	}
+	return args
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"bitterlink/core/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Notification blackouts, see migrations/0028_notification_blackouts.sql: the
+// global kill-switch an admin turns on when everything goes down at once.
+
+var (
+	// ErrBlackoutActive is returned by StartBlackout while a blackout is already on.
+	ErrBlackoutActive = errors.New("a notification blackout is already active")
+	// ErrNoBlackout is returned by EndBlackout when no blackout is on.
+	ErrNoBlackout = errors.New("no notification blackout is active")
+)
+
+const blackoutColumns = `id, reason, started_by, started_at, first_event_id, ended_by, ended_at,
+	COALESCE(last_event_id, 0), send_summary, summary_sent_at`
+
+func scanBlackout(row interface{ Scan(...any) error }) (*models.NotificationBlackout, error) {
+	var b models.NotificationBlackout
+	err := row.Scan(&b.ID, &b.Reason, &b.StartedBy, &b.StartedAt, &b.FirstEventID, &b.EndedBy, &b.EndedAt,
+		&b.LastEventID, &b.SendSummary, &b.SummarySentAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ActiveBlackout returns the blackout that is on, nil when there is none.
+func (r *mysqlCheckRepository) ActiveBlackout(ctx context.Context) (*models.NotificationBlackout, error) {
+	query := `SELECT ` + blackoutColumns + ` FROM notification_blackouts WHERE active = 1`
+	b, err := scanBlackout(r.db.QueryRowContext(ctx, query))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logQueryError(ctx, "ActiveBlackout - Query failed: %v", err)
+		return nil, canceledErr(ctx, fmt.Errorf("database error reading notification blackout: %w", err))
+	}
+	return b, nil
+}
+
+// StartBlackout turns the kill-switch on for userID, an admin. It returns
+// ErrBlackoutActive, with the blackout that is on, when there already is one.
+func (r *mysqlCheckRepository) StartBlackout(ctx context.Context, userID int64, reason string) (*models.NotificationBlackout, error) {
+	query := `INSERT INTO notification_blackouts (reason, started_by, started_at, first_event_id)
+		SELECT NULLIF(?, ''), ?, UTC_TIMESTAMP(), COALESCE(MAX(id), 0) FROM check_events`
+	if _, err := r.db.ExecContext(ctx, query, reason, userID); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 { // Duplicate entry: the active key
+			logQueryError(ctx, "StartBlackout - Insert failed for user %d: %v", userID, err)
+			return nil, canceledErr(ctx, fmt.Errorf("database error starting notification blackout: %w", err))
+		}
+		b, err := r.ActiveBlackout(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return b, ErrBlackoutActive
+	}
+	return r.ActiveBlackout(ctx)
+}
+
+// EndBlackout turns the kill-switch off for userID, an admin, asking the worker
+// for a summary when sendSummary is set. It returns the ended blackout, or
+// ErrNoBlackout when none was on.
+func (r *mysqlCheckRepository) EndBlackout(ctx context.Context, userID int64, sendSummary bool) (b *models.NotificationBlackout, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, `SELECT id FROM notification_blackouts WHERE active = 1 FOR UPDATE`).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoBlackout
+		}
+		logQueryError(ctx, "EndBlackout - Failed to lock blackout: %v", err)
+		return nil, fmt.Errorf("database error locking notification blackout: %w", err)
+	}
+	endQuery := `UPDATE notification_blackouts
+		SET ended_by = ?, ended_at = UTC_TIMESTAMP(), send_summary = ?,
+			last_event_id = (SELECT COALESCE(MAX(id), 0) FROM check_events)
+		WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, endQuery, userID, sendSummary, id); err != nil {
+		logQueryError(ctx, "EndBlackout - Update failed for blackout %d: %v", id, err)
+		return nil, fmt.Errorf("database error ending notification blackout: %w", err)
+	}
+	if b, err = scanBlackout(tx.QueryRowContext(ctx, `SELECT `+blackoutColumns+` FROM notification_blackouts WHERE id = ?`, id)); err != nil {
+		return nil, fmt.Errorf("database error reading notification blackout: %w", err)
+	}
+	return b, tx.Commit()
+}
+
+// ClaimBlackoutSummary returns an ended blackout whose summary is due, marked
+// sent in the same transaction so only one worker sends it; nil when none is.
+func (r *mysqlCheckRepository) ClaimBlackoutSummary(ctx context.Context) (b *models.NotificationBlackout, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + blackoutColumns + ` FROM notification_blackouts
+		WHERE send_summary AND ended_at IS NOT NULL AND summary_sent_at IS NULL
+		ORDER BY id LIMIT 1 FOR UPDATE`
+	b, err = scanBlackout(tx.QueryRowContext(ctx, query))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logQueryError(ctx, "ClaimBlackoutSummary - Query failed: %v", err)
+		return nil, fmt.Errorf("database error reading notification blackouts: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE notification_blackouts SET summary_sent_at = UTC_TIMESTAMP() WHERE id = ?`, b.ID); err != nil {
+		logQueryError(ctx, "ClaimBlackoutSummary - Update failed for blackout %d: %v", b.ID, err)
+		return nil, fmt.Errorf("database error claiming blackout summary: %w", err)
+	}
+	return b, tx.Commit()
+}
+
+// ListBlackoutChanges returns up to limit checks whose status changed during
+// b, so far when it is still active, with their status before and after.
+func (r *mysqlCheckRepository) ListBlackoutChanges(ctx context.Context, b *models.NotificationBlackout, limit int) ([]models.BlackoutChange, error) {
+	lastEventID := int64(math.MaxInt64)
+	if !b.Active() {
+		lastEventID = b.LastEventID
+	}
+	query := `
+		SELECT c.id, c.uuid, c.name, c.user_id, COALESCE(f.from_status, ''), COALESCE(l.to_status, ''), g.changes
+		FROM (
+			SELECT check_id, MIN(id) AS first_id, MAX(id) AS last_id, COUNT(*) AS changes
+			FROM check_events
+			WHERE id > ? AND id <= ? AND event_type = ?
+			GROUP BY check_id
+		) g
+		JOIN check_events f ON f.id = g.first_id
+		JOIN check_events l ON l.id = g.last_id
+		JOIN checks c ON c.id = g.check_id
+		ORDER BY c.id
+		LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, b.FirstEventID, lastEventID, models.EventStatusChanged, limit)
+	if err != nil {
+		logQueryError(ctx, "ListBlackoutChanges - Query failed for blackout %d: %v", b.ID, err)
+		return nil, canceledErr(ctx, fmt.Errorf("database error listing blackout changes: %w", err))
+	}
+	defer rows.Close()
+
+	changes := []models.BlackoutChange{}
+	for rows.Next() {
+		var change models.BlackoutChange
+		if err := rows.Scan(&change.CheckID, &change.CheckUUID, &change.Name, &change.UserID,
+			&change.FromStatus, &change.ToStatus, &change.Changes); err != nil {
+			return nil, fmt.Errorf("error scanning blackout change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
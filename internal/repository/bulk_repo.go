@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/models"
+)
+
+// Account-wide and filtered bulk changes. Each is a handful of set-based
+// statements in one transaction, however many checks it affects: one event per
+// affected check plus a single account_events entry for the whole action.
+
+// ErrBulkLimitExceeded is returned when more checks match a bulk action than
+// its limit allows. Nothing was changed.
+var ErrBulkLimitExceeded = errors.New("bulk action matches too many checks")
+
+// Bulk actions.
+const (
+	BulkPause   = "pause"
+	BulkResume  = "resume"
+	BulkEnable  = "enable"
+	BulkDisable = "disable"
+	BulkDelete  = "delete" // Soft delete, like Delete
+)
+
+// BulkFilter narrows the checks a bulk action applies to, on top of the
+// owner. Zero values mean "no filter".
+type BulkFilter struct {
+	Statuses []string // models.Status* values
+}
+
+// resumedStatus is the status a paused check resumes to, given the statement's
+// start time as its only placeholder: 'up' if its last ping is still within
+// expected_interval + grace_period (so the worker takes it from there as
+// usual), 'new' otherwise. A 'new' check waits for its next ping without
+// alerting, so resuming after a long pause doesn't page for every check at once.
+const resumedStatus = `CASE
+            WHEN last_ping_at >= (? - INTERVAL (expected_interval + grace_period) SECOND) THEN 'up'
+            ELSE 'new'
+        END`
+
+// bulkOp is what a bulk action does in SQL. Placeholders in set and toStatus all
+// stand for the transaction's start time, so the events and the update can't
+// disagree on a check's new status.
+type bulkOp struct {
+	condition    string // Which of the filtered checks the action changes
+	set          string // SET clause of the UPDATE
+	checkEvent   string // check_events.event_type
+	toStatus     string // check_events.to_status, "" for events that aren't status changes
+	accountEvent string // account_events.event_type
+}
+
+var bulkOps = map[string]bulkOp{
+	BulkPause: {
+		condition:    "status <> 'paused'",
+		set:          "status = 'paused'",
+		checkEvent:   models.EventStatusChanged,
+		toStatus:     "'paused'",
+		accountEvent: models.EventChecksPaused,
+	},
+	BulkResume: {
+		condition: "status = 'paused'",
+		// A check that lands in 'new' loses any pending recovery notification,
+		// which would otherwise fire on its next ping. MySQL assigns left to
+		// right, so status is already the new one there.
+		set: "status = " + resumedStatus + `,
+            recovery_pending_since = CASE WHEN status = 'new' THEN NULL ELSE recovery_pending_since END`,
+		checkEvent:   models.EventStatusChanged,
+		toStatus:     resumedStatus,
+		accountEvent: models.EventChecksResumed,
+	},
+	BulkEnable: {
+		condition:    "is_enabled = FALSE",
+		set:          "is_enabled = TRUE",
+		checkEvent:   models.EventCheckEnabled,
+		accountEvent: models.EventChecksEnabled,
+	},
+	BulkDisable: {
+		condition:    "is_enabled = TRUE",
+		set:          "is_enabled = FALSE",
+		checkEvent:   models.EventCheckDisabled,
+		accountEvent: models.EventChecksDisabled,
+	},
+	BulkDelete: {
+		condition:    "TRUE",
+		set:          "deleted_at = UTC_TIMESTAMP()",
+		checkEvent:   models.EventCheckDeleted,
+		accountEvent: models.EventChecksDeleted,
+	},
+}
+
+// IsValidBulkAction reports whether action is one of the Bulk* constants.
+func IsValidBulkAction(action string) bool {
+	_, ok := bulkOps[action]
+	return ok
+}
+
+// PauseAllByUserID pauses every non-deleted check of userID that isn't paused
+// yet and returns how many it paused.
+func (r *mysqlCheckRepository) PauseAllByUserID(ctx context.Context, userID int64, source string) (int64, error) {
+	return r.ApplyBulkAction(ctx, userID, BulkPause, BulkFilter{}, source, 0)
+}
+
+// ResumeAllByUserID resumes every paused, non-deleted check of userID (see
+// resumedStatus) and returns how many it resumed.
+func (r *mysqlCheckRepository) ResumeAllByUserID(ctx context.Context, userID int64, source string) (int64, error) {
+	return r.ApplyBulkAction(ctx, userID, BulkResume, BulkFilter{}, source, 0)
+}
+
+// bulkWhere is the WHERE clause selecting the checks of userID that action
+// would change, with its arguments.
+func bulkWhere(userID int64, op bulkOp, filter BulkFilter) (string, []any) {
+	conditions := []string{"user_id = ?", "deleted_at IS NULL", op.condition}
+	args := []any{userID}
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "status IN ("+placeholders(len(filter.Statuses))+")")
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+		}
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListBulkTargets previews a bulk action: it returns the UUIDs of up to limit
+// checks, in ID order, that ApplyBulkAction would change, and how many there
+// are in total.
+func (r *mysqlCheckRepository) ListBulkTargets(ctx context.Context, userID int64, action string, filter BulkFilter, limit int) (_ []string, total int64, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+	op, ok := bulkOps[action]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown bulk action %q", action)
+	}
+	where, args := bulkWhere(userID, op, filter)
+
+	if err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM checks `+where, args...).Scan(&total); err != nil {
+		logQueryError(ctx, "ListBulkTargets - Count failed for user %d: %v", userID, err)
+		return nil, 0, fmt.Errorf("error counting checks: %w", err)
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT uuid FROM checks `+where+` ORDER BY id ASC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		logQueryError(ctx, "ListBulkTargets - Query failed for user %d: %v", userID, err)
+		return nil, 0, fmt.Errorf("error querying checks: %w", err)
+	}
+	defer rows.Close()
+	uuids := []string{}
+	for rows.Next() {
+		var uuid string
+		if err := rows.Scan(&uuid); err != nil {
+			return nil, 0, fmt.Errorf("error scanning check UUID: %w", err)
+		}
+		uuids = append(uuids, uuid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating check results: %w", err)
+	}
+	return uuids, total, nil
+}
+
+// ApplyBulkAction applies action to every check of userID matching filter and
+// returns how many it changed. With a positive limit and more matching checks
+// than that, nothing is changed and ErrBulkLimitExceeded is returned with the
+// number that matched.
+func (r *mysqlCheckRepository) ApplyBulkAction(ctx context.Context, userID int64, action string, filter BulkFilter, source string, limit int) (_ int64, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+	op, ok := bulkOps[action]
+	if !ok {
+		return 0, fmt.Errorf("unknown bulk action %q", action)
+	}
+	where, whereArgs := bulkWhere(userID, op, filter)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the affected checks up front, so pings, the worker and a concurrent
+	// action wait for us instead of changing a check between the statements below
+	var affected int64
+	var now time.Time
+	lockQuery := `SELECT COUNT(*), UTC_TIMESTAMP() FROM checks ` + where + ` FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, lockQuery, whereArgs...).Scan(&affected, &now); err != nil {
+		logQueryError(ctx, "ApplyBulkAction - Failed to lock checks of user %d: %v", userID, err)
+		return 0, fmt.Errorf("database error locking checks: %w", err)
+	}
+	if affected == 0 {
+		return 0, nil // Nothing changes, so no history entry either
+	}
+	if limit > 0 && affected > int64(limit) {
+		return affected, ErrBulkLimitExceeded
+	}
+	nowArgs := func(expr string) []any {
+		return slices.Repeat([]any{now}, strings.Count(expr, "?"))
+	}
+
+	// 2. One event per check, while the old status is still there
+	statusColumns := "NULL, NULL"
+	if op.toStatus != "" {
+		statusColumns = "status, " + op.toStatus
+	}
+	eventQuery := `
+        INSERT INTO check_events (check_id, event_type, from_status, to_status, source, created_at)
+        SELECT id, ?, ` + statusColumns + `, ?, UTC_TIMESTAMP()
+        FROM checks ` + where
+	args := append([]any{op.checkEvent}, nowArgs(op.toStatus)...)
+	args = append(append(args, source), whereArgs...)
+	if _, err = tx.ExecContext(ctx, eventQuery, args...); err != nil {
+		logQueryError(ctx, "ApplyBulkAction - Failed to record events for user %d: %v", userID, err)
+		return 0, fmt.Errorf("database error recording %s events: %w", op.checkEvent, err)
+	}
+
+	// 3. Then the update itself
+	updateQuery := `UPDATE checks SET ` + op.set + `, updated_at = UTC_TIMESTAMP() ` + where
+	result, err := tx.ExecContext(ctx, updateQuery, append(nowArgs(op.set), whereArgs...)...)
+	if err != nil {
+		logQueryError(ctx, "ApplyBulkAction - Failed to %s checks of user %d: %v", action, userID, err)
+		return 0, fmt.Errorf("database error updating checks: %w", err)
+	}
+	if affected, err = result.RowsAffected(); err != nil {
+		return 0, fmt.Errorf("failed to count updated checks: %w", err)
+	}
+
+	// 4. And the single account-level entry
+	accountQuery := `
+        INSERT INTO account_events (user_id, event_type, source, checks_affected, created_at)
+        VALUES (?, ?, ?, ?, UTC_TIMESTAMP())`
+	if _, err = tx.ExecContext(ctx, accountQuery, userID, op.accountEvent, source, affected); err != nil {
+		logQueryError(ctx, "ApplyBulkAction - Failed to record %s for user %d: %v", op.accountEvent, userID, err)
+		return 0, fmt.Errorf("database error recording %s event: %w", op.accountEvent, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logQueryError(ctx, "ApplyBulkAction - Failed to commit %s for user %d: %v", action, userID, err)
+		return 0, fmt.Errorf("database error committing bulk %s: %w", action, err)
+	}
+	log.Printf("INFO: %s: %d checks of user %d by %s", op.accountEvent, affected, userID, source)
+	return affected, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"bitterlink/core/internal/logging"
+)
+
+// ErrCanceled marks an error caused by the caller's context ending (the client
+// disconnected or a deadline passed) rather than by the database. The original
+// error stays wrapped alongside it.
+var ErrCanceled = errors.New("request canceled")
+
+// canceledErr adds ErrCanceled to err when ctx is done. It looks at ctx rather
+// than err because the driver doesn't always return ctx.Err() itself: a query
+// interrupted mid-read can surface as mysql.ErrInvalidConn instead.
+func canceledErr(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil || errors.Is(err, ErrCanceled) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrCanceled, err)
+}
+
+// logQueryError logs a failed database call at ERROR, or only at DEBUG once ctx
+// is done: a client that hung up is not a database problem. ERROR lines are
+// deduplicated, so an unreachable database doesn't log once per request.
+func logQueryError(ctx context.Context, format string, args ...any) {
+	if ctx.Err() != nil {
+		log.Printf("DEBUG: "+format, args...)
+		return
+	}
+	logging.Errorf(format, args...)
+}
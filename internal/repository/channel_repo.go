@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/models"
+)
+
+// Notification statuses recorded in notifications_log.
+const (
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+// ErrChannelNotFound is returned when no live channel of the account matches.
+var ErrChannelNotFound = fmt.Errorf("channel: %w", ErrNotFound)
+
+// Verification failures.
+var (
+	// ErrVerificationTooSoon is returned by BeginChannelVerification within the
+	// minimum interval of the previous attempt
+	ErrVerificationTooSoon = errors.New("channel verification attempted too recently")
+	// ErrVerificationInvalid is returned by ConfirmChannel for a wrong or
+	// expired token
+	ErrVerificationInvalid = errors.New("invalid or expired channel verification")
+)
+
+// maxNotificationErrorLen bounds notifications_log.error_message.
+const maxNotificationErrorLen = 255
+
+// mysqlChannelRepository implements ChannelRepository using a MySQL database
+type mysqlChannelRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLChannelRepository creates a new instance of mysqlChannelRepository
+func NewMySQLChannelRepository(dbPool *sql.DB) ChannelRepository {
+	return &mysqlChannelRepository{db: dbPool}
+}
+
+// ListChannelTargets returns the enabled, verified, non-deleted channels of the
+// given kind (models.Channel*) attached to any of checkIDs, one entry per check
+// and channel. Deleted checks have none. Channels pending verification are left
+// out: an address with a typo would swallow the alerts. So is an association
+// whose min_severity is above the check's severity, which is how only critical
+// checks are routed to a pager, say.
+func (r *mysqlChannelRepository) ListChannelTargets(ctx context.Context, kind string, checkIDs []int64) ([]models.ChannelTarget, error) {
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+	args := []any{kind}
+	for _, id := range checkIDs {
+		args = append(args, id)
+	}
+	query := `
+        SELECT nc.id, nc.value, c.id, c.name, c.uuid, c.severity
+        FROM check_notification_channel cnc
+        JOIN notification_channels nc ON nc.id = cnc.notification_channel_id
+        JOIN checks c ON c.id = cnc.check_id
+        WHERE nc.type = ? AND nc.is_enabled = TRUE AND nc.is_verified = TRUE AND nc.deleted_at IS NULL AND c.deleted_at IS NULL
+            AND c.severity + 0 >= cnc.min_severity + 0 -- ENUM indexes, in ascending severity
+            AND cnc.check_id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(checkIDs)), ",") + `)
+        ORDER BY nc.id ASC, c.id ASC`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logQueryError(ctx, "ListChannelTargets - Query failed for %d checks: %v", len(checkIDs), err)
+		return nil, fmt.Errorf("error querying notification channels: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []models.ChannelTarget
+	for rows.Next() {
+		var t models.ChannelTarget
+		if err := rows.Scan(&t.ChannelID, &t.Value, &t.CheckID, &t.CheckName, &t.CheckUUID, &t.CheckSeverity); err != nil {
+			return nil, fmt.Errorf("error scanning notification channel: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification channels: %w", err)
+	}
+	return targets, nil
+}
+
+// LogNotification records one delivery attempt of a kind notification
+// (notify.Kind*) for checkID to channelID in notifications_log: sent when
+// deliveryErr is nil, failed with its message otherwise.
+func (r *mysqlChannelRepository) LogNotification(ctx context.Context, checkID, channelID int64, kind string, deliveryErr error) error {
+	status := NotificationSent
+	var message sql.NullString
+	if deliveryErr != nil {
+		status = NotificationFailed
+		message = sql.NullString{String: deliveryErr.Error(), Valid: true}
+		if len(message.String) > maxNotificationErrorLen {
+			message.String = message.String[:maxNotificationErrorLen]
+		}
+	}
+	query := `
+        INSERT INTO notifications_log (check_id, notification_channel_id, notification_type, status, attempted_at, error_message)
+        VALUES (?, ?, ?, ?, UTC_TIMESTAMP(), ?)`
+	if _, err := r.db.ExecContext(ctx, query, checkID, channelID, kind, status, message); err != nil {
+		logQueryError(ctx, "LogNotification - Insert failed for check %d, channel %d: %v", checkID, channelID, err)
+		return fmt.Errorf("database error logging notification: %w", err)
+	}
+	return nil
+}
+
+// channelColumns is the column list matching scanChannel's field order.
+const channelColumns = `id, user_id, type, value, config, label, is_enabled, is_verified, verification_sent_at, created_at, updated_at`
+
+func scanChannel(row rowScanner, ch *models.NotificationChannel, extra ...any) error {
+	return row.Scan(append([]any{&ch.ID, &ch.UserID, &ch.Type, &ch.Value, &ch.Config, &ch.Label, &ch.IsEnabled, &ch.IsVerified,
+		&ch.VerificationSentAt, &ch.CreatedAt, &ch.UpdatedAt}, extra...)...)
+}
+
+// ListChannels returns the live channels of userID, oldest first.
+func (r *mysqlChannelRepository) ListChannels(ctx context.Context, userID int64) ([]models.NotificationChannel, error) {
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE user_id = ? AND deleted_at IS NULL ORDER BY id ASC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		logQueryError(ctx, "ListChannels - Query failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error querying notification channels: %w", err)
+	}
+	defer rows.Close()
+	channels := []models.NotificationChannel{}
+	for rows.Next() {
+		var ch models.NotificationChannel
+		if err := scanChannel(rows, &ch); err != nil {
+			return nil, fmt.Errorf("error scanning notification channel: %w", err)
+		}
+		channels = append(channels, ch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification channels: %w", err)
+	}
+	return channels, nil
+}
+
+// FindChannel returns userID's live channel channelID, or ErrChannelNotFound.
+func (r *mysqlChannelRepository) FindChannel(ctx context.Context, userID, channelID int64) (_ *models.NotificationChannel, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	var ch models.NotificationChannel
+	query := `SELECT ` + channelColumns + ` FROM notification_channels WHERE id = ? AND user_id = ? AND deleted_at IS NULL`
+	if err := scanChannel(r.db.QueryRowContext(ctx, query, channelID, userID), &ch); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrChannelNotFound
+		}
+		logQueryError(ctx, "FindChannel - Query failed for channel %d: %v", channelID, err)
+		return nil, fmt.Errorf("error retrieving notification channel: %w", err)
+	}
+	return &ch, nil
+}
+
+// CreateChannel stores a new channel of ch.UserID, unverified whatever
+// ch.IsVerified says, and fills in ch as stored. It gets no alerts until
+// verified, see BeginChannelVerification.
+func (r *mysqlChannelRepository) CreateChannel(ctx context.Context, ch *models.NotificationChannel) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	query := `
+        INSERT INTO notification_channels (user_id, type, value, config, label, is_enabled, is_verified, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, FALSE, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+	result, err := r.db.ExecContext(ctx, query, ch.UserID, ch.Type, ch.Value, ch.Config, ch.Label, ch.IsEnabled)
+	if err != nil {
+		logQueryError(ctx, "CreateChannel - Insert failed for user %d: %v", ch.UserID, err)
+		return fmt.Errorf("database error creating notification channel: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve new channel ID after insert: %w", err)
+	}
+	stored, err := r.FindChannel(ctx, ch.UserID, id)
+	if err != nil {
+		return fmt.Errorf("reading back channel %d: %w", id, err)
+	}
+	*ch = *stored
+	log.Printf("INFO: Created %s channel %d for user %d", ch.Type, ch.ID, ch.UserID)
+	return nil
+}
+
+// UpdateChannel saves the value, config, label and is_enabled of ch.UserID's channel
+// ch.ID, and fills in ch as stored. A changed value is a new destination, so
+// the channel goes back to pending verification. Returns ErrChannelNotFound
+// for an unknown or deleted channel.
+func (r *mysqlChannelRepository) UpdateChannel(ctx context.Context, ch *models.NotificationChannel) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the channel and see whether its destination changes
+	var oldValue string
+	lockQuery := `SELECT value FROM notification_channels WHERE id = ? AND user_id = ? AND deleted_at IS NULL FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, lockQuery, ch.ID, ch.UserID).Scan(&oldValue); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrChannelNotFound
+		}
+		logQueryError(ctx, "UpdateChannel - Failed to lock channel %d: %v", ch.ID, err)
+		return fmt.Errorf("database error locking notification channel: %w", err)
+	}
+
+	// 2. Save, dropping the verification of an old destination
+	query := `UPDATE notification_channels SET value = ?, config = ?, label = ?, is_enabled = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?`
+	if ch.Value != oldValue {
+		query = `
+            UPDATE notification_channels
+            SET value = ?, config = ?, label = ?, is_enabled = ?, is_verified = FALSE, verification_token = NULL,
+                verification_sent_at = NULL, updated_at = UTC_TIMESTAMP()
+            WHERE id = ?`
+	}
+	if _, err = tx.ExecContext(ctx, query, ch.Value, ch.Config, ch.Label, ch.IsEnabled, ch.ID); err != nil {
+		logQueryError(ctx, "UpdateChannel - Update failed for channel %d: %v", ch.ID, err)
+		return fmt.Errorf("database error updating notification channel: %w", err)
+	}
+
+	// 3. Hand back the channel as stored
+	if err = scanChannel(tx.QueryRowContext(ctx, `SELECT `+channelColumns+` FROM notification_channels WHERE id = ?`, ch.ID), ch); err != nil {
+		return fmt.Errorf("database error reading updated notification channel: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit notification channel update: %w", err)
+	}
+	return nil
+}
+
+// DeleteChannel soft-deletes userID's channel channelID. Its check associations
+// are kept but deliver nothing, as ListChannelTargets skips deleted channels.
+// Returns ErrChannelNotFound for an unknown or already deleted channel.
+func (r *mysqlChannelRepository) DeleteChannel(ctx context.Context, userID, channelID int64) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	query := `UPDATE notification_channels SET deleted_at = UTC_TIMESTAMP(), updated_at = UTC_TIMESTAMP() WHERE id = ? AND user_id = ? AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, channelID, userID)
+	if err != nil {
+		logQueryError(ctx, "DeleteChannel - Update failed for channel %d: %v", channelID, err)
+		return fmt.Errorf("database error deleting notification channel: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to confirm channel deletion: %w", err)
+	}
+	if affected != 1 {
+		return ErrChannelNotFound
+	}
+	log.Printf("INFO: Soft-deleted channel %d of user %d", channelID, userID)
+	return nil
+}
+
+// BeginChannelVerification records a verification attempt of userID's channel
+// channelID, storing token as the secret of the link sent by email ("" for
+// channels verified by a test delivery). Attempts less than minInterval apart
+// get ErrVerificationTooSoon and the time left to wait; an unknown channel gets
+// ErrChannelNotFound. It returns the channel as it was before.
+func (r *mysqlChannelRepository) BeginChannelVerification(ctx context.Context, userID, channelID int64, token string, minInterval time.Duration) (_ *models.NotificationChannel, wait time.Duration, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the channel, so concurrent attempts are counted one after the other
+	var ch models.NotificationChannel
+	var sinceSent sql.NullInt64
+	query := `
+        SELECT ` + channelColumns + `, TIMESTAMPDIFF(SECOND, verification_sent_at, UTC_TIMESTAMP())
+        FROM notification_channels
+        WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+        FOR UPDATE`
+	err = scanChannel(tx.QueryRowContext(ctx, query, channelID, userID), &ch, &sinceSent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, ErrChannelNotFound
+	}
+	if err != nil {
+		logQueryError(ctx, "BeginChannelVerification - Failed to lock channel %d: %v", channelID, err)
+		return nil, 0, fmt.Errorf("database error locking channel: %w", err)
+	}
+
+	// 2. Rate limit
+	if sinceSent.Valid {
+		if elapsed := time.Duration(max(sinceSent.Int64, 0)) * time.Second; elapsed < minInterval {
+			return &ch, minInterval - elapsed, ErrVerificationTooSoon
+		}
+	}
+
+	// 3. Record the attempt
+	update := `UPDATE notification_channels SET verification_token = ?, verification_sent_at = UTC_TIMESTAMP(), updated_at = UTC_TIMESTAMP() WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, update, sql.NullString{String: token, Valid: token != ""}, channelID); err != nil {
+		logQueryError(ctx, "BeginChannelVerification - Update failed for channel %d: %v", channelID, err)
+		return nil, 0, fmt.Errorf("database error recording verification: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("database error committing verification: %w", err)
+	}
+	return &ch, 0, nil
+}
+
+// SetChannelVerified marks channelID verified, e.g. after a test delivery.
+func (r *mysqlChannelRepository) SetChannelVerified(ctx context.Context, channelID int64) error {
+	query := `UPDATE notification_channels SET is_verified = TRUE, verification_token = NULL, updated_at = UTC_TIMESTAMP() WHERE id = ? AND deleted_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, channelID); err != nil {
+		logQueryError(ctx, "SetChannelVerified - Update failed for channel %d: %v", channelID, err)
+		return fmt.Errorf("database error verifying channel: %w", err)
+	}
+	return nil
+}
+
+// ConfirmChannel verifies channelID with the token from its confirmation link,
+// which is good for ttl after it was sent. A wrong, used or expired token gets
+// ErrVerificationInvalid. The token is compared in constant time.
+func (r *mysqlChannelRepository) ConfirmChannel(ctx context.Context, channelID int64, token string, ttl time.Duration) error {
+	var stored sql.NullString
+	var fresh bool
+	query := `
+        SELECT verification_token, verification_sent_at > (UTC_TIMESTAMP() - INTERVAL ? SECOND)
+        FROM notification_channels
+        WHERE id = ? AND deleted_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, int64(ttl.Seconds()), channelID).Scan(&stored, &fresh)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVerificationInvalid
+	}
+	if err != nil {
+		logQueryError(ctx, "ConfirmChannel - Query failed for channel %d: %v", channelID, err)
+		return fmt.Errorf("database error confirming channel: %w", err)
+	}
+	if !stored.Valid || !hmac.Equal([]byte(stored.String), []byte(token)) || !fresh {
+		return ErrVerificationInvalid
+	}
+
+	// The token is cleared with the update, so it only works once
+	update := `UPDATE notification_channels SET is_verified = TRUE, verification_token = NULL, updated_at = UTC_TIMESTAMP() WHERE id = ? AND verification_token = ?`
+	result, err := r.db.ExecContext(ctx, update, channelID, stored.String)
+	if err != nil {
+		logQueryError(ctx, "ConfirmChannel - Update failed for channel %d: %v", channelID, err)
+		return fmt.Errorf("database error confirming channel: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrVerificationInvalid // Confirmed or replaced meanwhile
+	}
+	return nil
+}
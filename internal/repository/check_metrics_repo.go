@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CheckGauge is one check's state as exported to Prometheus, see
+// httptransport.GetCheckMetrics.
+type CheckGauge struct {
+	UUID        string
+	Name        string
+	Status      string
+	IsEnabled   bool
+	LastPingAge sql.NullInt64 // Seconds by the database clock, NULL when never pinged
+}
+
+// ListCheckGauges returns up to limit of userID's live checks in ID order,
+// with how long ago each was last pinged, and whether there were more. It is a
+// single query on the checks table, cheap enough to run on every scrape.
+func (r *mysqlCheckRepository) ListCheckGauges(ctx context.Context, userID int64, limit int) ([]CheckGauge, bool, error) {
+	query := `
+        SELECT uuid, name, status, is_enabled, TIMESTAMPDIFF(SECOND, last_ping_at, UTC_TIMESTAMP())
+        FROM checks
+        WHERE user_id = ? AND deleted_at IS NULL
+        ORDER BY id ASC
+        LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit+1) // One more tells whether there are more
+	if err != nil {
+		logQueryError(ctx, "ListCheckGauges - Query failed for user %d: %v", userID, err)
+		return nil, false, fmt.Errorf("error querying check gauges: %w", err)
+	}
+	defer rows.Close()
+
+	var gauges []CheckGauge
+	for rows.Next() {
+		var g CheckGauge
+		if err := rows.Scan(&g.UUID, &g.Name, &g.Status, &g.IsEnabled, &g.LastPingAge); err != nil {
+			return nil, false, fmt.Errorf("error scanning check gauge: %w", err)
+		}
+		gauges = append(gauges, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("error iterating check gauges: %w", err)
+	}
+	if len(gauges) > limit {
+		return gauges[:limit], true, nil
+	}
+	return gauges, false, nil
+}
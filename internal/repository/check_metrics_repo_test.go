@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// ListCheckGauges asks for one row more than the cap to tell whether there
+// were more, and drops it.
+func TestListCheckGauges(t *testing.T) {
+	tests := []struct {
+		name          string
+		rows          int
+		wantLen       int
+		wantTruncated bool
+	}{
+		{name: "under the cap", rows: 2, wantLen: 2},
+		{name: "at the cap", rows: 3, wantLen: 3},
+		{name: "over the cap", rows: 4, wantLen: 3, wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMockCheckRepo(t)
+			rows := sqlmock.NewRows([]string{"uuid", "name", "status", "is_enabled", "age"})
+			for i := range tt.rows {
+				rows.AddRow("u-"+string(rune('a'+i)), "check", "up", true, nil)
+			}
+			mock.ExpectQuery(`FROM checks\s+WHERE user_id = \? AND deleted_at IS NULL`).
+				WithArgs(int64(7), 4).
+				WillReturnRows(rows)
+
+			gauges, truncated, err := repo.ListCheckGauges(context.Background(), 7, 3)
+			if err != nil {
+				t.Fatalf("ListCheckGauges: %v", err)
+			}
+			if len(gauges) != tt.wantLen || truncated != tt.wantTruncated {
+				t.Errorf("got %d gauges, truncated %t; want %d, %t", len(gauges), truncated, tt.wantLen, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestListCheckGaugesScansPingAge(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectQuery(`FROM checks`).
+		WillReturnRows(sqlmock.NewRows([]string{"uuid", "name", "status", "is_enabled", "age"}).
+			AddRow("u-1", "backup", "down", true, int64(90)).
+			AddRow("u-2", "fresh", "new", false, nil))
+
+	gauges, _, err := repo.ListCheckGauges(context.Background(), 7, 10)
+	if err != nil {
+		t.Fatalf("ListCheckGauges: %v", err)
+	}
+	want := []CheckGauge{
+		{UUID: "u-1", Name: "backup", Status: "down", IsEnabled: true, LastPingAge: sql.NullInt64{Int64: 90, Valid: true}},
+		{UUID: "u-2", Name: "fresh", Status: "new"},
+	}
+	if !reflect.DeepEqual(gauges, want) {
+		t.Errorf("gauges = %+v, want %+v", gauges, want)
+	}
+}
@@ -6,19 +6,56 @@ import (
 	"errors"
 	"fmt" // For error wrapping
 	"log"
+	"strings"
+	"time"
 
 	"bitterlink/core/internal/models" // Import your Check struct definition
 
 	"github.com/go-sql-driver/mysql"
 )
 
-// ErrCheckNotFound --- Add Custom Error ---
-var ErrCheckNotFound = errors.New("check not found or not active")
+// ErrCheckNotFound is returned when no live check matches the lookup. It wraps
+// ErrNotFound.
+var ErrCheckNotFound = fmt.Errorf("check: %w", ErrNotFound)
 
-// Create inserts a new Check record into the database.
+// ErrCheckAlreadyDeleted is returned by Delete for a check that was deleted
+// before. It wraps ErrCheckNotFound, so callers that don't care answer both
+// the same way.
+var ErrCheckAlreadyDeleted = fmt.Errorf("already deleted: %w", ErrCheckNotFound)
+
+// Duplicate key errors from Create, telling apart which unique constraint failed
+// so callers can point at the offending field.
+var (
+	ErrDuplicateUUID = errors.New("check with this UUID already exists")
+	ErrDuplicateName = errors.New("check with this name already exists")
+)
+
+// Unique index names on checks, as they appear in MySQL's duplicate entry message.
+const (
+	uuidUniqueKey = "uuid"
+	nameUniqueKey = "uq_checks_user_name" // (user_id, name), for the planned name uniqueness
+)
+
+// clientPingIDUniqueKey is the index on pings (check_id, client_ping_id), see
+// migrations/0027_ping_client_ids.sql.
+const clientPingIDUniqueKey = "uq_pings_check_client_ping_id"
+
+// ErrCheckInactive is returned by RecordPing when the check is disabled or paused
+// and the InactivePingPolicy is InactivePingReject.
+var ErrCheckInactive = errors.New("check is disabled or paused")
+
+// ErrDuplicatePing is returned by RecordPing, and per ping by RecordPingsBatch,
+// when the check already has a ping with the record's ClientPingID: a retry of
+// a ping that was recorded. Nothing is recorded again, and Response is filled
+// in as for the original.
+var ErrDuplicatePing = errors.New("ping already recorded")
+
+// Create inserts a new Check record into the database, with a created event.
 // It sets the auto-generated ID and potentially CreatedAt/UpdatedAt
 // back onto the input check pointer upon success.
-func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check) error {
+func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
 	// 1. Basic Validation (more complex validation often belongs in a service layer)
 	if check == nil {
 		return errors.New("can not create nil check")
@@ -40,13 +77,15 @@ func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check)
 
 	// 2. Define the INSERT Query
 	// We specify the columns we are providing values for.
-	// Let the DB handle defaults for id, last_ping_at, deleted_at,
+	// Let the DB handle defaults for id, deleted_at,
 	// but explicitly set created_at and updated_at using UTC_TIMESTAMP().
+	// last_ping_at is normally NULL here; imports pass the original value through.
 	query := `
         INSERT INTO checks (
             user_id, uuid, name, description, expected_interval, grace_period,
-            status, is_enabled, created_at, updated_at
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+            last_ping_at, status, is_enabled, notify_late, severity, recovery_stabilization, color, icon, metadata, forward_url, pings_history_limit, require_signed_pings,
+            ping_response_code, ping_response_body, max_duration, warmup_pings, created_at, updated_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
 
 	// 3. Prepare Arguments
 	// Set default status if empty (e.g., 'new')
@@ -54,16 +93,23 @@ func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check)
 	if status == "" {
 		status = "new"
 	}
-	// Set default enabled state (usually true)
-	isEnabled := true // Assuming default is false if not set, make it true?
-	// Let's assume new checks are enabled unless specified otherwise.
-	// If check.IsEnabled was not explicitly set before calling repo, default it.
-	// However, it's better if the caller sets IsEnabled explicitly. For safety:
-	// isEnabled := true // Or use check.IsEnabled if the caller sets it.
+	// The caller decides the enabled state (the handler defaults it to true),
+	// so disabled checks can be created directly, e.g. by the seed command.
+	isEnabled := check.IsEnabled
+	severity := check.Severity
+	if severity == "" {
+		severity = models.SeverityWarning
+	}
 
 	// 4. Execute the Query
-	// Use ExecContext for INSERT, UPDATE, DELETE statements.
-	result, err := r.db.ExecContext(
+	// In a transaction with the created event, so the event feed never
+	// misses a check (see SequenceEvents)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	result, err := tx.ExecContext(
 		ctx,
 		query,
 		check.UserID,
@@ -72,8 +118,22 @@ func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check)
 		check.Description, // Pass sql.NullString directly
 		check.ExpectedInterval,
 		check.GracePeriod,
-		status,    // Use the determined status
-		isEnabled, // Use the value from the struct (caller should set default)
+		check.LastPingAt, // Pass sql.NullTime directly
+		status,           // Use the determined status
+		isEnabled,        // Use the value from the struct (caller should set default)
+		check.NotifyLate,
+		severity,
+		check.RecoveryStabilization,
+		check.Color,
+		check.Icon,
+		check.Metadata,
+		check.ForwardURL,
+		check.PingsHistoryLimit,
+		check.RequireSignedPings,
+		check.PingResponseCode,
+		check.PingResponseBody,
+		check.MaxDuration,
+		max(check.WarmupPings, 1), // 0 (unset) is no warmup, like 1
 	)
 
 	// 5. Handle Errors
@@ -81,12 +141,19 @@ func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check)
 		// Check for specific MySQL errors, like duplicate entry for UNIQUE constraints
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 { // 1062 is 'Duplicate entry'
-			// Could check mysqlErr.Message to see which key constraint failed (e.g., uuid)
-			log.Printf("WARN: Attempted to create check with duplicate entry (likely UUID '%s'): %v", check.UUID, err)
-			return fmt.Errorf("check with this UUID already exists: %w", err)
+			// The message names the key constraint that failed
+			key := duplicateKeyName(mysqlErr.Message)
+			log.Printf("WARN: Attempted to create check with duplicate entry on key '%s' (UUID '%s'): %v", key, check.UUID, err)
+			switch key {
+			case uuidUniqueKey:
+				return fmt.Errorf("%w: %w", ErrDuplicateUUID, err)
+			case nameUniqueKey:
+				return fmt.Errorf("%w: %w", ErrDuplicateName, err)
+			}
+			return fmt.Errorf("duplicate check (key '%s'): %w", key, err)
 		}
 		// Log generic database error
-		log.Printf("ERROR: Failed to insert check for user %d (UUID: %s): %v", check.UserID, check.UUID, err)
+		logQueryError(ctx, "Failed to insert check for user %d (UUID: %s): %v", check.UserID, check.UUID, err)
 		return fmt.Errorf("database error creating check: %w", err)
 	}
 
@@ -94,63 +161,415 @@ func (r *mysqlCheckRepository) Create(ctx context.Context, check *models.Check)
 	id, err := result.LastInsertId()
 	if err != nil {
 		// This is less likely but possible
-		log.Printf("ERROR: Failed to get last insert ID for check UUID %s: %v", check.UUID, err)
+		logQueryError(ctx, "Failed to get last insert ID for check UUID %s: %v", check.UUID, err)
 		// The insert likely succeeded, but we can't confirm the ID. Critical? Maybe return error.
 		return fmt.Errorf("failed to retrieve new check ID after insert: %w", err)
 	}
 
-	// 7. Update the input struct pointer with the new ID
+	// 7. Record the event and commit
+	if err = InsertEvent(ctx, tx, models.CheckEvent{CheckID: id, Type: models.EventCheckCreated, Source: models.EventSourceAPI}); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit check creation: %w", err)
+	}
+
+	// 8. Update the input struct pointer with the new ID
 	check.ID = id
 	// We could also set check.CreatedAt/UpdatedAt based on time.Now(), but the DB values are the source of truth.
 	// Setting the ID is usually the most important part.
 	check.Status = status // Ensure status is set if defaulted
+	check.Severity = severity
 
 	log.Printf("INFO: Successfully created check with ID %d (UUID: %s)", check.ID, check.UUID)
 	return nil // Success!
 }
 
-func (r *mysqlCheckRepository) Update(ctx context.Context, check *models.Check) error {
-	// TODO: Implement SQL UPDATE statement using r.db.ExecContext
-	log.Printf("DEBUG: Update check called (Not Implemented): ID=%d", check.ID)
-	return fmt.Errorf("repository Update method not implemented yet")
+// duplicateKeyName extracts the index name from a MySQL 1062 message, e.g.
+// "Duplicate entry 'x' for key 'checks.uuid'" (8.0) or "... for key 'uuid'" (5.7).
+// Returns "" if the message doesn't have the expected shape.
+func duplicateKeyName(message string) string {
+	const marker = "for key '"
+	i := strings.LastIndex(message, marker)
+	if i < 0 || !strings.HasSuffix(message, "'") {
+		return ""
+	}
+	key := message[i+len(marker) : len(message)-1]
+	// MySQL 8.0 prefixes the table name
+	if dot := strings.LastIndex(key, "."); dot >= 0 {
+		key = key[dot+1:]
+	}
+	return key
 }
 
-func (r *mysqlCheckRepository) Delete(ctx context.Context, id int64) error {
-	// TODO: Implement soft delete (UPDATE checks SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL)
-	log.Printf("DEBUG: Delete check called (Not Implemented): ID=%d", id)
-	return fmt.Errorf("repository Delete method not implemented yet")
+// CheckUpdate is a partial update of a check's user-editable settings for
+// Update. Only the non-nil fields are written; the others keep whatever the
+// row holds at the time, so concurrent updates of different fields don't undo
+// each other.
+type CheckUpdate struct {
+	Name              *string
+	Description       *sql.NullString
+	ExpectedInterval  *uint32
+	GracePeriod       *uint32
+	IsEnabled         *bool
+	Status            *string // models.StatusNew or StatusPaused, see setClause
+	NotifyLate        *bool
+	Severity          *string
+	Metadata          *models.CheckMetadata
+	MaxDuration       *sql.NullInt32
+	WarmupPings       *uint32         // A lowered value takes effect with the next ping
+	Color             *sql.NullString // NULL removes it
+	Icon              *sql.NullString // NULL removes it
+	PingsHistoryLimit *sql.NullInt32  // NULL removes it; a lower one is enforced by the HistoryTrimmer's next pass
+	PingResponseCode  *sql.NullInt32  // NULL restores the default 200
+	PingResponseBody  *sql.NullString // NULL restores the default {"status":"ok"}
+	// RecoveryStabilization also applies to a recovery already pending: the
+	// worker compares against the column on each tick.
+	RecoveryStabilization *uint32
 }
 
-// FindByID Add FindByID if you haven't already
-func (r *mysqlCheckRepository) FindByID(ctx context.Context, id int64) (*models.Check, error) {
-	// TODO: Implement SQL SELECT ... WHERE id = ? AND deleted_at IS NULL logic using r.db.QueryRowContext
-	log.Printf("DEBUG: FindByID check called (Not Implemented): ID=%d", id)
-	// Example of returning ErrCheckNotFound if appropriate
-	// return nil, ErrCheckNotFound
-	return nil, fmt.Errorf("repository FindByID method not implemented yet")
+// setClause returns the SET assignments for the provided fields, with their
+// arguments. Column names come from here only, never from the caller.
+func (u CheckUpdate) setClause() (assignments []string, args []any) {
+	add := func(column string, value any) {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, value)
+	}
+	if u.Name != nil {
+		add("name", *u.Name)
+	}
+	if u.Description != nil {
+		add("description", *u.Description)
+	}
+	if u.ExpectedInterval != nil {
+		add("expected_interval", *u.ExpectedInterval)
+	}
+	if u.GracePeriod != nil {
+		add("grace_period", *u.GracePeriod)
+	}
+	if u.IsEnabled != nil {
+		add("is_enabled", *u.IsEnabled)
+	}
+	if u.Status != nil {
+		add("status", *u.Status)
+		if *u.Status == models.StatusNew {
+			// As when resuming into 'new': a pending recovery notification
+			// would otherwise fire on the next ping
+			assignments = append(assignments, "recovery_pending_since = NULL")
+		}
+	}
+	if u.NotifyLate != nil {
+		add("notify_late", *u.NotifyLate)
+	}
+	if u.Severity != nil {
+		add("severity", *u.Severity)
+	}
+	if u.Metadata != nil {
+		add("metadata", *u.Metadata)
+	}
+	if u.MaxDuration != nil {
+		add("max_duration", *u.MaxDuration)
+	}
+	if u.WarmupPings != nil {
+		add("warmup_pings", *u.WarmupPings)
+	}
+	if u.Color != nil {
+		add("color", *u.Color)
+	}
+	if u.Icon != nil {
+		add("icon", *u.Icon)
+	}
+	if u.PingsHistoryLimit != nil {
+		add("pings_history_limit", *u.PingsHistoryLimit)
+	}
+	if u.PingResponseCode != nil {
+		add("ping_response_code", *u.PingResponseCode)
+	}
+	if u.PingResponseBody != nil {
+		add("ping_response_body", *u.PingResponseBody)
+	}
+	if u.RecoveryStabilization != nil {
+		add("recovery_stabilization", *u.RecoveryStabilization)
+	}
+	return assignments, args
 }
 
-// FindActiveByUserID Ensure FindActiveByUserID is also implemented if it's in the interface
-func (r *mysqlCheckRepository) FindActiveByUserID(ctx context.Context, userID int64) ([]models.Check, error) {
-	// TODO: Implement the logic from the previous example if you haven't moved it here yet
-	log.Printf("DEBUG: FindActiveByUserID check called (Not Implemented): UserID=%d", userID)
-	return nil, fmt.Errorf("repository FindActiveByUserID method not implemented yet")
+// Update writes the provided fields of update to a live check, and nothing
+// else; the other settings have their own paths. A missing or deleted check is
+// detected from the rows the UPDATE matched, and returns ErrCheckNotFound; a
+// taken name returns ErrDuplicateName. Each save is recorded as an updated
+// event, and a change of is_enabled or status as its own event as well, as the
+// bulk actions do. An update without fields changes nothing.
+func (r *mysqlCheckRepository) Update(ctx context.Context, id int64, update CheckUpdate) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	assignments, args := update.setClause()
+	if len(assignments) == 0 {
+		return r.ensureLiveCheck(ctx, r.db, id)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. The history records is_enabled and status changes with what they were,
+	// so read those two first when they are being set. A missing check is left
+	// to the UPDATE to report.
+	var wasEnabled sql.NullBool
+	var wasStatus sql.NullString
+	if update.IsEnabled != nil || update.Status != nil {
+		readQuery := `SELECT is_enabled, status FROM checks WHERE id = ? AND deleted_at IS NULL FOR UPDATE`
+		err = tx.QueryRowContext(ctx, readQuery, id).Scan(&wasEnabled, &wasStatus)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logQueryError(ctx, "Failed to read check ID %d for update: %v", id, err)
+			return fmt.Errorf("database error reading check: %w", err)
+		}
+	}
+
+	// 2. Save the provided settings
+	query := `UPDATE checks SET ` + strings.Join(assignments, ", ") + `, updated_at = UTC_TIMESTAMP() WHERE id = ? AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, append(args, id)...)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			// As in Create; the UUID can't change, so only the name can clash
+			if duplicateKeyName(mysqlErr.Message) == nameUniqueKey {
+				return fmt.Errorf("%w: %w", ErrDuplicateName, err)
+			}
+			return fmt.Errorf("duplicate check (key '%s'): %w", duplicateKeyName(mysqlErr.Message), err)
+		}
+		logQueryError(ctx, "Failed to update check ID %d: %v", id, err)
+		return fmt.Errorf("database error updating check: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to confirm check update: %w", err)
+	}
+	if affected == 0 {
+		// MySQL counts changed rows only: a save of the same values within the
+		// same second matches the check but changes nothing, so tell that apart
+		// as Delete does
+		if err = r.ensureLiveCheck(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	// 3. Record the save and the state changes
+	if err = InsertEvent(ctx, tx, models.CheckEvent{CheckID: id, Type: models.EventCheckUpdated, Source: models.EventSourceAPI}); err != nil {
+		return err
+	}
+	if update.IsEnabled != nil && *update.IsEnabled != wasEnabled.Bool {
+		eventType := models.EventCheckDisabled
+		if *update.IsEnabled {
+			eventType = models.EventCheckEnabled
+		}
+		if err = InsertEvent(ctx, tx, models.CheckEvent{CheckID: id, Type: eventType, Source: models.EventSourceAPI}); err != nil {
+			return err
+		}
+	}
+	if update.Status != nil && *update.Status != wasStatus.String {
+		if err = InsertEvent(ctx, tx, StatusChangedEvent(id, wasStatus.String, *update.Status, models.EventSourceAPI)); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit check update: %w", err)
+	}
+
+	log.Printf("INFO: Updated %d settings of check ID %d", len(assignments), id)
+	return nil
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ensureLiveCheck returns ErrCheckNotFound unless check id exists and isn't
+// deleted.
+func (r *mysqlCheckRepository) ensureLiveCheck(ctx context.Context, q queryRower, id int64) error {
+	var exists int
+	err := q.QueryRowContext(ctx, `SELECT 1 FROM checks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&exists)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrCheckNotFound
+	default:
+		logQueryError(ctx, "Failed to look up check ID %d: %v", id, err)
+		return fmt.Errorf("database error looking up check: %w", err)
+	}
+}
+
+// Delete soft-deletes one of userID's checks, stamping deleted_at and
+// updated_at, and records a deleted event. Returns ErrCheckNotFound if the check doesn't exist or belongs to
+// another user, and ErrCheckAlreadyDeleted (which wraps it) if it was deleted
+// before, so deleting twice is a not-found rather than an error. FindByUUID,
+// ListByUserID, the worker and the ping paths all skip deleted rows.
+func (r *mysqlCheckRepository) Delete(ctx context.Context, id, userID int64) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE checks SET deleted_at = UTC_TIMESTAMP(), updated_at = UTC_TIMESTAMP() WHERE id = ? AND user_id = ? AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		logQueryError(ctx, "Failed to soft-delete check ID %d: %v", id, err)
+		return fmt.Errorf("database error deleting check: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to confirm check deletion: %w", err)
+	}
+	if affected != 1 {
+		// Tell a repeated delete apart from a check that was never there
+		var deletedAt sql.NullTime
+		err := tx.QueryRowContext(ctx, `SELECT deleted_at FROM checks WHERE id = ? AND user_id = ?`, id, userID).Scan(&deletedAt)
+		switch {
+		case err == nil && deletedAt.Valid:
+			return ErrCheckAlreadyDeleted
+		case err == nil, errors.Is(err, sql.ErrNoRows):
+			return ErrCheckNotFound
+		default:
+			logQueryError(ctx, "Failed to look up undeleted check ID %d: %v", id, err)
+			return fmt.Errorf("database error deleting check: %w", err)
+		}
+	}
+
+	if err = InsertEvent(ctx, tx, models.CheckEvent{CheckID: id, Type: models.EventCheckDeleted, Source: models.EventSourceAPI}); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit check deletion: %w", err)
+	}
+
+	log.Printf("INFO: Soft-deleted check ID %d of user %d", id, userID)
+	return nil
+}
+
+// FindByID returns the check with the given numeric ID, whoever owns it;
+// callers serving a user compare check.UserID themselves. Returns
+// ErrCheckNotFound for missing or soft-deleted checks.
+func (r *mysqlCheckRepository) FindByID(ctx context.Context, id int64) (_ *models.Check, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	query := `SELECT ` + checkColumns + `
+              FROM checks WHERE id = ? AND deleted_at IS NULL`
+	var check models.Check
+	if err := scanCheck(r.db.QueryRowContext(ctx, query, id), &check); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCheckNotFound
+		}
+		logQueryError(ctx, "FindByID - Scan failed for check ID %d: %v", id, err)
+		return nil, fmt.Errorf("error retrieving check data: %w", err)
+	}
+	return &check, nil
+}
+
+// FindActiveByUserID returns userID's active checks by name: those the timeout
+// worker evaluates, i.e. enabled, not paused and not deleted (the SQL form of
+// models.Check.IsMonitored, and what ExistsByUUID calls active). The slice is
+// empty, not nil, when there are none.
+func (r *mysqlCheckRepository) FindActiveByUserID(ctx context.Context, userID int64) (_ []models.Check, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE user_id = ? AND is_enabled = TRUE AND status <> 'paused' AND deleted_at IS NULL
+		ORDER BY name ASC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		logQueryError(ctx, "FindActiveByUserID - Query failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error querying active user checks: %w", err)
+	}
+	defer rows.Close()
+	return scanCheckRows(rows)
 }
 
 // mysqlCheckRepository implements CheckRepository using a MySQL database
 type mysqlCheckRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	config Config
+}
+
+// Config holds tunables for the check repository.
+type Config struct {
+	// CompressPayloads enables gzip compression of stored ping payloads.
+	CompressPayloads bool
+	// CompressThresholdBytes is the payload size above which compression kicks in.
+	CompressThresholdBytes int
+	// InactivePingPolicy decides what a ping to a disabled or paused check does,
+	// one of the InactivePing* constants. Empty means InactivePingRecordNoStatus.
+	InactivePingPolicy string
+	// PingFastPath lets RecordPing try recordPingFast before the transactional
+	// path. Off by default until it has proven itself in production.
+	PingFastPath bool
+}
+
+// Policies for pings received by disabled or paused checks.
+const (
+	// InactivePingRecordNoStatus stores the ping and updates last_ping_at, but
+	// leaves the status alone (the default).
+	InactivePingRecordNoStatus = "record_no_status"
+	// InactivePingRecord stores the ping for history only; the check row is not
+	// touched, so a re-enabled check is judged on pings received after that.
+	InactivePingRecord = "record"
+	// InactivePingReject stores nothing and fails with ErrCheckInactive.
+	InactivePingReject = "reject"
+)
+
+// IsValidInactivePingPolicy reports whether policy is one of the InactivePing* constants.
+func IsValidInactivePingPolicy(policy string) bool {
+	switch policy {
+	case InactivePingRecordNoStatus, InactivePingRecord, InactivePingReject:
+		return true
+	}
+	return false
 }
 
 // NewMySQLCheckRepository creates a new repository instance
-func NewMySQLCheckRepository(dbPool *sql.DB) CheckRepository {
-	return &mysqlCheckRepository{db: dbPool}
+func NewMySQLCheckRepository(dbPool *sql.DB, cfg Config) CheckRepository {
+	return &mysqlCheckRepository{db: dbPool, config: cfg}
+}
+
+// PingRecord is a single incoming ping to be recorded.
+type PingRecord struct {
+	UUID      string
+	Kind      string // models.PingKind*, defaults to success
+	SourceIP  sql.NullString
+	UserAgent sql.NullString
+	Payload   []byte // nil when the ping carried no body
+	RunID     string // Client-generated run ID (?rid=), empty for none; see models.IsValidRunID
+	// ClientPingID (X-Ping-ID or ?pid=) identifies the ping across the client's
+	// retries, empty for none; see models.IsValidClientPingID and ErrDuplicatePing
+	ClientPingID string
+	// Response, when set, receives the check's custom response to successful
+	// pings. It is left zero (the default response) for recorded pings to checks
+	// without one.
+	Response *models.PingResponse
 }
 
 // RecordPing --- Implement RecordPing ---
 // RecordPing finds a check by UUID, updates its last ping time and status (if down),
 // and inserts a record into the pings table. It performs these operations in a transaction.
-func (r *mysqlCheckRepository) RecordPing(ctx context.Context, uuid string, sourceIP sql.NullString, userAgent sql.NullString) error {
+func (r *mysqlCheckRepository) RecordPing(ctx context.Context, ping PingRecord) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	if r.config.PingFastPath {
+		done, err := r.recordPingFast(ctx, ping)
+		if done || err != nil {
+			return err
+		}
+		// Not the steady state (or not a known check), take the full path
+	}
+
 	// Use a transaction to ensure atomicity
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -158,85 +577,450 @@ func (r *mysqlCheckRepository) RecordPing(ctx context.Context, uuid string, sour
 	}
 	defer tx.Rollback()
 
+	// 1-5. Find the check, update it, insert the ping and record any transition
+	checkID, err := r.recordPingTx(ctx, tx, ping, 0)
+	if err != nil {
+		return err
+	}
+
+	// 6. If all went well, commit the transaction
+	if err = tx.Commit(); err != nil {
+		logQueryError(ctx, "RecordPing - Failed to commit transaction for check ID %d: %v", checkID, err)
+		return fmt.Errorf("database error committing ping record: %w", err)
+	}
+
+	log.Printf("DEBUG: Successfully recorded ping for check ID %d (UUID: %s)", checkID, ping.UUID)
+	return nil // Success
+
+}
+
+// recordPingFast records a success ping to a check that is already 'up' in two
+// statements and no explicit transaction, instead of SELECT ... FOR UPDATE, UPDATE
+// and INSERT in one. It reports done=false, without writing anything, for every
+// other case, which the caller hands to the transactional path:
+//   - unknown UUIDs, so ErrCheckNotFound keeps coming from one place;
+//   - checks that are new, late, down, paused or disabled, i.e. whenever the status
+//     could change or the InactivePingPolicy applies. The status transition (and
+//     its event) is therefore always detected with the prior status in hand;
+//   - checks with a pending start ping, which need duration_ms, and pings with
+//     a run ID, which pair with their own start;
+//   - pings with a client ping ID, which are deduplicated under the check's lock;
+//   - checks with a custom ping response, which the slow path reads anyway, so
+//     the fast path never needs a third statement.
+//
+// A second ping within the same second stays on the fast path: last_ping_at
+// doesn't change, but the ping counters do, so the row still counts as affected.
+//
+// Without the transaction a failed INSERT leaves last_ping_at advanced with no
+// ping row behind it. That's the accepted trade-off: the check was pinged, only
+// the history entry is missing.
+func (r *mysqlCheckRepository) recordPingFast(ctx context.Context, ping PingRecord) (bool, error) {
+	if ping.Kind != "" && ping.Kind != models.PingKindSuccess || ping.RunID != "" || ping.ClientPingID != "" {
+		return false, nil
+	}
+	storedPayload, compressed, err := r.encodePayload(ping.Payload)
+	if err != nil {
+		log.Printf("ERROR: RecordPing - Failed to encode payload for UUID '%s': %v", ping.UUID, err)
+		return false, err
+	}
+
+	// 1. Touch the check if it is in the steady state. LAST_INSERT_ID(id) hands the
+	// matched row's ID back in the OK packet, saving the SELECT.
+	updateQuery := `
+        UPDATE checks
+        SET last_ping_at = UTC_TIMESTAMP(), updated_at = UTC_TIMESTAMP(), id = LAST_INSERT_ID(id),
+            total_pings = total_pings + 1, pings_this_week = pings_this_week + 1
+        WHERE uuid = ? AND deleted_at IS NULL AND status = 'up' AND is_enabled = TRUE AND last_start_at IS NULL
+            AND ping_response_code IS NULL AND ping_response_body IS NULL`
+	result, err := r.db.ExecContext(ctx, updateQuery, ping.UUID)
+	if err != nil {
+		logQueryError(ctx, "RecordPing - Fast path update failed for UUID '%s': %v", ping.UUID, err)
+		return false, fmt.Errorf("database error updating check: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return false, nil
+	}
+	checkID, err := result.LastInsertId()
+	if err != nil || checkID == 0 {
+		// Shouldn't happen; the update went through, so don't record the ping twice
+		logQueryError(ctx, "RecordPing - Fast path got no check ID for UUID '%s': %v", ping.UUID, err)
+		return true, fmt.Errorf("failed to retrieve check ID: %w", err)
+	}
+
+	// 2. Insert the ping. No start ping is pending, so duration_ms stays NULL.
+	insertQuery := `
+        INSERT INTO pings (check_id, kind, received_at, source_ip, user_agent, payload, payload_compressed, created_at)
+        VALUES (?, ?, UTC_TIMESTAMP(), ?, ?, ?, ?, UTC_TIMESTAMP())`
+	if _, err := r.db.ExecContext(ctx, insertQuery, checkID, models.PingKindSuccess, ping.SourceIP, ping.UserAgent, storedPayload, compressed); err != nil {
+		logQueryError(ctx, "RecordPing - Fast path failed to insert ping record for check ID %d: %v", checkID, err)
+		return true, fmt.Errorf("database error recording ping details: %w", err)
+	}
+
+	log.Printf("DEBUG: Recorded ping for check ID %d (UUID: %s) on the fast path", checkID, ping.UUID)
+	return true, nil
+}
+
+// RecordPingsBatch records several pings in a single transaction, only matching
+// checks owned by userID. The returned slice is aligned with pings: nil for a
+// recorded ping, ErrCheckNotFound for an unknown UUID, ErrCheckInactive for a
+// ping rejected by the InactivePingPolicy, ErrDuplicatePing for a retry of a
+// recorded ping (also within the batch). Any other error rolls
+// back the whole batch and is returned as the second value.
+func (r *mysqlCheckRepository) RecordPingsBatch(ctx context.Context, userID int64, pings []PingRecord) (_ []error, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	results := make([]error, len(pings))
+	for i, ping := range pings {
+		_, err := r.recordPingTx(ctx, tx, ping, userID)
+		// A duplicate caught by the unique index wraps the MySQL error and has
+		// already touched the check, so it fails the whole batch instead
+		if err != nil && !errors.Is(err, ErrCheckNotFound) && !errors.Is(err, ErrCheckInactive) && err != ErrDuplicatePing {
+			return nil, err
+		}
+		results[i] = err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logQueryError(ctx, "RecordPingsBatch - Failed to commit batch of %d pings for user %d: %v", len(pings), userID, err)
+		return nil, fmt.Errorf("database error committing ping batch: %w", err)
+	}
+	return results, nil
+}
+
+// recordPingTx does the work of RecordPing inside tx and returns the check ID.
+// A non-zero userID restricts the lookup to that user's checks.
+func (r *mysqlCheckRepository) recordPingTx(ctx context.Context, tx *sql.Tx, ping PingRecord, userID int64) (int64, error) {
+	kind := ping.Kind
+	if kind == "" {
+		kind = models.PingKindSuccess
+	}
+	storedPayload, compressed, err := r.encodePayload(ping.Payload)
+	if err != nil {
+		log.Printf("ERROR: RecordPing - Failed to encode payload for UUID '%s': %v", ping.UUID, err)
+		return 0, err
+	}
+
+	// 1. Find (and lock) the check
 	var checkID int64
 	var currentStatus string
-	findQuery := "SELECT id, status FROM checks WHERE uuid = ? AND deleted_at IS NULL LIMIT 1"
-	err = tx.QueryRowContext(ctx, findQuery, uuid).Scan(&checkID, &currentStatus)
+	var isEnabled bool
+	var lastStartAt sql.NullTime
+	var response models.PingResponse
+	var hasMaxDuration, inSlowIncident, overran, suppressed bool // See step 3
+	var warmupPings, warmupSuccesses uint32
+	findQuery := `SELECT id, status, is_enabled, last_start_at, ping_response_code, ping_response_body, name, expected_interval,
+			warmup_pings, warmup_successes,
+			max_duration IS NOT NULL, slow_since IS NOT NULL,
+			last_start_at IS NOT NULL AND max_duration IS NOT NULL AND last_start_at < (UTC_TIMESTAMP() - INTERVAL max_duration SECOND),
+			notification_suppressed_by IS NOT NULL
+		FROM checks
+		WHERE uuid = ? AND deleted_at IS NULL AND (? = 0 OR user_id = ?) LIMIT 1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, findQuery, ping.UUID, userID, userID).Scan(&checkID, &currentStatus, &isEnabled, &lastStartAt,
+		&response.Code, &response.Body, &response.CheckName, &response.ExpectedInterval, &warmupPings, &warmupSuccesses, &hasMaxDuration, &inSlowIncident, &overran, &suppressed)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Use the custom error for clear handling in the handler
-			return ErrCheckNotFound
+			return 0, ErrCheckNotFound
 		}
 		// Log the technical error but return a generic one potentially
-		log.Printf("ERROR: RecordPing - Failed to find check by UUID '%s': %v", uuid, err)
-		return fmt.Errorf("database error finding check: %w", err)
+		logQueryError(ctx, "RecordPing - Failed to find check by UUID '%s': %v", ping.UUID, err)
+		return 0, fmt.Errorf("database error finding check: %w", err)
 	}
 
-	// 2. Update the check's last_ping_at and status (if it was 'down')
-	// Note: We update last_ping_at even for 'paused' checks, but status only flips from 'down'.
-	// If it's a new check. The first ping brings it to up.
-	newStatus := currentStatus
-	if currentStatus == "down" || currentStatus == "new" {
-		newStatus = "up"
+	// A retry of a recorded ping is answered like the original. Holding the
+	// check's lock, concurrent retries queue up here, so only the first records
+	// the ping; the unique index on (check_id, client_ping_id) backs this up.
+	if ping.ClientPingID != "" {
+		var originalID int64
+		dupQuery := `SELECT id FROM pings WHERE check_id = ? AND client_ping_id = ? LIMIT 1 FOR UPDATE`
+		err = tx.QueryRowContext(ctx, dupQuery, checkID, ping.ClientPingID).Scan(&originalID)
+		switch {
+		case err == nil:
+			if ping.Response != nil {
+				*ping.Response = response
+			}
+			return checkID, ErrDuplicatePing
+		case !errors.Is(err, sql.ErrNoRows):
+			logQueryError(ctx, "RecordPing - Failed to look up client ping ID of check ID %d: %v", checkID, err)
+			return 0, fmt.Errorf("database error looking up client ping ID: %w", err)
+		}
 	}
 
-	updateQuery := `
+	// 2. Apply the inactive ping policy to disabled and paused checks
+	touchCheck := true
+	if !isEnabled || currentStatus == models.StatusPaused {
+		switch r.config.InactivePingPolicy {
+		case InactivePingReject:
+			return 0, ErrCheckInactive
+		case InactivePingRecord:
+			touchCheck = false
+		}
+	}
+
+	// 3. Update the check's last_ping_at and status (if it was 'down')
+	// Note: By default we update last_ping_at even for paused and disabled checks, but status
+	// only flips from 'down' or 'new', and only while the check is enabled.
+	// A start signal isn't a completed run, so it only remembers when the run began;
+	// the next success/fail ping clears it again. See models.StatusAfterPing for the full rules.
+	// A 'new' check with a warmup stays 'new' until enough successes in a row
+	// arrived (models.StatusAfterWarmup).
+	newStatus := models.StatusAfterPing(currentStatus, kind, isEnabled)
+	newStatus, warmupSuccesses = models.StatusAfterWarmup(currentStatus, newStatus, kind, warmupPings, warmupSuccesses)
+
+	// A completion pairs with the check's open run, or with the start ping of its
+	// own run when it carries a run ID. Then it leaves the other runs open: the
+	// check's last_start_at moves on to the earliest of them.
+	runStart := lastStartAt
+	nextStartAt := sql.NullTime{}
+	if ping.RunID != "" && kind != models.PingKindStart {
+		if runStart, overran, err = findRunStart(ctx, tx, checkID, ping.RunID); err == nil {
+			nextStartAt, err = nextOpenStart(ctx, tx, checkID, ping.RunID, lastStartAt)
+		}
+		if err != nil {
+			logQueryError(ctx, "RecordPing - Failed to pair run '%s' of check ID %d: %v", ping.RunID, checkID, err)
+			return 0, fmt.Errorf("database error pairing run: %w", err)
+		}
+	}
+
+	// A success closing a start ping after more than max_duration is a slow run.
+	// The first of a streak gets a "ran slow" notification, sent by the worker
+	// (worker.slowCondition), unless the overrun already took the check down; a
+	// run within max_duration ends the streak.
+	ranSlow := touchCheck && kind == models.PingKindSuccess && overran
+	ranOnTime := touchCheck && kind == models.PingKindSuccess && hasMaxDuration && runStart.Valid && !overran
+	notifySlow := ranSlow && !inSlowIncident && currentStatus != models.StatusDown
+
+	switch {
+	case !touchCheck:
+		// History only, see InactivePingRecord
+	case kind == models.PingKindStart:
+		err = r.recordStart(ctx, tx, checkID, lastStartAt.Valid, ping.RunID)
+	default:
+		// A recovery from 'down' starts the stabilization window for the deferred
+		// recovery notification (see worker.recoveryCondition); going down cancels it.
+		// An outage whose notification a down dependency suppressed recovers
+		// silently too, and leaving 'down' ends the suppression.
+		recovered := currentStatus == models.StatusDown && newStatus == models.StatusUp && !suppressed
+		updateQuery := `
         UPDATE checks
-        SET last_ping_at = UTC_TIMESTAMP(), status = ?, updated_at = UTC_TIMESTAMP()
+        SET last_ping_at = UTC_TIMESTAMP(), last_start_at = ?, status = ?, updated_at = UTC_TIMESTAMP(),
+            recovery_pending_since = CASE WHEN ? THEN UTC_TIMESTAMP() WHEN ? = 'down' THEN NULL ELSE recovery_pending_since END,
+            notification_suppressed_by = IF(? = 'down', notification_suppressed_by, NULL),
+            slow_since = CASE WHEN ? THEN COALESCE(slow_since, UTC_TIMESTAMP()) WHEN ? THEN NULL ELSE slow_since END,
+            slow_pending_since = CASE WHEN ? THEN UTC_TIMESTAMP() ELSE slow_pending_since END,
+            warmup_successes = ?
         WHERE id = ?`
-	_, err = tx.ExecContext(ctx, updateQuery, newStatus, checkID)
+		_, err = tx.ExecContext(ctx, updateQuery, nextStartAt, newStatus, recovered, newStatus, newStatus, ranSlow, ranOnTime, notifySlow, warmupSuccesses, checkID)
+	}
 	if err != nil {
-		log.Printf("ERROR: RecordPing - Failed to update check ID %d: %v", checkID, err)
-		return fmt.Errorf("database error updating check: %w", err)
+		logQueryError(ctx, "RecordPing - Failed to update check ID %d: %v", checkID, err)
+		return 0, fmt.Errorf("database error updating check: %w", err)
 	}
 
-	// 3. Insert the ping details into the pings table
-	// For now, payload is NULL. Handle payload later if needed.
+	// 4. Insert the ping details into the pings table
+	// storedPayload is nil (NULL) when the ping carried no body. duration_ms is the
+	// time since the run's start ping, NULL when there was none.
+	// The check's ping counters count it too, whatever step 3 did.
+	var startedAt sql.NullTime
+	if kind != models.PingKindStart {
+		startedAt = runStart
+	}
+	runID := sql.NullString{String: ping.RunID, Valid: ping.RunID != ""}
+	clientPingID := sql.NullString{String: ping.ClientPingID, Valid: ping.ClientPingID != ""}
 	insertQuery := `
-        INSERT INTO pings (check_id, received_at, source_ip, user_agent, payload, created_at)
-        VALUES (?, UTC_TIMESTAMP(), ?, ?, NULL, UTC_TIMESTAMP())`
-	_, err = tx.ExecContext(ctx, insertQuery, checkID, sourceIP, userAgent)
+        INSERT INTO pings (check_id, kind, received_at, source_ip, user_agent, duration_ms, run_id, client_ping_id, payload, payload_compressed, created_at)
+        VALUES (?, ?, UTC_TIMESTAMP(), ?, ?, TIMESTAMPDIFF(MICROSECOND, ?, UTC_TIMESTAMP()) DIV 1000, ?, ?, ?, ?, UTC_TIMESTAMP())`
+	result, err := tx.ExecContext(ctx, insertQuery, checkID, kind, ping.SourceIP, ping.UserAgent, startedAt, runID, clientPingID, storedPayload, compressed)
 	if err != nil {
-		log.Printf("ERROR: RecordPing - Failed to insert ping record for check ID %d: %v", checkID, err)
-		return fmt.Errorf("database error recording ping details: %w", err)
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 && duplicateKeyName(mysqlErr.Message) == clientPingIDUniqueKey {
+			// A retry that got past the lookup above; step 3 is already written,
+			// so tx must not be committed
+			log.Printf("WARN: RecordPing - Client ping ID of check ID %d hit the unique index: %v", checkID, err)
+			if ping.Response != nil {
+				*ping.Response = response
+			}
+			return checkID, fmt.Errorf("%w: %w", ErrDuplicatePing, err)
+		}
+		logQueryError(ctx, "RecordPing - Failed to insert ping record for check ID %d: %v", checkID, err)
+		return 0, fmt.Errorf("database error recording ping details: %w", err)
+	}
+	counterQuery := `
+        UPDATE checks
+        SET total_pings = total_pings + 1, pings_this_week = pings_this_week + 1,
+            last_failure_at = IF(? = 'fail', UTC_TIMESTAMP(), last_failure_at)
+        WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, counterQuery, kind, checkID); err != nil {
+		logQueryError(ctx, "RecordPing - Failed to update ping counters of check ID %d: %v", checkID, err)
+		return 0, fmt.Errorf("database error updating ping counters: %w", err)
 	}
 
-	// 4. If all went well, commit the transaction
-	if err = tx.Commit(); err != nil {
-		log.Printf("ERROR: RecordPing - Failed to commit transaction for check ID %d: %v", checkID, err)
-		return fmt.Errorf("database error committing ping record: %w", err)
+	// 5. Record the status transition and the slow run, linked to the ping that
+	// caused them
+	var events []models.CheckEvent
+	if newStatus != currentStatus {
+		events = append(events, StatusChangedEvent(checkID, currentStatus, newStatus, models.EventSourcePing))
+	}
+	if ranSlow {
+		events = append(events, models.CheckEvent{CheckID: checkID, Type: models.EventCheckSlow, Source: models.EventSourcePing})
+	}
+	if len(events) > 0 {
+		pingID, err := result.LastInsertId()
+		if err != nil {
+			logQueryError(ctx, "RecordPing - Failed to get ping ID for check ID %d: %v", checkID, err)
+			return 0, fmt.Errorf("failed to retrieve new ping ID: %w", err)
+		}
+		for _, event := range events {
+			event.PingID = sql.NullInt64{Int64: pingID, Valid: true}
+			if err = InsertEvent(ctx, tx, event); err != nil {
+				logQueryError(ctx, "RecordPing - Failed to record event for check ID %d: %v", checkID, err)
+				return 0, err
+			}
+		}
 	}
+	if ping.Response != nil {
+		*ping.Response = response
+	}
+	return checkID, nil
+}
 
-	log.Printf("DEBUG: Successfully recorded ping for check ID %d (UUID: %s)", checkID, uuid)
-	return nil // Success
+// lateStartWindow is how soon after a completed run without a start ping a
+// start ping is taken to be that run's, delayed in transit, rather than the
+// start of the next one.
+const lateStartWindow = 30 * time.Second
+
+// recordStart opens a run on checkID for a start ping, tolerating repeated and
+// late start pings so they don't distort the next duration_ms:
+//   - a start ping while a run is open within max_duration is a retry of the
+//     same start (or an overlapping run) and keeps the earlier start time. An
+//     open run past max_duration was abandoned, and the new start replaces it;
+//   - a start ping shortly after a completion that had no start is that run's
+//     start arriving out of order. It stays in the history but opens no run,
+//     which would otherwise never be closed and eventually count as overdue.
+//     With a run ID there is no guessing: the start is late when its run
+//     already has a completion, however long ago.
+func (r *mysqlCheckRepository) recordStart(ctx context.Context, tx *sql.Tx, checkID int64, runOpen bool, runID string) error {
+	if runID != "" {
+		var completed bool
+		completedQuery := `SELECT EXISTS (SELECT 1 FROM pings WHERE check_id = ? AND run_id = ? AND kind <> 'start')`
+		if err := tx.QueryRowContext(ctx, completedQuery, checkID, runID).Scan(&completed); err != nil {
+			return fmt.Errorf("database error reading run: %w", err)
+		}
+		if completed {
+			log.Printf("DEBUG: Start ping for run '%s' of check ID %d arrived after the run completed, not opening a run", runID, checkID)
+			return nil
+		}
+	} else if !runOpen {
+		var orphan bool
+		orphanQuery := `
+            SELECT kind <> 'start' AND duration_ms IS NULL AND received_at >= (UTC_TIMESTAMP() - INTERVAL ? SECOND)
+            FROM pings WHERE check_id = ? ORDER BY id DESC LIMIT 1`
+		err := tx.QueryRowContext(ctx, orphanQuery, int64(lateStartWindow.Seconds()), checkID).Scan(&orphan)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("database error reading last ping: %w", err)
+		}
+		if orphan {
+			log.Printf("DEBUG: Start ping for check ID %d arrived after its run completed, not opening a run", checkID)
+			return nil
+		}
+	}
+	query := `
+        UPDATE checks
+        SET last_start_at = CASE
+                WHEN last_start_at IS NOT NULL AND max_duration IS NOT NULL
+                    AND last_start_at >= (UTC_TIMESTAMP() - INTERVAL max_duration SECOND) THEN last_start_at
+                ELSE UTC_TIMESTAMP()
+            END
+        WHERE id = ?`
+	_, err := tx.ExecContext(ctx, query, checkID)
+	return err
+}
+
+// findRunStart returns when run runID of checkID started, and whether it has
+// overrun max_duration, for a completion with that run ID. start is NULL when
+// the run has no start ping: the completion then has no duration and doesn't
+// count as slow or on time.
+func findRunStart(ctx context.Context, tx *sql.Tx, checkID int64, runID string) (start sql.NullTime, overran bool, err error) {
+	query := `
+        SELECT s.received_at, c.max_duration IS NOT NULL AND s.received_at < (UTC_TIMESTAMP() - INTERVAL c.max_duration SECOND)
+        FROM pings s
+        JOIN checks c ON c.id = s.check_id
+        WHERE s.check_id = ? AND s.run_id = ? AND s.kind = 'start'
+        ORDER BY s.id DESC LIMIT 1`
+	err = tx.QueryRowContext(ctx, query, checkID, runID).Scan(&start, &overran)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sql.NullTime{}, false, nil
+	}
+	return start, overran, err
+}
 
+// nextOpenStart returns the earliest start among checkID's runs other than
+// runID that are still open, i.e. start pings with a run ID since the check's
+// open run began (openSince) whose run has no completion yet. It is NULL when
+// there are none. Starts without a run ID can't be told apart, so any
+// completion ends them.
+func nextOpenStart(ctx context.Context, tx *sql.Tx, checkID int64, runID string, openSince sql.NullTime) (sql.NullTime, error) {
+	var next sql.NullTime
+	if !openSince.Valid {
+		return next, nil
+	}
+	query := `
+        SELECT MIN(s.received_at)
+        FROM pings s
+        WHERE s.check_id = ? AND s.kind = 'start' AND s.run_id IS NOT NULL AND s.run_id <> ? AND s.received_at >= ?
+            AND NOT EXISTS (
+                SELECT 1 FROM pings e
+                WHERE e.check_id = s.check_id AND e.run_id = s.run_id AND e.kind <> 'start')`
+	err := tx.QueryRowContext(ctx, query, checkID, runID, openSince).Scan(&next)
+	return next, err
 }
 
 // FindByUUID Implement other CheckRepository methods (FindByID, Create, etc.) here...
 // Example: FindByUUID (useful for other parts of the API perhaps)
 func (r *mysqlCheckRepository) FindByUUID(ctx context.Context, uuid string) (*models.Check, error) {
-	query := `SELECT id, user_id, uuid, name, description, expected_interval, grace_period, 
-                     last_ping_at, status, is_enabled, created_at, updated_at 
+	query := `SELECT ` + checkColumns + `
               FROM checks WHERE uuid = ? AND deleted_at IS NULL LIMIT 1`
 	row := r.db.QueryRowContext(ctx, query, uuid)
 	var check models.Check
-	err := row.Scan(
-		&check.ID, &check.UserID, &check.UUID, &check.Name, &check.Description,
-		&check.ExpectedInterval, &check.GracePeriod, &check.LastPingAt, &check.Status,
-		&check.IsEnabled, &check.CreatedAt, &check.UpdatedAt,
-	)
+	err := scanCheck(row, &check)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrCheckNotFound
 		}
-		log.Printf("ERROR: FindByUUID - Scan failed for UUID %s: %v", uuid, err)
+		logQueryError(ctx, "FindByUUID - Scan failed for UUID %s: %v", uuid, err)
 		return nil, fmt.Errorf("error retrieving check data: %w", err)
 	}
 	return &check, nil
 }
 
+// ExistsByUUID is a lightweight FindByUUID for callers that only need to know
+// whether a check exists: it reads the ID and whether the check is active
+// (enabled and not paused, the checks the InactivePingPolicy leaves alone)
+// instead of the whole row. Returns ErrCheckNotFound when there is no such
+// non-deleted check.
+func (r *mysqlCheckRepository) ExistsByUUID(ctx context.Context, uuid string) (checkID int64, active bool, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+	query := `SELECT id, is_enabled AND status <> 'paused' FROM checks WHERE uuid = ? AND deleted_at IS NULL LIMIT 1`
+	err = r.db.QueryRowContext(ctx, query, uuid).Scan(&checkID, &active)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, ErrCheckNotFound
+		}
+		logQueryError(ctx, "ExistsByUUID - Query failed for UUID %s: %v", uuid, err)
+		return 0, false, fmt.Errorf("error looking up check: %w", err)
+	}
+	return checkID, active, nil
+}
+
 // ListByUserID GetActiveChecksForUser retrieves all non-deleted checks for a specific user.
-func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) ([]models.Check, error) {
+func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) (_ []models.Check, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
 
 	// 1. Define the SQL Query
 	// Select the columns in the order you expect to Scan them.
@@ -244,7 +1028,10 @@ func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) (
 	query := `
 		SELECT
 			id, user_id, uuid, name, description, expected_interval,
-			grace_period, last_ping_at, status, is_enabled, created_at, updated_at
+			grace_period, last_ping_at, status, is_enabled, notify_late, severity, recovery_stabilization, color, icon, metadata,
+			forward_url, forward_failures, forward_last_error, forward_failed_at, pings_history_limit, require_signed_pings,
+			ping_response_code, ping_response_body, max_duration, warmup_pings, warmup_successes, muted_until,
+			total_pings, pings_this_week, last_failure_at, created_at, updated_at
 		FROM checks
 		WHERE user_id = ? AND deleted_at IS NULL
 		ORDER BY name ASC` // Or ORDER BY created_at, etc.
@@ -253,7 +1040,7 @@ func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) (
 	// Pass the context, query string, and any arguments (userID in this case).
 	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
-		log.Printf("ERROR: dbPool.QueryContext failed for user %d: %v", userID, err)
+		logQueryError(ctx, "dbPool.QueryContext failed for user %d: %v", userID, err)
 		// Return a wrapped error for context, hiding internal details if necessary
 		return nil, fmt.Errorf("error querying user checks: %w", err)
 	}
@@ -281,12 +1068,33 @@ func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) (
 			&check.LastPingAt, // Scan directly into sql.NullTime
 			&check.Status,
 			&check.IsEnabled,
+			&check.NotifyLate,
+			&check.Severity,
+			&check.RecoveryStabilization,
+			&check.Color,
+			&check.Icon,
+			&check.Metadata,
+			&check.ForwardURL,
+			&check.ForwardFailures,
+			&check.ForwardLastError,
+			&check.ForwardFailedAt,
+			&check.PingsHistoryLimit,
+			&check.RequireSignedPings,
+			&check.PingResponseCode,
+			&check.PingResponseBody,
+			&check.MaxDuration,
+			&check.WarmupPings,
+			&check.WarmupSuccesses,
+			&check.MutedUntil,
+			&check.TotalPings,
+			&check.PingsThisWeek,
+			&check.LastFailureAt,
 			&check.CreatedAt,
 			&check.UpdatedAt,
 		)
 		if err != nil {
 			// Log the error and potentially stop processing, returning the error.
-			log.Printf("ERROR: Failed to scan row for user %d check: %v", userID, err)
+			logQueryError(ctx, "Failed to scan row for user %d check: %v", userID, err)
 			return nil, fmt.Errorf("error scanning check data: %w", err)
 		}
 
@@ -296,7 +1104,7 @@ func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) (
 
 	// 8. Check for errors that may have occurred during iteration
 	if err = rows.Err(); err != nil {
-		log.Printf("ERROR: Error during row iteration for user %d checks: %v", userID, err)
+		logQueryError(ctx, "Error during row iteration for user %d checks: %v", userID, err)
 		return nil, fmt.Errorf("error iterating check results: %w", err)
 	}
 
@@ -304,3 +1112,228 @@ func (r *mysqlCheckRepository) ListByUserID(ctx context.Context, userID int64) (
 	log.Printf("INFO: Found %d checks for user %d", len(checks), userID)
 	return checks, nil
 }
+
+// CountByUserID returns how many non-deleted checks a user has.
+func (r *mysqlCheckRepository) CountByUserID(ctx context.Context, userID int64) (_ int, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	var count int
+	query := `SELECT COUNT(*) FROM checks WHERE user_id = ? AND deleted_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		logQueryError(ctx, "CountByUserID - Query failed for user %d: %v", userID, err)
+		return 0, fmt.Errorf("error counting user checks: %w", err)
+	}
+	return count, nil
+}
+
+// ListByUserIDPaginated returns one page of the checks ListByUserID lists: at
+// most limit of them, after skipping offset. The id tiebreak keeps pages
+// stable between requests. Pair it with CountByUserID for the total.
+func (r *mysqlCheckRepository) ListByUserIDPaginated(ctx context.Context, userID, offset, limit int64) (_ []models.Check, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE user_id = ? AND deleted_at IS NULL
+		ORDER BY name ASC, id ASC
+		LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		logQueryError(ctx, "ListByUserIDPaginated - Query failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error querying user checks: %w", err)
+	}
+	defer rows.Close()
+	return scanCheckRows(rows)
+}
+
+// EachByUserID streams the same checks as ListByUserID, in the same order,
+// calling fn for each without loading them all into memory. Iteration stops at
+// the first error returned by fn.
+func (r *mysqlCheckRepository) EachByUserID(ctx context.Context, userID int64, fn func(models.Check) error) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE user_id = ? AND deleted_at IS NULL
+		ORDER BY name ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		logQueryError(ctx, "EachByUserID - Query failed for user %d: %v", userID, err)
+		return fmt.Errorf("error querying user checks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var check models.Check
+		if err := scanCheck(rows, &check); err != nil {
+			return fmt.Errorf("error scanning check data: %w", err)
+		}
+		if err := fn(check); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating check results: %w", err)
+	}
+	return nil
+}
+
+// ListByMetadata returns userID's live checks whose metadata has key set to
+// value, ordered by name as ListByUserID. key must satisfy
+// models.IsValidMetadataKey, which keeps it safe inside the JSON path.
+func (r *mysqlCheckRepository) ListByMetadata(ctx context.Context, userID int64, key, value string) (_ []models.Check, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	if !models.IsValidMetadataKey(key) {
+		return nil, fmt.Errorf("invalid metadata key %q", key)
+	}
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE user_id = ? AND deleted_at IS NULL
+			AND JSON_UNQUOTE(JSON_EXTRACT(metadata, ?)) = ?
+		ORDER BY name ASC`
+	rows, err := r.db.QueryContext(ctx, query, userID, `$."`+key+`"`, value)
+	if err != nil {
+		logQueryError(ctx, "ListByMetadata - Query failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error querying user checks by metadata: %w", err)
+	}
+	defer rows.Close()
+	return scanCheckRows(rows)
+}
+
+// checkColumns is the column list matching scanCheck's field order.
+const checkColumns = `id, user_id, uuid, name, description, expected_interval,
+			grace_period, last_ping_at, status, is_enabled, notify_late, severity, recovery_stabilization, color, icon, metadata,
+			forward_url, forward_failures, forward_last_error, forward_failed_at, pings_history_limit, require_signed_pings,
+			ping_response_code, ping_response_body, max_duration, warmup_pings, warmup_successes, muted_until,
+			total_pings, pings_this_week, last_failure_at, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanCheck scans a row selected with checkColumns into a Check.
+func scanCheck(row rowScanner, check *models.Check) error {
+	return row.Scan(
+		&check.ID,
+		&check.UserID,
+		&check.UUID,
+		&check.Name,
+		&check.Description,
+		&check.ExpectedInterval,
+		&check.GracePeriod,
+		&check.LastPingAt,
+		&check.Status,
+		&check.IsEnabled,
+		&check.NotifyLate,
+		&check.Severity,
+		&check.RecoveryStabilization,
+		&check.Color,
+		&check.Icon,
+		&check.Metadata,
+		&check.ForwardURL,
+		&check.ForwardFailures,
+		&check.ForwardLastError,
+		&check.ForwardFailedAt,
+		&check.PingsHistoryLimit,
+		&check.RequireSignedPings,
+		&check.PingResponseCode,
+		&check.PingResponseBody,
+		&check.MaxDuration,
+		&check.WarmupPings,
+		&check.WarmupSuccesses,
+		&check.MutedUntil,
+		&check.TotalPings,
+		&check.PingsThisWeek,
+		&check.LastFailureAt,
+		&check.CreatedAt,
+		&check.UpdatedAt,
+	)
+}
+
+// scanCheckRows drains rows selected with checkColumns, always returning a non-nil slice.
+func scanCheckRows(rows *sql.Rows) ([]models.Check, error) {
+	checks := []models.Check{}
+	for rows.Next() {
+		var check models.Check
+		if err := scanCheck(rows, &check); err != nil {
+			return nil, fmt.Errorf("error scanning check data: %w", err)
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating check results: %w", err)
+	}
+	return checks, nil
+}
+
+// ListByStatus returns all non-deleted checks with the given status, oldest ping first.
+// A userID of 0 lists checks across all users (admin tooling).
+func (r *mysqlCheckRepository) ListByStatus(ctx context.Context, status string, userID int64) ([]models.Check, error) {
+	query := `SELECT ` + checkColumns + `
+		FROM checks
+		WHERE status = ? AND deleted_at IS NULL AND (? = 0 OR user_id = ?)
+		ORDER BY last_ping_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, status, userID, userID)
+	if err != nil {
+		logQueryError(ctx, "ListByStatus - Query failed for status '%s': %v", status, err)
+		return nil, fmt.Errorf("error querying checks by status: %w", err)
+	}
+	defer rows.Close()
+
+	checks, err := scanCheckRows(rows)
+	if err != nil {
+		logQueryError(ctx, "ListByStatus - %v", err)
+		return nil, err
+	}
+	return checks, nil
+}
+
+// SetStatus forces a check's status and records the transition in check_events,
+// attributed to source. Both happen in one transaction so history stays consistent.
+func (r *mysqlCheckRepository) SetStatus(ctx context.Context, uuid string, status string, source string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var checkID int64
+	var currentStatus string
+	findQuery := "SELECT id, status FROM checks WHERE uuid = ? AND deleted_at IS NULL LIMIT 1 FOR UPDATE"
+	err = tx.QueryRowContext(ctx, findQuery, uuid).Scan(&checkID, &currentStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCheckNotFound
+		}
+		logQueryError(ctx, "SetStatus - Failed to find check by UUID '%s': %v", uuid, err)
+		return fmt.Errorf("database error finding check: %w", err)
+	}
+
+	// Nothing changes, so don't write a misleading history entry
+	if currentStatus == status {
+		return nil
+	}
+
+	updateQuery := `UPDATE checks SET status = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, updateQuery, status, checkID); err != nil {
+		logQueryError(ctx, "SetStatus - Failed to update check ID %d: %v", checkID, err)
+		return fmt.Errorf("database error updating check status: %w", err)
+	}
+
+	if err = InsertEvent(ctx, tx, StatusChangedEvent(checkID, currentStatus, status, source)); err != nil {
+		logQueryError(ctx, "SetStatus - Failed to record event for check ID %d: %v", checkID, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logQueryError(ctx, "SetStatus - Failed to commit transaction for check ID %d: %v", checkID, err)
+		return fmt.Errorf("database error committing status change: %w", err)
+	}
+
+	log.Printf("INFO: Check ID %d (UUID: %s) status changed %s -> %s by %s", checkID, uuid, currentStatus, status, source)
+	return nil
+}
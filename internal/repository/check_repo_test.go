@@ -0,0 +1,637 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"bitterlink/core/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestCheckUpdateSetClause(t *testing.T) {
+	name, interval, enabled := "nightly", uint32(600), false
+	description := sql.NullString{}
+	metadata := models.CheckMetadata{"team": "ops"}
+	paused, fresh := models.StatusPaused, models.StatusNew
+	color, noIcon := sql.NullString{String: "#1e90ff", Valid: true}, sql.NullString{}
+	stabilization := uint32(900)
+
+	tests := []struct {
+		name            string
+		update          CheckUpdate
+		wantAssignments []string
+		wantArgs        []any
+	}{
+		{name: "empty", update: CheckUpdate{}},
+		{
+			name:            "one field",
+			update:          CheckUpdate{Name: &name},
+			wantAssignments: []string{"name = ?"},
+			wantArgs:        []any{"nightly"},
+		},
+		{
+			name:            "several fields, cleared description",
+			update:          CheckUpdate{Description: &description, ExpectedInterval: &interval, IsEnabled: &enabled, Metadata: &metadata},
+			wantAssignments: []string{"description = ?", "expected_interval = ?", "is_enabled = ?", "metadata = ?"},
+			wantArgs:        []any{sql.NullString{}, uint32(600), false, metadata},
+		},
+		{
+			name:            "paused",
+			update:          CheckUpdate{Status: &paused},
+			wantAssignments: []string{"status = ?"},
+			wantArgs:        []any{"paused"},
+		},
+		{
+			name:            "presentation, cleared icon",
+			update:          CheckUpdate{Color: &color, Icon: &noIcon},
+			wantAssignments: []string{"color = ?", "icon = ?"},
+			wantArgs:        []any{color, sql.NullString{}},
+		},
+		{
+			name:            "recovery stabilization",
+			update:          CheckUpdate{RecoveryStabilization: &stabilization},
+			wantAssignments: []string{"recovery_stabilization = ?"},
+			wantArgs:        []any{uint32(900)},
+		},
+		{
+			name:            "back to new clears a pending recovery",
+			update:          CheckUpdate{Status: &fresh},
+			wantAssignments: []string{"status = ?", "recovery_pending_since = NULL"},
+			wantArgs:        []any{"new"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignments, args := tt.update.setClause()
+			if !reflect.DeepEqual(assignments, tt.wantAssignments) {
+				t.Errorf("assignments = %q, want %q", assignments, tt.wantAssignments)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// newMockCheckRepo returns a check repository over sqlmock, failing the test
+// on unmet expectations.
+func newMockCheckRepo(t *testing.T) (*mysqlCheckRepository, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+		db.Close()
+	})
+	return &mysqlCheckRepository{db: db}, mock
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	name := "nightly"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE checks SET name = ?, updated_at = UTC_TIMESTAMP() WHERE id = ? AND deleted_at IS NULL")).
+		WithArgs("nightly", int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM checks WHERE id = ? AND deleted_at IS NULL")).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}))
+	mock.ExpectRollback()
+
+	err := repo.Update(context.Background(), 7, CheckUpdate{Name: &name})
+	if !errors.Is(err, ErrCheckNotFound) {
+		t.Fatalf("Update = %v, want ErrCheckNotFound", err)
+	}
+}
+
+func TestUpdateUnchangedRowIsNotMissing(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	name := "nightly"
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE checks SET name = \\?").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM checks")).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO check_events").
+		WithArgs(int64(7), models.EventCheckUpdated, sqlmock.AnyArg(), sqlmock.AnyArg(), models.EventSourceAPI, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Update(context.Background(), 7, CheckUpdate{Name: &name}); err != nil {
+		t.Fatalf("Update = %v, want nil", err)
+	}
+}
+
+func TestUpdateStatusRecordsTransition(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	status := models.StatusPaused
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT is_enabled, status FROM checks WHERE id = ?")).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"is_enabled", "status"}).AddRow(true, models.StatusUp))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE checks SET status = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?")).
+		WithArgs(models.StatusPaused, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO check_events").
+		WithArgs(int64(7), models.EventCheckUpdated, sqlmock.AnyArg(), sqlmock.AnyArg(), models.EventSourceAPI, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO check_events").
+		WithArgs(int64(7), models.EventStatusChanged, sqlmock.AnyArg(), sqlmock.AnyArg(), models.EventSourceAPI, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Update(context.Background(), 7, CheckUpdate{Status: &status}); err != nil {
+		t.Fatalf("Update = %v, want nil", err)
+	}
+}
+
+func TestUpdateWithoutFieldsWritesNothing(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 1 FROM checks")).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	if err := repo.Update(context.Background(), 7, CheckUpdate{}); err != nil {
+		t.Fatalf("Update = %v, want nil", err)
+	}
+}
+
+// expectPingCheck expects RecordPing to open its transaction and find check 7
+// with status and isEnabled, no open run and no warmup.
+func expectPingCheck(mock sqlmock.Sqlmock, status string, isEnabled bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, status, is_enabled, last_start_at").
+		WithArgs("uuid-7", int64(0), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "is_enabled", "last_start_at", "ping_response_code", "ping_response_body",
+			"name", "expected_interval", "warmup_pings", "warmup_successes", "has_max_duration", "in_slow_incident", "overran", "suppressed"}).
+			AddRow(7, status, isEnabled, nil, nil, nil, "backup", 3600, 1, 0, false, false, false, false))
+}
+
+// expectPingUpdate expects the check's update for a success ping, moving it to
+// newStatus.
+func expectPingUpdate(mock sqlmock.Sqlmock, newStatus string, recovered bool) {
+	mock.ExpectExec(regexp.QuoteMeta("SET last_ping_at = UTC_TIMESTAMP(), last_start_at = ?, status = ?")).
+		WithArgs(sqlmock.AnyArg(), newStatus, recovered, newStatus, newStatus, false, false, false, uint32(0), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectPingInsert expects the ping row and the check's ping counters.
+func expectPingInsert(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("INSERT INTO pings").WillReturnResult(sqlmock.NewResult(99, 1))
+	mock.ExpectExec("SET total_pings = total_pings \\+ 1").
+		WithArgs(models.PingKindSuccess, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestRecordPingFlipsEnabledCheckUp(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	expectPingCheck(mock, models.StatusDown, true)
+	expectPingUpdate(mock, models.StatusUp, true)
+	expectPingInsert(mock)
+	mock.ExpectExec("INSERT INTO check_events").
+		WithArgs(int64(7), models.EventStatusChanged, sqlmock.AnyArg(), sqlmock.AnyArg(), models.EventSourcePing, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"}); err != nil {
+		t.Fatalf("RecordPing = %v, want nil", err)
+	}
+}
+
+func TestRecordPingLeavesDisabledCheckStatus(t *testing.T) {
+	for _, status := range []string{models.StatusDown, models.StatusNew} {
+		t.Run(status, func(t *testing.T) {
+			repo, mock := newMockCheckRepo(t)
+			expectPingCheck(mock, status, false)
+			expectPingUpdate(mock, status, false) // Recorded, last_ping_at moves, status doesn't
+			expectPingInsert(mock)
+			mock.ExpectCommit() // No status_changed event
+
+			if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"}); err != nil {
+				t.Fatalf("RecordPing = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestRecordPingLeavesPausedCheckPaused(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	expectPingCheck(mock, models.StatusPaused, true)
+	expectPingUpdate(mock, models.StatusPaused, false)
+	expectPingInsert(mock)
+	mock.ExpectCommit()
+
+	if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"}); err != nil {
+		t.Fatalf("RecordPing = %v, want nil", err)
+	}
+}
+
+func TestRecordPingInactivePolicy(t *testing.T) {
+	tests := []struct {
+		policy  string
+		status  string
+		enabled bool
+	}{
+		{policy: InactivePingReject, status: models.StatusDown, enabled: false},
+		{policy: InactivePingReject, status: models.StatusPaused, enabled: true},
+		{policy: InactivePingRecord, status: models.StatusDown, enabled: false},
+		{policy: InactivePingRecord, status: models.StatusPaused, enabled: true},
+		{policy: InactivePingRecordNoStatus, status: models.StatusDown, enabled: false},
+		{policy: "", status: models.StatusPaused, enabled: true}, // The default
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+tt.status, func(t *testing.T) {
+			repo, mock := newMockCheckRepo(t)
+			repo.config.InactivePingPolicy = tt.policy
+			expectPingCheck(mock, tt.status, tt.enabled)
+			switch tt.policy {
+			case InactivePingReject:
+				// Nothing stored
+				mock.ExpectRollback()
+			case InactivePingRecord:
+				// The ping is kept but the check row isn't touched
+				expectPingInsert(mock)
+				mock.ExpectCommit()
+			default:
+				expectPingUpdate(mock, tt.status, false)
+				expectPingInsert(mock)
+				mock.ExpectCommit()
+			}
+
+			err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"})
+			if tt.policy == InactivePingReject {
+				if !errors.Is(err, ErrCheckInactive) {
+					t.Fatalf("RecordPing = %v, want ErrCheckInactive", err)
+				}
+			} else if err != nil {
+				t.Fatalf("RecordPing = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestRecordPingInactivePolicyIgnoresActiveChecks(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	repo.config.InactivePingPolicy = InactivePingReject
+	expectPingCheck(mock, models.StatusUp, true)
+	expectPingUpdate(mock, models.StatusUp, false)
+	expectPingInsert(mock)
+	mock.ExpectCommit()
+
+	if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"}); err != nil {
+		t.Fatalf("RecordPing = %v, want nil", err)
+	}
+}
+
+func TestIsValidInactivePingPolicy(t *testing.T) {
+	for _, policy := range []string{InactivePingRecordNoStatus, InactivePingRecord, InactivePingReject} {
+		if !IsValidInactivePingPolicy(policy) {
+			t.Errorf("IsValidInactivePingPolicy(%q) = false", policy)
+		}
+	}
+	for _, policy := range []string{"", "drop", "Record"} {
+		if IsValidInactivePingPolicy(policy) {
+			t.Errorf("IsValidInactivePingPolicy(%q) = true", policy)
+		}
+	}
+}
+
+func TestDuplicateKeyName(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Duplicate entry 'abc' for key 'checks.uuid'", "uuid"}, // MySQL 8.0
+		{"Duplicate entry 'abc' for key 'uuid'", "uuid"},        // MySQL 5.7
+		{"Duplicate entry '7-backup' for key 'checks.uq_checks_user_name'", "uq_checks_user_name"},
+		{"Duplicate entry 'it's' for key 'checks.uuid'", "uuid"}, // Quote in the value
+		{"Duplicate entry 'abc' for key 'checks.uuid", ""},       // Unterminated
+		{"Lock wait timeout exceeded", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := duplicateKeyName(tt.message); got != tt.want {
+			t.Errorf("duplicateKeyName(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestCreateDuplicateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    error
+	}{
+		{name: "uuid", message: "Duplicate entry 'uuid-7' for key 'checks.uuid'", want: ErrDuplicateUUID},
+		{name: "name", message: "Duplicate entry '1-backup' for key 'checks.uq_checks_user_name'", want: ErrDuplicateName},
+		{name: "other key", message: "Duplicate entry 'x' for key 'checks.uq_other'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMockCheckRepo(t)
+			mock.ExpectBegin()
+			mock.ExpectExec("INSERT INTO checks").WillReturnError(&mysql.MySQLError{Number: 1062, Message: tt.message})
+			mock.ExpectRollback()
+
+			err := repo.Create(context.Background(), &models.Check{UserID: 1, UUID: "uuid-7", Name: "backup", ExpectedInterval: 60})
+			if err == nil {
+				t.Fatal("Create = nil, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Create = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (errors.Is(err, ErrDuplicateUUID) || errors.Is(err, ErrDuplicateName)) {
+				t.Errorf("Create = %v, want neither typed duplicate error", err)
+			}
+		})
+	}
+}
+
+// A retry of a recorded ping is found under the check's lock and answered
+// like the original, with nothing written.
+func TestRecordPingDuplicateClientPingID(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	expectPingCheck(mock, models.StatusUp, true)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM pings WHERE check_id = ? AND client_ping_id = ? LIMIT 1 FOR UPDATE")).
+		WithArgs(int64(7), "retry-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(98))
+	mock.ExpectRollback()
+
+	var response models.PingResponse
+	err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7", ClientPingID: "retry-1", Response: &response})
+	if !errors.Is(err, ErrDuplicatePing) {
+		t.Fatalf("RecordPing = %v, want ErrDuplicatePing", err)
+	}
+	if response.CheckName != "backup" {
+		t.Errorf("response check name = %q, want the original's answer for backup", response.CheckName)
+	}
+}
+
+// Two first submissions that both miss the lookup race on the unique index;
+// the loser's insert fails with 1062 and it is rolled back as a duplicate.
+func TestRecordPingClientPingIDUniqueIndex(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	expectPingCheck(mock, models.StatusUp, true)
+	mock.ExpectQuery("SELECT id FROM pings WHERE check_id = ?").
+		WithArgs(int64(7), "retry-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	expectPingUpdate(mock, models.StatusUp, false)
+	mock.ExpectExec("INSERT INTO pings").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '7-retry-1' for key 'pings.uq_pings_check_client_ping_id'"})
+	mock.ExpectRollback()
+
+	err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7", ClientPingID: "retry-1"})
+	if !errors.Is(err, ErrDuplicatePing) {
+		t.Fatalf("RecordPing = %v, want ErrDuplicatePing", err)
+	}
+}
+
+// Any other duplicate entry on the insert stays a plain error.
+func TestRecordPingOtherDuplicateKey(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	expectPingCheck(mock, models.StatusUp, true)
+	expectPingUpdate(mock, models.StatusUp, false)
+	mock.ExpectExec("INSERT INTO pings").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '99' for key 'pings.PRIMARY'"})
+	mock.ExpectRollback()
+
+	err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"})
+	if err == nil || errors.Is(err, ErrDuplicatePing) {
+		t.Fatalf("RecordPing = %v, want a database error", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE checks SET deleted_at = UTC_TIMESTAMP(), updated_at = UTC_TIMESTAMP() WHERE id = ? AND user_id = ? AND deleted_at IS NULL")).
+		WithArgs(int64(42), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO check_events").
+		WithArgs(int64(42), models.EventCheckDeleted, sqlmock.AnyArg(), sqlmock.AnyArg(), models.EventSourceAPI, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.Delete(context.Background(), 42, 7); err != nil {
+		t.Fatalf("Delete = %v, want nil", err)
+	}
+}
+
+// When no row was deleted, the follow-up lookup tells a repeated delete from a
+// check that isn't there (or isn't the user's); both are not-found.
+func TestDeleteNothingDeleted(t *testing.T) {
+	deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		rows        *sqlmock.Rows
+		wantAlready bool
+	}{
+		{name: "deleted before", rows: sqlmock.NewRows([]string{"deleted_at"}).AddRow(deletedAt), wantAlready: true},
+		{name: "never existed", rows: sqlmock.NewRows([]string{"deleted_at"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newMockCheckRepo(t)
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE checks SET deleted_at").
+				WithArgs(int64(42), int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT deleted_at FROM checks WHERE id = ? AND user_id = ?")).
+				WithArgs(int64(42), int64(7)).
+				WillReturnRows(tt.rows)
+			mock.ExpectRollback()
+
+			err := repo.Delete(context.Background(), 42, 7)
+			if !errors.Is(err, ErrCheckNotFound) {
+				t.Fatalf("Delete = %v, want ErrCheckNotFound", err)
+			}
+			if got := errors.Is(err, ErrCheckAlreadyDeleted); got != tt.wantAlready {
+				t.Errorf("errors.Is(err, ErrCheckAlreadyDeleted) = %t, want %t", got, tt.wantAlready)
+			}
+		})
+	}
+}
+
+func TestDeleteDatabaseError(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE checks SET deleted_at").WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	err := repo.Delete(context.Background(), 42, 7)
+	if err == nil || errors.Is(err, ErrCheckNotFound) {
+		t.Fatalf("Delete = %v, want a database error", err)
+	}
+}
+
+// checkColumnNames are the columns of checkColumns, for mocked rows.
+var checkColumnNames = []string{"id", "user_id", "uuid", "name", "description", "expected_interval",
+	"grace_period", "last_ping_at", "status", "is_enabled", "notify_late", "severity", "recovery_stabilization", "color", "icon", "metadata",
+	"forward_url", "forward_failures", "forward_last_error", "forward_failed_at", "pings_history_limit", "require_signed_pings",
+	"ping_response_code", "ping_response_body", "max_duration", "warmup_pings", "warmup_successes", "muted_until",
+	"total_pings", "pings_this_week", "last_failure_at", "created_at", "updated_at"}
+
+// checkRow is a mocked checks row for check 42 of user 7, with the given
+// description and last_ping_at (nil for NULL) and every other nullable column NULL.
+func checkRow(description any, lastPingAt any) *sqlmock.Rows {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return sqlmock.NewRows(checkColumnNames).AddRow(
+		42, 7, "uuid-42", "backup", description, 3600,
+		300, lastPingAt, models.StatusUp, true, false, models.SeverityCritical, 0, nil, nil, `{"team":"ops"}`,
+		nil, 0, nil, nil, nil, false,
+		nil, nil, nil, 1, 0, nil,
+		12, 3, nil, created, created)
+}
+
+func TestFindByID(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	lastPing := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM checks WHERE id = ? AND deleted_at IS NULL")).
+		WithArgs(int64(42)).
+		WillReturnRows(checkRow("nightly dump", lastPing))
+
+	check, err := repo.FindByID(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if check.ID != 42 || check.UserID != 7 || check.UUID != "uuid-42" || check.Name != "backup" || check.ExpectedInterval != 3600 || check.GracePeriod != 300 {
+		t.Errorf("check = %+v, want check 42 of user 7", check)
+	}
+	if check.Description != (sql.NullString{String: "nightly dump", Valid: true}) || !check.LastPingAt.Valid || !check.LastPingAt.Time.Equal(lastPing) {
+		t.Errorf("description %v, last_ping_at %v; want both set", check.Description, check.LastPingAt)
+	}
+	if check.Status != models.StatusUp || !check.IsEnabled || check.Metadata["team"] != "ops" || check.TotalPings != 12 || check.PingsThisWeek != 3 {
+		t.Errorf("check = %+v, want up, enabled, team=ops, 12/3 pings", check)
+	}
+}
+
+// Missing and soft-deleted checks both select no row.
+func TestFindByIDNotFound(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectQuery("FROM checks WHERE id = \\? AND deleted_at IS NULL").
+		WithArgs(int64(42)).
+		WillReturnRows(sqlmock.NewRows(checkColumnNames))
+
+	if _, err := repo.FindByID(context.Background(), 42); !errors.Is(err, ErrCheckNotFound) {
+		t.Fatalf("FindByID = %v, want ErrCheckNotFound", err)
+	}
+}
+
+func TestFindByIDDatabaseError(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	dbErr := errors.New("server has gone away")
+	mock.ExpectQuery("FROM checks WHERE id = \\?").WillReturnError(dbErr)
+
+	_, err := repo.FindByID(context.Background(), 42)
+	if !errors.Is(err, dbErr) || errors.Is(err, ErrCheckNotFound) {
+		t.Fatalf("FindByID = %v, want the wrapped database error", err)
+	}
+}
+
+// nullArg matches a NULL query argument.
+type nullArg struct{}
+
+func (nullArg) Match(v driver.Value) bool { return v == nil }
+
+// A check without a description that was never pinged is written with both
+// columns NULL and comes back with both NULLs intact, not as "" and the zero time.
+func TestCheckNullColumnsRoundTrip(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	args := make([]driver.Value, 22)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[3], args[6] = nullArg{}, nullArg{} // description, last_ping_at
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO checks").WithArgs(args...).WillReturnResult(sqlmock.NewResult(42, 1))
+	mock.ExpectExec("INSERT INTO check_events").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("FROM checks WHERE id = \\?").
+		WithArgs(int64(42)).
+		WillReturnRows(checkRow(nil, nil))
+
+	created := models.Check{UserID: 7, UUID: "uuid-42", Name: "backup", ExpectedInterval: 3600}
+	if err := repo.Create(context.Background(), &created); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	check, err := repo.FindByID(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if check.Description.Valid || check.LastPingAt.Valid {
+		t.Errorf("description %v, last_ping_at %v; want both NULL", check.Description, check.LastPingAt)
+	}
+	if check.Color.Valid || check.MaxDuration.Valid || check.MutedUntil.Valid || check.LastFailureAt.Valid {
+		t.Errorf("check = %+v, want the other nullable columns NULL too", check)
+	}
+}
+
+// BenchmarkRecordPing compares a steady-state success ping on the fast path
+// (two statements, no transaction) with the transactional path it replaced
+// (BEGIN, SELECT ... FOR UPDATE, UPDATE, INSERT, counters, COMMIT). Against
+// sqlmock this measures the client-side cost per ping: statements, round trips
+// and allocations, not MySQL's lock time.
+func BenchmarkRecordPing(b *testing.B) {
+	b.Run("fast", func(b *testing.B) {
+		benchmarkRecordPing(b, true, func(mock sqlmock.Sqlmock) {
+			mock.ExpectExec("UPDATE checks").WithArgs("uuid-7").WillReturnResult(sqlmock.NewResult(7, 1))
+			mock.ExpectExec("INSERT INTO pings").WillReturnResult(sqlmock.NewResult(99, 1))
+		})
+	})
+	b.Run("transactional", func(b *testing.B) {
+		benchmarkRecordPing(b, false, func(mock sqlmock.Sqlmock) {
+			expectPingCheck(mock, models.StatusUp, true)
+			expectPingUpdate(mock, models.StatusUp, false)
+			expectPingInsert(mock)
+			mock.ExpectCommit()
+		})
+	})
+}
+
+// benchmarkRecordPing times one RecordPing per iteration against a fresh
+// sqlmock set up by expect, outside the timer. sqlmock scans every expectation
+// on each statement, so sharing one across iterations would time the mock.
+func benchmarkRecordPing(b *testing.B, fastPath bool, expect func(sqlmock.Sqlmock)) {
+	log.SetOutput(io.Discard) // The DEBUG line per ping
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+	b.ReportAllocs()
+	for range b.N {
+		b.StopTimer()
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			b.Fatalf("sqlmock.New: %v", err)
+		}
+		expect(mock)
+		repo := &mysqlCheckRepository{db: db}
+		repo.config.PingFastPath = fastPath
+		b.StartTimer()
+
+		if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"}); err != nil {
+			b.Fatalf("RecordPing = %v", err)
+		}
+
+		b.StopTimer()
+		if err := mock.ExpectationsWereMet(); err != nil {
+			b.Fatalf("unmet expectations: %v", err)
+		}
+		db.Close()
+		b.StartTimer()
+	}
+}
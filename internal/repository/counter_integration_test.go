@@ -0,0 +1,127 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"bitterlink/core/internal/models"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// These tests need a migrated database, named by INTEGRATION_DB_DSN (a
+// go-sql-driver DSN with parseTime=true). Run them with -tags integration.
+
+func openIntegrationDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("INTEGRATION_DB_DSN")
+	if dsn == "" {
+		t.Skip("INTEGRATION_DB_DSN not set")
+	}
+	pool, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+// seedCheck creates a user with one new check, removed again when the test
+// ends, and returns the check's ID and UUID.
+func seedCheck(t *testing.T, pool *sql.DB) (int64, string) {
+	t.Helper()
+	ctx := context.Background()
+	email := fmt.Sprintf("repo-test-%s@example.com", uuid.NewString()[:8])
+	result, err := pool.ExecContext(ctx, `INSERT INTO users (name, email, password_hash, created_at, updated_at)
+		VALUES ('repository test', ?, '-', UTC_TIMESTAMP(), UTC_TIMESTAMP())`, email)
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	userID, _ := result.LastInsertId()
+	t.Cleanup(func() {
+		pool.Exec(`DELETE FROM pings WHERE check_id IN (SELECT id FROM checks WHERE user_id = ?)`, userID)
+		pool.Exec(`DELETE FROM check_events WHERE check_id IN (SELECT id FROM checks WHERE user_id = ?)`, userID)
+		pool.Exec(`DELETE FROM checks WHERE user_id = ?`, userID)
+		pool.Exec(`DELETE FROM users WHERE id = ?`, userID)
+	})
+
+	checkUUID := uuid.NewString()
+	result, err = pool.ExecContext(ctx, `INSERT INTO checks (user_id, uuid, name, expected_interval, grace_period, status, is_enabled, created_at, updated_at)
+		VALUES (?, ?, 'counters', 3600, 60, 'new', TRUE, UTC_TIMESTAMP(), UTC_TIMESTAMP())`, userID, checkUUID)
+	if err != nil {
+		t.Fatalf("creating check: %v", err)
+	}
+	checkID, _ := result.LastInsertId()
+	return checkID, checkUUID
+}
+
+type pingCounters struct {
+	total, week   int64
+	lastFailureAt sql.NullTime
+}
+
+func readPingCounters(t *testing.T, pool *sql.DB, checkID int64) pingCounters {
+	t.Helper()
+	var c pingCounters
+	err := pool.QueryRow(`SELECT total_pings, pings_this_week, last_failure_at FROM checks WHERE id = ?`, checkID).
+		Scan(&c.total, &c.week, &c.lastFailureAt)
+	if err != nil {
+		t.Fatalf("reading counters: %v", err)
+	}
+	return c
+}
+
+// Pings bump the counters, pruning leaves them too high, and reconciling
+// brings them back in line while keeping the pruned failure's time.
+func TestPingCountersDriftAndReconcile(t *testing.T) {
+	pool := openIntegrationDB(t)
+	ctx := context.Background()
+	repo := NewMySQLCheckRepository(pool, Config{}).(*mysqlCheckRepository)
+	checkID, checkUUID := seedCheck(t, pool)
+
+	// 1. Increment
+	for _, kind := range []string{models.PingKindSuccess, models.PingKindFail, models.PingKindSuccess} {
+		if err := repo.RecordPing(ctx, PingRecord{UUID: checkUUID, Kind: kind}); err != nil {
+			t.Fatalf("RecordPing(%s): %v", kind, err)
+		}
+	}
+	recorded := readPingCounters(t, pool, checkID)
+	if recorded.total != 3 || recorded.week != 3 || !recorded.lastFailureAt.Valid {
+		t.Fatalf("after 3 pings: %+v, want 3, 3 and a failure time", recorded)
+	}
+
+	// 2. Prune the two oldest pings, the failure among them; nothing updates the counters
+	if _, err := pool.ExecContext(ctx, `DELETE FROM pings WHERE check_id = ? ORDER BY id LIMIT 2`, checkID); err != nil {
+		t.Fatalf("pruning pings: %v", err)
+	}
+	if drifted := readPingCounters(t, pool, checkID); drifted.total != 3 {
+		t.Fatalf("after pruning: total %d, want it still at 3", drifted.total)
+	}
+
+	// 3. Reconcile
+	lastID, corrected, err := repo.ReconcilePingCounters(ctx, checkID-1, 1)
+	if err != nil {
+		t.Fatalf("ReconcilePingCounters: %v", err)
+	}
+	if lastID != checkID || corrected != 1 {
+		t.Errorf("lastID, corrected = %d, %d; want %d, 1", lastID, corrected, checkID)
+	}
+	reconciled := readPingCounters(t, pool, checkID)
+	if reconciled.total != 1 || reconciled.week != 1 {
+		t.Errorf("after reconciling: total %d, week %d; want 1, 1", reconciled.total, reconciled.week)
+	}
+	if !reconciled.lastFailureAt.Valid || !reconciled.lastFailureAt.Time.Equal(recorded.lastFailureAt.Time) {
+		t.Errorf("last_failure_at = %v, want the pruned failure's %v", reconciled.lastFailureAt, recorded.lastFailureAt)
+	}
+
+	// A second pass finds nothing to correct
+	if _, corrected, err := repo.ReconcilePingCounters(ctx, checkID-1, 1); err != nil || corrected != 0 {
+		t.Errorf("second pass corrected %d (%v), want 0", corrected, err)
+	}
+}
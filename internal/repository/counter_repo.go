@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping counters, see migrations/0026_check_ping_counters.sql: RecordPing bumps
+// total_pings and pings_this_week and sets last_failure_at with each ping, but
+// nothing takes them back down when pings are pruned or trimmed, or age out of
+// the week. ReconcilePingCounters recomputes them.
+
+// ReconcilePingCounters recomputes the ping counters of up to limit live checks
+// with IDs above afterID, in ID order, from their pings. It returns the last ID
+// covered, 0 once no check is left, and how many checks had drifted. A
+// last_failure_at whose fail ping was pruned is kept: the check did fail then.
+//
+// The checks are locked first, so pings recorded meanwhile wait and aren't
+// lost from the counts. Only the fast path's insert, which follows its own
+// update outside a transaction, can slip through; the next pass catches it.
+func (r *mysqlCheckRepository) ReconcilePingCounters(ctx context.Context, afterID int64, limit int) (lastID, corrected int64, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the next batch of checks
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM checks WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ? FOR UPDATE`, afterID, limit)
+	if err != nil {
+		logQueryError(ctx, "ReconcilePingCounters - Failed to lock checks after ID %d: %v", afterID, err)
+		return 0, 0, fmt.Errorf("database error locking checks: %w", err)
+	}
+	var ids []any
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("error scanning check ID: %w", err)
+		}
+		ids = append(ids, id)
+		lastID = id
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("error iterating check IDs: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, 0, nil
+	}
+
+	// 2. Recompute their counters. MySQL only counts changed rows, so the
+	// affected rows are the checks that had drifted.
+	in := placeholders(len(ids))
+	query := `
+        UPDATE checks c
+        LEFT JOIN (
+            SELECT check_id, COUNT(*) AS total,
+                SUM(received_at >= UTC_TIMESTAMP() - INTERVAL 7 DAY) AS week,
+                MAX(IF(kind = 'fail', received_at, NULL)) AS last_failure
+            FROM pings
+            WHERE check_id IN (` + in + `)
+            GROUP BY check_id
+        ) agg ON agg.check_id = c.id
+        SET c.total_pings = COALESCE(agg.total, 0),
+            c.pings_this_week = COALESCE(agg.week, 0),
+            c.last_failure_at = COALESCE(agg.last_failure, c.last_failure_at)
+        WHERE c.id IN (` + in + `)`
+	result, err := tx.ExecContext(ctx, query, append(ids, ids...)...)
+	if err != nil {
+		logQueryError(ctx, "ReconcilePingCounters - Update failed for checks after ID %d: %v", afterID, err)
+		return 0, 0, fmt.Errorf("database error reconciling ping counters: %w", err)
+	}
+	if corrected, err = result.RowsAffected(); err != nil {
+		return 0, 0, fmt.Errorf("failed to count reconciled checks: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("database error committing ping counters: %w", err)
+	}
+	return lastID, corrected, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"bitterlink/core/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// A fail ping bumps the counters and stamps last_failure_at in the same
+// transaction as the ping row.
+func TestRecordPingIncrementsCounters(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	expectPingCheck(mock, models.StatusUp, true)
+	mock.ExpectExec(regexp.QuoteMeta("SET last_ping_at = UTC_TIMESTAMP(), last_start_at = ?, status = ?")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO pings").WillReturnResult(sqlmock.NewResult(99, 1))
+	mock.ExpectExec(regexp.QuoteMeta("SET total_pings = total_pings + 1, pings_this_week = pings_this_week + 1,")).
+		WithArgs(models.PingKindFail, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO check_events").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7", Kind: models.PingKindFail}); err != nil {
+		t.Fatalf("RecordPing = %v, want nil", err)
+	}
+}
+
+// The fast path bumps the counters in its one UPDATE.
+func TestRecordPingFastPathIncrementsCounters(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	repo.config.PingFastPath = true
+	mock.ExpectExec(regexp.QuoteMeta("total_pings = total_pings + 1, pings_this_week = pings_this_week + 1")).
+		WithArgs("uuid-7").
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("INSERT INTO pings").WillReturnResult(sqlmock.NewResult(99, 1))
+
+	if err := repo.RecordPing(context.Background(), PingRecord{UUID: "uuid-7"}); err != nil {
+		t.Fatalf("RecordPing = %v, want nil", err)
+	}
+}
+
+func TestReconcilePingCounters(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM checks WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ? FOR UPDATE")).
+		WithArgs(int64(10), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12).AddRow(15))
+	// The IDs go to the pings aggregate and to the outer WHERE. Two of the
+	// three had drifted, e.g. after pruning, and are the rows changed.
+	mock.ExpectExec(regexp.QuoteMeta("SET c.total_pings = COALESCE(agg.total, 0)")).
+		WithArgs(int64(11), int64(12), int64(15), int64(11), int64(12), int64(15)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	lastID, corrected, err := repo.ReconcilePingCounters(context.Background(), 10, 3)
+	if err != nil {
+		t.Fatalf("ReconcilePingCounters: %v", err)
+	}
+	if lastID != 15 || corrected != 2 {
+		t.Errorf("lastID, corrected = %d, %d; want 15, 2", lastID, corrected)
+	}
+}
+
+func TestReconcilePingCountersDone(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM checks").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	lastID, corrected, err := repo.ReconcilePingCounters(context.Background(), 15, 3)
+	if err != nil || lastID != 0 || corrected != 0 {
+		t.Errorf("ReconcilePingCounters = %d, %d, %v; want 0, 0, nil past the last check", lastID, corrected, err)
+	}
+}
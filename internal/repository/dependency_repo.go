@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"bitterlink/core/internal/models"
+)
+
+// Check dependencies, see migrations/0020_check_dependencies.sql. A check only
+// depends on checks of the same account, and the graph never has a cycle: a
+// cycle would let two down checks suppress each other's notifications forever.
+
+// ErrDependencyCycle is returned when a new dependency would close a cycle,
+// including a check depending on itself. Nothing was changed.
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// ListDependencies returns the live checks checkID depends on, by name.
+func (r *mysqlCheckRepository) ListDependencies(ctx context.Context, checkID int64) ([]models.Check, error) {
+	query := `
+        SELECT ` + checkColumns + `
+        FROM checks
+        WHERE id IN (SELECT depends_on_check_id FROM check_dependencies WHERE check_id = ?)
+            AND deleted_at IS NULL
+        ORDER BY name ASC, id ASC`
+	rows, err := r.db.QueryContext(ctx, query, checkID)
+	if err != nil {
+		logQueryError(ctx, "ListDependencies - Query failed for check %d: %v", checkID, err)
+		return nil, fmt.Errorf("error querying dependencies: %w", err)
+	}
+	defer rows.Close()
+	return scanCheckRows(rows)
+}
+
+// AddDependency makes checkID depend on dependsOnID. Both must be live checks
+// of userID, or ErrCheckNotFound is returned; ErrDependencyCycle if dependsOnID
+// already depends on checkID, directly or not. Adding an existing dependency is
+// a no-op.
+func (r *mysqlCheckRepository) AddDependency(ctx context.Context, userID, checkID, dependsOnID int64) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+	if checkID == dependsOnID {
+		return ErrDependencyCycle
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Serialize dependency changes per account on the user row: two
+	// concurrent additions could each be cycle-free and close a cycle together
+	var lockedID int64
+	if err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = ? FOR UPDATE`, userID).Scan(&lockedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCheckNotFound
+		}
+		logQueryError(ctx, "AddDependency - Failed to lock user %d: %v", userID, err)
+		return fmt.Errorf("database error locking account: %w", err)
+	}
+
+	// 2. Both ends must be the account's live checks
+	var owned int
+	ownedQuery := `SELECT COUNT(*) FROM checks WHERE id IN (?, ?) AND user_id = ? AND deleted_at IS NULL`
+	if err = tx.QueryRowContext(ctx, ownedQuery, checkID, dependsOnID, userID).Scan(&owned); err != nil {
+		logQueryError(ctx, "AddDependency - Failed to verify checks of user %d: %v", userID, err)
+		return fmt.Errorf("database error verifying checks: %w", err)
+	}
+	if owned != 2 {
+		return ErrCheckNotFound
+	}
+
+	// 3. Walk the account's graph from dependsOnID; reaching checkID means the
+	// new edge would close a cycle
+	edges, err := listDependencyEdgesTx(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	if dependencyReachable(edges, dependsOnID, checkID) {
+		return ErrDependencyCycle
+	}
+
+	// 4. Insert, ignoring a dependency that already exists
+	insertQuery := `INSERT IGNORE INTO check_dependencies (check_id, depends_on_check_id, created_at) VALUES (?, ?, UTC_TIMESTAMP())`
+	if _, err = tx.ExecContext(ctx, insertQuery, checkID, dependsOnID); err != nil {
+		logQueryError(ctx, "AddDependency - Insert failed for check %d on %d: %v", checkID, dependsOnID, err)
+		return fmt.Errorf("database error adding dependency: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("database error committing dependency: %w", err)
+	}
+	return nil
+}
+
+// RemoveDependency removes checkID's dependency on dependsOnID and reports
+// whether there was one. A notification it is suppressing right now is
+// released by the worker on its next tick.
+func (r *mysqlCheckRepository) RemoveDependency(ctx context.Context, checkID, dependsOnID int64) (bool, error) {
+	query := `DELETE FROM check_dependencies WHERE check_id = ? AND depends_on_check_id = ?`
+	result, err := r.db.ExecContext(ctx, query, checkID, dependsOnID)
+	if err != nil {
+		logQueryError(ctx, "RemoveDependency - Delete failed for check %d on %d: %v", checkID, dependsOnID, err)
+		return false, fmt.Errorf("database error removing dependency: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to count removed dependencies: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// listDependencyEdgesTx returns every dependency among userID's checks, as
+// check ID to the IDs it depends on. Soft-deleted checks keep their edges
+// (they may yet be restored), so they count for cycles too.
+func listDependencyEdgesTx(ctx context.Context, tx *sql.Tx, userID int64) (map[int64][]int64, error) {
+	query := `
+        SELECT d.check_id, d.depends_on_check_id
+        FROM check_dependencies d
+        JOIN checks c ON c.id = d.check_id
+        WHERE c.user_id = ?`
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		logQueryError(ctx, "AddDependency - Failed to load dependencies of user %d: %v", userID, err)
+		return nil, fmt.Errorf("database error loading dependencies: %w", err)
+	}
+	defer rows.Close()
+	edges := make(map[int64][]int64)
+	for rows.Next() {
+		var from, to int64
+		if err := rows.Scan(&from, &to); err != nil {
+			return nil, fmt.Errorf("error scanning dependency: %w", err)
+		}
+		edges[from] = append(edges[from], to)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependencies: %w", err)
+	}
+	return edges, nil
+}
+
+// dependencyReachable reports whether target is reachable from start.
+func dependencyReachable(edges map[int64][]int64, start, target int64) bool {
+	seen := map[int64]bool{start: true}
+	stack := []int64{start}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == target {
+			return true
+		}
+		for _, next := range edges[id] {
+			if !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"bitterlink/core/internal/models"
+)
+
+// Execer is satisfied by both *sql.DB and *sql.Tx, so events can be written
+// inside whatever transaction made the state change.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// InsertEvent records an event for a check. Pass the transaction that made
+// the corresponding state change so the two commit (or roll back) together.
+func InsertEvent(ctx context.Context, ex Execer, event models.CheckEvent) error {
+	query := `
+        INSERT INTO check_events (check_id, event_type, from_status, to_status, source, ping_id, cycle_id, related_check_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+	_, err := ex.ExecContext(ctx, query,
+		event.CheckID, event.Type, event.FromStatus, event.ToStatus, event.Source, event.PingID, event.CycleID, event.RelatedCheckID)
+	if err != nil {
+		return fmt.Errorf("database error recording %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// StatusChangedEvent builds a status_changed event for a check.
+func StatusChangedEvent(checkID int64, fromStatus, toStatus, source string) models.CheckEvent {
+	return models.CheckEvent{
+		CheckID:    checkID,
+		Type:       models.EventStatusChanged,
+		FromStatus: sql.NullString{String: fromStatus, Valid: fromStatus != ""},
+		ToStatus:   sql.NullString{String: toStatus, Valid: toStatus != ""},
+		Source:     source,
+	}
+}
+
+// eventColumns is the column list matching scanEvent's field order.
+const eventColumns = `id, check_id, event_type, from_status, to_status, source, ping_id, cycle_id, related_check_id, created_at`
+
+// scanEvent scans a row selected with eventColumns into a CheckEvent.
+func scanEvent(row rowScanner, event *models.CheckEvent) error {
+	err := row.Scan(
+		&event.ID, &event.CheckID, &event.Type, &event.FromStatus, &event.ToStatus,
+		&event.Source, &event.PingID, &event.CycleID, &event.RelatedCheckID, &event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error scanning event data: %w", err)
+	}
+	return nil
+}
+
+// ListEventsByCheckID returns the most recent events for a check, newest first.
+func (r *mysqlCheckRepository) ListEventsByCheckID(ctx context.Context, checkID int64, limit int) ([]models.CheckEvent, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM check_events
+		WHERE check_id = ?
+		ORDER BY id DESC
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, checkID, limit)
+	if err != nil {
+		log.Printf("ERROR: ListEventsByCheckID - Query failed for check %d: %v", checkID, err)
+		return nil, fmt.Errorf("error querying events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.CheckEvent{}
+	for rows.Next() {
+		var event models.CheckEvent
+		if err := scanEvent(rows, &event); err != nil {
+			log.Printf("ERROR: ListEventsByCheckID - Check %d: %v", checkID, err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: ListEventsByCheckID - Row iteration failed for check %d: %v", checkID, err)
+		return nil, fmt.Errorf("error iterating event results: %w", err)
+	}
+	return events, nil
+}
+
+// ListEventsAfter returns up to limit of the events of userID's checks,
+// deleted ones included, whose feed sequence number is above afterSeq, in
+// sequence order: the event feed. Sequence numbers are assigned after commit,
+// in order (SequenceEvents), so none can still show up below the last one a
+// consumer saw; events not yet sequenced are left for a later page.
+func (r *mysqlCheckRepository) ListEventsAfter(ctx context.Context, userID, afterSeq int64, limit int) (_ []models.FeedEvent, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	// check_events has no user_id; the feed_seq index walks from afterSeq
+	query := `
+		SELECT feed_seq, ` + eventColumns + `
+		FROM check_events
+		WHERE feed_seq > ? AND check_id IN (SELECT id FROM checks WHERE user_id = ?)
+		ORDER BY feed_seq ASC
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, afterSeq, userID, limit)
+	if err != nil {
+		logQueryError(ctx, "ListEventsAfter - Query failed for user %d after sequence %d: %v", userID, afterSeq, err)
+		return nil, fmt.Errorf("error querying events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.FeedEvent{}
+	for rows.Next() {
+		var event models.FeedEvent
+		if err := scanEvent(seqScanner{rows, &event.Seq}, &event.CheckEvent); err != nil {
+			log.Printf("ERROR: ListEventsAfter - User %d: %v", userID, err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		logQueryError(ctx, "ListEventsAfter - Row iteration failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error iterating event results: %w", err)
+	}
+	return events, nil
+}
+
+// seqScanner scans a leading feed_seq column into seq ahead of the columns
+// scanEvent reads.
+type seqScanner struct {
+	rowScanner
+	seq *int64
+}
+
+func (s seqScanner) Scan(dest ...any) error {
+	return s.rowScanner.Scan(append([]any{s.seq}, dest...)...)
+}
+
+// SequenceEvents gives up to limit committed events without a feed sequence
+// number the next ones, in ID order, and returns how many it sequenced. The
+// lock on event_feed_sequence serializes callers, and each sees every commit
+// made before it took the lock, so a number commits only after all lower ones.
+func (r *mysqlCheckRepository) SequenceEvents(ctx context.Context, limit int) (sequenced int, err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Take the sequence
+	var lastSeq int64
+	if err = tx.QueryRowContext(ctx, `SELECT last_seq FROM event_feed_sequence WHERE id = 1 FOR UPDATE`).Scan(&lastSeq); err != nil {
+		logQueryError(ctx, "SequenceEvents - Failed to lock the event feed sequence: %v", err)
+		return 0, fmt.Errorf("database error locking event feed sequence: %w", err)
+	}
+
+	// 2. Collect the committed events still waiting; a locking read sees the
+	// latest commits rather than a snapshot
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM check_events WHERE feed_seq IS NULL ORDER BY id ASC LIMIT ? FOR UPDATE`, limit)
+	if err != nil {
+		logQueryError(ctx, "SequenceEvents - Query failed: %v", err)
+		return 0, fmt.Errorf("error querying unsequenced events: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning event ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("error iterating unsequenced events: %w", err)
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// 3. Number them and move the sequence on
+	for _, id := range ids {
+		lastSeq++
+		if _, err = tx.ExecContext(ctx, `UPDATE check_events SET feed_seq = ? WHERE id = ?`, lastSeq, id); err != nil {
+			logQueryError(ctx, "SequenceEvents - Failed to sequence event %d: %v", id, err)
+			return 0, fmt.Errorf("database error sequencing event: %w", err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE event_feed_sequence SET last_seq = ? WHERE id = 1`, lastSeq); err != nil {
+		logQueryError(ctx, "SequenceEvents - Failed to save the event feed sequence: %v", err)
+		return 0, fmt.Errorf("database error saving event feed sequence: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit event sequence: %w", err)
+	}
+	return len(ids), nil
+}
+
+// ImportEvent inserts an event with its original created_at (data import only).
+func (r *mysqlCheckRepository) ImportEvent(ctx context.Context, event *models.CheckEvent) error {
+	query := `
+        INSERT INTO check_events (check_id, event_type, from_status, to_status, source, ping_id, cycle_id, related_check_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.db.ExecContext(ctx, query,
+		event.CheckID, event.Type, event.FromStatus, event.ToStatus, event.Source, event.PingID, event.CycleID, event.RelatedCheckID, event.CreatedAt)
+	if err != nil {
+		log.Printf("ERROR: ImportEvent - Failed to insert event for check ID %d: %v", event.CheckID, err)
+		return fmt.Errorf("database error importing event: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve new event ID after insert: %w", err)
+	}
+	event.ID = id
+	return nil
+}
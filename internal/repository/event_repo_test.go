@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSequenceEventsNumbersInIDOrder(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT last_seq FROM event_feed_sequence WHERE id = 1 FOR UPDATE")).
+		WillReturnRows(sqlmock.NewRows([]string{"last_seq"}).AddRow(40))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM check_events WHERE feed_seq IS NULL ORDER BY id ASC LIMIT ? FOR UPDATE")).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(52).AddRow(55))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE check_events SET feed_seq = ? WHERE id = ?")).
+		WithArgs(int64(41), int64(52)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE check_events SET feed_seq = ? WHERE id = ?")).
+		WithArgs(int64(42), int64(55)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE event_feed_sequence SET last_seq = ? WHERE id = 1")).
+		WithArgs(int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	sequenced, err := repo.SequenceEvents(context.Background(), 10)
+	if err != nil || sequenced != 2 {
+		t.Fatalf("SequenceEvents = %d, %v; want 2, nil", sequenced, err)
+	}
+}
+
+func TestSequenceEventsNothingWaiting(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT last_seq FROM event_feed_sequence").
+		WillReturnRows(sqlmock.NewRows([]string{"last_seq"}).AddRow(40))
+	mock.ExpectQuery("SELECT id FROM check_events WHERE feed_seq IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	sequenced, err := repo.SequenceEvents(context.Background(), 10)
+	if err != nil || sequenced != 0 {
+		t.Fatalf("SequenceEvents = %d, %v; want 0, nil", sequenced, err)
+	}
+}
+
+func TestListEventsAfterReadsSequence(t *testing.T) {
+	repo, mock := newMockCheckRepo(t)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE feed_seq > ? AND check_id IN (SELECT id FROM checks WHERE user_id = ?)")).
+		WithArgs(int64(41), int64(7), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"feed_seq", "id", "check_id", "event_type", "from_status", "to_status", "source", "ping_id", "cycle_id", "related_check_id", "created_at"}).
+			AddRow(42, 55, 3, "check_updated", nil, nil, "api", nil, nil, nil, created))
+
+	events, err := repo.ListEventsAfter(context.Background(), 7, 41, 100)
+	if err != nil {
+		t.Fatalf("ListEventsAfter: %v", err)
+	}
+	if len(events) != 1 || events[0].Seq != 42 || events[0].ID != 55 || events[0].CheckID != 3 || !events[0].CreatedAt.Equal(created) {
+		t.Errorf("events = %+v, want seq 42 of event 55 for check 3", events)
+	}
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// maxForwardErrorLen is the size of checks.forward_last_error.
+const maxForwardErrorLen = 255
+
+// RecordForwardResult records the outcome of forwarding a ping for checkID. A
+// failure bumps forward_failures and stores why; a success resets the counter.
+// updated_at is left alone: these columns aren't user edits.
+func (r *mysqlCheckRepository) RecordForwardResult(ctx context.Context, checkID int64, forwardErr error) error {
+	if forwardErr == nil {
+		// Most forwards succeed against a healthy downstream, so only write when
+		// there is a failure streak to reset
+		query := `UPDATE checks SET forward_failures = 0, forward_last_error = NULL WHERE id = ? AND forward_failures > 0`
+		if _, err := r.db.ExecContext(ctx, query, checkID); err != nil {
+			return fmt.Errorf("error resetting forward failures: %w", err)
+		}
+		return nil
+	}
+
+	message := forwardErr.Error()
+	if len(message) > maxForwardErrorLen {
+		message = message[:maxForwardErrorLen]
+	}
+	query := `
+		UPDATE checks
+		SET forward_failures = forward_failures + 1, forward_last_error = ?, forward_failed_at = UTC_TIMESTAMP()
+		WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, message, checkID); err != nil {
+		log.Printf("ERROR: RecordForwardResult - Update failed for check ID %d: %v", checkID, err)
+		return fmt.Errorf("error recording forward failure: %w", err)
+	}
+	return nil
+}
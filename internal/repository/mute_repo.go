@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"bitterlink/core/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Muting, see migrations/0023_action_links.sql: a muted check changes status as
+// usual, but the worker records its late and down notifications as
+// notification_muted events instead of sending them.
+
+// ErrActionTokenUsed is returned by MuteCheck when the single-use link it is
+// applied from was used before. Nothing was changed.
+var ErrActionTokenUsed = errors.New("action link already used")
+
+// MuteRequest is a mute applied from an action link.
+type MuteRequest struct {
+	CheckUUID string
+	Until     time.Time
+	// Nonce and NonceExpires identify the link, when it is single use; an empty
+	// Nonce skips the check
+	Nonce        string
+	NonceExpires time.Time
+	Source       string // models.EventSource*
+}
+
+// MuteCheck mutes the live check with req.CheckUUID until req.Until, replacing
+// any earlier mute, and records a muted event. It returns ErrCheckNotFound for
+// an unknown or deleted check, and ErrActionTokenUsed when req.Nonce was used
+// before; using it is committed with the mute. Used links that have expired
+// are forgotten on the way, a few at a time: mutes are rare, so the table
+// stays small without a separate cleanup job.
+func (r *mysqlCheckRepository) MuteCheck(ctx context.Context, req MuteRequest) (err error) {
+	defer func() { err = canceledErr(ctx, err) }()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM used_action_tokens WHERE expires_at < UTC_TIMESTAMP() LIMIT 100`); err != nil {
+		logQueryError(ctx, "MuteCheck - Failed to delete expired action links: %v", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the check
+	var checkID int64
+	lockQuery := `SELECT id FROM checks WHERE uuid = ? AND deleted_at IS NULL FOR UPDATE`
+	if err = tx.QueryRowContext(ctx, lockQuery, req.CheckUUID).Scan(&checkID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCheckNotFound
+		}
+		logQueryError(ctx, "MuteCheck - Failed to lock check %s: %v", req.CheckUUID, err)
+		return fmt.Errorf("database error locking check: %w", err)
+	}
+
+	// 2. Use up the link; the primary key refuses a second use, even a
+	// concurrent one
+	if req.Nonce != "" {
+		useQuery := `INSERT INTO used_action_tokens (nonce, check_id, expires_at) VALUES (?, ?, ?)`
+		if _, err = tx.ExecContext(ctx, useQuery, req.Nonce, checkID, req.NonceExpires.UTC()); err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return ErrActionTokenUsed
+			}
+			logQueryError(ctx, "MuteCheck - Failed to record action link use for check %d: %v", checkID, err)
+			return fmt.Errorf("database error recording link use: %w", err)
+		}
+	}
+
+	// 3. Mute and record it
+	if _, err = tx.ExecContext(ctx, `UPDATE checks SET muted_until = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?`, req.Until.UTC(), checkID); err != nil {
+		logQueryError(ctx, "MuteCheck - Update failed for check %d: %v", checkID, err)
+		return fmt.Errorf("database error muting check: %w", err)
+	}
+	event := models.CheckEvent{CheckID: checkID, Type: models.EventCheckMuted, Source: req.Source}
+	if err = InsertEvent(ctx, tx, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+// ErrNotFound is the entity-agnostic "no such row" error. Each entity's own
+// sentinel wraps it (ErrCheckNotFound, ErrUserNotFound), so callers that only
+// care whether something exists can test errors.Is(err, ErrNotFound), while
+// errors.Is(err, ErrCheckNotFound) still tells a missing check apart.
+var ErrNotFound = errors.New("not found")
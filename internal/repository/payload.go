@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// encodePayload prepares a ping payload for storage in pings.payload.
+// When compression is enabled and the payload is larger than the configured
+// threshold it is gzipped; the returned flag is stored in pings.payload_compressed
+// so reads know whether to decompress.
+func (r *mysqlCheckRepository) encodePayload(payload []byte) ([]byte, bool, error) {
+	if len(payload) == 0 {
+		return nil, false, nil
+	}
+	if !r.config.CompressPayloads || len(payload) <= r.config.CompressThresholdBytes {
+		return payload, false, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, false, fmt.Errorf("failed to compress ping payload: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to finish compressing ping payload: %w", err)
+	}
+
+	// Compression isn't guaranteed to help (e.g. already-compressed output),
+	// so only keep the gzipped form when it's actually smaller.
+	if buf.Len() >= len(payload) {
+		return payload, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decodePayload reverses encodePayload, returning the original payload bytes.
+func decodePayload(stored []byte, compressed bool) ([]byte, error) {
+	if !compressed || len(stored) == 0 {
+		return stored, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(stored))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compressed ping payload: %w", err)
+	}
+	defer zr.Close()
+
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress ping payload: %w", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	compressible := []byte(strings.Repeat("backup finished: ok\n", 200))
+	// Short and varied enough that gzip's overhead outweighs the gain
+	incompressible := []byte("x9Q#2kLp")
+
+	tests := []struct {
+		name           string
+		config         Config
+		payload        []byte
+		wantCompressed bool
+	}{
+		{name: "empty", config: Config{CompressPayloads: true}, payload: nil},
+		{name: "compression off", config: Config{}, payload: compressible},
+		{name: "above threshold", config: Config{CompressPayloads: true, CompressThresholdBytes: 100}, payload: compressible, wantCompressed: true},
+		{name: "at threshold", config: Config{CompressPayloads: true, CompressThresholdBytes: len(compressible)}, payload: compressible},
+		{name: "one byte over threshold", config: Config{CompressPayloads: true, CompressThresholdBytes: len(compressible) - 1}, payload: compressible, wantCompressed: true},
+		{name: "gzip not smaller", config: Config{CompressPayloads: true}, payload: incompressible},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mysqlCheckRepository{config: tt.config}
+			stored, compressed, err := repo.encodePayload(tt.payload)
+			if err != nil {
+				t.Fatalf("encodePayload: %v", err)
+			}
+			if compressed != tt.wantCompressed {
+				t.Fatalf("compressed = %v, want %v", compressed, tt.wantCompressed)
+			}
+			if compressed && len(stored) >= len(tt.payload) {
+				t.Errorf("stored %d bytes for a %d byte payload", len(stored), len(tt.payload))
+			}
+
+			decoded, err := decodePayload(stored, compressed)
+			if err != nil {
+				t.Fatalf("decodePayload: %v", err)
+			}
+			if !bytes.Equal(decoded, tt.payload) {
+				t.Errorf("round trip = %q, want %q", decoded, tt.payload)
+			}
+		})
+	}
+}
+
+func TestDecodePayloadCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(strings.Repeat("payload ", 100)))
+	zw.Close()
+	valid := buf.Bytes()
+
+	tests := []struct {
+		name   string
+		stored []byte
+	}{
+		{name: "not gzip", stored: []byte("plain text, flagged compressed")},
+		{name: "truncated", stored: valid[:len(valid)/2]},
+		{name: "bad checksum", stored: append(append([]byte{}, valid[:len(valid)-8]...), 0, 0, 0, 0, 0, 0, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if decoded, err := decodePayload(tt.stored, true); err == nil {
+				t.Errorf("decodePayload = %q, want an error", decoded)
+			}
+		})
+	}
+}
+
+func TestDecodePayloadUncompressedPassesThrough(t *testing.T) {
+	stored := []byte("\x1f\x8b looks like gzip but isn't flagged")
+	decoded, err := decodePayload(stored, false)
+	if err != nil || !bytes.Equal(decoded, stored) {
+		t.Errorf("decodePayload = %q, %v; want the stored bytes", decoded, err)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// Concurrent submissions of one client ping ID record a single ping; every
+// other one is answered as a duplicate.
+func TestRecordPingConcurrentDuplicates(t *testing.T) {
+	pool := openIntegrationDB(t)
+	repo := NewMySQLCheckRepository(pool, Config{})
+	checkID, checkUUID := seedCheck(t, pool)
+
+	const submissions = 8
+	errs := make([]error, submissions)
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := range submissions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = repo.RecordPing(context.Background(), PingRecord{UUID: checkUUID, ClientPingID: "retry-1"})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	recorded, duplicates := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			recorded++
+		case errors.Is(err, ErrDuplicatePing):
+			duplicates++
+		default:
+			t.Errorf("RecordPing: %v", err)
+		}
+	}
+	if recorded != 1 || duplicates != submissions-1 {
+		t.Errorf("recorded %d, duplicates %d; want 1, %d", recorded, duplicates, submissions-1)
+	}
+	var rows, total int64
+	if err := pool.QueryRow(`SELECT COUNT(*) FROM pings WHERE check_id = ?`, checkID).Scan(&rows); err != nil {
+		t.Fatalf("counting pings: %v", err)
+	}
+	if err := pool.QueryRow(`SELECT total_pings FROM checks WHERE id = ?`, checkID).Scan(&total); err != nil {
+		t.Fatalf("reading total_pings: %v", err)
+	}
+	if rows != 1 || total != 1 {
+		t.Errorf("pings rows %d, total_pings %d; want 1, 1", rows, total)
+	}
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/models"
+)
+
+// pingColumns is the column list matching scanPing's field order.
+const pingColumns = `id, check_id, kind, received_at, source_ip, user_agent, duration_ms, run_id, payload, payload_compressed, created_at`
+
+// scanPing scans a row selected with pingColumns into a Ping, decompressing the payload.
+func scanPing(row rowScanner, ping *models.Ping) error {
+	var storedPayload []byte // NULL scans to a nil slice
+	var compressed bool
+	err := row.Scan(
+		&ping.ID,
+		&ping.CheckID,
+		&ping.Kind,
+		&ping.ReceivedAt,
+		&ping.SourceIP,
+		&ping.UserAgent,
+		&ping.DurationMs,
+		&ping.RunID,
+		&storedPayload,
+		&compressed,
+		&ping.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error scanning ping data: %w", err)
+	}
+
+	if storedPayload != nil {
+		payload, err := decodePayload(storedPayload, compressed)
+		if err != nil {
+			return fmt.Errorf("failed to decode payload of ping %d: %w", ping.ID, err)
+		}
+		ping.Payload = sql.NullString{String: string(payload), Valid: true}
+	}
+	return nil
+}
+
+// ListPingsByCheckID returns up to limit of a check's most recent pings, newest
+// first, skipping the offset newest ones (for paging back through the history).
+// Compressed payloads are transparently decompressed.
+func (r *mysqlCheckRepository) ListPingsByCheckID(ctx context.Context, checkID int64, limit, offset int) ([]models.Ping, error) {
+	query := `SELECT ` + pingColumns + `
+		FROM pings
+		WHERE check_id = ?
+		ORDER BY received_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, checkID, limit, offset)
+	if err != nil {
+		log.Printf("ERROR: ListPingsByCheckID - Query failed for check %d: %v", checkID, err)
+		return nil, fmt.Errorf("error querying pings: %w", err)
+	}
+	defer rows.Close()
+
+	pings := []models.Ping{}
+	for rows.Next() {
+		var ping models.Ping
+		if err := scanPing(rows, &ping); err != nil {
+			log.Printf("ERROR: ListPingsByCheckID - Check %d: %v", checkID, err)
+			return nil, err
+		}
+		pings = append(pings, ping)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("ERROR: ListPingsByCheckID - Row iteration failed for check %d: %v", checkID, err)
+		return nil, fmt.Errorf("error iterating ping results: %w", err)
+	}
+	return pings, nil
+}
+
+// EachPingSince streams a check's pings received at or after since, oldest first,
+// calling fn for each without loading them all into memory. Iteration stops at
+// the first error returned by fn.
+func (r *mysqlCheckRepository) EachPingSince(ctx context.Context, checkID int64, since time.Time, fn func(models.Ping) error) error {
+	query := `SELECT ` + pingColumns + `
+		FROM pings
+		WHERE check_id = ? AND received_at >= ?
+		ORDER BY received_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, checkID, since)
+	if err != nil {
+		log.Printf("ERROR: EachPingSince - Query failed for check %d: %v", checkID, err)
+		return fmt.Errorf("error querying pings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ping models.Ping
+		if err := scanPing(rows, &ping); err != nil {
+			return err
+		}
+		if err := fn(ping); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating ping results: %w", err)
+	}
+	return nil
+}
+
+// ImportPing inserts a ping with its original timestamps (used by seeding and
+// data import, not the live ping path), advances the check's last_ping_at if
+// the ping is newer and counts it in the ping counters. Status is left untouched. The generated ID is set on ping.
+func (r *mysqlCheckRepository) ImportPing(ctx context.Context, ping *models.Ping) error {
+	var payload []byte
+	if ping.Payload.Valid {
+		payload = []byte(ping.Payload.String)
+	}
+	storedPayload, compressed, err := r.encodePayload(payload)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	kind := ping.Kind
+	if kind == "" {
+		kind = models.PingKindSuccess
+	}
+
+	insertQuery := `
+        INSERT INTO pings (check_id, kind, received_at, source_ip, user_agent, duration_ms, run_id, payload, payload_compressed, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.ExecContext(ctx, insertQuery,
+		ping.CheckID, kind, ping.ReceivedAt, ping.SourceIP, ping.UserAgent, ping.DurationMs, ping.RunID, storedPayload, compressed, ping.CreatedAt)
+	if err != nil {
+		log.Printf("ERROR: ImportPing - Failed to insert ping for check ID %d: %v", ping.CheckID, err)
+		return fmt.Errorf("database error importing ping: %w", err)
+	}
+
+	// The ping counters count it too; an old one doesn't count for this week
+	updateQuery := `
+        UPDATE checks
+        SET last_ping_at = IF(last_ping_at IS NULL OR last_ping_at < ?, ?, last_ping_at),
+            total_pings = total_pings + 1,
+            pings_this_week = pings_this_week + (? >= UTC_TIMESTAMP() - INTERVAL 7 DAY),
+            last_failure_at = IF(? = 'fail' AND (last_failure_at IS NULL OR last_failure_at < ?), ?, last_failure_at)
+        WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, updateQuery, ping.ReceivedAt, ping.ReceivedAt, ping.ReceivedAt,
+		kind, ping.ReceivedAt, ping.ReceivedAt, ping.CheckID); err != nil {
+		log.Printf("ERROR: ImportPing - Failed to update last_ping_at for check ID %d: %v", ping.CheckID, err)
+		return fmt.Errorf("database error updating check: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("database error committing imported ping: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve new ping ID after insert: %w", err)
+	}
+	ping.ID = id
+	return nil
+}
+
+// FindByIDWithLastPing returns a check and its most recent ping in a single
+// query. The ping is nil when the check has never been pinged. Payloads are not
+// loaded. Returns ErrCheckNotFound for missing or soft-deleted checks.
+func (r *mysqlCheckRepository) FindByIDWithLastPing(ctx context.Context, id int64) (*models.Check, *models.Ping, error) {
+	query := `
+		SELECT
+			c.id, c.user_id, c.uuid, c.name, c.description, c.expected_interval,
+			c.grace_period, c.last_ping_at, c.status, c.is_enabled, c.notify_late, c.severity, c.recovery_stabilization, c.color, c.icon, c.metadata,
+			c.forward_url, c.forward_failures, c.forward_last_error, c.forward_failed_at, c.pings_history_limit, c.require_signed_pings,
+			c.ping_response_code, c.ping_response_body, c.max_duration, c.warmup_pings, c.warmup_successes, c.muted_until,
+			c.total_pings, c.pings_this_week, c.last_failure_at, c.created_at, c.updated_at,
+			p.id, p.kind, p.received_at, p.source_ip, p.user_agent, p.duration_ms, p.run_id, p.created_at
+		FROM checks c
+		LEFT JOIN pings p ON p.id = (
+			SELECT latest.id FROM pings latest
+			WHERE latest.check_id = c.id
+			ORDER BY latest.received_at DESC, latest.id DESC
+			LIMIT 1
+		)
+		WHERE c.id = ? AND c.deleted_at IS NULL`
+
+	var check models.Check
+	var pingID sql.NullInt64
+	var pingKind sql.NullString
+	var pingReceivedAt, pingCreatedAt sql.NullTime
+	var ping models.Ping
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&check.ID, &check.UserID, &check.UUID, &check.Name, &check.Description, &check.ExpectedInterval,
+		&check.GracePeriod, &check.LastPingAt, &check.Status, &check.IsEnabled, &check.NotifyLate, &check.Severity, &check.RecoveryStabilization, &check.Color, &check.Icon, &check.Metadata,
+		&check.ForwardURL, &check.ForwardFailures, &check.ForwardLastError, &check.ForwardFailedAt, &check.PingsHistoryLimit, &check.RequireSignedPings,
+		&check.PingResponseCode, &check.PingResponseBody, &check.MaxDuration, &check.WarmupPings, &check.WarmupSuccesses, &check.MutedUntil,
+		&check.TotalPings, &check.PingsThisWeek, &check.LastFailureAt, &check.CreatedAt, &check.UpdatedAt,
+		&pingID, &pingKind, &pingReceivedAt, &ping.SourceIP, &ping.UserAgent, &ping.DurationMs, &ping.RunID, &pingCreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrCheckNotFound
+		}
+		log.Printf("ERROR: FindByIDWithLastPing - Scan failed for check %d: %v", id, err)
+		return nil, nil, fmt.Errorf("error retrieving check data: %w", err)
+	}
+
+	if !pingID.Valid {
+		return &check, nil, nil
+	}
+	ping.ID = pingID.Int64
+	ping.CheckID = check.ID
+	ping.Kind = pingKind.String
+	ping.ReceivedAt = pingReceivedAt.Time
+	ping.CreatedAt = pingCreatedAt.Time
+	return &check, &ping, nil
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// CountPingsOlderThan counts pings received before cutoff.
+func (r *mysqlCheckRepository) CountPingsOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM pings WHERE received_at < ?`
+	if err := r.db.QueryRowContext(ctx, query, cutoff).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting old pings: %w", err)
+	}
+	return count, nil
+}
+
+// DeletePingsOlderThan deletes at most limit pings received before cutoff and
+// returns how many were deleted. Callers loop until it returns fewer than limit.
+func (r *mysqlCheckRepository) DeletePingsOlderThan(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `DELETE FROM pings WHERE received_at < ? ORDER BY received_at ASC LIMIT ?`
+	result, err := r.db.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		log.Printf("ERROR: DeletePingsOlderThan - Delete failed: %v", err)
+		return 0, fmt.Errorf("error deleting old pings: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CountDeletedChecksOlderThan counts soft-deleted checks whose deleted_at is before cutoff.
+func (r *mysqlCheckRepository) CountDeletedChecksOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM checks WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	if err := r.db.QueryRowContext(ctx, query, cutoff).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting deleted checks: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeDeletedChecks permanently removes at most limit checks that were
+// soft-deleted before cutoff, together with everything that references them.
+// Each batch is one transaction. Returns how many checks were purged.
+func (r *mysqlCheckRepository) PurgeDeletedChecks(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// deleted_at IS NOT NULL is explicit: live checks must never be selected here
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM checks
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		ORDER BY id ASC
+		LIMIT ?
+		FOR UPDATE`, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("error selecting deleted checks: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning deleted check ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating deleted checks: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := hardDeleteChecksTx(ctx, tx, ids); err != nil {
+		log.Printf("ERROR: PurgeDeletedChecks - Cascade delete failed: %v", err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("database error committing purge: %w", err)
+	}
+	return int64(len(ids)), nil
+}
+
+// ListPurgeableCheckIDs returns up to limit IDs of checks soft-deleted before
+// cutoff, in the order PurgeDeletedChecks takes them.
+func (r *mysqlCheckRepository) ListPurgeableCheckIDs(ctx context.Context, cutoff time.Time, limit int) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM checks
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		ORDER BY id ASC
+		LIMIT ?`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting deleted checks: %w", err)
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning deleted check ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted checks: %w", err)
+	}
+	return ids, nil
+}
+
+// DeletePingsOfPurgeableCheck deletes at most limit pings of checkID, provided
+// the check was soft-deleted before cutoff, and returns how many were deleted.
+// Emptying a long history this way first keeps PurgeDeletedChecks' cascade
+// transaction small. The condition is re-checked here, so a check restored in
+// the meantime keeps its pings.
+func (r *mysqlCheckRepository) DeletePingsOfPurgeableCheck(ctx context.Context, checkID int64, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM pings
+		WHERE check_id = ?
+			AND EXISTS (SELECT 1 FROM checks WHERE id = ? AND deleted_at IS NOT NULL AND deleted_at < ?)
+		ORDER BY id ASC
+		LIMIT ?`
+	result, err := r.db.ExecContext(ctx, query, checkID, checkID, cutoff, limit)
+	if err != nil {
+		log.Printf("ERROR: DeletePingsOfPurgeableCheck - Delete failed for check %d: %v", checkID, err)
+		return 0, fmt.Errorf("error deleting pings of deleted check: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// cascadeTables reference checks by check_id and are cleared before the check row.
+var cascadeTables = []string{"pings", "check_events", "check_notification_channel", "notifications_log", "check_dependencies", "used_action_tokens"}
+
+// hardDeleteChecksTx permanently deletes the given checks and all rows that
+// reference them. This is the single cascade path for permanent deletion.
+func hardDeleteChecksTx(ctx context.Context, tx *sql.Tx, ids []int64) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	for _, table := range cascadeTables {
+		query := `DELETE FROM ` + table + ` WHERE check_id IN (` + placeholders + `)`
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("error deleting %s of purged checks: %w", table, err)
+		}
+	}
+	// Dependencies point the other way too: checks that depend on a purged one
+	query := `DELETE FROM check_dependencies WHERE depends_on_check_id IN (` + placeholders + `)`
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("error deleting dependencies on purged checks: %w", err)
+	}
+	query = `UPDATE checks SET notification_suppressed_by = NULL WHERE notification_suppressed_by IN (` + placeholders + `)`
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("error clearing suppressions by purged checks: %w", err)
+	}
+	query = `DELETE FROM checks WHERE id IN (` + placeholders + `)`
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("error deleting purged checks: %w", err)
+	}
+	return nil
+}
+
+// HistoryLimit is a check's pings_history_limit.
+type HistoryLimit struct {
+	CheckID int64
+	Keep    int
+}
+
+// ListHistoryLimits returns every live check that has a pings_history_limit.
+func (r *mysqlCheckRepository) ListHistoryLimits(ctx context.Context) ([]HistoryLimit, error) {
+	query := `
+		SELECT id, pings_history_limit FROM checks
+		WHERE pings_history_limit IS NOT NULL AND deleted_at IS NULL
+		ORDER BY id ASC`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying history limits: %w", err)
+	}
+	defer rows.Close()
+
+	var limits []HistoryLimit
+	for rows.Next() {
+		var l HistoryLimit
+		if err := rows.Scan(&l.CheckID, &l.Keep); err != nil {
+			return nil, fmt.Errorf("error scanning history limit: %w", err)
+		}
+		limits = append(limits, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history limits: %w", err)
+	}
+	return limits, nil
+}
+
+// TrimPingHistory deletes at most limit of a check's pings beyond its newest
+// keep, oldest first, and returns how many were deleted. Callers loop until it
+// returns fewer than limit, so shrinking a long history never runs as one giant
+// DELETE.
+func (r *mysqlCheckRepository) TrimPingHistory(ctx context.Context, checkID int64, keep, limit int) (int64, error) {
+	// 1. Find the newest ping past the limit, in the order history is listed in.
+	// MySQL can't DELETE with a subquery on the same table, hence two statements.
+	var cutoffAt time.Time
+	var cutoffID int64
+	query := `
+		SELECT received_at, id FROM pings
+		WHERE check_id = ?
+		ORDER BY received_at DESC, id DESC
+		LIMIT 1 OFFSET ?`
+	err := r.db.QueryRowContext(ctx, query, checkID, keep).Scan(&cutoffAt, &cutoffID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil // Within the limit
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error finding history cutoff for check %d: %w", checkID, err)
+	}
+
+	// 2. Delete it and everything older, one batch at a time
+	deleteQuery := `
+		DELETE FROM pings
+		WHERE check_id = ? AND (received_at < ? OR (received_at = ? AND id <= ?))
+		ORDER BY received_at ASC, id ASC
+		LIMIT ?`
+	result, err := r.db.ExecContext(ctx, deleteQuery, checkID, cutoffAt, cutoffAt, cutoffID, limit)
+	if err != nil {
+		log.Printf("ERROR: TrimPingHistory - Delete failed for check %d: %v", checkID, err)
+		return 0, fmt.Errorf("error trimming ping history: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Orphaned pings point at a check_id no checks row has, not even a soft-deleted
+// one: PurgeDeletedChecks cascades to pings, so they are left by hard deletes
+// made outside of it, or by data issues. Nothing reads them, they only take
+// space.
+
+// OrphanedPings is a missing check that pings still point at.
+type OrphanedPings struct {
+	CheckID int64 `json:"check_id"`
+	Pings   int64 `json:"pings"`
+}
+
+// orphanedPingsCondition selects the pings p whose check is gone.
+const orphanedPingsCondition = `NOT EXISTS (SELECT 1 FROM checks c WHERE c.id = p.check_id)`
+
+// FindOrphanedPings returns up to limit missing checks that pings point at,
+// lowest ID first, with their ping counts. It reads through the whole pings
+// index on check_id, without locking.
+func (r *mysqlCheckRepository) FindOrphanedPings(ctx context.Context, limit int) ([]OrphanedPings, error) {
+	query := `
+		SELECT p.check_id, COUNT(*)
+		FROM pings p
+		WHERE ` + orphanedPingsCondition + `
+		GROUP BY p.check_id
+		ORDER BY p.check_id ASC
+		LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		log.Printf("ERROR: FindOrphanedPings - Query failed: %v", err)
+		return nil, fmt.Errorf("error finding orphaned pings: %w", err)
+	}
+	defer rows.Close()
+	orphans := []OrphanedPings{}
+	for rows.Next() {
+		var o OrphanedPings
+		if err := rows.Scan(&o.CheckID, &o.Pings); err != nil {
+			return nil, fmt.Errorf("error scanning orphaned pings: %w", err)
+		}
+		orphans = append(orphans, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned pings: %w", err)
+	}
+	return orphans, nil
+}
+
+// DeleteOrphanedPings deletes at most limit orphaned pings and returns how many
+// were deleted. Callers loop until it returns fewer than limit. The missing
+// checks are looked up first, and the DELETE then goes by check_id, so each
+// batch only locks the rows it removes.
+func (r *mysqlCheckRepository) DeleteOrphanedPings(ctx context.Context, limit int) (int64, error) {
+	var deleted int64
+	for deleted < int64(limit) {
+		checkIDs, err := r.orphanedCheckIDs(ctx, orphanedChecksPerBatch)
+		if err != nil || len(checkIDs) == 0 {
+			return deleted, err
+		}
+
+		// Delete their pings, re-checking that the checks are still missing
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(checkIDs)), ",")
+		query := `
+		DELETE FROM pings
+		WHERE check_id IN (` + placeholders + `)
+			AND NOT EXISTS (SELECT 1 FROM checks c WHERE c.id = pings.check_id)
+		LIMIT ?`
+		result, err := r.db.ExecContext(ctx, query, append(checkIDs, int64(limit)-deleted)...)
+		if err != nil {
+			log.Printf("ERROR: DeleteOrphanedPings - Delete failed: %v", err)
+			return deleted, fmt.Errorf("error deleting orphaned pings: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+		if len(checkIDs) < orphanedChecksPerBatch || n == 0 {
+			// That was every missing check; n == 0 only if they were restored meanwhile
+			return deleted, nil
+		}
+	}
+	return deleted, nil
+}
+
+// orphanedChecksPerBatch caps the check IDs one DeleteOrphanedPings DELETE
+// goes by.
+const orphanedChecksPerBatch = 100
+
+// orphanedCheckIDs returns up to limit missing checks that pings point at,
+// lowest first, as query arguments.
+func (r *mysqlCheckRepository) orphanedCheckIDs(ctx context.Context, limit int) ([]any, error) {
+	query := `
+		SELECT DISTINCT p.check_id
+		FROM pings p
+		WHERE ` + orphanedPingsCondition + `
+		ORDER BY p.check_id ASC
+		LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		log.Printf("ERROR: DeleteOrphanedPings - Query failed: %v", err)
+		return nil, fmt.Errorf("error finding orphaned pings: %w", err)
+	}
+	defer rows.Close()
+	var checkIDs []any
+	for rows.Next() {
+		var checkID int64
+		if err := rows.Scan(&checkID); err != nil {
+			return nil, fmt.Errorf("error scanning orphaned check ID: %w", err)
+		}
+		checkIDs = append(checkIDs, checkID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orphaned check IDs: %w", err)
+	}
+	return checkIDs, nil
+}
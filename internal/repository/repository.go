@@ -3,17 +3,118 @@ package repository
 import (
 	"bitterlink/core/internal/models"
 	"context"
-	"database/sql"
+	"time"
 )
 
 type CheckRepository interface {
 	FindByID(ctx context.Context, id int64) (*models.Check, error)
 	FindByUUID(ctx context.Context, uuid string) (*models.Check, error)
-	FindActiveByUserID(ctx context.Context, userID int64) ([]models.Check, error) // Like our previous example!
-	Create(ctx context.Context, check *models.Check) error                        // Might return the ID or the full check
-	Update(ctx context.Context, check *models.Check) error
-	Delete(ctx context.Context, id int64) error                                                           // Handles soft delete logic
-	RecordPing(ctx context.Context, uuid string, sourceIP sql.NullString, userAgent sql.NullString) error // Added sourceIP/userAgent
+	ExistsByUUID(ctx context.Context, uuid string) (checkID int64, active bool, err error)   // ID and enabled/paused state only
+	FindByIDWithLastPing(ctx context.Context, id int64) (*models.Check, *models.Ping, error) // Ping is nil if never pinged
+	FindActiveByUserID(ctx context.Context, userID int64) ([]models.Check, error)            // Enabled, not paused, see implementation
+	Create(ctx context.Context, check *models.Check) error                                   // Might return the ID or the full check
+	Update(ctx context.Context, id int64, update CheckUpdate) error                          // Only the provided fields
+	Delete(ctx context.Context, id, userID int64) error                                      // Soft delete, ErrCheckNotFound unless userID's live check
+	RecordPing(ctx context.Context, ping PingRecord) error
+	RecordPingsBatch(ctx context.Context, userID int64, pings []PingRecord) ([]error, error) // Per-ping results, see implementation
 	ListByUserID(ctx context.Context, userID int64) ([]models.Check, error)
+	CountByUserID(ctx context.Context, userID int64) (int, error)
+	ListByUserIDPaginated(ctx context.Context, userID, offset, limit int64) ([]models.Check, error)
+	EachByUserID(ctx context.Context, userID int64, fn func(models.Check) error) error // Streams ListByUserID
+	ListByMetadata(ctx context.Context, userID int64, key, value string) ([]models.Check, error)
+	ListPingsByCheckID(ctx context.Context, checkID int64, limit, offset int) ([]models.Ping, error) // Newest first
+	ListByStatus(ctx context.Context, status string, userID int64) ([]models.Check, error)           // userID 0 means all users
+	SetStatus(ctx context.Context, uuid string, status string, source string) error                  // Records a status event
+	ListEventsByCheckID(ctx context.Context, checkID int64, limit int) ([]models.CheckEvent, error)
+	ListEventsAfter(ctx context.Context, userID, afterSeq int64, limit int) ([]models.FeedEvent, error)
+	SequenceEvents(ctx context.Context, limit int) (int, error)
+	ImportPing(ctx context.Context, ping *models.Ping) error // Inserts a historical ping (seeding/import)
+	ImportEvent(ctx context.Context, event *models.CheckEvent) error
+	EachPingSince(ctx context.Context, checkID int64, since time.Time, fn func(models.Ping) error) error
+
+	// Retention / cleanup, see prune_repo.go
+	CountPingsOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	DeletePingsOlderThan(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	CountDeletedChecksOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+	PurgeDeletedChecks(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	ListPurgeableCheckIDs(ctx context.Context, cutoff time.Time, limit int) ([]int64, error)
+	DeletePingsOfPurgeableCheck(ctx context.Context, checkID int64, cutoff time.Time, limit int) (int64, error)
+	ListHistoryLimits(ctx context.Context) ([]HistoryLimit, error)
+	TrimPingHistory(ctx context.Context, checkID int64, keep, limit int) (int64, error)
+	FindOrphanedPings(ctx context.Context, limit int) ([]OrphanedPings, error) // Pings of checks that no longer exist at all
+	DeleteOrphanedPings(ctx context.Context, limit int) (int64, error)
+	ReconcilePingCounters(ctx context.Context, afterID int64, limit int) (int64, int64, error) // See counter_repo.go; last ID covered, checks corrected
+
+	// Batch reads across many checks, see batch_repo.go
+	ListChecksPage(ctx context.Context, userID int64, filter CheckFilter) ([]models.Check, error)
+	IterateAllChecks(ctx context.Context, batchSize int, fn func([]models.Check) error) error // Every user, keyset-paginated by ID
+	ListPingsByCheckIDs(ctx context.Context, checkIDs []int64, limitPerCheck int) (map[int64][]models.Ping, error)
+	ListEventsByCheckIDs(ctx context.Context, checkIDs []int64, limitPerCheck int) (map[int64][]models.CheckEvent, error)
+	ListEventsSince(ctx context.Context, checkIDs []int64, since time.Time) (map[int64][]models.CheckEvent, error) // Oldest first
+
+	// Instance-wide aggregates for operators, see stats_repo.go
+	CountInstanceTotals(ctx context.Context) (InstanceTotals, error)
+	CountPingsSince(ctx context.Context, since time.Time) (int64, error)
+	ListChecksOutsideBounds(ctx context.Context, bounds models.TimingBounds, limit int) ([]models.Check, int64, error) // Plus the total
+
+	// Per-check Prometheus gauges, see check_metrics_repo.go
+	ListCheckGauges(ctx context.Context, userID int64, limit int) ([]CheckGauge, bool, error) // Plus whether there were more
+
+	// Account-wide and filtered bulk changes, see bulk_repo.go
+	PauseAllByUserID(ctx context.Context, userID int64, source string) (int64, error)  // Returns the number of checks paused
+	ResumeAllByUserID(ctx context.Context, userID int64, source string) (int64, error) // Returns the number of checks resumed
+	ListBulkTargets(ctx context.Context, userID int64, action string, filter BulkFilter, limit int) ([]string, int64, error)
+	ApplyBulkAction(ctx context.Context, userID int64, action string, filter BulkFilter, source string, limit int) (int64, error) // Bulk* actions
+
+	// Check dependencies, see dependency_repo.go
+	ListDependencies(ctx context.Context, checkID int64) ([]models.Check, error)
+	AddDependency(ctx context.Context, userID, checkID, dependsOnID int64) error // ErrDependencyCycle, ErrCheckNotFound
+	RemoveDependency(ctx context.Context, checkID, dependsOnID int64) (bool, error)
+
+	MuteCheck(ctx context.Context, req MuteRequest) error // Silences notifications, see mute_repo.go
+
+	// Global notification kill-switch, see blackout_repo.go
+	ActiveBlackout(ctx context.Context) (*models.NotificationBlackout, error)                              // nil when off
+	StartBlackout(ctx context.Context, userID int64, reason string) (*models.NotificationBlackout, error)  // ErrBlackoutActive
+	EndBlackout(ctx context.Context, userID int64, sendSummary bool) (*models.NotificationBlackout, error) // ErrNoBlackout
+	ClaimBlackoutSummary(ctx context.Context) (*models.NotificationBlackout, error)                        // nil when none is due
+	ListBlackoutChanges(ctx context.Context, b *models.NotificationBlackout, limit int) ([]models.BlackoutChange, error)
+
+	RecordForwardResult(ctx context.Context, checkID int64, forwardErr error) error // Ping forwarding outcome, see forward_repo.go
 	// ... other methods as needed (e.g., UpdateStatus, UpdateLastPing)
 }
+
+type UserRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Each(ctx context.Context, fn func(*models.User) error) error
+	FindWebhook(ctx context.Context, userID int64) (*models.UserWebhook, error) // nil when unset
+	FindWebhookByCheckUUID(ctx context.Context, checkUUID string) (*models.UserWebhook, int64, error)
+	SetWebhook(ctx context.Context, userID int64, url string) error // "" removes it
+	RecordWebhookResult(ctx context.Context, userID int64, deliveryErr error) error
+}
+
+// ChannelRepository reads notification channels for delivery and manages them
+// for their account, see channel_repo.go.
+type ChannelRepository interface {
+	ListChannelTargets(ctx context.Context, kind string, checkIDs []int64) ([]models.ChannelTarget, error)
+	LogNotification(ctx context.Context, checkID, channelID int64, kind string, deliveryErr error) error
+
+	// Management, by the owning account
+	ListChannels(ctx context.Context, userID int64) ([]models.NotificationChannel, error)
+	FindChannel(ctx context.Context, userID, channelID int64) (*models.NotificationChannel, error) // ErrChannelNotFound
+	CreateChannel(ctx context.Context, ch *models.NotificationChannel) error
+	UpdateChannel(ctx context.Context, ch *models.NotificationChannel) error // A new value resets verification
+	DeleteChannel(ctx context.Context, userID, channelID int64) error
+
+	// Verification before first use
+	BeginChannelVerification(ctx context.Context, userID, channelID int64, token string, minInterval time.Duration) (*models.NotificationChannel, time.Duration, error) // ErrVerificationTooSoon plus the wait
+	SetChannelVerified(ctx context.Context, channelID int64) error
+	ConfirmChannel(ctx context.Context, channelID int64, token string, ttl time.Duration) error // ErrVerificationInvalid
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	ListByUserID(ctx context.Context, userID int64) ([]models.APIKey, error) // Metadata only, no key values
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bitterlink/core/internal/models"
+)
+
+// InstanceTotals are instance-wide counts across all users, for operators.
+// Soft-deleted users and checks are not counted.
+type InstanceTotals struct {
+	Checks     int64
+	Users      int64
+	ChecksDown int64 // Enabled checks currently 'down'
+}
+
+// CountInstanceTotals counts checks, users and down checks across the instance.
+// These only scan the users and checks tables, which stay small next to pings.
+func (r *mysqlCheckRepository) CountInstanceTotals(ctx context.Context) (InstanceTotals, error) {
+	var totals InstanceTotals
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM checks WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM users WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM checks WHERE deleted_at IS NULL AND is_enabled = TRUE AND status = 'down')`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&totals.Checks, &totals.Users, &totals.ChecksDown); err != nil {
+		return InstanceTotals{}, fmt.Errorf("error counting instance totals: %w", err)
+	}
+	return totals, nil
+}
+
+// CountPingsSince counts pings received at or after since, for all checks. This
+// walks a slice of the pings table, so callers should cache the result.
+func (r *mysqlCheckRepository) CountPingsSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM pings WHERE received_at >= ?`
+	if err := r.db.QueryRowContext(ctx, query, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting recent pings: %w", err)
+	}
+	return count, nil
+}
+
+// ListChecksOutsideBounds returns up to limit non-deleted checks, in ID order,
+// whose expected_interval or grace_period is outside bounds, and how many there
+// are in total. For auditing after the bounds were tightened; nothing is changed.
+func (r *mysqlCheckRepository) ListChecksOutsideBounds(ctx context.Context, bounds models.TimingBounds, limit int) ([]models.Check, int64, error) {
+	condition := `deleted_at IS NULL AND (
+			expected_interval < ? OR (? > 0 AND expected_interval > ?)
+			OR grace_period < ? OR (? > 0 AND grace_period > ?))`
+	args := []any{
+		max(bounds.MinExpectedInterval, 1), bounds.MaxExpectedInterval, bounds.MaxExpectedInterval,
+		bounds.MinGracePeriod, bounds.MaxGracePeriod, bounds.MaxGracePeriod,
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM checks WHERE `+condition, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting checks outside bounds: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+checkColumns+` FROM checks WHERE `+condition+` ORDER BY id ASC LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying checks outside bounds: %w", err)
+	}
+	defer rows.Close()
+	checks, err := scanCheckRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return checks, total, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"bitterlink/core/internal/db"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// transientServerErrors are MySQL error numbers that say the server is
+// unavailable or overloaded for now, not that the statement was wrong.
+var transientServerErrors = map[uint16]bool{
+	1040: true, // ER_CON_COUNT_ERROR, too many connections
+	1053: true, // ER_SERVER_SHUTDOWN
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+	1290: true, // ER_OPTION_PREVENTS_STATEMENT, e.g. --read-only during a failover
+	1836: true, // ER_READ_ONLY_MODE
+	1927: true, // ER_CONNECTION_KILLED
+}
+
+// IsTransient reports whether err means the database was briefly unavailable
+// (connection refused or lost, circuit breaker open, server restarting,
+// failover, lock timeout), so the same call may well succeed shortly. A
+// canceled request is not transient: nobody is waiting for the retry.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, ErrCanceled) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, db.ErrCircuitOpen) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return transientServerErrors[mysqlErr.Number]
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
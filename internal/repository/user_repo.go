@@ -1 +1,120 @@
-package repository
\ No newline at end of file
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"bitterlink/core/internal/models"
+)
+
+// ErrUserNotFound is returned when no (non-deleted) user matches the lookup.
+// It wraps ErrNotFound.
+var ErrUserNotFound = fmt.Errorf("user: %w", ErrNotFound)
+
+// mysqlUserRepository implements UserRepository using a MySQL database
+type mysqlUserRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLUserRepository creates a new repository instance
+func NewMySQLUserRepository(dbPool *sql.DB) UserRepository {
+	return &mysqlUserRepository{db: dbPool}
+}
+
+const userColumns = `id, name, email, password_hash, email_verified_at, deleted_at, created_at, updated_at`
+
+// scanUser scans a row selected with userColumns into a User.
+func scanUser(row rowScanner, user *models.User) error {
+	var name sql.NullString // users.name is nullable, models.User.Name is not
+	err := row.Scan(
+		&user.ID, &name, &user.Email, &user.PasswordHash,
+		&user.EmailVerifiedAt, &user.DeletedAt, &user.CreatedAt, &user.UpdatedAt,
+	)
+	user.Name = name.String
+	return err
+}
+
+// FindByID returns the non-deleted user with the given ID.
+func (r *mysqlUserRepository) FindByID(ctx context.Context, id int64) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ? AND deleted_at IS NULL LIMIT 1`
+	var user models.User
+	if err := scanUser(r.db.QueryRowContext(ctx, query, id), &user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		log.Printf("ERROR: FindByID - Scan failed for user %d: %v", id, err)
+		return nil, fmt.Errorf("error retrieving user data: %w", err)
+	}
+	return &user, nil
+}
+
+// FindByEmail returns the non-deleted user with the given email address.
+func (r *mysqlUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ? AND deleted_at IS NULL LIMIT 1`
+	var user models.User
+	if err := scanUser(r.db.QueryRowContext(ctx, query, email), &user); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		log.Printf("ERROR: FindByEmail - Scan failed for email %s: %v", email, err)
+		return nil, fmt.Errorf("error retrieving user data: %w", err)
+	}
+	return &user, nil
+}
+
+// Create inserts a new user, setting the generated ID back onto user.
+func (r *mysqlUserRepository) Create(ctx context.Context, user *models.User) error {
+	if user == nil {
+		return errors.New("can not create nil user")
+	}
+	if user.Email == "" {
+		return errors.New("Email is required to create a user")
+	}
+	if user.PasswordHash == "" {
+		return errors.New("PasswordHash is required to create a user")
+	}
+
+	query := `
+        INSERT INTO users (name, email, password_hash, email_verified_at, created_at, updated_at)
+        VALUES (?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+	result, err := r.db.ExecContext(ctx, query, user.Name, user.Email, user.PasswordHash, user.EmailVerifiedAt)
+	if err != nil {
+		log.Printf("ERROR: Failed to insert user %s: %v", user.Email, err)
+		return fmt.Errorf("database error creating user: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve new user ID after insert: %w", err)
+	}
+	user.ID = id
+	return nil
+}
+
+// Each streams all non-deleted users ordered by ID, stopping at the first error returned by fn.
+func (r *mysqlUserRepository) Each(ctx context.Context, fn func(*models.User) error) error {
+	query := `SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NULL ORDER BY id ASC`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("ERROR: Each - User query failed: %v", err)
+		return fmt.Errorf("error querying users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return fmt.Errorf("error scanning user data: %w", err)
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating user results: %w", err)
+	}
+	return nil
+}
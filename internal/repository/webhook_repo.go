@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"bitterlink/core/internal/models"
+)
+
+// maxWebhookErrorLen is the size of users.webhook_last_error.
+const maxWebhookErrorLen = 255
+
+// FindWebhook returns userID's webhook, or nil if it has none.
+func (r *mysqlUserRepository) FindWebhook(ctx context.Context, userID int64) (*models.UserWebhook, error) {
+	query := `
+        SELECT id, webhook_url, webhook_failures, webhook_last_error, webhook_failed_at
+        FROM users
+        WHERE id = ? AND deleted_at IS NULL AND webhook_url IS NOT NULL`
+	var hook models.UserWebhook
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&hook.UserID, &hook.URL, &hook.Failures, &hook.LastError, &hook.FailedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("ERROR: FindWebhook - Scan failed for user %d: %v", userID, err)
+		return nil, fmt.Errorf("error retrieving webhook: %w", err)
+	}
+	return &hook, nil
+}
+
+// FindWebhookByCheckUUID returns the webhook of the user owning the check with
+// the given UUID, with the check's ID, or a nil webhook if the owner has none.
+func (r *mysqlUserRepository) FindWebhookByCheckUUID(ctx context.Context, checkUUID string) (*models.UserWebhook, int64, error) {
+	query := `
+        SELECT u.id, u.webhook_url, u.webhook_failures, u.webhook_last_error, u.webhook_failed_at, c.id
+        FROM checks c
+        JOIN users u ON u.id = c.user_id
+        WHERE c.uuid = ? AND c.deleted_at IS NULL AND u.deleted_at IS NULL AND u.webhook_url IS NOT NULL`
+	var hook models.UserWebhook
+	var checkID int64
+	err := r.db.QueryRowContext(ctx, query, checkUUID).Scan(&hook.UserID, &hook.URL, &hook.Failures, &hook.LastError, &hook.FailedAt, &checkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, nil
+		}
+		log.Printf("ERROR: FindWebhookByCheckUUID - Scan failed for check %s: %v", checkUUID, err)
+		return nil, 0, fmt.Errorf("error retrieving webhook: %w", err)
+	}
+	return &hook, checkID, nil
+}
+
+// SetWebhook sets userID's webhook URL, or removes the webhook when url is "".
+// Either way the delivery state starts over.
+func (r *mysqlUserRepository) SetWebhook(ctx context.Context, userID int64, url string) error {
+	query := `
+        UPDATE users
+        SET webhook_url = ?, webhook_failures = 0, webhook_last_error = NULL, webhook_failed_at = NULL, updated_at = UTC_TIMESTAMP()
+        WHERE id = ? AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, sql.NullString{String: url, Valid: url != ""}, userID)
+	if err != nil {
+		log.Printf("ERROR: SetWebhook - Update failed for user %d: %v", userID, err)
+		return fmt.Errorf("database error setting webhook: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// RecordWebhookResult records the outcome of a delivery to userID's webhook,
+// as RecordForwardResult does for ping forwards.
+func (r *mysqlUserRepository) RecordWebhookResult(ctx context.Context, userID int64, deliveryErr error) error {
+	if deliveryErr == nil {
+		query := `UPDATE users SET webhook_failures = 0, webhook_last_error = NULL WHERE id = ? AND webhook_failures > 0`
+		if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+			return fmt.Errorf("error resetting webhook failures: %w", err)
+		}
+		return nil
+	}
+
+	message := deliveryErr.Error()
+	if len(message) > maxWebhookErrorLen {
+		message = message[:maxWebhookErrorLen]
+	}
+	query := `
+		UPDATE users
+		SET webhook_failures = webhook_failures + 1, webhook_last_error = ?, webhook_failed_at = UTC_TIMESTAMP()
+		WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, message, userID); err != nil {
+		log.Printf("ERROR: RecordWebhookResult - Update failed for user %d: %v", userID, err)
+		return fmt.Errorf("error recording webhook failure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package slack talks to Slack incoming webhooks, the destination of 'slack'
+// notification channels. For now it only verifies new channels with a test
+// message; like 'webhook' and 'email' channels, they get no per-check alerts
+// yet.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/version"
+)
+
+// TestMessage is the text of the message verifying a channel.
+const TestMessage = "Bitterlink test message: this channel is set up to receive alerts."
+
+// Config configures the Client. Zero values get the defaults noted.
+type Config struct {
+	Timeout time.Duration // Per request, default 10s
+	// SelfHosts are this instance's host names; channels pointing at them are
+	// refused, as for forward URLs.
+	SelfHosts []string
+}
+
+// Client posts messages to Slack incoming webhooks.
+type Client struct {
+	config Config
+	client *http.Client
+}
+
+// New creates a Client.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Client{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse // Incoming webhooks don't redirect
+			},
+		},
+	}
+}
+
+// TestChannel verifies a new channel by posting TestMessage to webhookURL,
+// which must be answered with a 2xx. Slack answers a bad or revoked webhook
+// with a 4xx, so a typo fails here rather than on the first outage.
+func (s *Client) TestChannel(ctx context.Context, webhookURL string) error {
+	return s.post(ctx, webhookURL, TestMessage)
+}
+
+// post sends a plain-text message to webhookURL.
+func (s *Client) post(ctx context.Context, webhookURL, text string) error {
+	if err := forward.ValidateURL(webhookURL, s.config.SelfHosts); err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bitterlink-slack/"+version.Version)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTestChannel(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s with %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	if err := New(Config{}).TestChannel(context.Background(), srv.URL+"/services/T1/B2/C3"); err != nil {
+		t.Fatalf("TestChannel: %v", err)
+	}
+	if got["text"] != TestMessage {
+		t.Errorf("posted %v, want the test message", got)
+	}
+}
+
+// Slack answers a revoked or mistyped webhook with a 4xx.
+func TestTestChannelRefused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no_service", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if err := New(Config{}).TestChannel(context.Background(), srv.URL); err == nil {
+		t.Fatal("TestChannel succeeded against a 404")
+	}
+}
+
+func TestTestChannelSelfHost(t *testing.T) {
+	client := New(Config{SelfHosts: []string{"bitterlink.example.com"}})
+	if err := client.TestChannel(context.Background(), "https://bitterlink.example.com/hook"); err == nil {
+		t.Fatal("TestChannel posted to this instance")
+	}
+}
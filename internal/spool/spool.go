@@ -0,0 +1,376 @@
+// Package spool keeps heartbeats that arrived while the database was briefly
+// unavailable, so a MySQL restart or failover doesn't lose them and later show
+// up as false downs. A ping that failed with a transient database error
+// (repository.IsTransient) is appended to a local file and acknowledged; the
+// replayer records it once the database is back.
+//
+// Pings are replayed in the order they arrived. While a check has pings in the
+// spool, its new pings are spooled behind them rather than recorded directly,
+// so a replayed success can't overtake a newer fail. A spooled ping is
+// recorded with the time of its replay, not of its arrival: the check's
+// deadline is counted from the replay, which errs on the side of not alerting.
+//
+// The spool is a local file, so it is per instance: an instance that is
+// replaced before it could replay loses what it spooled.
+package spool
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/repository"
+)
+
+// ErrFull is returned by Append when the spool is at MaxBytes. The ping is
+// lost, as it would have been without the spool.
+var ErrFull = errors.New("ping spool is full")
+
+// Config configures the Spool. Zero values get the defaults noted.
+type Config struct {
+	Path           string        // The spool file; Path+".replaying" is used during a replay
+	MaxBytes       int64         // Across both files, default 64 MiB
+	ReplayInterval time.Duration // How often to try replaying, default 5s
+}
+
+// Entry is one spooled ping, a line of JSON in the file.
+type Entry struct {
+	UUID      string    `json:"uuid"`
+	Kind      string    `json:"kind"`
+	SourceIP  string    `json:"source_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Payload   []byte    `json:"payload,omitempty"`
+	RunID     string    `json:"rid,omitempty"`
+	PingID    string    `json:"pid,omitempty"` // PingRecord.ClientPingID
+	SpooledAt time.Time `json:"spooled_at"`
+}
+
+// NewEntry spools ping.
+func NewEntry(ping repository.PingRecord) Entry {
+	return Entry{
+		UUID:      ping.UUID,
+		Kind:      ping.Kind,
+		SourceIP:  ping.SourceIP.String,
+		UserAgent: ping.UserAgent.String,
+		Payload:   ping.Payload,
+		RunID:     ping.RunID,
+		PingID:    ping.ClientPingID,
+		SpooledAt: time.Now().UTC(),
+	}
+}
+
+// record turns e back into the ping to record.
+func (e Entry) record() repository.PingRecord {
+	return repository.PingRecord{
+		UUID:         e.UUID,
+		Kind:         e.Kind,
+		SourceIP:     sql.NullString{String: e.SourceIP, Valid: e.SourceIP != ""},
+		UserAgent:    sql.NullString{String: e.UserAgent, Valid: e.UserAgent != ""},
+		Payload:      e.Payload,
+		RunID:        e.RunID,
+		ClientPingID: e.PingID,
+	}
+}
+
+// Store is the part of the check repository the replayer needs.
+type Store interface {
+	RecordPing(ctx context.Context, ping repository.PingRecord) error
+}
+
+// Spool is the append-only ping file and its replayer.
+type Spool struct {
+	store  Store
+	config Config
+
+	mu      sync.Mutex
+	file    *os.File       // Opened for appending
+	size    int64          // Of both files
+	pending map[string]int // Spooled pings per check UUID, in both files
+	depth   int            // Spooled pings in both files
+}
+
+// New opens (or creates) the spool at cfg.Path, picking up pings a previous
+// run left there. Call Start to run the replayer.
+func New(store Store, cfg Config) (*Spool, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 64 << 20
+	}
+	if cfg.ReplayInterval <= 0 {
+		cfg.ReplayInterval = 5 * time.Second
+	}
+	s := &Spool{store: store, config: cfg, pending: make(map[string]int)}
+
+	// 1. Count what is left over, oldest file first
+	for _, path := range []string{s.replayPath(), cfg.Path} {
+		entries, size, err := readEntries(path)
+		if err != nil {
+			return nil, err
+		}
+		s.size += size
+		s.depth += len(entries)
+		for _, e := range entries {
+			s.pending[e.UUID]++
+		}
+	}
+
+	// 2. Open the spool file for appending
+	file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening ping spool: %w", err)
+	}
+	s.file = file
+	if len(s.pending) > 0 {
+		log.Printf("INFO: Ping spool %s holds pings of %d checks from a previous run (%d bytes)", cfg.Path, len(s.pending), s.size)
+	}
+	return s, nil
+}
+
+func (s *Spool) replayPath() string { return s.config.Path + ".replaying" }
+
+// Append spools ping. The ping can be acknowledged once it returns nil: the
+// line was written and synced.
+func (s *Spool) Append(ping repository.PingRecord) error {
+	line, err := json.Marshal(NewEntry(ping))
+	if err != nil {
+		return fmt.Errorf("encoding spooled ping: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size+int64(len(line)) > s.config.MaxBytes {
+		return ErrFull
+	}
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("writing ping spool: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("syncing ping spool: %w", err)
+	}
+	s.size += int64(len(line))
+	s.depth++
+	s.pending[ping.UUID]++
+	return nil
+}
+
+// Pending reports whether the check with checkUUID has pings in the spool, in
+// which case its new pings must be spooled behind them too.
+func (s *Spool) Pending(checkUUID string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending[checkUUID] > 0
+}
+
+// Depth returns the number of pings waiting in the spool. It only reads a
+// counter, so it suits a metrics gauge.
+func (s *Spool) Depth() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depth
+}
+
+// Start replays the spool every ReplayInterval until ctx is cancelled. What is
+// left then stays on disk for the next run.
+func (s *Spool) Start(ctx context.Context) {
+	log.Printf("INFO: Ping spool replayer started (%s, every %s, max %d bytes)", s.config.Path, s.config.ReplayInterval, s.config.MaxBytes)
+	ticker := time.NewTicker(s.config.ReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.replay(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("ERROR: Ping spool replay failed: %v", err)
+			}
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.file.Close()
+			s.mu.Unlock()
+			log.Println("INFO: Ping spool replayer stopped.")
+			return
+		}
+	}
+}
+
+// replay records spooled pings until the spool is empty or the database fails
+// again. The spool file is first moved aside, so new pings can be appended to a
+// fresh one meanwhile; a replay file left by an interrupted replay is older, and
+// is finished first.
+func (s *Spool) replay(ctx context.Context) error {
+	for {
+		// 1. Move the spool file aside, unless a replay file is still there
+		if _, err := os.Stat(s.replayPath()); errors.Is(err, os.ErrNotExist) {
+			rotated, err := s.rotate()
+			if err != nil || !rotated {
+				return err
+			}
+		} else if err != nil {
+			return fmt.Errorf("checking ping spool: %w", err)
+		}
+
+		// 2. Record its pings in order
+		entries, size, err := readEntries(s.replayPath())
+		if err != nil {
+			return err
+		}
+		done := 0
+		for _, e := range entries {
+			err := s.store.RecordPing(ctx, e.record())
+			if repository.IsTransient(err) || ctx.Err() != nil {
+				break // Still unavailable; try again on the next tick
+			}
+			if errors.Is(err, repository.ErrDuplicatePing) {
+				// A retry of a ping recorded before it, from the spool or not
+				log.Printf("DEBUG: Spooled '%s' ping for UUID %s was already recorded", e.Kind, e.UUID)
+			} else if err != nil {
+				// An unknown or, by policy, inactive check: it wouldn't have been
+				// recorded at the time either
+				log.Printf("WARN: Dropping spooled '%s' ping for UUID %s from %s: %v", e.Kind, e.UUID, e.SpooledAt.Format(time.RFC3339), err)
+			}
+			done++
+		}
+
+		// 3. Remove what was recorded
+		if err := s.finish(entries, done, size); err != nil {
+			return err
+		}
+		if done > 0 {
+			log.Printf("INFO: Replayed %d spooled pings, %d left in this batch", done, len(entries)-done)
+		}
+		if done < len(entries) {
+			return nil
+		}
+	}
+}
+
+// rotate moves a non-empty spool file aside for replay and reopens an empty
+// one. It reports whether there was anything to replay.
+func (s *Spool) rotate() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, err := s.file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("checking ping spool: %w", err)
+	}
+	if info.Size() == 0 {
+		return false, nil
+	}
+	if err := os.Rename(s.config.Path, s.replayPath()); err != nil {
+		return false, fmt.Errorf("moving ping spool aside: %w", err)
+	}
+	file, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false, fmt.Errorf("reopening ping spool: %w", err)
+	}
+	s.file.Close()
+	s.file = file
+	return true, nil
+}
+
+// finish drops the first done entries of the replay file, removing the file
+// once all are done. The rest is written to a temporary file first, so a crash
+// leaves either the old or the new replay file, never half of one.
+func (s *Spool) finish(entries []Entry, done int, size int64) error {
+	if done == 0 {
+		return nil
+	}
+	var err error
+	rest := entries[done:]
+	remaining := int64(0)
+	if len(rest) == 0 {
+		err = os.Remove(s.replayPath())
+	} else {
+		remaining, err = writeEntries(s.replayPath(), rest)
+	}
+	if err != nil {
+		return fmt.Errorf("updating ping spool: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size -= size - remaining
+	s.depth -= done
+	for _, e := range entries[:done] {
+		if s.pending[e.UUID]--; s.pending[e.UUID] <= 0 {
+			delete(s.pending, e.UUID)
+		}
+	}
+	return nil
+}
+
+// readEntries reads the spool file at path, which may not exist, and returns
+// its entries and size. A line that doesn't parse (the tail of a write cut off
+// by a crash) is skipped.
+func readEntries(path string) ([]Entry, int64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening ping spool: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	var size int64
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20) // Payloads are capped well below this
+	for scanner.Scan() {
+		size += int64(len(scanner.Bytes())) + 1
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.Printf("WARN: Skipping unreadable line in ping spool %s: %v", path, err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("reading ping spool: %w", err)
+	}
+	return entries, size, nil
+}
+
+// writeEntries replaces the file at path with entries and returns its size.
+func writeEntries(path string, entries []Entry) (int64, error) {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	w := bufio.NewWriter(file)
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			file.Close()
+			return 0, err
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+		size += int64(len(line)) + 1
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+	return size, os.Rename(tmp, path)
+}
@@ -0,0 +1,88 @@
+package gqltransport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+// checkBatch is the dataloader for one page of checks. The first nested field
+// resolved on any check loads that field for every check in the page with one
+// repository call; the other checks are served from the cached result.
+// Loads are keyed by field and argument, since aliases may ask for different
+// page sizes.
+type checkBatch struct {
+	repo repository.CheckRepository
+	ids  []int64
+
+	mu    sync.Mutex
+	loads map[batchKey]*batchLoad
+}
+
+type batchKey struct {
+	field string
+	arg   int
+}
+
+type batchLoad struct {
+	once   sync.Once
+	pings  map[int64][]models.Ping
+	events map[int64][]models.CheckEvent
+	now    time.Time // uptime window end, shared by the batch
+	err    error
+}
+
+func newCheckBatch(repo repository.CheckRepository, checks []models.Check) *checkBatch {
+	ids := make([]int64, len(checks))
+	for i := range checks {
+		ids[i] = checks[i].ID
+	}
+	return &checkBatch{repo: repo, ids: ids, loads: map[batchKey]*batchLoad{}}
+}
+
+// load runs fetch once per key and returns the shared result.
+func (b *checkBatch) load(key batchKey, fetch func(*batchLoad)) *batchLoad {
+	b.mu.Lock()
+	l, ok := b.loads[key]
+	if !ok {
+		l = &batchLoad{}
+		b.loads[key] = l
+	}
+	b.mu.Unlock()
+
+	l.once.Do(func() { fetch(l) })
+	return l
+}
+
+func (b *checkBatch) pings(ctx context.Context, limit int) (map[int64][]models.Ping, error) {
+	l := b.load(batchKey{"pings", limit}, func(l *batchLoad) {
+		if l.err = charge(ctx, limit*len(b.ids)); l.err == nil {
+			l.pings, l.err = b.repo.ListPingsByCheckIDs(ctx, b.ids, limit)
+		}
+	})
+	return l.pings, l.err
+}
+
+func (b *checkBatch) events(ctx context.Context, limit int) (map[int64][]models.CheckEvent, error) {
+	l := b.load(batchKey{"events", limit}, func(l *batchLoad) {
+		if l.err = charge(ctx, limit*len(b.ids)); l.err == nil {
+			l.events, l.err = b.repo.ListEventsByCheckIDs(ctx, b.ids, limit)
+		}
+	})
+	return l.events, l.err
+}
+
+// uptimeEvents backs the uptime field: the events of the last days days, and
+// the window end they were loaded for. Every check in the batch shares the window.
+func (b *checkBatch) uptimeEvents(ctx context.Context, days int) (map[int64][]models.CheckEvent, time.Time, error) {
+	l := b.load(batchKey{"uptime", days}, func(l *batchLoad) {
+		l.now = time.Now().UTC()
+		if l.err = charge(ctx, len(b.ids)); l.err == nil {
+			l.events, l.err = b.repo.ListEventsSince(ctx, b.ids, l.now.AddDate(0, 0, -days))
+		}
+	})
+	return l.events, l.now, l.err
+}
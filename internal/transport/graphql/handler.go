@@ -0,0 +1,67 @@
+package gqltransport
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// request is a GraphQL-over-HTTP POST body.
+type request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler serves POST /api/v1/graphql. It must run behind the API key middleware.
+type Handler struct {
+	schema *graphql.Schema
+}
+
+// NewHandler parses the schema and binds it to the repository.
+func NewHandler(cr repository.CheckRepository) *Handler {
+	schema := graphql.MustParseSchema(schemaSDL, &rootResolver{repo: cr},
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxQueryLength(maxQueryLength),
+		graphql.UseStringDescriptions(),
+	)
+	return &Handler{schema: schema}
+}
+
+// ServeGraphQL executes one query for the authenticated user.
+func (h *Handler) ServeGraphQL(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/graphql")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+
+	var req request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), userIDKey{}, int64(userID))
+	resp := h.schema.Exec(withCost(ctx), req.Query, req.OperationName, req.Variables)
+	if len(resp.Errors) > 0 {
+		log.Printf("WARN: GraphQL query for user %d returned %d errors, first: %v", userID, len(resp.Errors), resp.Errors[0])
+	}
+
+	// GraphQL reports query errors in the body with a 200
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("ERROR: Failed to encode GraphQL response for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
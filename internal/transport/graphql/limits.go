@@ -0,0 +1,61 @@
+package gqltransport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Query limits. The schema has no cycles, so depth is naturally bounded; the
+// cost budget is what stops wide queries (many aliases, large pages) from
+// turning into a lot of database work.
+const (
+	maxDepth       = 6
+	maxQueryLength = 10000 // bytes
+	maxCost        = 10000 // see cost.charge
+
+	maxChecksPerPage  = 100
+	maxPingsPerCheck  = 50
+	maxEventsPerCheck = 50
+	maxUptimeDays     = 365
+)
+
+// errCostExceeded is returned once a query has spent its budget.
+var errCostExceeded = fmt.Errorf("query exceeds the maximum cost of %d", maxCost)
+
+// cost tracks the estimated work of one request: every resolver that loads a
+// list charges the number of rows it may return before querying.
+type cost struct {
+	spent atomic.Int64
+}
+
+type costKey struct{}
+
+func withCost(ctx context.Context) context.Context {
+	return context.WithValue(ctx, costKey{}, &cost{})
+}
+
+// charge adds n to the request's cost, failing once the budget is exceeded.
+func charge(ctx context.Context, n int) error {
+	c, ok := ctx.Value(costKey{}).(*cost)
+	if !ok {
+		return errors.New("missing query cost tracker")
+	}
+	if c.spent.Add(int64(n)) > maxCost {
+		return errCostExceeded
+	}
+	return nil
+}
+
+// clampArg bounds a count argument to [1, max]. Defaults live in the schema.
+func clampArg(arg int32, max int) int {
+	n := int(arg)
+	if n < 1 {
+		return 1
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
@@ -0,0 +1,252 @@
+package gqltransport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type userIDKey struct{}
+
+// userIDFromContext returns the authenticated user set by the handler.
+func userIDFromContext(ctx context.Context) (int64, error) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	if !ok {
+		return 0, errors.New("authentication context error")
+	}
+	return userID, nil
+}
+
+// rootResolver resolves the Query type.
+type rootResolver struct {
+	repo repository.CheckRepository
+}
+
+type checksArgs struct {
+	Status *string
+	UUIDs  *[]string
+	First  int32
+	After  *graphql.ID
+}
+
+func (r *rootResolver) Checks(ctx context.Context, args checksArgs) (*connectionResolver, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	limit := clampArg(args.First, maxChecksPerPage)
+	if err := charge(ctx, limit); err != nil {
+		return nil, err
+	}
+
+	filter := repository.CheckFilter{Limit: limit + 1} // one extra row tells us if there is a next page
+	if args.Status != nil {
+		filter.Status = *args.Status
+	}
+	if args.UUIDs != nil {
+		if len(*args.UUIDs) == 0 {
+			return &connectionResolver{}, nil
+		}
+		filter.UUIDs = *args.UUIDs
+	}
+	if args.After != nil {
+		if filter.AfterID, err = strconv.ParseInt(string(*args.After), 10, 64); err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+
+	checks, err := r.repo.ListChecksPage(ctx, userID, filter)
+	if err != nil {
+		return nil, errors.New("failed to retrieve checks")
+	}
+	conn := &connectionResolver{hasNextPage: len(checks) > limit}
+	if conn.hasNextPage {
+		checks = checks[:limit]
+	}
+	conn.nodes = checkResolvers(r.repo, checks)
+	return conn, nil
+}
+
+func (r *rootResolver) Check(ctx context.Context, args struct{ UUID string }) (*checkResolver, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := charge(ctx, 1); err != nil {
+		return nil, err
+	}
+	check, err := r.repo.FindByUUID(ctx, args.UUID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to retrieve check")
+	}
+	// Other users' checks are simply not found
+	if check.UserID != userID {
+		return nil, nil
+	}
+	return checkResolvers(r.repo, []models.Check{*check})[0], nil
+}
+
+// checkResolvers wraps a page of checks sharing one batch loader.
+func checkResolvers(repo repository.CheckRepository, checks []models.Check) []*checkResolver {
+	batch := newCheckBatch(repo, checks)
+	resolvers := make([]*checkResolver, len(checks))
+	for i := range checks {
+		resolvers[i] = &checkResolver{check: &checks[i], batch: batch}
+	}
+	return resolvers
+}
+
+type connectionResolver struct {
+	nodes       []*checkResolver
+	hasNextPage bool
+}
+
+func (c *connectionResolver) Nodes() []*checkResolver { return c.nodes }
+func (c *connectionResolver) HasNextPage() bool       { return c.hasNextPage }
+func (c *connectionResolver) EndCursor() *graphql.ID {
+	if len(c.nodes) == 0 {
+		return nil
+	}
+	return idOf(c.nodes[len(c.nodes)-1].check.ID)
+}
+
+type checkResolver struct {
+	check *models.Check
+	batch *checkBatch
+}
+
+func (c *checkResolver) ID() graphql.ID            { return *idOf(c.check.ID) }
+func (c *checkResolver) UUID() string              { return c.check.UUID }
+func (c *checkResolver) Name() string              { return c.check.Name }
+func (c *checkResolver) Description() *string      { return nullString(c.check.Description) }
+func (c *checkResolver) ExpectedInterval() int32   { return int32(c.check.ExpectedInterval) }
+func (c *checkResolver) GracePeriod() int32        { return int32(c.check.GracePeriod) }
+func (c *checkResolver) LastPingAt() *graphql.Time { return nullTime(c.check.LastPingAt) }
+func (c *checkResolver) Status() string            { return c.check.Status }
+func (c *checkResolver) IsEnabled() bool           { return c.check.IsEnabled }
+func (c *checkResolver) NotifyLate() bool          { return c.check.NotifyLate }
+func (c *checkResolver) Severity() string          { return c.check.Severity }
+func (c *checkResolver) Color() *string            { return nullString(c.check.Color) }
+func (c *checkResolver) Icon() *string             { return nullString(c.check.Icon) }
+func (c *checkResolver) CreatedAt() graphql.Time   { return graphql.Time{Time: c.check.CreatedAt} }
+func (c *checkResolver) UpdatedAt() graphql.Time   { return graphql.Time{Time: c.check.UpdatedAt} }
+
+func (c *checkResolver) Pings(ctx context.Context, args struct{ First int32 }) ([]*pingResolver, error) {
+	pings, err := c.batch.pings(ctx, clampArg(args.First, maxPingsPerCheck))
+	if err != nil {
+		return nil, loadError(err, "pings")
+	}
+	resolvers := make([]*pingResolver, 0, len(pings[c.check.ID]))
+	for i := range pings[c.check.ID] {
+		resolvers = append(resolvers, &pingResolver{ping: &pings[c.check.ID][i]})
+	}
+	return resolvers, nil
+}
+
+func (c *checkResolver) Events(ctx context.Context, args struct{ First int32 }) ([]*eventResolver, error) {
+	events, err := c.batch.events(ctx, clampArg(args.First, maxEventsPerCheck))
+	if err != nil {
+		return nil, loadError(err, "events")
+	}
+	resolvers := make([]*eventResolver, 0, len(events[c.check.ID]))
+	for i := range events[c.check.ID] {
+		resolvers = append(resolvers, &eventResolver{event: &events[c.check.ID][i]})
+	}
+	return resolvers, nil
+}
+
+func (c *checkResolver) Uptime(ctx context.Context, args struct{ Days int32 }) (*uptimeResolver, error) {
+	days := clampArg(args.Days, maxUptimeDays)
+	events, now, err := c.batch.uptimeEvents(ctx, days)
+	if err != nil {
+		return nil, loadError(err, "uptime")
+	}
+	since := now.AddDate(0, 0, -days)
+	if c.check.CreatedAt.After(since) {
+		since = c.check.CreatedAt // don't count time before the check existed
+	}
+
+	checkEvents := events[c.check.ID]
+	downEvents := 0
+	for _, event := range checkEvents {
+		if event.Type == models.EventStatusChanged && event.ToStatus.String == models.StatusDown {
+			downEvents++
+		}
+	}
+	return &uptimeResolver{
+		days:       int32(days),
+		ratio:      models.UptimeRatio(checkEvents, c.check.Status, since, now),
+		downEvents: int32(downEvents),
+	}, nil
+}
+
+type pingResolver struct{ ping *models.Ping }
+
+func (p *pingResolver) ID() graphql.ID           { return *idOf(p.ping.ID) }
+func (p *pingResolver) Kind() string             { return p.ping.Kind }
+func (p *pingResolver) ReceivedAt() graphql.Time { return graphql.Time{Time: p.ping.ReceivedAt} }
+func (p *pingResolver) SourceIp() *string        { return nullString(p.ping.SourceIP) }
+func (p *pingResolver) UserAgent() *string       { return nullString(p.ping.UserAgent) }
+func (p *pingResolver) DurationMs() *int32 {
+	if !p.ping.DurationMs.Valid {
+		return nil
+	}
+	ms := int32(p.ping.DurationMs.Int64)
+	return &ms
+}
+func (p *pingResolver) Rid() *string { return nullString(p.ping.RunID) }
+
+type eventResolver struct{ event *models.CheckEvent }
+
+func (e *eventResolver) ID() graphql.ID          { return *idOf(e.event.ID) }
+func (e *eventResolver) Type() string            { return e.event.Type }
+func (e *eventResolver) FromStatus() *string     { return nullString(e.event.FromStatus) }
+func (e *eventResolver) ToStatus() *string       { return nullString(e.event.ToStatus) }
+func (e *eventResolver) Source() string          { return e.event.Source }
+func (e *eventResolver) CreatedAt() graphql.Time { return graphql.Time{Time: e.event.CreatedAt} }
+
+type uptimeResolver struct {
+	days       int32
+	ratio      float64
+	downEvents int32
+}
+
+func (u *uptimeResolver) Days() int32       { return u.days }
+func (u *uptimeResolver) Ratio() float64    { return u.ratio }
+func (u *uptimeResolver) DownEvents() int32 { return u.downEvents }
+
+// loadError keeps the cost error visible to clients and hides database errors.
+func loadError(err error, field string) error {
+	if errors.Is(err, errCostExceeded) {
+		return err
+	}
+	return errors.New("failed to load " + field)
+}
+
+func idOf(id int64) *graphql.ID {
+	gid := graphql.ID(strconv.FormatInt(id, 10))
+	return &gid
+}
+
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullTime(t sql.NullTime) *graphql.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &graphql.Time{Time: t.Time}
+}
@@ -0,0 +1,76 @@
+// Package gqltransport serves a read-only GraphQL API for dashboards, resolved
+// through the check repository with per-request batching so nested fields cost
+// one query per field and page, not one per check.
+package gqltransport
+
+// schemaSDL is the GraphQL schema. Mutations are intentionally not offered;
+// writes go through the REST API.
+const schemaSDL = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# Checks of the authenticated user, ordered by ID. Pass endCursor as after for the next page.
+	checks(status: String, uuids: [String!], first: Int = 50, after: ID): CheckConnection!
+	check(uuid: String!): Check
+}
+
+type CheckConnection {
+	nodes: [Check!]!
+	endCursor: ID
+	hasNextPage: Boolean!
+}
+
+type Check {
+	id: ID!
+	uuid: String!
+	name: String!
+	description: String
+	expectedInterval: Int!
+	gracePeriod: Int!
+	lastPingAt: Time
+	status: String!
+	isEnabled: Boolean!
+	notifyLate: Boolean!
+	severity: String!
+	color: String
+	icon: String
+	createdAt: Time!
+	updatedAt: Time!
+	# Most recent pings, newest first.
+	pings(first: Int = 10): [Ping!]!
+	# Most recent events, newest first.
+	events(first: Int = 10): [Event!]!
+	uptime(days: Int = 30): Uptime!
+}
+
+type Ping {
+	id: ID!
+	kind: String!
+	receivedAt: Time!
+	sourceIp: String
+	userAgent: String
+	durationMs: Int
+	rid: String
+}
+
+type Event {
+	id: ID!
+	type: String!
+	fromStatus: String
+	toStatus: String
+	source: String!
+	createdAt: Time!
+}
+
+type Uptime {
+	days: Int!
+	# Fraction of the window the check was not down, 0..1.
+	ratio: Float!
+	# Number of transitions to down in the window.
+	downEvents: Int!
+}
+`
@@ -0,0 +1,63 @@
+package grpctransport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	coredb "bitterlink/core/internal/db"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/middleware"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// userIDKey is the context key the interceptor stores the caller's user ID under.
+type userIDKey struct{}
+
+// APIKeyAuthInterceptor is the gRPC counterpart of middleware.APIKeyAuthMiddleware.
+// It reads "authorization: Bearer <key>" from the request metadata and validates
+// it with middleware.ResolveAPIKey, so both transports accept the same keys.
+func APIKeyAuthInterceptor(db *sql.DB) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		// Fail fast while the database is unreachable, like DBCircuitMiddleware
+		if ready, _ := coredb.CircuitReady(); !ready {
+			metrics.Incr(metrics.DBCircuitRejected, "transport:grpc")
+			return nil, status.Error(codes.Unavailable, "database unavailable, try again later")
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+		}
+		apiKey, ok := strings.CutPrefix(values[0], "Bearer")
+		if !ok || strings.TrimSpace(apiKey) == "" {
+			return nil, status.Error(codes.Unauthenticated, "authorization must be Bearer {token}")
+		}
+
+		userID, err := middleware.ResolveAPIKey(ctx, db, apiKey)
+		if err != nil {
+			switch {
+			case errors.Is(err, middleware.ErrInvalidAPIKey):
+				return nil, status.Error(codes.Unauthenticated, "invalid API key")
+			case errors.Is(err, middleware.ErrInactiveAPIKey):
+				return nil, status.Error(codes.PermissionDenied, "API key is inactive")
+			default:
+				log.Printf("ERROR: gRPC %s: database error during API key validation: %v", info.FullMethod, err)
+				return nil, status.Error(codes.Internal, "could not validate API key")
+			}
+		}
+		return handler(context.WithValue(ctx, userIDKey{}, int64(userID)), req)
+	}
+}
+
+// userIDFromContext returns the user ID set by APIKeyAuthInterceptor.
+func userIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	return userID, ok
+}
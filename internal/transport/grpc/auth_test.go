@@ -0,0 +1,38 @@
+package grpctransport
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Requests without usable credentials are refused before any database lookup.
+func TestAPIKeyAuthInterceptorRejectsMissingCredentials(t *testing.T) {
+	interceptor := APIKeyAuthInterceptor(nil)
+	info := &grpc.UnaryServerInfo{FullMethod: "/checks.v1.CheckService/GetCheck"}
+	handler := func(context.Context, any) (any, error) {
+		t.Fatal("handler called without credentials")
+		return nil, nil
+	}
+
+	for name, md := range map[string]metadata.MD{
+		"no metadata":  nil,
+		"not bearer":   metadata.Pairs("authorization", "Basic dXNlcjpwYXNz"),
+		"empty bearer": metadata.Pairs("authorization", "Bearer  "),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if md != nil {
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+			_, err := interceptor(ctx, nil, info, handler)
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("error = %v, want Unauthenticated", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package grpctransport
+
+import (
+	"database/sql"
+	"time"
+
+	"bitterlink/core/internal/models"
+	checksv1 "bitterlink/core/proto/checks/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// checkToProto converts a check to its wire form. NULL columns become unset
+// fields rather than zero values, so clients can tell "no description" from "".
+func checkToProto(check *models.Check) *checksv1.Check {
+	return &checksv1.Check{
+		Id:               check.ID,
+		Uuid:             check.UUID,
+		Name:             check.Name,
+		Description:      nullStringToProto(check.Description),
+		ExpectedInterval: check.ExpectedInterval,
+		GracePeriod:      check.GracePeriod,
+		LastPingAt:       nullTimeToProto(check.LastPingAt),
+		Status:           check.Status,
+		IsEnabled:        check.IsEnabled,
+		NotifyLate:       check.NotifyLate,
+		CreatedAt:        timeToProto(check.CreatedAt),
+		UpdatedAt:        timeToProto(check.UpdatedAt),
+	}
+}
+
+// checkFromCreateRequest maps a create request onto a new check, applying the
+// same defaults as the HTTP API: enabled, status 'new', grace period 0.
+// The caller sets UserID and UUID.
+func checkFromCreateRequest(req *checksv1.CreateCheckRequest) models.Check {
+	check := models.Check{
+		Name:             req.GetName(),
+		ExpectedInterval: req.GetExpectedInterval(),
+		GracePeriod:      req.GetGracePeriod(),
+		IsEnabled:        true,
+		Status:           models.StatusNew,
+		NotifyLate:       req.GetNotifyLate(),
+	}
+	if req.Description != nil {
+		check.Description = sql.NullString{String: req.GetDescription(), Valid: true}
+	}
+	if req.IsEnabled != nil {
+		check.IsEnabled = req.GetIsEnabled()
+	}
+	if req.Status != nil {
+		check.Status = req.GetStatus()
+	}
+	return check
+}
+
+func nullStringToProto(s sql.NullString) *wrapperspb.StringValue {
+	if !s.Valid {
+		return nil
+	}
+	return wrapperspb.String(s.String)
+}
+
+func nullTimeToProto(t sql.NullTime) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
+	}
+	return timestamppb.New(t.Time)
+}
+
+func timeToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,82 @@
+package grpctransport
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"bitterlink/core/internal/models"
+	checksv1 "bitterlink/core/proto/checks/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCheckToProtoNullFields(t *testing.T) {
+	created := time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC)
+	pinged := created.Add(time.Hour)
+
+	t.Run("NULL columns stay unset", func(t *testing.T) {
+		got := checkToProto(&models.Check{ID: 3, UUID: "uuid-3", Name: "backup", Status: models.StatusNew, CreatedAt: created})
+		if got.Description != nil {
+			t.Errorf("Description = %v, want unset", got.Description)
+		}
+		if got.LastPingAt != nil {
+			t.Errorf("LastPingAt = %v, want unset", got.LastPingAt)
+		}
+		if got.UpdatedAt != nil {
+			t.Errorf("UpdatedAt = %v for a zero time, want unset", got.UpdatedAt)
+		}
+		if !got.CreatedAt.AsTime().Equal(created) {
+			t.Errorf("CreatedAt = %v, want %v", got.CreatedAt.AsTime(), created)
+		}
+	})
+
+	t.Run("empty description is not NULL", func(t *testing.T) {
+		got := checkToProto(&models.Check{Description: sql.NullString{Valid: true}})
+		if got.Description == nil || got.Description.GetValue() != "" {
+			t.Errorf("Description = %v, want set to \"\"", got.Description)
+		}
+	})
+
+	t.Run("set columns", func(t *testing.T) {
+		check := models.Check{
+			ID: 3, UUID: "uuid-3", Name: "backup", Description: sql.NullString{String: "nightly", Valid: true},
+			ExpectedInterval: 3600, GracePeriod: 300, LastPingAt: sql.NullTime{Time: pinged, Valid: true},
+			Status: models.StatusUp, IsEnabled: true, NotifyLate: true, CreatedAt: created, UpdatedAt: pinged,
+		}
+		got := checkToProto(&check)
+		if got.GetId() != 3 || got.GetUuid() != "uuid-3" || got.GetName() != "backup" || got.GetDescription().GetValue() != "nightly" ||
+			got.GetExpectedInterval() != 3600 || got.GetGracePeriod() != 300 || got.GetStatus() != models.StatusUp ||
+			!got.GetIsEnabled() || !got.GetNotifyLate() {
+			t.Errorf("checkToProto = %v", got)
+		}
+		if !got.LastPingAt.AsTime().Equal(pinged) || !got.UpdatedAt.AsTime().Equal(pinged) {
+			t.Errorf("LastPingAt, UpdatedAt = %v, %v; want %v", got.LastPingAt.AsTime(), got.UpdatedAt.AsTime(), pinged)
+		}
+	})
+}
+
+func TestCheckFromCreateRequest(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		got := checkFromCreateRequest(&checksv1.CreateCheckRequest{Name: "backup", ExpectedInterval: 60})
+		if got.Name != "backup" || got.ExpectedInterval != 60 || got.GracePeriod != 0 {
+			t.Errorf("check = %+v", got)
+		}
+		if !got.IsEnabled || got.Status != models.StatusNew || got.Description.Valid {
+			t.Errorf("IsEnabled, Status, Description = %v, %q, %v; want true, new, NULL", got.IsEnabled, got.Status, got.Description)
+		}
+	})
+
+	t.Run("provided fields", func(t *testing.T) {
+		got := checkFromCreateRequest(&checksv1.CreateCheckRequest{
+			Name: "backup", ExpectedInterval: 60, GracePeriod: proto.Uint32(30), NotifyLate: true,
+			Description: proto.String(""), IsEnabled: proto.Bool(false), Status: proto.String(models.StatusPaused),
+		})
+		if got.Description != (sql.NullString{Valid: true}) {
+			t.Errorf("Description = %v, want an empty, non-NULL string", got.Description)
+		}
+		if got.IsEnabled || got.Status != models.StatusPaused || got.GracePeriod != 30 || !got.NotifyLate {
+			t.Errorf("check = %+v", got)
+		}
+	})
+}
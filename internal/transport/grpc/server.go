@@ -0,0 +1,201 @@
+// Package grpctransport serves the CheckService gRPC API (proto/checks/v1) on top
+// of the same repositories as the HTTP API.
+package grpctransport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+	"bitterlink/core/internal/webhook"
+	checksv1 "bitterlink/core/proto/checks/v1"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config holds the limits shared with the HTTP API.
+type Config struct {
+	// Bounds limits expected_interval and grace_period, as over HTTP.
+	Bounds models.TimingBounds
+	// MaxPayloadBytes caps how much of a ping payload is stored.
+	MaxPayloadBytes int
+	// Descriptions limits and sanitizes check descriptions, as over HTTP.
+	Descriptions models.DescriptionRules
+}
+
+// Server implements checksv1.CheckServiceServer.
+type Server struct {
+	checksv1.UnimplementedCheckServiceServer
+	CheckRepo repository.CheckRepository
+	Forwarder *forward.Forwarder // nil unless ping forwarding is enabled
+	Webhooks  *webhook.Sender    // nil unless account webhooks are enabled
+	Config    Config
+}
+
+// NewServer creates a CheckService implementation. fwd and hooks may be nil.
+func NewServer(cr repository.CheckRepository, fwd *forward.Forwarder, hooks *webhook.Sender, cfg Config) *Server {
+	return &Server{CheckRepo: cr, Forwarder: fwd, Webhooks: hooks, Config: cfg}
+}
+
+// CreateCheck mirrors POST /api/v1/checks.
+func (s *Server) CreateCheck(ctx context.Context, req *checksv1.CreateCheckRequest) (*checksv1.Check, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "authentication context error")
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	check := checkFromCreateRequest(req)
+	if err := check.Validate(s.Config.Bounds); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if check.Description.Valid {
+		cleaned, ok := s.Config.Descriptions.Clean(check.Description.String)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "description must not exceed %d characters", s.Config.Descriptions.MaxLength)
+		}
+		check.Description.String = cleaned
+	}
+	// A check can only start out 'new' or 'paused'; 'up'/'down' are earned via pings and the worker
+	if check.Status != models.StatusNew && check.Status != models.StatusPaused {
+		return nil, status.Error(codes.InvalidArgument, "status must be 'new' or 'paused' when creating a check")
+	}
+	check.UserID = userID
+	check.UUID = uuid.NewString()
+
+	if err := s.CheckRepo.Create(ctx, &check); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateName):
+			return nil, status.Error(codes.AlreadyExists, "a check with this name already exists")
+		case errors.Is(err, repository.ErrDuplicateUUID):
+			return nil, status.Error(codes.AlreadyExists, "a check with this UUID already exists")
+		}
+		log.Printf("ERROR: gRPC CreateCheck failed for user %d: %v", userID, err)
+		return nil, status.Error(codes.Internal, "failed to create check")
+	}
+	return checkToProto(&check), nil
+}
+
+// GetCheck mirrors GET /api/v1/checks/{id}.
+func (s *Server) GetCheck(ctx context.Context, req *checksv1.GetCheckRequest) (*checksv1.Check, error) {
+	check, err := s.ownedCheck(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return checkToProto(check), nil
+}
+
+// ListChecks mirrors GET /api/v1/checks.
+func (s *Server) ListChecks(ctx context.Context, _ *checksv1.ListChecksRequest) (*checksv1.ListChecksResponse, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "authentication context error")
+	}
+	checks, err := s.CheckRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("ERROR: gRPC ListChecks failed for user %d: %v", userID, err)
+		return nil, status.Error(codes.Internal, "failed to retrieve checks")
+	}
+	resp := &checksv1.ListChecksResponse{Checks: make([]*checksv1.Check, 0, len(checks))}
+	for i := range checks {
+		resp.Checks = append(resp.Checks, checkToProto(&checks[i]))
+	}
+	return resp, nil
+}
+
+// DeleteCheck soft-deletes one of the caller's checks.
+func (s *Server) DeleteCheck(ctx context.Context, req *checksv1.DeleteCheckRequest) (*checksv1.DeleteCheckResponse, error) {
+	check, err := s.ownedCheck(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckRepo.Delete(ctx, check.ID, check.UserID); err != nil {
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			return nil, status.Error(codes.NotFound, "check not found")
+		}
+		log.Printf("ERROR: gRPC DeleteCheck failed for check %d: %v", check.ID, err)
+		return nil, status.Error(codes.Internal, "failed to delete check")
+	}
+	return &checksv1.DeleteCheckResponse{}, nil
+}
+
+// RecordPing mirrors /api/v1/ping/{uuid}[/{signal}].
+func (s *Server) RecordPing(ctx context.Context, req *checksv1.RecordPingRequest) (*checksv1.RecordPingResponse, error) {
+	kind := req.GetKind()
+	if kind == "" {
+		kind = models.PingKindSuccess
+	}
+	if req.GetUuid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "uuid is required")
+	}
+	if !models.IsValidPingKind(kind) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown ping kind %q", kind)
+	}
+
+	payload := req.GetPayload()
+	if len(payload) == 0 {
+		payload = nil
+	} else if s.Config.MaxPayloadBytes > 0 && len(payload) > s.Config.MaxPayloadBytes {
+		payload = payload[:s.Config.MaxPayloadBytes]
+	}
+
+	err := s.CheckRepo.RecordPing(ctx, repository.PingRecord{UUID: req.GetUuid(), Kind: kind, Payload: payload})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrCheckNotFound):
+			metrics.Incr(metrics.PingsIngested, "kind:"+kind, "result:not_found")
+			return nil, status.Error(codes.NotFound, "check not found or inactive")
+		case errors.Is(err, repository.ErrCheckInactive):
+			metrics.Incr(metrics.PingsIngested, "kind:"+kind, "result:inactive")
+			return nil, status.Error(codes.FailedPrecondition, "check is disabled or paused")
+		default:
+			metrics.Incr(metrics.PingsIngested, "kind:"+kind, "result:error")
+			log.Printf("ERROR: gRPC RecordPing failed for UUID %s: %v", req.GetUuid(), err)
+			return nil, status.Error(codes.Internal, "failed to process ping")
+		}
+	}
+	metrics.Incr(metrics.PingsIngested, "kind:"+kind, "result:ok")
+	if s.Forwarder != nil {
+		// Forwarded as the HTTP ping it replaces: POST when there is a payload
+		method := http.MethodGet
+		if payload != nil {
+			method = http.MethodPost
+		}
+		s.Forwarder.Enqueue(forward.Ping{UUID: req.GetUuid(), Kind: kind, Method: method, Payload: payload})
+	}
+	s.Webhooks.Enqueue(webhook.Event{Type: webhook.EventPing, CheckUUID: req.GetUuid(), PingKind: kind})
+	return &checksv1.RecordPingResponse{}, nil
+}
+
+// ownedCheck loads a check and hides other users' checks behind NotFound.
+func (s *Server) ownedCheck(ctx context.Context, id int64) (*models.Check, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "authentication context error")
+	}
+	if id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid check ID")
+	}
+	check, _, err := s.CheckRepo.FindByIDWithLastPing(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			return nil, status.Error(codes.NotFound, "check not found")
+		}
+		log.Printf("ERROR: gRPC failed to load check %d: %v", id, err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to retrieve check %d", id))
+	}
+	if check.UserID != userID {
+		return nil, status.Error(codes.NotFound, "check not found")
+	}
+	return check, nil
+}
@@ -0,0 +1,189 @@
+package httptransport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"bitterlink/core/internal/export"
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHeader carries the account password the export endpoints require on
+// top of the API key, so a stolen key alone can't take all of an account's data.
+const PasswordHeader = "X-Account-Password"
+
+// AccountConfig configures the account data export.
+type AccountConfig struct {
+	// PingHistory is how far back exports include pings, normally the instance's
+	// ping retention window. 0 exports no pings.
+	PingHistory time.Duration
+	// Webhooks is whether account webhooks are delivered on this instance
+	// (USER_WEBHOOKS); without it a webhook can't be set.
+	Webhooks bool
+	// WebhookSelfHosts are this instance's own host names, which a webhook may
+	// not point at (see forward.ValidateURL).
+	WebhookSelfHosts []string
+}
+
+// AccountHandler serves the account's own data ("all data you hold about me")
+// as an export in the internal/export dump format.
+type AccountHandler struct {
+	UserRepo   repository.UserRepository
+	CheckRepo  repository.CheckRepository
+	APIKeyRepo repository.APIKeyRepository
+	Jobs       *export.JobManager
+	Config     AccountConfig
+}
+
+// NewAccountHandler creates a handler for the account export endpoints. jobs
+// must be running (see export.JobManager.Start) for asynchronous exports.
+func NewAccountHandler(ur repository.UserRepository, cr repository.CheckRepository, kr repository.APIKeyRepository, jobs *export.JobManager, cfg AccountConfig) *AccountHandler {
+	return &AccountHandler{UserRepo: ur, CheckRepo: cr, APIKeyRepo: kr, Jobs: jobs, Config: cfg}
+}
+
+// ExportAccount streams the caller's export as a download. Fine for most
+// accounts; large ones should use RequestExport instead, which can't time out
+// half-way.
+// Method: GET /api/v1/account/export
+func (h *AccountHandler) ExportAccount(c *gin.Context) {
+	user, ok := h.confirmedUser(c)
+	if !ok {
+		return
+	}
+
+	openStreams.Add(1)
+	defer openStreams.Add(-1)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+exportFilename(user.ID)+`"`)
+	c.Header("Trailer", streamErrorTrailer)
+	c.Status(http.StatusOK)
+
+	// As in streamJSONArray, a failure after the first byte leaves the document
+	// unterminated and sets the trailer
+	if err := h.writeExport(c.Request.Context(), c.Writer, user); err != nil {
+		if isClientGone(err) {
+			log.Printf("DEBUG: Client went away during account export for user %d: %v", user.ID, err)
+			return
+		}
+		log.Printf("ERROR: Account export failed for user %d: %v", user.ID, err)
+		c.Writer.Header().Set(streamErrorTrailer, "Failed to export account")
+	}
+}
+
+// RequestExport queues an export to be built in the background and answers 202
+// with the job. Poll GetExport with its ID until it is ready.
+// Method: POST /api/v1/account/export
+func (h *AccountHandler) RequestExport(c *gin.Context) {
+	user, ok := h.confirmedUser(c)
+	if !ok {
+		return
+	}
+	job, err := h.Jobs.Submit(user.ID, func(ctx context.Context, w io.Writer) error {
+		return h.writeExport(ctx, w, user)
+	})
+	if errors.Is(err, export.ErrQueueFull) {
+		c.Header("Retry-After", "60")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many exports are being prepared, try again later"})
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to queue account export for user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue export"})
+		return
+	}
+	log.Printf("INFO: Account export %s queued for user %d", job.ID, user.ID)
+	c.Header("Location", "/api/v1/account/export/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExport reports on an export job: 202 while it is being built, the file
+// once it is ready, 410 after it expired. Jobs of other users are 404.
+// Method: GET /api/v1/account/export/{id}
+func (h *AccountHandler) GetExport(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/account/export")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	job, path, err := h.Jobs.Get(c.Param("id"), int64(userID))
+	switch {
+	case errors.Is(err, export.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+	case errors.Is(err, export.ErrJobExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Export expired, request a new one"})
+	case err != nil:
+		log.Printf("ERROR: Failed to look up account export %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve export"})
+	case job.Status == export.JobReady:
+		c.FileAttachment(path, exportFilename(job.UserID))
+	case job.Status == export.JobFailed:
+		c.JSON(http.StatusOK, job)
+	default:
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// writeExport writes a one-account dump of user to w.
+func (h *AccountHandler) writeExport(ctx context.Context, w io.Writer, user *models.User) error {
+	exporter := &export.Exporter{CheckRepo: h.CheckRepo, APIKeyRepo: h.APIKeyRepo}
+	if h.Config.PingHistory > 0 {
+		exporter.Options.PingsSince = time.Now().UTC().Add(-h.Config.PingHistory)
+	}
+	dw := export.NewWriter(w)
+	if err := dw.Begin(); err != nil {
+		return err
+	}
+	if err := exporter.Account(ctx, dw, user); err != nil {
+		return err
+	}
+	return dw.End()
+}
+
+// confirmedUser loads the authenticated user and checks the PasswordHeader
+// against their password, answering 401 and returning false when it doesn't
+// match.
+func (h *AccountHandler) confirmedUser(c *gin.Context) (*models.User, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/account/export")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return nil, false
+	}
+	password := c.GetHeader(PasswordHeader)
+	if password == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": PasswordHeader + " header required to export account data"})
+		return nil, false
+	}
+
+	user, err := h.UserRepo.FindByID(c.Request.Context(), int64(userID))
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "account export", err)
+			return nil, false
+		}
+		log.Printf("ERROR: Failed to load user %d for account export: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load account"})
+		return nil, false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		log.Printf("WARN: Account export refused for user %d: password mismatch", userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		return nil, false
+	}
+	return user, true
+}
+
+func exportFilename(userID int64) string {
+	return fmt.Sprintf("bitterlink-account-%d-%s.json", userID, time.Now().UTC().Format("20060102"))
+}
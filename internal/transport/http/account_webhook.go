@@ -0,0 +1,112 @@
+package httptransport
+
+import (
+	"log"
+	"net/http"
+
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookRequest is the body of PUT /api/v1/account/webhook.
+type WebhookRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// GetWebhook returns the caller's account webhook and its delivery state, or
+// 404 if none is set.
+// Method: GET /api/v1/account/webhook
+func (h *AccountHandler) GetWebhook(c *gin.Context) {
+	userID, ok := webhookUserID(c)
+	if !ok {
+		return
+	}
+	hook, err := h.UserRepo.FindWebhook(c.Request.Context(), userID)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "webhook lookup", err)
+			return
+		}
+		log.Printf("ERROR: Failed to load webhook of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook"})
+		return
+	}
+	if hook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No webhook set"})
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// SetWebhook sets the caller's account webhook, which receives every event of
+// every check of the account (see internal/webhook). Replacing it resets its
+// failure count.
+// Method: PUT /api/v1/account/webhook
+func (h *AccountHandler) SetWebhook(c *gin.Context) {
+	userID, ok := webhookUserID(c)
+	if !ok {
+		return
+	}
+	if !h.Config.Webhooks {
+		c.JSON(http.StatusConflict, gin.H{"error": "Account webhooks are not enabled on this instance"})
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if err := forward.ValidateURL(req.URL, h.Config.WebhookSelfHosts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url: " + err.Error()})
+		return
+	}
+	if !h.saveWebhook(c, userID, req.URL) {
+		return
+	}
+	log.Printf("INFO: Account webhook set for user %d", userID)
+	c.JSON(http.StatusOK, gin.H{"url": req.URL, "failures": 0})
+}
+
+// DeleteWebhook removes the caller's account webhook. Removing none is not an
+// error.
+// Method: DELETE /api/v1/account/webhook
+func (h *AccountHandler) DeleteWebhook(c *gin.Context) {
+	userID, ok := webhookUserID(c)
+	if !ok {
+		return
+	}
+	if !h.saveWebhook(c, userID, "") {
+		return
+	}
+	log.Printf("INFO: Account webhook removed for user %d", userID)
+	c.Status(http.StatusNoContent)
+}
+
+// saveWebhook stores url ("" removes the webhook), answering the request itself
+// on failure.
+func (h *AccountHandler) saveWebhook(c *gin.Context, userID int64, url string) bool {
+	err := h.UserRepo.SetWebhook(c.Request.Context(), userID, url)
+	switch {
+	case err == nil:
+		return true
+	case isClientGone(err):
+		abortClientGone(c, "webhook update", err)
+	case abortNotFound(c, err, "Account"):
+	default:
+		log.Printf("ERROR: Failed to update webhook of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+	}
+	return false
+}
+
+func webhookUserID(c *gin.Context) (int64, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/account/webhook")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return 0, false
+	}
+	return int64(userID), true
+}
@@ -0,0 +1,186 @@
+package httptransport
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"bitterlink/core/internal/actionlink"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Action links (internal/actionlink) are opened from alerts, in a browser and
+// without an API key: the signed token is the authorization. GET only shows a
+// confirmation page, as mail scanners and link previews fetch links on their
+// own; the action is applied by the page's POST. Both answer in HTML.
+
+//go:embed templates/action.html
+var actionPageSource string
+
+// actionPage is parsed with html/template, which escapes the check name.
+var actionPage = template.Must(template.New("action").Parse(actionPageSource))
+
+// actionPageData is what actionPage shows: the confirmation form, the applied
+// mute (MutedUntil set) or why the link can't be used (Error set).
+type actionPageData struct {
+	CheckName  string
+	Durations  []actionDuration
+	MutedUntil time.Time
+	Error      string
+}
+
+// actionDuration is one choice of the mute duration select.
+type actionDuration struct {
+	Value string // time.Duration.String(), posted back as "duration"
+	Label string
+}
+
+// ActionConfig configures the action link endpoints.
+type ActionConfig struct {
+	Signer *actionlink.Signer
+}
+
+// ActionHandler serves GET and POST /actions/:token.
+type ActionHandler struct {
+	CheckRepo repository.CheckRepository
+	Config    ActionConfig
+}
+
+// NewActionHandler creates a handler for action links. cfg.Signer must be set.
+func NewActionHandler(cr repository.CheckRepository, cfg ActionConfig) *ActionHandler {
+	return &ActionHandler{CheckRepo: cr, Config: cfg}
+}
+
+// ShowAction shows the confirmation page of an action link.
+// Method: GET /actions/:token
+func (h *ActionHandler) ShowAction(c *gin.Context) {
+	token, check, ok := h.loadAction(c)
+	if !ok {
+		return
+	}
+	data := actionPageData{CheckName: check.Name}
+	if token.Action == actionlink.ActionMute {
+		for _, d := range actionlink.MuteDurations {
+			data.Durations = append(data.Durations, actionDuration{Value: d.String(), Label: muteDurationLabel(d)})
+		}
+	}
+	writeActionPage(c, http.StatusOK, data)
+}
+
+// ApplyAction applies an action link, with the duration chosen on its
+// confirmation page. The change is recorded in the check's events with source
+// models.EventSourceEmailLink.
+// Method: POST /actions/:token
+func (h *ActionHandler) ApplyAction(c *gin.Context) {
+	// 1. Verify the link and find its check
+	token, check, ok := h.loadAction(c)
+	if !ok {
+		return
+	}
+
+	// 2. Only the offered durations are accepted
+	var duration time.Duration
+	for _, d := range actionlink.MuteDurations {
+		if c.PostForm("duration") == d.String() {
+			duration = d
+		}
+	}
+	if duration == 0 {
+		writeActionPage(c, http.StatusBadRequest, actionPageData{Error: "Please choose one of the offered durations."})
+		return
+	}
+
+	// 3. Mute, using up the link when it is single use
+	req := repository.MuteRequest{
+		CheckUUID: check.UUID,
+		Until:     time.Now().UTC().Add(duration).Truncate(time.Second),
+		Source:    models.EventSourceEmailLink,
+	}
+	if actionlink.SingleUse(token.Action) {
+		req.Nonce, req.NonceExpires = token.Nonce, token.Expires
+	}
+	err := h.CheckRepo.MuteCheck(c.Request.Context(), req)
+	switch {
+	case err == nil:
+	case isClientGone(err):
+		abortClientGone(c, "action link", err)
+		return
+	case errors.Is(err, repository.ErrActionTokenUsed):
+		writeActionPage(c, http.StatusConflict, actionPageData{Error: "This link was already used. Open the latest alert for a new one."})
+		return
+	case errors.Is(err, repository.ErrCheckNotFound):
+		writeActionPage(c, http.StatusNotFound, actionPageData{Error: "This check no longer exists."})
+		return
+	default:
+		log.Printf("ERROR: ApplyAction failed to mute check %s: %v", check.UUID, err)
+		writeActionPage(c, http.StatusInternalServerError, actionPageData{Error: "The check could not be muted. Please try again."})
+		return
+	}
+	log.Printf("INFO: Check %s muted until %s from an action link", check.UUID, req.Until.Format(time.RFC3339))
+	writeActionPage(c, http.StatusOK, actionPageData{CheckName: check.Name, MutedUntil: req.Until})
+}
+
+// loadAction verifies the :token of the request and finds its check. It
+// answers with an error page and reports false when either fails.
+func (h *ActionHandler) loadAction(c *gin.Context) (actionlink.Token, *models.Check, bool) {
+	token, err := h.Config.Signer.Verify(c.Param("token"), time.Now())
+	if errors.Is(err, actionlink.ErrExpired) {
+		writeActionPage(c, http.StatusGone, actionPageData{Error: "This link has expired."})
+		return token, nil, false
+	}
+	if err != nil {
+		writeActionPage(c, http.StatusNotFound, actionPageData{Error: "This link is not valid."})
+		return token, nil, false
+	}
+	check, err := h.CheckRepo.FindByUUID(c.Request.Context(), token.CheckUUID)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "action link", err)
+			return token, nil, false
+		}
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			writeActionPage(c, http.StatusNotFound, actionPageData{Error: "This check no longer exists."})
+			return token, nil, false
+		}
+		log.Printf("ERROR: Action link lookup failed for check %s: %v", token.CheckUUID, err)
+		writeActionPage(c, http.StatusInternalServerError, actionPageData{Error: "Something went wrong. Please try again."})
+		return token, nil, false
+	}
+	return token, check, true
+}
+
+// writeActionPage renders actionPage. The token is in the URL, so the page is
+// neither cached nor allowed to leak it in a Referer.
+func writeActionPage(c *gin.Context, status int, data actionPageData) {
+	setNoCacheHeaders(c)
+	c.Header("Referrer-Policy", "no-referrer")
+	var page bytes.Buffer
+	if err := actionPage.Execute(&page, data); err != nil {
+		log.Printf("ERROR: Failed to render action page: %v", err)
+		c.String(http.StatusInternalServerError, "Something went wrong. Please try again.")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
+
+// muteDurationLabel spells out one of actionlink.MuteDurations.
+func muteDurationLabel(d time.Duration) string {
+	switch {
+	case d == time.Hour:
+		return "1 hour"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	case d == 24*time.Hour:
+		return "1 day"
+	default:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,131 @@
+package httptransport
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The global notification kill-switch, see internal/blackout. While it is on
+// the workers send no notifications for any check.
+
+// blackoutChangesLimit caps the checks listed by GetNotificationBlackout.
+const blackoutChangesLimit = 100
+
+// StartBlackoutRequest is the optional body of StartNotificationBlackout.
+type StartBlackoutRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// EndBlackoutRequest is the optional body of EndNotificationBlackout.
+type EndBlackoutRequest struct {
+	SendSummary bool `json:"send_summary"` // One summary of the status changes, sent by a worker
+}
+
+// GetNotificationBlackout reports whether the kill-switch is on and, if so,
+// the checks whose status changed since.
+// Method: GET /api/v1/admin/notification-blackout
+func (h *AdminHandler) GetNotificationBlackout(c *gin.Context) {
+	ctx := c.Request.Context()
+	b, err := h.CheckRepo.ActiveBlackout(ctx)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "notification blackout", err)
+			return
+		}
+		log.Printf("ERROR: GetNotificationBlackout failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read notification blackout"})
+		return
+	}
+	if b == nil {
+		c.JSON(http.StatusOK, gin.H{"active": false})
+		return
+	}
+	changes, err := h.CheckRepo.ListBlackoutChanges(ctx, b, blackoutChangesLimit+1)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "notification blackout", err)
+			return
+		}
+		log.Printf("ERROR: GetNotificationBlackout failed to list changes of blackout %d: %v", b.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read notification blackout"})
+		return
+	}
+	truncated := len(changes) > blackoutChangesLimit
+	if truncated {
+		changes = changes[:blackoutChangesLimit]
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"active":    true,
+		"blackout":  b,
+		"changes":   changes,
+		"truncated": truncated, // More checks changed than listed
+	})
+}
+
+// StartNotificationBlackout turns the kill-switch on. Workers notice within
+// their refresh interval. Answers 409 while it is already on.
+// Method: PUT /api/v1/admin/notification-blackout
+func (h *AdminHandler) StartNotificationBlackout(c *gin.Context) {
+	var req StartBlackoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	b, err := h.CheckRepo.StartBlackout(c.Request.Context(), int64(userID), req.Reason)
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrBlackoutActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "A notification blackout is already active", "blackout": b})
+		return
+	case isClientGone(err):
+		abortClientGone(c, "notification blackout", err)
+		return
+	default:
+		log.Printf("ERROR: StartNotificationBlackout failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start notification blackout"})
+		return
+	}
+	log.Printf("WARN: Notification blackout %d started by user %d (reason %q): no notifications will be sent", b.ID, userID, req.Reason)
+	c.JSON(http.StatusOK, gin.H{"active": true, "blackout": b})
+}
+
+// EndNotificationBlackout turns the kill-switch off, optionally asking for a
+// summary of what changed meanwhile. Answers 404 when it is not on.
+// Method: DELETE /api/v1/admin/notification-blackout
+func (h *AdminHandler) EndNotificationBlackout(c *gin.Context) {
+	var req EndBlackoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	b, err := h.CheckRepo.EndBlackout(c.Request.Context(), int64(userID), req.SendSummary)
+	switch {
+	case err == nil:
+	case errors.Is(err, repository.ErrNoBlackout):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No notification blackout is active"})
+		return
+	case isClientGone(err):
+		abortClientGone(c, "notification blackout", err)
+		return
+	default:
+		log.Printf("ERROR: EndNotificationBlackout failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end notification blackout"})
+		return
+	}
+	log.Printf("INFO: Notification blackout %d ended by user %d (summary requested: %t)", b.ID, userID, req.SendSummary)
+	c.JSON(http.StatusOK, gin.H{"active": false, "blackout": b})
+}
@@ -0,0 +1,198 @@
+package httptransport
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminConfig configures the operator endpoints.
+type AdminConfig struct {
+	// PingCountTTL is how long the pings-in-the-last-24h count is reused before
+	// the pings table is counted again.
+	PingCountTTL time.Duration
+	// OrphanBatchSize is how many orphaned pings one DELETE removes (default
+	// 1000), OrphanMaxDelete how many one cleanup request removes at most
+	// (default 100000) and OrphanBatchPause the pause between batches, to
+	// limit replication lag (default 100ms, negative for none).
+	OrphanBatchSize  int
+	OrphanMaxDelete  int
+	OrphanBatchPause time.Duration
+}
+
+// AdminHandler serves instance-wide endpoints under /api/v1/admin. Routes are
+// guarded by middleware.RequireAdminScope.
+type AdminHandler struct {
+	CheckRepo repository.CheckRepository
+	Config    AdminConfig
+
+	mu          sync.Mutex
+	pingCount   int64
+	pingCountAt time.Time // Zero until the first successful count
+}
+
+// NewAdminHandler creates a handler for the operator endpoints.
+func NewAdminHandler(cr repository.CheckRepository, cfg AdminConfig) *AdminHandler {
+	if cfg.OrphanBatchSize <= 0 {
+		cfg.OrphanBatchSize = 1000
+	}
+	if cfg.OrphanMaxDelete <= 0 {
+		cfg.OrphanMaxDelete = 100000
+	}
+	if cfg.OrphanBatchPause < 0 {
+		cfg.OrphanBatchPause = 0
+	} else if cfg.OrphanBatchPause == 0 {
+		cfg.OrphanBatchPause = 100 * time.Millisecond
+	}
+	return &AdminHandler{CheckRepo: cr, Config: cfg}
+}
+
+// GetStats reports headline numbers for the instance.
+// Method: GET /api/v1/admin/stats
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 1. The cheap counts are always live
+	totals, err := h.CheckRepo.CountInstanceTotals(ctx)
+	if err != nil {
+		log.Printf("ERROR: GetStats failed to count instance totals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats"})
+		return
+	}
+
+	// 2. The ping count is cached, see recentPingCount
+	pings, countedAt, err := h.recentPingCount(ctx)
+	if err != nil {
+		log.Printf("ERROR: GetStats failed to count recent pings: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total_checks":     totals.Checks,
+		"total_users":      totals.Users,
+		"checks_down":      totals.ChecksDown,
+		"pings_last_24h":   pings,
+		"pings_counted_at": countedAt.UTC().Format(time.RFC3339), // pings_last_24h may be up to PingCountTTL old
+	})
+}
+
+// recentPingCount returns the number of pings received in the last 24h and when
+// it was counted. The count is reused for Config.PingCountTTL. Concurrent calls
+// with a stale cache wait for one query rather than each scanning pings.
+func (h *AdminHandler) recentPingCount(ctx context.Context) (int64, time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if !h.pingCountAt.IsZero() && now.Sub(h.pingCountAt) < h.Config.PingCountTTL {
+		return h.pingCount, h.pingCountAt, nil
+	}
+	count, err := h.CheckRepo.CountPingsSince(ctx, now.UTC().Add(-24*time.Hour))
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	h.pingCount, h.pingCountAt = count, now
+	return count, now, nil
+}
+
+// orphanedPingsReportLimit caps the missing checks GetOrphanedPings lists.
+const orphanedPingsReportLimit = 100
+
+// GetOrphanedPings reports pings whose check no longer exists, not even
+// soft-deleted, per missing check ID.
+// Method: GET /api/v1/admin/orphaned-pings
+func (h *AdminHandler) GetOrphanedPings(c *gin.Context) {
+	orphans, err := h.CheckRepo.FindOrphanedPings(c.Request.Context(), orphanedPingsReportLimit+1)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "orphaned pings", err)
+			return
+		}
+		log.Printf("ERROR: GetOrphanedPings failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find orphaned pings"})
+		return
+	}
+	truncated := len(orphans) > orphanedPingsReportLimit
+	if truncated {
+		orphans = orphans[:orphanedPingsReportLimit]
+	}
+	var pings int64
+	for _, o := range orphans {
+		pings += o.Pings
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"checks":    orphans,
+		"pings":     pings,     // Of the checks listed
+		"truncated": truncated, // More missing checks than listed
+	})
+}
+
+// CleanupOrphanedPingsRequest is the optional body of CleanupOrphanedPings.
+type CleanupOrphanedPingsRequest struct {
+	Max int `json:"max"` // Pings to delete at most, capped by AdminConfig.OrphanMaxDelete
+}
+
+// CleanupOrphanedPings deletes orphaned pings (see GetOrphanedPings) in
+// batches of AdminConfig.OrphanBatchSize, until there are none left or max
+// were deleted. Run it again when max_reached is true.
+// Method: POST /api/v1/admin/orphaned-pings/cleanup
+func (h *AdminHandler) CleanupOrphanedPings(c *gin.Context) {
+	var req CleanupOrphanedPingsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+	if req.Max < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max must not be negative"})
+		return
+	}
+	maxDelete := h.Config.OrphanMaxDelete
+	if req.Max > 0 && req.Max < maxDelete {
+		maxDelete = req.Max
+	}
+
+	// Batches run to completion even if the client goes away, so the count is
+	// right in the log; the loop stops at the next batch
+	ctx := c.Request.Context()
+	batchCtx := context.WithoutCancel(ctx)
+	var deleted int64
+	batches := 0
+	for deleted < int64(maxDelete) && ctx.Err() == nil {
+		if batches > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(h.Config.OrphanBatchPause):
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		limit := min(h.Config.OrphanBatchSize, maxDelete-int(deleted))
+		n, err := h.CheckRepo.DeleteOrphanedPings(batchCtx, limit)
+		deleted += n
+		batches++
+		if err != nil {
+			log.Printf("ERROR: CleanupOrphanedPings failed in batch %d, after deleting %d pings: %v", batches, deleted, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete orphaned pings", "deleted": deleted})
+			return
+		}
+		if n < int64(limit) {
+			break
+		}
+	}
+	log.Printf("INFO: CleanupOrphanedPings deleted %d orphaned pings in %d batches", deleted, batches)
+	c.JSON(http.StatusOK, gin.H{
+		"deleted":     deleted,
+		"batches":     batches,
+		"max_reached": deleted >= int64(maxDelete),
+	})
+}
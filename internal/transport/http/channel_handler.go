@@ -0,0 +1,269 @@
+package httptransport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"log"
+	"maps"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Notification channels only get alerts once verified, so a typo in an address
+// or URL shows up at once instead of swallowing the first outage's alerts.
+// Email channels are verified by the link in a confirmation message, URL
+// channels by a test delivery answered with a 2xx. POST
+// /notification-channels/{id}/verify starts either, and can be repeated
+// (resend or re-test) once per ChannelConfig.VerifyInterval.
+
+// ChannelTester verifies a URL channel, given its value, with a test delivery.
+type ChannelTester interface {
+	TestChannel(ctx context.Context, value string) error
+}
+
+// ChannelMailer sends the confirmation message of an email channel, with the
+// link that verifies it.
+type ChannelMailer interface {
+	SendChannelConfirmation(ctx context.Context, to, link string) error
+}
+
+//go:embed templates/channel_confirm.html
+var channelConfirmPageSource string
+
+var channelConfirmPage = template.Must(template.New("channel_confirm").Parse(channelConfirmPageSource))
+
+// ChannelConfig configures the channel endpoints. Zero values get the defaults
+// noted.
+type ChannelConfig struct {
+	Testers map[string]ChannelTester // By channel type (models.Channel*)
+	// Mailer sends email confirmations; nil when this instance sends no email,
+	// and email channels can't be verified
+	Mailer         ChannelMailer
+	PublicBaseURL  string        // Confirmation links point here
+	VerifyInterval time.Duration // Between verification attempts of a channel, default 1m
+	ConfirmTTL     time.Duration // How long a confirmation link works, default 24h
+	// SelfHosts are this instance's own host names, which URL channels may not
+	// point at (see forward.ValidateURL)
+	SelfHosts []string
+	// EnabledTypes are the types channels may be created with on this instance
+	// (NOTIFY_CHANNEL_TYPES); nil allows all of models.ChannelTypes
+	EnabledTypes []string
+}
+
+// typeEnabled reports whether new channels of channelType are allowed here.
+func (cfg ChannelConfig) typeEnabled(channelType string) bool {
+	return cfg.EnabledTypes == nil || slices.Contains(cfg.EnabledTypes, channelType)
+}
+
+// ChannelHandler serves the notification channel endpoints.
+type ChannelHandler struct {
+	ChannelRepo repository.ChannelRepository
+	Config      ChannelConfig
+}
+
+// NewChannelHandler creates a handler for the notification channel endpoints.
+func NewChannelHandler(chr repository.ChannelRepository, cfg ChannelConfig) *ChannelHandler {
+	if cfg.VerifyInterval <= 0 {
+		cfg.VerifyInterval = time.Minute
+	}
+	if cfg.ConfirmTTL <= 0 {
+		cfg.ConfirmTTL = 24 * time.Hour
+	}
+	cfg.PublicBaseURL = strings.TrimRight(cfg.PublicBaseURL, "/")
+	return &ChannelHandler{ChannelRepo: chr, Config: cfg}
+}
+
+// channelResponse is a channel in API responses.
+type channelResponse struct {
+	models.NotificationChannel
+	Verification string `json:"verification"` // models.ChannelVerified or models.ChannelPendingVerification
+}
+
+func newChannelResponse(ch models.NotificationChannel) channelResponse {
+	// Alertmanager URLs may carry basic-auth credentials
+	if u, err := url.Parse(ch.Value); err == nil && u.User != nil {
+		ch.Value = u.Redacted()
+		if key := models.ChannelDestinationKey(ch.Type); ch.Config[key] != "" {
+			ch.Config = maps.Clone(ch.Config)
+			ch.Config[key] = ch.Value
+		}
+	}
+	return channelResponse{NotificationChannel: ch, Verification: ch.VerificationState()}
+}
+
+// GetChannels lists the caller's notification channels with their
+// verification state.
+// Method: GET /api/v1/notification-channels
+func (h *ChannelHandler) GetChannels(c *gin.Context) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Printf("ERROR: UserID not found in context for protected route %s", c.FullPath())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	channels, err := h.ChannelRepo.ListChannels(c.Request.Context(), userID)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "channels", err)
+			return
+		}
+		log.Printf("ERROR: GetChannels repository call failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve channels"})
+		return
+	}
+	response := make([]channelResponse, len(channels))
+	for i, ch := range channels {
+		response[i] = newChannelResponse(ch)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyChannel (re)sends an email channel's confirmation message, or
+// (re)tests a URL channel, which is verified if the test delivery succeeds. A
+// failed re-test of a verified channel leaves it verified.
+// Method: POST /api/v1/notification-channels/:id/verify
+func (h *ChannelHandler) VerifyChannel(c *gin.Context) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Printf("ERROR: UserID not found in context for protected route %s", c.FullPath())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	channelID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || channelID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return
+	}
+
+	// 1. Record the attempt, which is rate limited per channel
+	token, err := newConfirmToken()
+	if err != nil {
+		log.Printf("ERROR: VerifyChannel failed to generate a token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify channel"})
+		return
+	}
+	ctx := c.Request.Context()
+	ch, wait, err := h.ChannelRepo.BeginChannelVerification(ctx, userID, channelID, token, h.Config.VerifyInterval)
+	switch {
+	case err == nil:
+	case isClientGone(err):
+		abortClientGone(c, "channel verification", err)
+		return
+	case errors.Is(err, repository.ErrVerificationTooSoon):
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Verification was attempted too recently", "retry_after": retryAfter})
+		return
+	case abortNotFound(c, err, "Channel"):
+		return
+	default:
+		log.Printf("ERROR: VerifyChannel repository call failed for channel %d: %v", channelID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify channel"})
+		return
+	}
+
+	// 2. Email channels get a confirmation link
+	if ch.Type == models.ChannelEmail {
+		if h.Config.Mailer == nil {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": "This instance sends no email, so email channels can't be verified"})
+			return
+		}
+		link := h.Config.PublicBaseURL + "/channels/confirm/" + strconv.FormatInt(ch.ID, 10) + "/" + token
+		if err := h.Config.Mailer.SendChannelConfirmation(ctx, ch.Value, link); err != nil {
+			log.Printf("WARN: Sending the confirmation of channel %d failed: %v", ch.ID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send the confirmation message", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"id": ch.ID, "verification": ch.VerificationState(), "message": "Confirmation message sent"})
+		return
+	}
+
+	// 3. URL channels get a test delivery
+	tester := h.Config.Testers[ch.Type]
+	if tester == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Channels of type " + ch.Type + " can't be verified on this instance"})
+		return
+	}
+	if err := tester.TestChannel(ctx, ch.Value); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":        "Test delivery failed",
+			"details":      err.Error(),
+			"id":           ch.ID,
+			"verification": ch.VerificationState(),
+		})
+		return
+	}
+	if !ch.IsVerified {
+		if err := h.ChannelRepo.SetChannelVerified(ctx, ch.ID); err != nil {
+			log.Printf("ERROR: VerifyChannel failed to mark channel %d verified: %v", ch.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify channel"})
+			return
+		}
+		log.Printf("INFO: Channel %d of user %d verified by a test delivery", ch.ID, userID)
+	}
+	c.JSON(http.StatusOK, gin.H{"id": ch.ID, "verification": models.ChannelVerified})
+}
+
+// ConfirmChannel verifies an email channel from the link in its confirmation
+// message. It needs no API key: the token is the proof.
+// Method: GET /channels/confirm/:id/:token
+func (h *ChannelHandler) ConfirmChannel(c *gin.Context) {
+	status := http.StatusOK
+	err := repository.ErrVerificationInvalid
+	channelID, parseErr := strconv.ParseInt(c.Param("id"), 10, 64)
+	if parseErr == nil && channelID > 0 {
+		err = h.ChannelRepo.ConfirmChannel(c.Request.Context(), channelID, c.Param("token"), h.Config.ConfirmTTL)
+	}
+	switch {
+	case err == nil:
+		log.Printf("INFO: Channel %d verified by its confirmation link", channelID)
+	case isClientGone(err):
+		abortClientGone(c, "channel confirmation", err)
+		return
+	case errors.Is(err, repository.ErrVerificationInvalid):
+		status = http.StatusNotFound
+	default:
+		log.Printf("ERROR: ConfirmChannel failed for channel %d: %v", channelID, err)
+		c.String(http.StatusInternalServerError, "Something went wrong. Please try again.")
+		return
+	}
+
+	setNoCacheHeaders(c)
+	c.Header("Referrer-Policy", "no-referrer")
+	var page bytes.Buffer
+	if err := channelConfirmPage.Execute(&page, gin.H{"Confirmed": status == http.StatusOK}); err != nil {
+		log.Printf("ERROR: Failed to render channel confirmation page: %v", err)
+		c.String(http.StatusInternalServerError, "Something went wrong. Please try again.")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
+
+// newConfirmToken returns the secret of a confirmation link.
+func newConfirmToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,278 @@
+package httptransport
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Channel management: create, read, update and delete an account's
+// notification channels, under /api/v1/notification-channels (and the older
+// /api/v1/channels). A new channel, and one whose destination changes, starts
+// out pending verification and gets no alerts until POST
+// /notification-channels/{id}/verify succeeds (see channel_handler.go).
+
+// Limits on channel fields. value is a VARCHAR(1024), see migration 0022.
+const (
+	maxChannelValueLength = 1024
+	maxChannelLabelLength = 100 // In characters (runes)
+)
+
+// CreateChannelRequest is the body of POST /api/v1/notification-channels.
+type CreateChannelRequest struct {
+	Type      string               `json:"type" binding:"required"`   // One of models.ChannelTypes
+	Config    models.ChannelConfig `json:"config" binding:"required"` // {"address": ...}, {"url": ...} or {"webhook_url": ...} by type
+	Label     *string              `json:"label"`
+	IsEnabled *bool                `json:"is_enabled"` // Omitted = true
+}
+
+// UpdateChannelRequest is the body of PATCH /api/v1/notification-channels/{id}.
+// Omitted fields keep their current value; the type can't change.
+type UpdateChannelRequest struct {
+	Config    *models.ChannelConfig `json:"config"` // Replaces the whole config; a new destination resets verification
+	Label     *string               `json:"label"`  // "" removes it
+	IsEnabled *bool                 `json:"is_enabled"`
+}
+
+// validateChannelConfig returns what is wrong with config for a channel of
+// channelType, or "". It must hold the type's destination key and nothing else.
+func (h *ChannelHandler) validateChannelConfig(channelType string, config models.ChannelConfig) string {
+	key := models.ChannelDestinationKey(channelType)
+	if key == "" {
+		return fmt.Sprintf("type must be one of %s", strings.Join(models.ChannelTypes, ", "))
+	}
+	for k := range config {
+		if k != key {
+			return fmt.Sprintf("config of %s channels takes only %q, not %q", channelType, key, k)
+		}
+	}
+	value := config[key]
+	if value == "" {
+		return fmt.Sprintf("config.%s is required", key)
+	}
+	if msg := h.validateChannelValue(channelType, value); msg != "" {
+		return "config." + key + ": " + msg
+	}
+	return ""
+}
+
+// validateChannelValue returns what is wrong with a destination for a channel
+// of channelType, or "". URLs are held to the rules of forward URLs, so a
+// channel can't point at this instance.
+func (h *ChannelHandler) validateChannelValue(channelType, value string) string {
+	if len(value) > maxChannelValueLength {
+		return fmt.Sprintf("must not exceed %d bytes", maxChannelValueLength)
+	}
+	switch channelType {
+	case models.ChannelEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return "must be a bare email address"
+		}
+	case models.ChannelWebhook, models.ChannelSlack, models.ChannelAlertmanager:
+		if err := forward.ValidateURL(value, h.Config.SelfHosts); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// channelLabelField turns an optional label into its column value: NULL when
+// empty. msg is set when it is too long.
+func channelLabelField(label *string) (value sql.NullString, msg string) {
+	if label == nil || *label == "" {
+		return value, ""
+	}
+	if utf8.RuneCountInString(*label) > maxChannelLabelLength {
+		return value, fmt.Sprintf("label must not exceed %d characters", maxChannelLabelLength)
+	}
+	return sql.NullString{String: *label, Valid: true}, ""
+}
+
+// CreateChannel adds a notification channel to the caller's account, pending
+// verification.
+// Method: POST /api/v1/notification-channels
+func (h *ChannelHandler) CreateChannel(c *gin.Context) {
+	var req CreateChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if !models.IsValidChannelType(req.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("type must be one of %s", strings.Join(models.ChannelTypes, ", "))})
+		return
+	}
+	if !h.Config.typeEnabled(req.Type) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Channels of type %s are disabled on this instance", req.Type)})
+		return
+	}
+	if msg := h.validateChannelConfig(req.Type, req.Config); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	label, msg := channelLabelField(req.Label)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	userID, ok := channelUserID(c)
+	if !ok {
+		return
+	}
+
+	ch := models.NotificationChannel{UserID: userID, Type: req.Type, Value: req.Config.Destination(req.Type), Config: req.Config, Label: label, IsEnabled: true}
+	if req.IsEnabled != nil {
+		ch.IsEnabled = *req.IsEnabled
+	}
+	if err := h.ChannelRepo.CreateChannel(c.Request.Context(), &ch); err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "CreateChannel", err)
+			return
+		}
+		log.Printf("ERROR: CreateChannel failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create channel"})
+		return
+	}
+	setCreatedLocation(c, strconv.FormatInt(ch.ID, 10))
+	c.JSON(http.StatusCreated, newChannelResponse(ch))
+}
+
+// GetChannel returns one of the caller's channels.
+// Method: GET /api/v1/notification-channels/:id
+func (h *ChannelHandler) GetChannel(c *gin.Context) {
+	ch, ok := h.loadOwnChannel(c, "GetChannel")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newChannelResponse(*ch))
+}
+
+// UpdateChannel changes the config, label or is_enabled of one of the caller's
+// channels. A new destination must be verified again before it gets alerts.
+// Method: PATCH /api/v1/notification-channels/:id
+func (h *ChannelHandler) UpdateChannel(c *gin.Context) {
+	var req UpdateChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	ch, ok := h.loadOwnChannel(c, "UpdateChannel")
+	if !ok {
+		return
+	}
+
+	// 1. Apply the provided fields
+	if req.Config != nil {
+		if msg := h.validateChannelConfig(ch.Type, *req.Config); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		ch.Config, ch.Value = *req.Config, req.Config.Destination(ch.Type)
+	}
+	if req.Label != nil {
+		label, msg := channelLabelField(req.Label)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		ch.Label = label
+	}
+	if req.IsEnabled != nil {
+		ch.IsEnabled = *req.IsEnabled
+	}
+
+	// 2. Save
+	err := h.ChannelRepo.UpdateChannel(c.Request.Context(), ch)
+	switch {
+	case err == nil:
+	case isClientGone(err):
+		abortClientGone(c, "UpdateChannel", err)
+		return
+	case abortNotFound(c, err, "Channel"):
+		return
+	default:
+		log.Printf("ERROR: UpdateChannel failed for channel %d: %v", ch.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update channel"})
+		return
+	}
+	c.JSON(http.StatusOK, newChannelResponse(*ch))
+}
+
+// DeleteChannel removes one of the caller's channels; the checks it was
+// attached to stop alerting through it. A second delete answers 404.
+// Method: DELETE /api/v1/notification-channels/:id
+func (h *ChannelHandler) DeleteChannel(c *gin.Context) {
+	userID, ok := channelUserID(c)
+	if !ok {
+		return
+	}
+	channelID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || channelID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return
+	}
+	err = h.ChannelRepo.DeleteChannel(c.Request.Context(), userID, channelID)
+	switch {
+	case err == nil:
+	case isClientGone(err):
+		abortClientGone(c, "DeleteChannel", err)
+		return
+	case abortNotFound(c, err, "Channel"):
+		return
+	default:
+		log.Printf("ERROR: DeleteChannel failed for channel %d: %v", channelID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete channel"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadOwnChannel loads the caller's channel named by :id, answering 400 or 404
+// (also for other users' channels) and returning ok=false when the handler
+// should stop.
+func (h *ChannelHandler) loadOwnChannel(c *gin.Context, caller string) (*models.NotificationChannel, bool) {
+	userID, ok := channelUserID(c)
+	if !ok {
+		return nil, false
+	}
+	channelID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || channelID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel ID"})
+		return nil, false
+	}
+	ch, err := h.ChannelRepo.FindChannel(c.Request.Context(), userID, channelID)
+	switch {
+	case err == nil:
+		return ch, true
+	case isClientGone(err):
+		abortClientGone(c, caller, err)
+	case abortNotFound(c, err, "Channel"):
+	default:
+		log.Printf("ERROR: %s failed to load channel %d: %v", caller, channelID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve channel"})
+	}
+	return nil, false
+}
+
+// channelUserID returns the authenticated user, answering 500 when the auth
+// middleware left none.
+func channelUserID(c *gin.Context) (int64, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Printf("ERROR: UserID not found in context for protected route %s", c.FullPath())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return 0, false
+	}
+	return int64(userID), true
+}
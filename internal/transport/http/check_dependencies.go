@@ -0,0 +1,140 @@
+package httptransport
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check dependencies. While a check's dependency is down the worker suppresses
+// the check's own notifications (see worker.Config.SuppressDependents), so one
+// failing host doesn't page once for every job on it. As on every /checks/:id
+// route, checks are named by their numeric ID.
+
+// AddDependencyRequest is the body of POST /api/v1/checks/{id}/dependencies.
+type AddDependencyRequest struct {
+	DependsOn int64 `json:"depends_on" binding:"required"` // ID of the check depended on
+}
+
+// GetCheckDependencies lists the checks one of the caller's checks depends on.
+// Method: GET /api/v1/checks/{id}/dependencies
+func (h *CheckHandler) GetCheckDependencies(c *gin.Context) {
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "GetCheckDependencies")
+	if !ok {
+		return
+	}
+	dependencies, err := h.CheckRepo.ListDependencies(c.Request.Context(), check.ID)
+	if err != nil {
+		log.Printf("ERROR: GetCheckDependencies repository call failed for check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dependencies"})
+		return
+	}
+	c.JSON(http.StatusOK, dependencies)
+}
+
+// AddCheckDependency makes one of the caller's checks depend on another of
+// theirs. A dependency that would close a cycle is refused with 409.
+// Method: POST /api/v1/checks/{id}/dependencies
+func (h *CheckHandler) AddCheckDependency(c *gin.Context) {
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "AddCheckDependency")
+	if !ok {
+		return
+	}
+	dependency, ok := h.loadOwnCheckID(c, req.DependsOn, "AddCheckDependency")
+	if !ok {
+		return
+	}
+
+	err := h.CheckRepo.AddDependency(c.Request.Context(), check.UserID, check.ID, dependency.ID)
+	switch {
+	case errors.Is(err, repository.ErrDependencyCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": "Dependency would create a cycle"})
+		return
+	case abortNotFound(c, err, "Check"):
+		return
+	case err != nil:
+		log.Printf("ERROR: AddCheckDependency failed for check %d on %d: %v", check.ID, dependency.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add dependency"})
+		return
+	}
+	log.Printf("INFO: Check ID %d now depends on check ID %d", check.ID, dependency.ID)
+	c.JSON(http.StatusCreated, gin.H{"check": check.ID, "depends_on": dependency.ID})
+}
+
+// RemoveCheckDependency removes a dependency of one of the caller's checks.
+// Method: DELETE /api/v1/checks/{id}/dependencies/{dependency id}
+func (h *CheckHandler) RemoveCheckDependency(c *gin.Context) {
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "RemoveCheckDependency")
+	if !ok {
+		return
+	}
+	dependency, ok := h.loadOwnCheck(c, c.Param("dependency"), "RemoveCheckDependency")
+	if !ok {
+		return
+	}
+	removed, err := h.CheckRepo.RemoveDependency(c.Request.Context(), check.ID, dependency.ID)
+	if err != nil {
+		log.Printf("ERROR: RemoveCheckDependency failed for check %d on %d: %v", check.ID, dependency.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove dependency"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadOwnCheck loads the caller's check whose numeric ID is rawID, usually a
+// route parameter. Otherwise it writes the error response (400 for an ID that
+// isn't one, 404 for a missing check, 403 for another user's) and returns false.
+func (h *CheckHandler) loadOwnCheck(c *gin.Context, rawID, caller string) (*models.Check, bool) {
+	checkID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || checkID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return nil, false
+	}
+	return h.loadOwnCheckID(c, checkID, caller)
+}
+
+// loadOwnCheckID is loadOwnCheck for an ID that is already parsed.
+func (h *CheckHandler) loadOwnCheckID(c *gin.Context, checkID int64, caller string) (*models.Check, bool) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Printf("ERROR: UserID not found in context for protected route %s", c.FullPath())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return nil, false
+	}
+	userID := int64(userIDtmp)
+
+	check, err := h.CheckRepo.FindByID(c.Request.Context(), checkID)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, caller, err)
+			return nil, false
+		}
+		if abortNotFound(c, err, "Check") {
+			return nil, false
+		}
+		log.Printf("ERROR: %s failed to load check for user %d: %v", caller, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve check"})
+		return nil, false
+	}
+	if check.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Check belongs to another user"})
+		return nil, false
+	}
+	return check, true
+}
@@ -1,14 +1,22 @@
 package httptransport
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"bitterlink/core/internal/agency"
+	"bitterlink/core/internal/forward"
 	"bitterlink/core/internal/middleware"
 	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/pingsig"
 	"bitterlink/core/internal/repository"
 
 	"github.com/gin-gonic/gin"
@@ -16,22 +24,188 @@ import (
 )
 
 type CreateCheckRequest struct {
-	Name             string  `json:"name" binding:"required"`                   // Use Gin binding tags for validation
-	Description      *string `json:"description"`                               // Pointer handles null/omitted vs ""
-	ExpectedInterval uint32  `json:"expected_interval" binding:"required,gt=0"` // required, greater than 0
-	GracePeriod      *uint32 `json:"grace_period"`                              // Pointer handles null/omitted vs 0
-	IsEnabled        *bool   `json:"is_enabled"`                                // Pointer handles null/omitted vs false
-	Status           *string `json:"status"`                                    // Optional override for initial status
+	Name                  string               `json:"name" binding:"required"`                   // Use Gin binding tags for validation
+	Description           *string              `json:"description"`                               // Pointer handles null/omitted vs ""
+	ExpectedInterval      DurationSeconds      `json:"expected_interval" binding:"required,gt=0"` // required, greater than 0; seconds, "24h" or "P1D"
+	GracePeriod           *DurationSeconds     `json:"grace_period"`                              // Pointer handles null/omitted vs 0
+	IsEnabled             *bool                `json:"is_enabled"`                                // Pointer handles null/omitted vs false
+	Status                *string              `json:"status"`                                    // Optional override for initial status
+	NotifyLate            bool                 `json:"notify_late"`                               // Opt in to grace-period warnings
+	Severity              *string              `json:"severity"`                                  // One of models.Severities, omitted = warning
+	RecoveryStabilization uint32               `json:"recovery_stabilization"`                    // Seconds up before the recovery notification, 0 = immediate
+	Color                 *string              `json:"color"`                                     // Dashboard color, #rgb or #rrggbb
+	Icon                  *string              `json:"icon"`                                      // Dashboard icon, one of models.CheckIcons
+	Metadata              models.CheckMetadata `json:"metadata"`                                  // Flat string map for the owner's tooling, see models.CheckMetadata
+	ForwardURL            *string              `json:"forward_url"`                               // Mirror pings to this URL, see internal/forward
+	PingsHistoryLimit     *uint32              `json:"pings_history_limit"`                       // Keep only this many newest pings, omitted = no per-check limit
+	RequireSignedPings    bool                 `json:"require_signed_pings"`                      // Refuse unsigned pings, needs PING_SIGNING_SECRET
+	PingResponseCode      *int                 `json:"ping_response_code"`                        // Status for successful pings, one of models.PingResponseCodes
+	PingResponseBody      *string              `json:"ping_response_body"`                        // Plain-text body for successful pings, omitted = {"status":"ok"}
+	MaxDuration           *uint32              `json:"max_duration"`                              // Seconds from start to success ping before a run counts as slow, omitted = no limit
+	WarmupPings           *uint32              `json:"warmup_pings"`                              // Successful pings in a row before going 'up', omitted = 1
+}
+
+// UpdateCheckRequest is the body of PATCH /api/v1/checks/{id}. Omitted
+// fields keep their current value.
+type UpdateCheckRequest struct {
+	Name              *string               `json:"name"`
+	Description       *string               `json:"description"`
+	ExpectedInterval  *DurationSeconds      `json:"expected_interval"` // Seconds, or a string as in CreateCheckRequest
+	GracePeriod       *DurationSeconds      `json:"grace_period"`
+	IsEnabled         *bool                 `json:"is_enabled"` // false stops monitoring, recorded as an event
+	Status            *string               `json:"status"`     // 'paused', or 'new' to start over, as in CreateCheckRequest
+	NotifyLate        *bool                 `json:"notify_late"`
+	Severity          *string               `json:"severity"`            // One of models.Severities
+	Metadata          *models.CheckMetadata `json:"metadata"`            // Replaces the whole map, {} removes it
+	MaxDuration       *uint32               `json:"max_duration"`        // 0 removes the limit
+	WarmupPings       *uint32               `json:"warmup_pings"`        // Applies while the check is 'new'
+	Color             Nullable[string]      `json:"color"`               // As in CreateCheckRequest, null removes it
+	Icon              Nullable[string]      `json:"icon"`                // As in CreateCheckRequest, null removes it
+	PingsHistoryLimit Nullable[uint32]      `json:"pings_history_limit"` // As in CreateCheckRequest, null removes the limit
+	PingResponseCode  Nullable[int]         `json:"ping_response_code"`  // As in CreateCheckRequest, null restores 200
+	PingResponseBody  Nullable[string]      `json:"ping_response_body"`  // As in CreateCheckRequest, null restores {"status":"ok"}
+	// RecoveryStabilization is bounded as in CreateCheckRequest, 0 = immediate
+	RecoveryStabilization *uint32 `json:"recovery_stabilization"`
+}
+
+// createCheckResponse is a created check plus, when ping signing is configured,
+// a signed ping URL for it. The signature can't be recovered later, only reissued.
+type createCheckResponse struct {
+	models.Check
+	SignedPingURL          string     `json:"signed_ping_url,omitempty"`
+	SignedPingURLExpiresAt *time.Time `json:"signed_ping_url_expires_at,omitempty"`
+}
+
+// CheckConfig holds instance-wide limits applied to check create/update requests.
+type CheckConfig struct {
+	// Bounds limits expected_interval and grace_period, see models.TimingBounds.
+	// Its MaxExpectedInterval also caps recovery_stabilization.
+	Bounds models.TimingBounds
+	// StreamListThreshold is the number of checks above which GET /checks streams
+	// its response instead of building it in memory. 0 disables streaming.
+	StreamListThreshold int
+	// ForwardSelfHosts are this instance's own host names, which forward_url may
+	// not point at (see forward.ValidateURL).
+	ForwardSelfHosts []string
+	// Descriptions limits and sanitizes check descriptions.
+	Descriptions models.DescriptionRules
+	// Signer, when set, lets checks require signed pings, and create responses
+	// include a ping URL under PublicBaseURL signed for SignedURLTTL.
+	Signer        *pingsig.Signer
+	SignedURLTTL  time.Duration
+	PublicBaseURL string
+	// MaxBulkChecks is the most checks one bulk action may change. A filter
+	// matching more is refused; 0 means no limit.
+	MaxBulkChecks int
+	// XMLResponses lets GET /checks and GET /checks/{id} answer in XML when the
+	// Accept header asks for it (see wantsXML). Off, they are JSON only.
+	XMLResponses bool
+	// MetricsMaxChecks caps how many checks GET /metrics/checks exports per
+	// scrape, to bound the series count. Defaults to 1000.
+	MetricsMaxChecks int
+	// Evaluator, when set, re-evaluates a check straight after its deadlines
+	// were shortened. Without it (ROLE=api) the worker's next tick does.
+	Evaluator CheckEvaluator
+}
+
+// CheckEvaluator moves a single check on to late or down if it is past its
+// deadline, notifying as the worker would. *worker.TimeoutChecker implements it.
+type CheckEvaluator interface {
+	EvaluateCheck(ctx context.Context, checkID int64) error
 }
 
 type CheckHandler struct {
 	CheckRepo repository.CheckRepository
+	Config    CheckConfig
 }
 
 // NewCheckHandler creates a new CheckHandler with necessary dependencies.
 // >>> Add this constructor function <<<
-func NewCheckHandler(cr repository.CheckRepository) *CheckHandler {
-	return &CheckHandler{CheckRepo: cr}
+func NewCheckHandler(cr repository.CheckRepository, cfg CheckConfig) *CheckHandler {
+	if cfg.MetricsMaxChecks <= 0 {
+		cfg.MetricsMaxChecks = 1000
+	}
+	return &CheckHandler{CheckRepo: cr, Config: cfg}
+}
+
+// presentationFields validates the dashboard-only color and icon of a create or
+// update request. Omitted fields stay NULL. msg is a client-facing message, or ""
+// when both are acceptable.
+func presentationFields(color, icon *string) (colorValue, iconValue sql.NullString, msg string) {
+	if color != nil {
+		normalized, ok := models.NormalizeCheckColor(*color)
+		if !ok {
+			return colorValue, iconValue, "color must be a hex code like #1e90ff"
+		}
+		colorValue = sql.NullString{String: normalized, Valid: true}
+	}
+	if icon != nil {
+		if !models.IsValidCheckIcon(*icon) {
+			return colorValue, iconValue, fmt.Sprintf("icon must be one of: %s", strings.Join(models.CheckIcons, ", "))
+		}
+		iconValue = sql.NullString{String: *icon, Valid: true}
+	}
+	return colorValue, iconValue, ""
+}
+
+// descriptionField cleans an optional description with rules. An omitted (nil)
+// description stays NULL. msg is a client-facing message, or "" when the
+// description is acceptable.
+func descriptionField(rules models.DescriptionRules, description *string) (value sql.NullString, msg string) {
+	if description == nil {
+		return value, ""
+	}
+	cleaned, ok := rules.Clean(*description)
+	if !ok {
+		return value, fmt.Sprintf("description must not exceed %d characters", rules.MaxLength)
+	}
+	return sql.NullString{String: cleaned, Valid: true}, ""
+}
+
+// historyLimitField checks an optional pings_history_limit against
+// models.MinPingsHistoryLimit and MaxPingsHistoryLimit. nil means no limit.
+func historyLimitField(limit *uint32) (value sql.NullInt32, msg string) {
+	if limit == nil {
+		return value, ""
+	}
+	if *limit < models.MinPingsHistoryLimit || *limit > models.MaxPingsHistoryLimit {
+		return value, fmt.Sprintf("pings_history_limit must be between %d and %d", models.MinPingsHistoryLimit, models.MaxPingsHistoryLimit)
+	}
+	return sql.NullInt32{Int32: int32(*limit), Valid: true}, ""
+}
+
+// pingResponseFields validates the optional custom response to successful
+// pings. Omitted fields stay NULL, i.e. the default 200 {"status":"ok"}. A 204
+// can't carry a body. msg is a client-facing message, or "" when both are
+// acceptable.
+func pingResponseFields(code *int, body *string) (codeValue sql.NullInt32, bodyValue sql.NullString, msg string) {
+	if code != nil {
+		if !models.IsValidPingResponseCode(*code) {
+			return codeValue, bodyValue, fmt.Sprintf("ping_response_code must be one of: %s", strings.Trim(fmt.Sprint(models.PingResponseCodes), "[]"))
+		}
+		codeValue = sql.NullInt32{Int32: int32(*code), Valid: true}
+	}
+	if body != nil {
+		if !models.IsPlainTextPingResponse(*body) {
+			return codeValue, bodyValue, fmt.Sprintf("ping_response_body must be plain text without control characters, at most %d characters", models.MaxPingResponseBodyLength)
+		}
+		if codeValue.Int32 == http.StatusNoContent {
+			return codeValue, bodyValue, "ping_response_body can't be used with ping_response_code 204"
+		}
+		bodyValue = sql.NullString{String: *body, Valid: true}
+	}
+	return codeValue, bodyValue, ""
+}
+
+// abortFieldErrors answers 400 for a failed models.Check.Validate, with the
+// per-field messages under "fields".
+func abortFieldErrors(c *gin.Context, err error) {
+	var fieldErrs models.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": fieldErrs})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 }
 
 func (h *CheckHandler) CreateCheck(c *gin.Context) {
@@ -44,6 +218,46 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		return
 	}
 
+	// Same bound as the interval: a longer window would effectively never notify
+	if maxInterval := h.Config.Bounds.MaxExpectedInterval; maxInterval > 0 && req.RecoveryStabilization > maxInterval {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("recovery_stabilization must not exceed %d seconds on this instance", maxInterval),
+		})
+		return
+	}
+	color, icon, msg := presentationFields(req.Color, req.Icon)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	description, msg := descriptionField(h.Config.Descriptions, req.Description)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	historyLimit, msg := historyLimitField(req.PingsHistoryLimit)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	pingResponseCode, pingResponseBody, msg := pingResponseFields(req.PingResponseCode, req.PingResponseBody)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if req.RequireSignedPings && h.Config.Signer == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "require_signed_pings needs ping signing, which is not configured on this instance"})
+		return
+	}
+	var forwardURL sql.NullString
+	if req.ForwardURL != nil && *req.ForwardURL != "" {
+		if err := forward.ValidateURL(*req.ForwardURL, h.Config.ForwardSelfHosts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "forward_url: " + err.Error()})
+			return
+		}
+		forwardURL = sql.NullString{String: *req.ForwardURL, Valid: true}
+	}
+
 	// 2. Get User ID (from auth middleware context)
 	userIDtmp, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
@@ -61,38 +275,73 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 		UserID:           userID,
 		UUID:             uuid.NewString(), // Generate UUID here
 		Name:             req.Name,         // Directly assign required fields
-		ExpectedInterval: req.ExpectedInterval,
+		Description:      description,      // NULL when omitted
+		ExpectedInterval: uint32(req.ExpectedInterval),
 		// Set defaults for optional/nullable fields first
-		IsEnabled: true,  // Default to enabled
-		Status:    "new", // Default to new status
+		IsEnabled:             true,             // Default to enabled
+		Status:                models.StatusNew, // Default to new status
+		NotifyLate:            req.NotifyLate,
+		Severity:              models.SeverityWarning,
+		RecoveryStabilization: req.RecoveryStabilization,
+		Color:                 color,
+		Icon:                  icon,
+		Metadata:              req.Metadata, // Checked by Validate
+		ForwardURL:            forwardURL,
+		PingsHistoryLimit:     historyLimit,
+		RequireSignedPings:    req.RequireSignedPings,
+		PingResponseCode:      pingResponseCode,
+		PingResponseBody:      pingResponseBody,
+		WarmupPings:           1,
 	}
 
 	// Populate optional fields from request if they were provided
-	if req.Description != nil {
-		newCheck.Description = sql.NullString{String: *req.Description, Valid: true}
-	} // Otherwise, Description remains sql.NullString{Valid: false} (NULL)
-
 	if req.GracePeriod != nil {
-		newCheck.GracePeriod = *req.GracePeriod
+		newCheck.GracePeriod = uint32(*req.GracePeriod)
 	} // Otherwise, GracePeriod remains 0
 
 	if req.IsEnabled != nil {
 		newCheck.IsEnabled = *req.IsEnabled // Override default if provided
 	}
+	if req.MaxDuration != nil {
+		// Clamped rather than wrapped, so Validate sees huge values as too large
+		newCheck.MaxDuration = sql.NullInt32{Int32: int32(min(*req.MaxDuration, math.MaxInt32)), Valid: true}
+	}
+	if req.WarmupPings != nil {
+		newCheck.WarmupPings = max(*req.WarmupPings, 1)
+	}
+	if req.Severity != nil {
+		if *req.Severity == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "severity must not be empty"})
+			return
+		}
+		newCheck.Severity = *req.Severity // Checked by Validate
+	}
 	if req.Status != nil {
-		// TODO: Add validation here if you only allow specific status values initially
+		// A check can only start out 'new' or 'paused'; 'up'/'down' are earned via pings and the worker
+		if *req.Status != models.StatusNew && *req.Status != models.StatusPaused {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'new' or 'paused' when creating a check"})
+			return
+		}
 		newCheck.Status = *req.Status // Override default if provided
 	}
+	if err := newCheck.Validate(h.Config.Bounds); err != nil {
+		abortFieldErrors(c, err)
+		return
+	}
 
 	// 4. Call Repository Create method with the populated models.Check
 	ctx := c.Request.Context()
 	err := h.CheckRepo.Create(ctx, &newCheck) // Pass pointer to the models.Check struct
 
 	if err != nil {
-		if errors.Is(err, repository.ErrCheckNotFound) {
+		if isClientGone(err) {
+			abortClientGone(c, "CreateCheck", err)
+		} else if errors.Is(err, repository.ErrCheckNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Related resource not found"})
-		} else if strings.Contains(err.Error(), "already exists") { // Basic duplicate check
-			c.JSON(http.StatusConflict, gin.H{"error": "Check with this UUID might already exist"})
+		} else if errors.Is(err, repository.ErrDuplicateName) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A check with this name already exists", "field": "name"})
+		} else if errors.Is(err, repository.ErrDuplicateUUID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A check with this UUID already exists", "field": "uuid"})
 		} else {
 			log.Printf("ERROR: CreateCheck handler failed: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create check"})
@@ -101,9 +350,28 @@ func (h *CheckHandler) CreateCheck(c *gin.Context) {
 	}
 
 	// 5. Return Success Response (using the populated models.Check struct)
-	c.JSON(http.StatusCreated, newCheck)
+	response := createCheckResponse{Check: newCheck}
+	if h.Config.Signer != nil {
+		expires := time.Now().Add(h.Config.SignedURLTTL).UTC().Truncate(time.Second)
+		response.SignedPingURL = h.Config.Signer.SignedURL(h.Config.PublicBaseURL, newCheck.UUID, expires)
+		response.SignedPingURLExpiresAt = &expires
+	}
+	setCreatedLocation(c, strconv.FormatInt(newCheck.ID, 10)) // What GET /checks/{id} takes
+	c.JSON(http.StatusCreated, response)
 }
 
+// setCreatedLocation points the Location header of a 201 at the created
+// resource, id under the collection the request was posted to. The path is
+// taken from the matched route, so it follows whatever prefix the routes are
+// mounted under.
+func setCreatedLocation(c *gin.Context, id string) {
+	c.Header("Location", strings.TrimSuffix(c.FullPath(), "/")+"/"+id)
+}
+
+// GetChecks lists the caller's checks by name, as a plain array. With ?page
+// or ?per_page it answers one page in a {"data", "meta"} envelope instead (see
+// getChecksPage), and with ?metadata=key:value only the checks labelled so.
+// Method: GET /api/v1/checks
 func (h *CheckHandler) GetChecks(c *gin.Context) {
 	// 1. Get User ID (from auth middleware context)
 	userIDtmp, exists := middleware.GetUserIDFromContext(c)
@@ -117,11 +385,72 @@ func (h *CheckHandler) GetChecks(c *gin.Context) {
 	userID := int64(userIDtmp)
 	log.Printf("INFO: GetChecks request received for user ID: %d", userID)
 
-	// 2. Call Repository List method
 	ctx := c.Request.Context()
+
+	// 2. ?metadata=key:value lists only the checks labelled so, never streamed
+	if filter, ok := c.GetQuery("metadata"); ok {
+		key, value, found := strings.Cut(filter, ":")
+		if !found || !models.IsValidMetadataKey(key) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "metadata must be key:value with a valid metadata key"})
+			return
+		}
+		checks, err := h.CheckRepo.ListByMetadata(ctx, userID, key, value)
+		if err != nil {
+			if isClientGone(err) {
+				abortClientGone(c, "GetChecks", err)
+				return
+			}
+			log.Printf("ERROR: GetChecks handler failed to filter checks by metadata for user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve checks"})
+			return
+		}
+		h.writeCheckList(c, checks)
+		return
+	}
+
+	// 3. ?page and ?per_page ask for one page, in an envelope with the total
+	if c.Query("page") != "" || c.Query("per_page") != "" {
+		h.getChecksPage(c, userID)
+		return
+	}
+
+	// 4. Stream large accounts, see streamJSONArray for how errors surface
+	if h.Config.StreamListThreshold > 0 {
+		count, err := h.CheckRepo.CountByUserID(ctx, userID)
+		if isClientGone(err) {
+			abortClientGone(c, "GetChecks", err)
+			return
+		}
+		if err != nil {
+			log.Printf("ERROR: GetChecks handler failed to count checks for user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve checks",
+			})
+			return
+		}
+		if count > h.Config.StreamListThreshold {
+			log.Printf("INFO: Streaming %d checks for user ID: %d", count, userID)
+			if h.wantsXML(c) {
+				streamXMLList(c, "checks", "checks", func(emit func(any) error) error {
+					return h.CheckRepo.EachByUserID(ctx, userID, func(check models.Check) error {
+						return emit(newCheckXML(check))
+					})
+				})
+				return
+			}
+			streamJSONArray(c, "checks", func(emit func(any) error) error {
+				return h.CheckRepo.EachByUserID(ctx, userID, func(check models.Check) error {
+					return emit(check)
+				})
+			})
+			return
+		}
+	}
+
+	// 5. Call Repository List method
 	checks, err := h.CheckRepo.ListByUserID(ctx, userID)
 
-	// 3. Handle Repository Errors
+	// 6. Handle Repository Errors
 	if err != nil {
 		// It's NOT an error if the user simply has no checks.
 		// sql.ErrNoRows is often not returned for list queries that find nothing,
@@ -129,16 +458,18 @@ func (h *CheckHandler) GetChecks(c *gin.Context) {
 		// However, if your repository method specifically returns ErrCheckNotFound or similar, handle it.
 		if errors.Is(err, repository.ErrCheckNotFound) {
 			log.Printf("INFO: No checks found for user ID: %d", userID)
-			c.JSON(http.StatusOK, []models.Check{})
+			checks = nil // Answered as an empty list below
+		} else if isClientGone(err) {
+			abortClientGone(c, "GetChecks", err)
+			return
+		} else {
+			// Handle other potential database errors
+			log.Printf("ERROR: GetChecks handler repository call failed for user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve checks",
+			})
 			return
 		}
-
-		// Handle other potential database errors
-		log.Printf("ERROR: GetChecks handler repository call failed for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve checks",
-		})
-		return
 	}
 
 	// Handle the case where the query runs fine but finds no rows (returns empty slice, nil error)
@@ -147,15 +478,601 @@ func (h *CheckHandler) GetChecks(c *gin.Context) {
 		checks = []models.Check{}
 	}
 
-	// 4. Return Success Response
+	// 7. Return Success Response
 	log.Printf("INFO: Successfully retrieved %d checks for user ID: %d", len(checks), userID)
+	h.writeCheckList(c, checks)
+}
+
+// checkPage is a page of GET /checks?page=P&per_page=PP.
+type checkPage struct {
+	Data []models.Check `json:"data"`
+	Meta pageMeta       `json:"meta"`
+}
+
+// pageMeta locates a page: Total is the count across all pages.
+type pageMeta struct {
+	Total   int64 `json:"total"`
+	Page    int64 `json:"page"`
+	PerPage int64 `json:"per_page"`
+}
+
+// getChecksPage answers GET /checks with one page of the caller's checks, by
+// name as the full list. page defaults to 1 and per_page to
+// defaultChecksPerPage, capped at maxChecksPerPage. A page past the end is empty,
+// with the total still set.
+func (h *CheckHandler) getChecksPage(c *gin.Context, userID int64) {
+	page, perPage := int64(1), int64(defaultChecksPerPage)
+	if raw := c.Query("page"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+			return
+		}
+		page = parsed
+	}
+	if raw := c.Query("per_page"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "per_page must be a positive integer"})
+			return
+		}
+		perPage = min(parsed, maxChecksPerPage)
+	}
+	if page > math.MaxInt64/perPage {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page is too large"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	total, err := h.CheckRepo.CountByUserID(ctx, userID)
+	var checks []models.Check
+	if err == nil {
+		checks, err = h.CheckRepo.ListByUserIDPaginated(ctx, userID, (page-1)*perPage, perPage)
+	}
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "GetChecks", err)
+			return
+		}
+		log.Printf("ERROR: GetChecks handler failed to list page %d of user %d: %v", page, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve checks"})
+		return
+	}
+
+	meta := pageMeta{Total: int64(total), Page: page, PerPage: perPage}
+	if h.wantsXML(c) {
+		body := checkPageXML{Total: meta.Total, Page: meta.Page, PerPage: meta.PerPage, Checks: make([]checkXML, 0, len(checks))}
+		for _, check := range checks {
+			body.Checks = append(body.Checks, newCheckXML(check))
+		}
+		c.XML(http.StatusOK, body)
+		return
+	}
+	c.JSON(http.StatusOK, checkPage{Data: checks, Meta: meta})
+}
+
+// writeCheckList answers GET /checks with checks, in XML when asked for.
+func (h *CheckHandler) writeCheckList(c *gin.Context, checks []models.Check) {
+	if h.wantsXML(c) {
+		list := checkListXML{Checks: make([]checkXML, 0, len(checks))}
+		for _, check := range checks {
+			list.Checks = append(list.Checks, newCheckXML(check))
+		}
+		c.XML(http.StatusOK, list)
+		return
+	}
 	c.JSON(http.StatusOK, checks)
 }
 
+// GetActiveChecks lists the caller's active checks by name: enabled and not
+// paused, the ones the timeout worker evaluates (see
+// repository.FindActiveByUserID). Never streamed.
+// Method: GET /api/v1/checks/active
+func (h *CheckHandler) GetActiveChecks(c *gin.Context) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/checks/active")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	checks, err := h.CheckRepo.FindActiveByUserID(c.Request.Context(), userID)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "GetActiveChecks", err)
+			return
+		}
+		log.Printf("ERROR: GetActiveChecks repository call failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve checks"})
+		return
+	}
+	h.writeCheckList(c, checks)
+}
+
+// checkWithLastPing is the GetCheck response when ?include=last_ping is set.
+type checkWithLastPing struct {
+	models.Check
+	LastPing *models.Ping `json:"last_ping"` // null when the check has never been pinged
+}
+
+// GetCheck returns one of the caller's checks by its numeric ID.
+// Method: GET /api/v1/checks/{id}?include=last_ping
+func (h *CheckHandler) GetCheck(c *gin.Context) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/checks/:id")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Authentication context error",
+		})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	checkID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || checkID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check ID"})
+		return
+	}
+
+	// The last ping costs a subquery on pings, so it is only joined when asked for
+	includePing := c.Query("include") == "last_ping"
+	var check *models.Check
+	var lastPing *models.Ping
+	if includePing {
+		check, lastPing, err = h.CheckRepo.FindByIDWithLastPing(c.Request.Context(), checkID)
+	} else {
+		check, err = h.CheckRepo.FindByID(c.Request.Context(), checkID)
+	}
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "GetCheck", err)
+			return
+		}
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+			return
+		}
+		log.Printf("ERROR: GetCheck repository call failed for check %d: %v", checkID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve check"})
+		return
+	}
+	// Another user's check: 403, as in loadOwnCheck
+	if check.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Check belongs to another user"})
+		return
+	}
+
+	if h.wantsXML(c) {
+		body := newCheckXML(*check)
+		if includePing && lastPing != nil {
+			body.LastPing = newPingXML(*lastPing)
+		}
+		c.XML(http.StatusOK, body)
+		return
+	}
+	if includePing {
+		c.JSON(http.StatusOK, checkWithLastPing{Check: *check, LastPing: lastPing})
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
+
+// PauseAll pauses every check of the caller that isn't paused yet, e.g. before
+// planned maintenance. Pings keep being handled per INACTIVE_PING_POLICY.
+// Method: POST /api/v1/checks/pause-all
+func (h *CheckHandler) PauseAll(c *gin.Context) {
+	h.setStatusAll(c, "pause-all", h.CheckRepo.PauseAllByUserID)
+}
+
+// ResumeAll resumes every paused check of the caller. Each goes back to 'up' if
+// its last ping is recent enough, to 'new' otherwise (see
+// repository.ResumeAllByUserID); none is blindly marked 'up'.
+// Method: POST /api/v1/checks/resume-all
+func (h *CheckHandler) ResumeAll(c *gin.Context) {
+	h.setStatusAll(c, "resume-all", h.CheckRepo.ResumeAllByUserID)
+}
+
+// setStatusAll runs one of the account-wide status changes for the caller and
+// reports how many checks it affected.
+func (h *CheckHandler) setStatusAll(c *gin.Context, action string, apply func(ctx context.Context, userID int64, source string) (int64, error)) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Printf("ERROR: UserID not found in context for protected route /api/v1/checks/%s", action)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	affected, err := apply(c.Request.Context(), userID, models.EventSourceAPI)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, action, err)
+			return
+		}
+		log.Printf("ERROR: Checks %s failed for user %d: %v", action, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"checks_affected": affected})
+}
+
+// BulkActionRequest is the body of POST /api/v1/checks/bulk-action.
+type BulkActionRequest struct {
+	Action string `json:"action" binding:"required"` // pause, resume, enable, disable or delete
+	Filter struct {
+		Tags   []string `json:"tags"`   // Not supported yet, checks have no tags
+		Status []string `json:"status"` // Only checks in one of these statuses
+	} `json:"filter"`
+	DryRun  bool `json:"dry_run"` // Only list the checks that would change
+	Confirm bool `json:"confirm"` // Required for delete
+}
+
+// BulkAction applies one action to every check of the caller matching a filter,
+// in one transaction (see repository.ApplyBulkAction). With dry_run it only
+// returns the UUIDs of the checks that would change. An empty filter matches
+// every check, so delete additionally needs confirm=true.
+// Method: POST /api/v1/checks/bulk-action
+func (h *CheckHandler) BulkAction(c *gin.Context) {
+	var req BulkActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if !repository.IsValidBulkAction(req.Action) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be one of: pause, resume, enable, disable, delete"})
+		return
+	}
+	if len(req.Filter.Tags) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "filter.tags is not supported, checks have no tags on this instance"})
+		return
+	}
+	for _, status := range req.Filter.Status {
+		if !models.IsValidStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("filter.status: unknown status %q", status)})
+			return
+		}
+	}
+	if req.Action == repository.BulkDelete && !req.DryRun && !req.Confirm {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delete needs \"confirm\": true, try \"dry_run\": true first to see what would be deleted"})
+		return
+	}
+
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/checks/bulk-action")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+	ctx := c.Request.Context()
+	filter := repository.BulkFilter{Statuses: req.Filter.Status}
+
+	if req.DryRun {
+		limit := h.Config.MaxBulkChecks
+		if limit <= 0 {
+			limit = math.MaxInt32
+		}
+		uuids, total, err := h.CheckRepo.ListBulkTargets(ctx, userID, req.Action, filter, limit)
+		if err != nil {
+			h.bulkError(c, req.Action, userID, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"action":          req.Action,
+			"dry_run":         true,
+			"checks_affected": total,
+			"uuids":           uuids, // At most MaxBulkChecks
+			"limit_exceeded":  h.Config.MaxBulkChecks > 0 && total > int64(h.Config.MaxBulkChecks),
+		})
+		return
+	}
+
+	affected, err := h.CheckRepo.ApplyBulkAction(ctx, userID, req.Action, filter, models.EventSourceAPI, h.Config.MaxBulkChecks)
+	if errors.Is(err, repository.ErrBulkLimitExceeded) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":          fmt.Sprintf("filter matches %d checks, more than the limit of %d for one bulk action; narrow it down", affected, h.Config.MaxBulkChecks),
+			"checks_matched": affected,
+		})
+		return
+	}
+	if err != nil {
+		h.bulkError(c, req.Action, userID, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"action": req.Action, "checks_affected": affected})
+}
+
+func (h *CheckHandler) bulkError(c *gin.Context, action string, userID int64, err error) {
+	if isClientGone(err) {
+		abortClientGone(c, "bulk "+action, err)
+		return
+	}
+	log.Printf("ERROR: Bulk %s failed for user %d: %v", action, userID, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checks"})
+}
+
+// UpdateCheck changes the name, description, timing, notify_late, severity,
+// metadata, status or is_enabled of one of the caller's checks. A shortened expected_interval or grace_period can put an
+// 'up' check past its deadline already; it is re-evaluated straight away
+// (CheckConfig.Evaluator) instead of on the worker's next tick, and the
+// response shows the resulting status.
+// Method: PATCH (or PUT) /api/v1/checks/{id}
 func (h *CheckHandler) UpdateCheck(c *gin.Context) {
+	var req UpdateCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "UpdateCheck")
+	if !ok {
+		return
+	}
 
+	// 1. Collect the provided fields, with the same validation as CreateCheck.
+	// They are applied to the loaded check only to validate them; the update
+	// writes nothing else, so a concurrent update of other fields survives.
+	var update repository.CheckUpdate
+	merged := *check
+	if req.Name != nil {
+		if *req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be empty"})
+			return
+		}
+		update.Name, merged.Name = req.Name, *req.Name
+	}
+	if req.Description != nil {
+		description, msg := descriptionField(h.Config.Descriptions, req.Description)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		update.Description, merged.Description = &description, description
+	}
+	if req.ExpectedInterval != nil {
+		interval := uint32(*req.ExpectedInterval)
+		update.ExpectedInterval, merged.ExpectedInterval = &interval, interval
+	}
+	if req.GracePeriod != nil {
+		grace := uint32(*req.GracePeriod)
+		update.GracePeriod, merged.GracePeriod = &grace, grace
+	}
+	if req.IsEnabled != nil {
+		update.IsEnabled, merged.IsEnabled = req.IsEnabled, *req.IsEnabled
+	}
+	if req.Status != nil {
+		// 'up'/'down' are earned via pings and the worker, as on create
+		if *req.Status != models.StatusNew && *req.Status != models.StatusPaused {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'new' or 'paused'"})
+			return
+		}
+		update.Status, merged.Status = req.Status, *req.Status
+	}
+	if req.NotifyLate != nil {
+		update.NotifyLate, merged.NotifyLate = req.NotifyLate, *req.NotifyLate
+	}
+	if req.Severity != nil {
+		if *req.Severity == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "severity must not be empty"})
+			return
+		}
+		update.Severity, merged.Severity = req.Severity, *req.Severity
+	}
+	if req.Metadata != nil {
+		update.Metadata, merged.Metadata = req.Metadata, *req.Metadata
+	}
+	if req.RecoveryStabilization != nil {
+		if maxInterval := h.Config.Bounds.MaxExpectedInterval; maxInterval > 0 && *req.RecoveryStabilization > maxInterval {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("recovery_stabilization must not exceed %d seconds on this instance", maxInterval),
+			})
+			return
+		}
+		update.RecoveryStabilization, merged.RecoveryStabilization = req.RecoveryStabilization, *req.RecoveryStabilization
+	}
+	if req.MaxDuration != nil {
+		maxDuration := sql.NullInt32{Int32: int32(min(*req.MaxDuration, math.MaxInt32)), Valid: *req.MaxDuration > 0}
+		update.MaxDuration, merged.MaxDuration = &maxDuration, maxDuration
+	}
+	if req.WarmupPings != nil {
+		warmup := max(*req.WarmupPings, 1)
+		update.WarmupPings, merged.WarmupPings = &warmup, warmup
+	}
+	if req.Color.Set || req.Icon.Set {
+		color, icon, msg := presentationFields(req.Color.ptr(), req.Icon.ptr())
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		if req.Color.Set {
+			update.Color, merged.Color = &color, color
+		}
+		if req.Icon.Set {
+			update.Icon, merged.Icon = &icon, icon
+		}
+	}
+	if req.PingsHistoryLimit.Set {
+		historyLimit, msg := historyLimitField(req.PingsHistoryLimit.ptr())
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		update.PingsHistoryLimit, merged.PingsHistoryLimit = &historyLimit, historyLimit
+	}
+	if req.PingResponseCode.Set || req.PingResponseBody.Set {
+		// Validated together with the field left as it is, so a 204 can't end up
+		// with a body either way round
+		code, body := req.PingResponseCode.ptr(), req.PingResponseBody.ptr()
+		if !req.PingResponseCode.Set && check.PingResponseCode.Valid {
+			current := int(check.PingResponseCode.Int32)
+			code = &current
+		}
+		if !req.PingResponseBody.Set && check.PingResponseBody.Valid {
+			body = &check.PingResponseBody.String
+		}
+		pingResponseCode, pingResponseBody, msg := pingResponseFields(code, body)
+		if msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		if req.PingResponseCode.Set {
+			update.PingResponseCode, merged.PingResponseCode = &pingResponseCode, pingResponseCode
+		}
+		if req.PingResponseBody.Set {
+			update.PingResponseBody, merged.PingResponseBody = &pingResponseBody, pingResponseBody
+		}
+	}
+	if err := merged.Validate(h.Config.Bounds); err != nil {
+		abortFieldErrors(c, err)
+		return
+	}
+
+	// 2. Save
+	ctx := c.Request.Context()
+	if err := h.CheckRepo.Update(ctx, check.ID, update); err != nil {
+		switch {
+		case isClientGone(err):
+			abortClientGone(c, "UpdateCheck", err)
+		case errors.Is(err, repository.ErrCheckNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+		case errors.Is(err, repository.ErrDuplicateName):
+			c.JSON(http.StatusConflict, gin.H{"error": "A check with this name already exists", "field": "name"})
+		default:
+			log.Printf("ERROR: UpdateCheck failed for check %d: %v", check.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update check"})
+		}
+		return
+	}
+
+	// 3. Catch up on deadlines that moved closer. The update is saved either
+	// way, so a failure here only leaves the check to the next tick.
+	shortened := merged.ExpectedInterval < check.ExpectedInterval || merged.GracePeriod < check.GracePeriod ||
+		(merged.MaxDuration.Valid && (!check.MaxDuration.Valid || merged.MaxDuration.Int32 < check.MaxDuration.Int32))
+	if shortened && h.Config.Evaluator != nil {
+		if err := h.Config.Evaluator.EvaluateCheck(ctx, check.ID); err != nil {
+			log.Printf("WARN: UpdateCheck could not re-evaluate check %d, leaving it to the worker: %v", check.ID, err)
+		}
+	}
+
+	// 4. Answer with the check as saved, including any concurrent changes
+	updated, err := h.CheckRepo.FindByID(ctx, check.ID)
+	switch {
+	case err == nil:
+	case isClientGone(err):
+		abortClientGone(c, "UpdateCheck", err)
+		return
+	case abortNotFound(c, err, "Check"): // Deleted meanwhile
+		return
+	default:
+		log.Printf("ERROR: UpdateCheck failed to read back check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Check updated, but reading it back failed"})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
 }
 
+// DeleteCheck soft-deletes one of the caller's checks. Its pings stop being
+// accepted and its history is kept until the purger removes it. Deleting a
+// check that is already gone, or another user's, answers 404.
+// Method: DELETE /api/v1/checks/{id}
 func (h *CheckHandler) DeleteCheck(c *gin.Context) {
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "DeleteCheck")
+	if !ok {
+		return
+	}
+	// Scoped to the owner again, and a concurrent delete is a not-found too
+	err := h.CheckRepo.Delete(c.Request.Context(), check.ID, check.UserID)
+	switch {
+	case err == nil:
+	case isClientGone(err):
+		abortClientGone(c, "DeleteCheck", err)
+		return
+	case errors.Is(err, repository.ErrCheckAlreadyDeleted):
+		log.Printf("INFO: DeleteCheck of check %d lost to a concurrent delete", check.ID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Check not found"})
+		return
+	case abortNotFound(c, err, "Check"):
+		return
+	default:
+		log.Printf("ERROR: DeleteCheck failed for check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete check"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+const (
+	defaultChecksPerPage = 20
+	maxChecksPerPage     = 100
+
+	defaultPingHistoryLimit = 50
+	maxPingHistoryLimit     = 500
+
+	defaultEventHistoryLimit = 50
+	maxEventHistoryLimit     = 500
+)
+
+// GetCheckPings returns the recent ping history for one of the caller's checks,
+// newest first. offset skips that many of the newest pings, to page back.
+// Method: GET /api/v1/checks/{id}/pings?limit=N&offset=M
+func (h *CheckHandler) GetCheckPings(c *gin.Context) {
+	limit := defaultPingHistoryLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = agency.Min(parsed, maxPingHistoryLimit)
+	}
+	offset := 0
+	if rawOffset := c.Query("offset"); rawOffset != "" {
+		parsed, err := strconv.Atoi(rawOffset)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+		offset = parsed
+	}
+
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "GetCheckPings")
+	if !ok {
+		return
+	}
+	pings, err := h.CheckRepo.ListPingsByCheckID(c.Request.Context(), check.ID, limit, offset)
+	if err != nil {
+		log.Printf("ERROR: GetCheckPings repository call failed for check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pings)
+}
+
+// GetCheckEvents returns the recent event history (status changes, with the ping
+// or worker cycle that caused them) for one of the caller's checks.
+// Method: GET /api/v1/checks/{id}/events?limit=N
+func (h *CheckHandler) GetCheckEvents(c *gin.Context) {
+	limit := defaultEventHistoryLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = agency.Min(parsed, maxEventHistoryLimit)
+	}
+
+	check, ok := h.loadOwnCheck(c, c.Param("id"), "GetCheckEvents")
+	if !ok {
+		return
+	}
+	events, err := h.CheckRepo.ListEventsByCheckID(c.Request.Context(), check.ID, limit)
+	if err != nil {
+		log.Printf("ERROR: GetCheckEvents repository call failed for check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
 
+	c.JSON(http.StatusOK, events)
 }
@@ -0,0 +1,468 @@
+package httptransport
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCheckTestRouter mounts the check routes as RegisterRoutes does, with the
+// caller authenticated as userID.
+func newCheckTestRouter(h *CheckHandler, userID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	apiV1 := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, userID)
+	})
+	apiV1.POST("/checks", h.CreateCheck)
+	apiV1.GET("/checks/:id", h.GetCheck)
+	apiV1.PATCH("/checks/:id", h.UpdateCheck)
+	apiV1.PUT("/checks/:id", h.UpdateCheck)
+	apiV1.DELETE("/checks/:id", h.DeleteCheck)
+	apiV1.GET("/checks/:id/pings", h.GetCheckPings)
+	apiV1.GET("/checks/:id/events", h.GetCheckEvents)
+	apiV1.GET("/checks/:id/dependencies", h.GetCheckDependencies)
+	return router
+}
+
+// serve sends a request with an optional JSON body through router.
+func serve(router http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			panic(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateCheckLocationResolves(t *testing.T) {
+	repo := newFakeCheckRepo()
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+	created := serve(router, http.MethodPost, "/api/v1/checks", gin.H{"name": "backup", "expected_interval": 3600})
+	if created.Code != http.StatusCreated {
+		t.Fatalf("POST /checks = %d, want 201: %s", created.Code, created.Body)
+	}
+	location := created.Header().Get("Location")
+	if location != "/api/v1/checks/1" {
+		t.Errorf("Location = %q, want /api/v1/checks/1", location)
+	}
+
+	got := serve(router, http.MethodGet, location, nil)
+	if got.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d, want 200: %s", location, got.Code, got.Body)
+	}
+	var check models.Check
+	if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil {
+		t.Fatalf("decoding GET %s: %v", location, err)
+	}
+	if check.Name != "backup" || check.UserID != 7 {
+		t.Errorf("GET %s returned check %q of user %d, want backup of user 7", location, check.Name, check.UserID)
+	}
+}
+
+// Every /checks/:id route names the check by its numeric ID, and refuses a UUID.
+func TestCheckRoutesTakeNumericID(t *testing.T) {
+	const checkUUID = "0b6f1a9e-8f0e-4f3c-9d55-6f2d3f1e2a10"
+	routes := []struct {
+		method, suffix string
+		want           int
+	}{
+		{http.MethodGet, "", http.StatusOK},
+		{http.MethodGet, "/pings", http.StatusOK},
+		{http.MethodGet, "/events", http.StatusOK},
+		{http.MethodGet, "/dependencies", http.StatusOK},
+		{http.MethodDelete, "", http.StatusNoContent},
+	}
+	for _, route := range routes {
+		t.Run(route.method+" /checks/:id"+route.suffix, func(t *testing.T) {
+			repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, UUID: checkUUID, Name: "backup", ExpectedInterval: 60})
+			router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+			if got := serve(router, route.method, "/api/v1/checks/42"+route.suffix, nil); got.Code != route.want {
+				t.Errorf("by ID = %d, want %d: %s", got.Code, route.want, got.Body)
+			}
+			if got := serve(router, route.method, "/api/v1/checks/"+checkUUID+route.suffix, nil); got.Code != http.StatusBadRequest {
+				t.Errorf("by UUID = %d, want 400: %s", got.Code, got.Body)
+			}
+		})
+	}
+}
+
+// Two partial updates read the check before either saves, as concurrent
+// requests would; each writes only its own field, so neither undoes the other.
+func TestUpdateCheckWritesOnlyProvidedFields(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600, IsEnabled: true})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"name": "nightly backup"}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH name = %d: %s", got.Code, got.Body)
+	}
+	got := serve(router, http.MethodPut, "/api/v1/checks/42", gin.H{"grace_period": 300})
+	if got.Code != http.StatusOK {
+		t.Fatalf("PUT grace_period = %d: %s", got.Code, got.Body)
+	}
+
+	if len(repo.updates) != 2 {
+		t.Fatalf("Update called %d times, want 2", len(repo.updates))
+	}
+	first, second := repo.updates[0], repo.updates[1]
+	if first.Name == nil || first.GracePeriod != nil || first.IsEnabled != nil || first.ExpectedInterval != nil {
+		t.Errorf("name update = %+v, want only Name", first)
+	}
+	if second.GracePeriod == nil || second.Name != nil || second.IsEnabled != nil || second.ExpectedInterval != nil {
+		t.Errorf("grace_period update = %+v, want only GracePeriod", second)
+	}
+
+	// The response is the check as saved, with both changes
+	var check models.Check
+	if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if check.Name != "nightly backup" || check.GracePeriod != 300 || check.ExpectedInterval != 3600 {
+		t.Errorf("saved check = %q, grace %d, interval %d; want nightly backup, 300, 3600", check.Name, check.GracePeriod, check.ExpectedInterval)
+	}
+}
+
+func TestUpdateCheckStatus(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600, Status: models.StatusUp})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+	for _, status := range []string{models.StatusUp, models.StatusDown, "bogus"} {
+		if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"status": status}); got.Code != http.StatusBadRequest {
+			t.Errorf("PATCH status %q = %d, want 400", status, got.Code)
+		}
+	}
+	if len(repo.updates) != 0 {
+		t.Fatalf("rejected statuses reached Update %d times", len(repo.updates))
+	}
+
+	got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"status": models.StatusPaused})
+	if got.Code != http.StatusOK {
+		t.Fatalf("PATCH status paused = %d: %s", got.Code, got.Body)
+	}
+	var check models.Check
+	if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if check.Status != models.StatusPaused {
+		t.Errorf("status = %q, want paused", check.Status)
+	}
+}
+
+func TestUpdateCheckPresentation(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+	patch := func(body gin.H) models.Check {
+		t.Helper()
+		got := serve(router, http.MethodPatch, "/api/v1/checks/42", body)
+		if got.Code != http.StatusOK {
+			t.Fatalf("PATCH %v = %d: %s", body, got.Code, got.Body)
+		}
+		var check models.Check
+		if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		return check
+	}
+
+	check := patch(gin.H{"color": "#1E9", "icon": "database"})
+	if check.Color.String != "#11ee99" || check.Icon.String != "database" {
+		t.Errorf("color %v, icon %v; want #11ee99 and database", check.Color, check.Icon)
+	}
+	// Omitted fields stay, null removes
+	check = patch(gin.H{"color": nil})
+	if check.Color.Valid || check.Icon.String != "database" {
+		t.Errorf("color %v, icon %v; want no color, database", check.Color, check.Icon)
+	}
+	if update := repo.updates[len(repo.updates)-1]; update.Icon != nil {
+		t.Errorf("icon written by an update that only cleared the color: %+v", update)
+	}
+
+	for _, body := range []gin.H{{"color": "blue"}, {"color": ""}, {"icon": "rocket"}} {
+		if got := serve(router, http.MethodPatch, "/api/v1/checks/42", body); got.Code != http.StatusBadRequest {
+			t.Errorf("PATCH %v = %d, want 400", body, got.Code)
+		}
+	}
+}
+
+func TestUpdateCheckHistoryLimit(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600, PingsHistoryLimit: sql.NullInt32{Int32: 10000, Valid: true}})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+	for _, limit := range []int{5, 10001} {
+		if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"pings_history_limit": limit}); got.Code != http.StatusBadRequest {
+			t.Errorf("PATCH pings_history_limit %d = %d, want 400", limit, got.Code)
+		}
+	}
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"pings_history_limit": 100}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH pings_history_limit 100 = %d: %s", got.Code, got.Body)
+	}
+	if limit := repo.checks[42].PingsHistoryLimit; limit != (sql.NullInt32{Int32: 100, Valid: true}) {
+		t.Errorf("limit = %v, want 100", limit)
+	}
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"name": "nightly"}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH name = %d: %s", got.Code, got.Body)
+	}
+	if limit := repo.checks[42].PingsHistoryLimit; !limit.Valid {
+		t.Errorf("limit removed by an update that left it out")
+	}
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"pings_history_limit": nil}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH pings_history_limit null = %d: %s", got.Code, got.Body)
+	}
+	if limit := repo.checks[42].PingsHistoryLimit; limit.Valid {
+		t.Errorf("limit = %v after null, want none", limit)
+	}
+}
+
+func TestUpdateCheckRecoveryStabilization(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "flapping link", ExpectedInterval: 3600, RecoveryStabilization: 300})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{Bounds: models.TimingBounds{MaxExpectedInterval: 86400}}), 7)
+
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"recovery_stabilization": 86401}); got.Code != http.StatusBadRequest {
+		t.Errorf("PATCH recovery_stabilization 86401 = %d, want 400", got.Code)
+	}
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"recovery_stabilization": 900}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH recovery_stabilization 900 = %d: %s", got.Code, got.Body)
+	}
+	if got := repo.checks[42].RecoveryStabilization; got != 900 {
+		t.Errorf("recovery_stabilization = %d, want 900", got)
+	}
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"name": "link"}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH name = %d: %s", got.Code, got.Body)
+	}
+	if got := repo.checks[42].RecoveryStabilization; got != 900 {
+		t.Errorf("recovery_stabilization = %d after an update that left it out, want 900", got)
+	}
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"recovery_stabilization": 0}); got.Code != http.StatusOK {
+		t.Fatalf("PATCH recovery_stabilization 0 = %d: %s", got.Code, got.Body)
+	}
+	if got := repo.checks[42].RecoveryStabilization; got != 0 {
+		t.Errorf("recovery_stabilization = %d, want 0", got)
+	}
+}
+
+func TestUpdateCheckPingResponse(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "legacy agent", ExpectedInterval: 3600})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+	patch := func(body gin.H, wantCode int) {
+		t.Helper()
+		if got := serve(router, http.MethodPatch, "/api/v1/checks/42", body); got.Code != wantCode {
+			t.Fatalf("PATCH %v = %d, want %d: %s", body, got.Code, wantCode, got.Body)
+		}
+	}
+
+	patch(gin.H{"ping_response_code": 201, "ping_response_body": "OK"}, http.StatusOK)
+	if check := repo.checks[42]; check.PingResponseCode.Int32 != 201 || check.PingResponseBody.String != "OK" {
+		t.Errorf("response = %v %v, want 201 OK", check.PingResponseCode, check.PingResponseBody)
+	}
+	patch(gin.H{"ping_response_code": 418}, http.StatusBadRequest)
+	patch(gin.H{"ping_response_body": "line\nbreak"}, http.StatusBadRequest)
+	// The stored body counts: a 204 can't be set under it
+	patch(gin.H{"ping_response_code": 204}, http.StatusBadRequest)
+	patch(gin.H{"ping_response_code": 204, "ping_response_body": nil}, http.StatusOK)
+	if check := repo.checks[42]; check.PingResponseCode.Int32 != 204 || check.PingResponseBody.Valid {
+		t.Errorf("response = %v %v, want 204 without a body", check.PingResponseCode, check.PingResponseBody)
+	}
+	// Nor a body under a stored 204
+	patch(gin.H{"ping_response_body": "OK"}, http.StatusBadRequest)
+	patch(gin.H{"ping_response_code": nil}, http.StatusOK)
+	if check := repo.checks[42]; check.PingResponseCode.Valid || check.PingResponseBody.Valid {
+		t.Errorf("response = %v %v, want the default", check.PingResponseCode, check.PingResponseBody)
+	}
+}
+
+func TestCreateCheckDuplicateConflict(t *testing.T) {
+	tests := []struct {
+		err       error
+		wantField string
+	}{
+		{err: fmt.Errorf("%w: 1062", repository.ErrDuplicateName), wantField: "name"},
+		{err: fmt.Errorf("%w: 1062", repository.ErrDuplicateUUID), wantField: "uuid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantField, func(t *testing.T) {
+			repo := newFakeCheckRepo()
+			repo.err = tt.err
+			router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+			got := serve(router, http.MethodPost, "/api/v1/checks", gin.H{"name": "backup", "expected_interval": 3600})
+			if got.Code != http.StatusConflict {
+				t.Fatalf("POST /checks = %d, want 409: %s", got.Code, got.Body)
+			}
+			var body struct {
+				Field string `json:"field"`
+			}
+			if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil || body.Field != tt.wantField {
+				t.Errorf("field = %q (%v), want %q", body.Field, err, tt.wantField)
+			}
+		})
+	}
+}
+
+// fakeEvaluator records the checks it is asked to re-evaluate and, like the
+// worker would for a check past its new deadline, marks them down.
+type fakeEvaluator struct {
+	repo      *fakeCheckRepo
+	evaluated []int64
+	err       error
+}
+
+func (e *fakeEvaluator) EvaluateCheck(_ context.Context, checkID int64) error {
+	e.evaluated = append(e.evaluated, checkID)
+	if e.err != nil {
+		return e.err
+	}
+	e.repo.mu.Lock()
+	defer e.repo.mu.Unlock()
+	e.repo.checks[checkID].Status = models.StatusDown
+	return nil
+}
+
+func TestUpdateCheckReevaluatesShortenedDeadlines(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          gin.H
+		wantEvaluated bool
+	}{
+		{name: "shorter interval", body: gin.H{"expected_interval": 60}, wantEvaluated: true},
+		{name: "shorter grace period", body: gin.H{"grace_period": 10}, wantEvaluated: true},
+		{name: "longer interval", body: gin.H{"expected_interval": 7200}},
+		{name: "unrelated field", body: gin.H{"name": "nightly backup"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600, GracePeriod: 300, Status: models.StatusUp})
+			evaluator := &fakeEvaluator{repo: repo}
+			router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{Evaluator: evaluator}), 7)
+
+			got := serve(router, http.MethodPatch, "/api/v1/checks/42", tt.body)
+			if got.Code != http.StatusOK {
+				t.Fatalf("PATCH = %d, want 200: %s", got.Code, got.Body)
+			}
+			var check models.Check
+			if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if !tt.wantEvaluated {
+				if len(evaluator.evaluated) != 0 || check.Status != models.StatusUp {
+					t.Errorf("evaluated %v, status %q; want no evaluation, still up", evaluator.evaluated, check.Status)
+				}
+				return
+			}
+			if len(evaluator.evaluated) != 1 || evaluator.evaluated[0] != 42 {
+				t.Errorf("evaluated %v, want [42]", evaluator.evaluated)
+			}
+			// The response is read after the evaluation, so it shows the new status
+			if check.Status != models.StatusDown {
+				t.Errorf("status = %q, want down", check.Status)
+			}
+		})
+	}
+}
+
+// A failed re-evaluation leaves the check to the worker; the update stands.
+func TestUpdateCheckEvaluatorFailure(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600, Status: models.StatusUp})
+	evaluator := &fakeEvaluator{repo: repo, err: errors.New("lock wait timeout")}
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{Evaluator: evaluator}), 7)
+
+	got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"expected_interval": 60})
+	if got.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d, want 200: %s", got.Code, got.Body)
+	}
+	if len(evaluator.evaluated) != 1 {
+		t.Errorf("evaluated %v, want one attempt", evaluator.evaluated)
+	}
+	var check models.Check
+	if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if check.ExpectedInterval != 60 || check.Status != models.StatusUp {
+		t.Errorf("saved check = interval %d, status %q; want 60, up", check.ExpectedInterval, check.Status)
+	}
+}
+
+func TestUpdateCheckNotFound(t *testing.T) {
+	repo := newFakeCheckRepo()
+	evaluator := &fakeEvaluator{repo: repo}
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{Evaluator: evaluator}), 7)
+
+	if got := serve(router, http.MethodPatch, "/api/v1/checks/42", gin.H{"expected_interval": 60}); got.Code != http.StatusNotFound {
+		t.Errorf("PATCH = %d, want 404: %s", got.Code, got.Body)
+	}
+	if len(repo.updates) != 0 || len(evaluator.evaluated) != 0 {
+		t.Errorf("missing check reached Update %d times, evaluated %v", len(repo.updates), evaluator.evaluated)
+	}
+}
+
+// Deleting twice answers 204 then 404, and the deleted check is gone from GET.
+func TestGetCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		repoErr  error
+		wantCode int
+	}{
+		{name: "own check", path: "/api/v1/checks/42", wantCode: http.StatusOK},
+		{name: "another user's check", path: "/api/v1/checks/43", wantCode: http.StatusForbidden},
+		{name: "missing check", path: "/api/v1/checks/44", wantCode: http.StatusNotFound},
+		{name: "database error", path: "/api/v1/checks/42", repoErr: errors.New("connection reset"), wantCode: http.StatusInternalServerError},
+		// Routes loading the check through loadOwnCheck answer the same way
+		{name: "another user's events", path: "/api/v1/checks/43/events", wantCode: http.StatusForbidden},
+		{name: "missing check's events", path: "/api/v1/checks/44/events", wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCheckRepo(
+				models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600},
+				models.Check{ID: 43, UserID: 8, Name: "someone else's", ExpectedInterval: 3600},
+			)
+			repo.err = tt.repoErr
+			router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+			got := serve(router, http.MethodGet, tt.path, nil)
+			if got.Code != tt.wantCode {
+				t.Fatalf("GET %s = %d, want %d: %s", tt.path, got.Code, tt.wantCode, got.Body)
+			}
+			if tt.wantCode == http.StatusOK {
+				var check models.Check
+				if err := json.Unmarshal(got.Body.Bytes(), &check); err != nil || check.ID != 42 {
+					t.Errorf("body = %s, want check 42", got.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteCheckTwice(t *testing.T) {
+	repo := newFakeCheckRepo(models.Check{ID: 42, UserID: 7, Name: "backup", ExpectedInterval: 3600})
+	router := newCheckTestRouter(NewCheckHandler(repo, CheckConfig{}), 7)
+
+	if got := serve(router, http.MethodDelete, "/api/v1/checks/42", nil); got.Code != http.StatusNoContent {
+		t.Fatalf("first DELETE = %d, want 204: %s", got.Code, got.Body)
+	}
+	if got := serve(router, http.MethodDelete, "/api/v1/checks/42", nil); got.Code != http.StatusNotFound {
+		t.Errorf("second DELETE = %d, want 404: %s", got.Code, got.Body)
+	}
+	if got := serve(router, http.MethodGet, "/api/v1/checks/42", nil); got.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE = %d, want 404: %s", got.Code, got.Body)
+	}
+}
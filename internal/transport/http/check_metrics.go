@@ -0,0 +1,124 @@
+package httptransport
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Per-check state in the Prometheus text exposition format, for teams that
+// alert from Prometheus rather than from our notifications. It is scraped with
+// one of the account's API keys:
+//
+//	scrape_configs:
+//	  - job_name: bitterlink
+//	    metrics_path: /metrics/checks
+//	    authorization: {credentials: <API key>}
+//	    static_configs: [{targets: ["bitterlink.example.com"]}]
+//
+// and alerted on like any other up metric:
+//
+//	groups:
+//	  - name: bitterlink
+//	    rules:
+//	      - record: bitterlink:checks_down:count
+//	        expr: count(bitterlink_check_up == 0)
+//	      - alert: BitterlinkCheckDown
+//	        expr: bitterlink_check_up == 0
+//	        for: 1m
+//	        annotations: {summary: "Check {{ $labels.name }} is down"}
+//	      - alert: BitterlinkCheckSilent
+//	        expr: bitterlink_check_last_ping_age_seconds > 86400
+//
+// Labels come from a fixed set of fields (the check's UUID and name) and never
+// from ping data, and the names are cut to checkMetricsMaxLabelLength. The
+// series count is capped by CheckConfig.MetricsMaxChecks; a scrape that hit the
+// cap reports bitterlink_checks_truncated 1.
+
+// checkMetricsContentType is the text exposition format, version 0.0.4.
+const checkMetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// checkMetricsMaxLabelLength caps a name label, in runes.
+const checkMetricsMaxLabelLength = 100
+
+// GetCheckMetrics exports the caller's checks as Prometheus gauges:
+// bitterlink_check_up (0 for an enabled check that is down, 1 otherwise, as
+// late, new and paused checks aren't failing) and
+// bitterlink_check_last_ping_age_seconds (checks pinged at least once).
+// Method: GET /metrics/checks
+func (h *CheckHandler) GetCheckMetrics(c *gin.Context) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /metrics/checks")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	gauges, truncated, err := h.CheckRepo.ListCheckGauges(c.Request.Context(), userID, h.Config.MetricsMaxChecks)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "check metrics", err)
+			return
+		}
+		log.Printf("ERROR: GetCheckMetrics repository call failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve checks"})
+		return
+	}
+	if truncated {
+		log.Printf("WARN: Check metrics of user %d truncated to %d checks", userID, h.Config.MetricsMaxChecks)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# HELP bitterlink_check_up Whether the check is up (1) or down (0).\n")
+	out.WriteString("# TYPE bitterlink_check_up gauge\n")
+	for _, g := range gauges {
+		up := 1
+		if g.IsEnabled && g.Status == models.StatusDown {
+			up = 0
+		}
+		fmt.Fprintf(&out, "bitterlink_check_up%s %d\n", checkMetricLabels(g.UUID, g.Name), up)
+	}
+	out.WriteString("# HELP bitterlink_check_last_ping_age_seconds Seconds since the check's last ping.\n")
+	out.WriteString("# TYPE bitterlink_check_last_ping_age_seconds gauge\n")
+	for _, g := range gauges {
+		if g.LastPingAge.Valid {
+			fmt.Fprintf(&out, "bitterlink_check_last_ping_age_seconds%s %d\n", checkMetricLabels(g.UUID, g.Name), max(g.LastPingAge.Int64, 0))
+		}
+	}
+	out.WriteString("# HELP bitterlink_checks_truncated Whether checks were left out to stay under the series cap.\n")
+	out.WriteString("# TYPE bitterlink_checks_truncated gauge\n")
+	if truncated {
+		out.WriteString("bitterlink_checks_truncated 1\n")
+	} else {
+		out.WriteString("bitterlink_checks_truncated 0\n")
+	}
+
+	setNoCacheHeaders(c)
+	c.Data(http.StatusOK, checkMetricsContentType, out.Bytes())
+}
+
+// checkMetricLabels formats a check's label set.
+func checkMetricLabels(checkUUID, name string) string {
+	name = strings.ToValidUTF8(name, "�")
+	if utf8.RuneCountInString(name) > checkMetricsMaxLabelLength {
+		name = string([]rune(name)[:checkMetricsMaxLabelLength])
+	}
+	return fmt.Sprintf(`{name="%s",uuid="%s"}`, escapeLabelValue(name), escapeLabelValue(checkUUID))
+}
+
+// labelValueEscaper escapes the characters the exposition format requires in
+// label values.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
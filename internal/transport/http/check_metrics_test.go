@@ -0,0 +1,109 @@
+package httptransport
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gaugeRepo serves a fixed ListCheckGauges result, honouring the limit.
+type gaugeRepo struct {
+	repository.CheckRepository
+	gauges []repository.CheckGauge
+	userID int64 // As passed to ListCheckGauges
+}
+
+func (r *gaugeRepo) ListCheckGauges(_ context.Context, userID int64, limit int) ([]repository.CheckGauge, bool, error) {
+	r.userID = userID
+	if len(r.gauges) > limit {
+		return r.gauges[:limit], true, nil
+	}
+	return r.gauges, false, nil
+}
+
+func scrapeCheckMetrics(t *testing.T, repo *gaugeRepo, cfg CheckConfig) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics/checks", func(c *gin.Context) { c.Set(middleware.UserIDKey, 7) }, NewCheckHandler(repo, cfg).GetCheckMetrics)
+
+	got := serve(router, http.MethodGet, "/metrics/checks", nil)
+	if got.Code != http.StatusOK {
+		t.Fatalf("GET /metrics/checks = %d, want 200: %s", got.Code, got.Body)
+	}
+	if ct := got.Header().Get("Content-Type"); ct != checkMetricsContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, checkMetricsContentType)
+	}
+	if repo.userID != 7 {
+		t.Errorf("gauges listed for user %d, want the caller (7)", repo.userID)
+	}
+	return got.Body.String()
+}
+
+func TestGetCheckMetricsExposition(t *testing.T) {
+	repo := &gaugeRepo{gauges: []repository.CheckGauge{
+		{UUID: "u-1", Name: "backup", Status: models.StatusUp, IsEnabled: true, LastPingAge: sql.NullInt64{Int64: 42, Valid: true}},
+		{UUID: "u-2", Name: "etl", Status: models.StatusDown, IsEnabled: true, LastPingAge: sql.NullInt64{Int64: 7200, Valid: true}},
+		{UUID: "u-3", Name: "paused", Status: models.StatusDown, IsEnabled: false, LastPingAge: sql.NullInt64{Int64: -3, Valid: true}},
+		{UUID: "u-4", Name: "fresh", Status: models.StatusNew, IsEnabled: true},
+	}}
+	want := `# HELP bitterlink_check_up Whether the check is up (1) or down (0).
+# TYPE bitterlink_check_up gauge
+bitterlink_check_up{name="backup",uuid="u-1"} 1
+bitterlink_check_up{name="etl",uuid="u-2"} 0
+bitterlink_check_up{name="paused",uuid="u-3"} 1
+bitterlink_check_up{name="fresh",uuid="u-4"} 1
+# HELP bitterlink_check_last_ping_age_seconds Seconds since the check's last ping.
+# TYPE bitterlink_check_last_ping_age_seconds gauge
+bitterlink_check_last_ping_age_seconds{name="backup",uuid="u-1"} 42
+bitterlink_check_last_ping_age_seconds{name="etl",uuid="u-2"} 7200
+bitterlink_check_last_ping_age_seconds{name="paused",uuid="u-3"} 0
+# HELP bitterlink_checks_truncated Whether checks were left out to stay under the series cap.
+# TYPE bitterlink_checks_truncated gauge
+bitterlink_checks_truncated 0
+`
+	if got := scrapeCheckMetrics(t, repo, CheckConfig{}); got != want {
+		t.Errorf("exposition =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGetCheckMetricsTruncated(t *testing.T) {
+	repo := &gaugeRepo{gauges: []repository.CheckGauge{
+		{UUID: "u-1", Name: "a", Status: models.StatusUp, IsEnabled: true},
+		{UUID: "u-2", Name: "b", Status: models.StatusUp, IsEnabled: true},
+		{UUID: "u-3", Name: "c", Status: models.StatusUp, IsEnabled: true},
+	}}
+	got := scrapeCheckMetrics(t, repo, CheckConfig{MetricsMaxChecks: 2})
+	if n := strings.Count(got, "bitterlink_check_up{"); n != 2 {
+		t.Errorf("exported %d check_up series, want the cap of 2", n)
+	}
+	if !strings.Contains(got, "\nbitterlink_checks_truncated 1\n") {
+		t.Errorf("exposition does not report truncation:\n%s", got)
+	}
+}
+
+func TestCheckMetricLabels(t *testing.T) {
+	tests := []struct {
+		name, checkName, want string
+	}{
+		{name: "plain", checkName: "backup", want: `{name="backup",uuid="u-1"}`},
+		{name: "escaped", checkName: "a \"quoted\"\\path\nnext", want: `{name="a \"quoted\"\\path\nnext",uuid="u-1"}`},
+		{name: "invalid UTF-8", checkName: "ok\xffok", want: `{name="ok` + "\uFFFD" + `ok",uuid="u-1"}`},
+		{name: "long", checkName: strings.Repeat("é", 150), want: `{name="` + strings.Repeat("é", checkMetricsMaxLabelLength) + `",uuid="u-1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkMetricLabels("u-1", tt.checkName); got != tt.want {
+				t.Errorf("checkMetricLabels(%q) = %s, want %s", tt.checkName, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package httptransport
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statusClientClosedRequest is nginx's non-standard 499. Nobody receives it;
+// it shows up in access logs and request metrics instead of a misleading 500.
+const statusClientClosedRequest = 499
+
+// isClientGone reports whether err only means the request's context ended: the
+// client disconnected or its deadline passed, so nothing is listening for the
+// response and there is no server fault to report.
+func isClientGone(err error) bool {
+	return errors.Is(err, repository.ErrCanceled) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// abortClientGone ends a request whose client has gone away: a 499 with no body,
+// logged at DEBUG only.
+func abortClientGone(c *gin.Context, what string, err error) {
+	log.Printf("DEBUG: Client went away during %s %s: %v", what, c.Request.URL.Path, err)
+	c.AbortWithStatus(statusClientClosedRequest)
+}
@@ -0,0 +1,69 @@
+package httptransport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/agency"
+)
+
+// DurationSeconds is a duration field of a check request, in whole seconds.
+// Besides the number of seconds it accepts a string: a Go duration with a day
+// unit ("24h", "1d12h", see agency.ParseDuration) or an ISO 8601 duration
+// ("P1D", "PT90M"). Responses always give the seconds.
+type DurationSeconds uint32
+
+func (d *DurationSeconds) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil // Like any other field: left as it was
+	}
+	if data[0] != '"' {
+		var seconds uint32
+		if err := json.Unmarshal(data, &seconds); err != nil {
+			return fmt.Errorf("duration must be a whole number of seconds or a duration string like \"24h\" or \"P1D\"")
+		}
+		*d = DurationSeconds(seconds)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseUint(s, 10, 32); err == nil {
+		*d = DurationSeconds(seconds)
+		return nil
+	}
+	if strings.HasPrefix(s, "P") {
+		duration, err := agency.ParseISODuration(s)
+		if err != nil {
+			return err
+		}
+		return d.set(s, duration)
+	}
+	duration, err := agency.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("duration %q: use seconds, a duration like \"24h\" or an ISO 8601 duration like \"P1D\"", s)
+	}
+	return d.set(s, duration)
+}
+
+// set stores duration, parsed from s, as whole seconds.
+func (d *DurationSeconds) set(s string, duration time.Duration) error {
+	switch {
+	case duration < 0:
+		return fmt.Errorf("duration %q must not be negative", s)
+	case duration%time.Second != 0:
+		return fmt.Errorf("duration %q must be a whole number of seconds", s)
+	case duration/time.Second > math.MaxUint32:
+		return fmt.Errorf("duration %q is too long", s)
+	}
+	*d = DurationSeconds(duration / time.Second)
+	return nil
+}
@@ -0,0 +1,88 @@
+package httptransport
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"bitterlink/core/internal/agency"
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The event feed lets an integration follow everything that happens to an
+// account's checks (created, updated, deleted, status changes, ...) without
+// missing any: each event gets an increasing sequence number once it has
+// committed (repository.SequenceEvents), and a consumer asks for those after
+// the last one it processed. Delivery is at-least-once: a consumer that
+// crashes before saving its cursor gets the same events again.
+
+const (
+	defaultEventFeedLimit = 100
+	maxEventFeedLimit     = 1000
+)
+
+// eventFeedPage is one page of the event feed. Pass NextAfter as ?after= for
+// the next one; it stays at the requested after while there is nothing new.
+type eventFeedPage struct {
+	Events    []models.FeedEvent `json:"events"`
+	NextAfter int64              `json:"next_after"`
+	HasMore   bool               `json:"has_more"` // A full page: ask again at once instead of waiting
+}
+
+// GetEventFeed returns the events of the caller's checks, deleted checks
+// included, with a sequence number above after, in sequence order. Events show
+// up once the worker has sequenced them, within about a second.
+// Method: GET /api/v1/events?after=<seq>&limit=N
+func (h *CheckHandler) GetEventFeed(c *gin.Context) {
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/events")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Authentication context error",
+		})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	// 1. Parse the cursor and page size; no cursor starts from the beginning
+	var after int64
+	if rawAfter := c.Query("after"); rawAfter != "" {
+		parsed, err := strconv.ParseInt(rawAfter, 10, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "after must be a non-negative integer"})
+			return
+		}
+		after = parsed
+	}
+	limit := defaultEventFeedLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = agency.Min(parsed, maxEventFeedLimit)
+	}
+
+	// 2. Read the page
+	events, err := h.CheckRepo.ListEventsAfter(c.Request.Context(), userID, after, limit)
+	if err != nil {
+		if isClientGone(err) {
+			abortClientGone(c, "GetEventFeed", err)
+			return
+		}
+		log.Printf("ERROR: GetEventFeed repository call failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	// 3. Respond with the cursor for the next page
+	page := eventFeedPage{Events: events, NextAfter: after, HasMore: len(events) == limit}
+	if len(events) > 0 {
+		page.NextAfter = events[len(events)-1].Seq
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,200 @@
+package httptransport
+
+import (
+	"context"
+	"sync"
+
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+)
+
+// fakeCheckRepo keeps checks in memory for handler tests. Only the methods the
+// tests reach are implemented; any other call panics on the nil embedded
+// interface, which points straight at the missing method.
+type fakeCheckRepo struct {
+	repository.CheckRepository
+
+	mu      sync.Mutex
+	checks  map[int64]*models.Check
+	deleted map[int64]bool // Soft-deleted, hidden from the lookups
+	nextID  int64
+	updates []repository.CheckUpdate // Passed to Update, in order
+	err     error                    // Returned by every call when set, to exercise the error paths
+}
+
+func newFakeCheckRepo(checks ...models.Check) *fakeCheckRepo {
+	r := &fakeCheckRepo{checks: map[int64]*models.Check{}, deleted: map[int64]bool{}, nextID: 1}
+	for _, check := range checks {
+		r.checks[check.ID] = &check
+		r.nextID = max(r.nextID, check.ID+1)
+	}
+	return r
+}
+
+func (r *fakeCheckRepo) Create(_ context.Context, check *models.Check) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	check.ID = r.nextID
+	r.nextID++
+	stored := *check
+	r.checks[check.ID] = &stored
+	return nil
+}
+
+func (r *fakeCheckRepo) FindByID(_ context.Context, id int64) (*models.Check, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	check, ok := r.checks[id]
+	if !ok || r.deleted[id] {
+		return nil, repository.ErrCheckNotFound
+	}
+	found := *check
+	return &found, nil
+}
+
+func (r *fakeCheckRepo) FindByUUID(_ context.Context, uuid string) (*models.Check, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	for id, check := range r.checks {
+		if check.UUID == uuid && !r.deleted[id] {
+			found := *check
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrCheckNotFound
+}
+
+// ListByUserID lists userID's live checks in ID order.
+func (r *fakeCheckRepo) ListByUserID(_ context.Context, userID int64) ([]models.Check, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	checks := []models.Check{}
+	for id := int64(1); id < r.nextID; id++ {
+		if check, ok := r.checks[id]; ok && check.UserID == userID && !r.deleted[id] {
+			checks = append(checks, *check)
+		}
+	}
+	return checks, nil
+}
+
+func (r *fakeCheckRepo) SetStatus(_ context.Context, uuid, status, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for id, check := range r.checks {
+		if check.UUID == uuid && !r.deleted[id] {
+			check.Status = status
+			return nil
+		}
+	}
+	return repository.ErrCheckNotFound
+}
+
+func (r *fakeCheckRepo) Delete(_ context.Context, id, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	check, ok := r.checks[id]
+	switch {
+	case !ok || check.UserID != userID:
+		return repository.ErrCheckNotFound
+	case r.deleted[id]:
+		return repository.ErrCheckAlreadyDeleted
+	}
+	r.deleted[id] = true
+	return nil
+}
+
+func (r *fakeCheckRepo) ListPingsByCheckID(context.Context, int64, int, int) ([]models.Ping, error) {
+	return []models.Ping{}, r.err
+}
+
+func (r *fakeCheckRepo) ListEventsByCheckID(context.Context, int64, int) ([]models.CheckEvent, error) {
+	return []models.CheckEvent{}, r.err
+}
+
+func (r *fakeCheckRepo) ListDependencies(context.Context, int64) ([]models.Check, error) {
+	return []models.Check{}, r.err
+}
+
+// Update applies the provided fields, as the MySQL implementation writes only those.
+func (r *fakeCheckRepo) Update(_ context.Context, id int64, update repository.CheckUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	check, ok := r.checks[id]
+	if !ok || r.deleted[id] {
+		return repository.ErrCheckNotFound
+	}
+	r.updates = append(r.updates, update)
+	if update.Name != nil {
+		check.Name = *update.Name
+	}
+	if update.Description != nil {
+		check.Description = *update.Description
+	}
+	if update.ExpectedInterval != nil {
+		check.ExpectedInterval = *update.ExpectedInterval
+	}
+	if update.GracePeriod != nil {
+		check.GracePeriod = *update.GracePeriod
+	}
+	if update.IsEnabled != nil {
+		check.IsEnabled = *update.IsEnabled
+	}
+	if update.Status != nil {
+		check.Status = *update.Status
+	}
+	if update.NotifyLate != nil {
+		check.NotifyLate = *update.NotifyLate
+	}
+	if update.Severity != nil {
+		check.Severity = *update.Severity
+	}
+	if update.Metadata != nil {
+		check.Metadata = *update.Metadata
+	}
+	if update.MaxDuration != nil {
+		check.MaxDuration = *update.MaxDuration
+	}
+	if update.WarmupPings != nil {
+		check.WarmupPings = *update.WarmupPings
+	}
+	if update.Color != nil {
+		check.Color = *update.Color
+	}
+	if update.Icon != nil {
+		check.Icon = *update.Icon
+	}
+	if update.PingsHistoryLimit != nil {
+		check.PingsHistoryLimit = *update.PingsHistoryLimit
+	}
+	if update.PingResponseCode != nil {
+		check.PingResponseCode = *update.PingResponseCode
+	}
+	if update.PingResponseBody != nil {
+		check.PingResponseBody = *update.PingResponseBody
+	}
+	if update.RecoveryStabilization != nil {
+		check.RecoveryStabilization = *update.RecoveryStabilization
+	}
+	return nil
+}
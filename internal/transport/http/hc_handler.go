@@ -0,0 +1,295 @@
+package httptransport
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HCConfig configures the healthchecks.io-compatible API.
+type HCConfig struct {
+	// BaseURL is the public URL of this instance, used for ping_url and friends.
+	BaseURL string
+	// Bounds and Descriptions are the same create limits as CheckConfig's.
+	Bounds       models.TimingBounds
+	Descriptions models.DescriptionRules
+}
+
+// healthchecks.io create defaults, used when timeout/grace are omitted.
+const (
+	hcDefaultTimeout = 86400
+	hcDefaultGrace   = 3600
+)
+
+// hcSupportedFields are the create request fields we translate. Everything else
+// healthchecks.io accepts (tags, schedule, tz, channels, unique, ...) has no
+// equivalent here and is rejected by name instead of being dropped.
+var hcSupportedFields = map[string]bool{
+	"name":    true,
+	"desc":    true,
+	"timeout": true,
+	"grace":   true,
+}
+
+// HCHandler serves a subset of the healthchecks.io management API under
+// /api/v1/hc, so tooling written against it (Terraform provider, scripts) can
+// manage checks here. Only list, create, pause and delete are implemented.
+type HCHandler struct {
+	CheckRepo repository.CheckRepository
+	Config    HCConfig
+}
+
+// NewHCHandler creates a handler for the healthchecks.io-compatible API.
+func NewHCHandler(cr repository.CheckRepository, cfg HCConfig) *HCHandler {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &HCHandler{CheckRepo: cr, Config: cfg}
+}
+
+// hcCheck is a check in healthchecks.io's field names.
+type hcCheck struct {
+	Name      string  `json:"name"`
+	Desc      string  `json:"desc"`
+	Timeout   uint32  `json:"timeout"` // expected_interval
+	Grace     uint32  `json:"grace"`   // grace_period
+	Status    string  `json:"status"`
+	Started   bool    `json:"started"`
+	LastPing  *string `json:"last_ping"`
+	NextPing  *string `json:"next_ping"`
+	UniqueKey string  `json:"unique_key"`
+	PingURL   string  `json:"ping_url"`
+	UpdateURL string  `json:"update_url"`
+	PauseURL  string  `json:"pause_url"`
+}
+
+// hcStatus maps our status onto healthchecks.io's vocabulary. It has no notion
+// of a disabled check, so those show as paused.
+func hcStatus(check *models.Check) string {
+	if !check.IsEnabled {
+		return "paused"
+	}
+	if check.Status == models.StatusLate {
+		return "grace"
+	}
+	return check.Status
+}
+
+func (h *HCHandler) toHC(check *models.Check) hcCheck {
+	// unique_key is a stable, non-secret ID for read-only use, derived the same way
+	// healthchecks.io derives it from the check code
+	sum := sha1.Sum([]byte(check.UUID))
+	out := hcCheck{
+		Name:      check.Name,
+		Desc:      check.Description.String,
+		Timeout:   check.ExpectedInterval,
+		Grace:     check.GracePeriod,
+		Status:    hcStatus(check),
+		UniqueKey: hex.EncodeToString(sum[:]),
+		PingURL:   h.Config.BaseURL + "/api/v1/ping/" + check.UUID,
+		UpdateURL: h.Config.BaseURL + "/api/v1/hc/checks/" + check.UUID,
+		PauseURL:  h.Config.BaseURL + "/api/v1/hc/checks/" + check.UUID + "/pause",
+	}
+	if check.LastPingAt.Valid {
+		lastPing := check.LastPingAt.Time.UTC().Format(time.RFC3339)
+		nextPing := check.LastPingAt.Time.Add(time.Duration(check.ExpectedInterval) * time.Second).UTC().Format(time.RFC3339)
+		out.LastPing, out.NextPing = &lastPing, &nextPing
+	}
+	return out
+}
+
+// ListChecks lists the caller's checks.
+// Method: GET /api/v1/hc/checks/
+func (h *HCHandler) ListChecks(c *gin.Context) {
+	userID, ok := hcUserID(c)
+	if !ok {
+		return
+	}
+	checks, err := h.CheckRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("ERROR: hc ListChecks failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve checks"})
+		return
+	}
+	out := make([]hcCheck, 0, len(checks))
+	for i := range checks {
+		out = append(out, h.toHC(&checks[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"checks": out})
+}
+
+// CreateCheck creates a check from a healthchecks.io create request.
+// Method: POST /api/v1/hc/checks/
+func (h *HCHandler) CreateCheck(c *gin.Context) {
+	userID, ok := hcUserID(c)
+	if !ok {
+		return
+	}
+
+	// 1. Decode loosely first so unsupported fields can be named in the error
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not read request body"})
+		return
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "could not parse request body"})
+		return
+	}
+	var unsupported []string
+	for field := range raw {
+		if !hcSupportedFields[field] {
+			unsupported = append(unsupported, field)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported fields: " + strings.Join(unsupported, ", ")})
+		return
+	}
+
+	// 2. Translate the supported ones
+	var req struct {
+		Name    string  `json:"name"`
+		Desc    *string `json:"desc"`
+		Timeout *uint32 `json:"timeout"`
+		Grace   *uint32 `json:"grace"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid field value: " + err.Error()})
+		return
+	}
+	if req.Name == "" {
+		// healthchecks.io allows unnamed checks; names are required here
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	check := models.Check{
+		UserID:           userID,
+		UUID:             uuid.NewString(),
+		Name:             req.Name,
+		ExpectedInterval: hcDefaultTimeout,
+		GracePeriod:      hcDefaultGrace,
+		IsEnabled:        true,
+		Status:           models.StatusNew,
+	}
+	description, msg := descriptionField(h.Config.Descriptions, req.Desc)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "desc: " + msg})
+		return
+	}
+	check.Description = description
+	if req.Timeout != nil {
+		check.ExpectedInterval = *req.Timeout
+	}
+	if req.Grace != nil {
+		check.GracePeriod = *req.Grace
+	}
+	if err := check.Validate(h.Config.Bounds); err != nil {
+		// Reported under healthchecks.io's field names
+		var fieldErrs models.FieldErrors
+		errors.As(err, &fieldErrs)
+		msgs := []string{}
+		for field, name := range map[string]string{"expected_interval": "timeout", "grace_period": "grace"} {
+			if msg, ok := fieldErrs[field]; ok {
+				msgs = append(msgs, name+" "+msg)
+			}
+		}
+		sort.Strings(msgs)
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(msgs, "; ")})
+		return
+	}
+
+	// 3. Create it
+	if err := h.CheckRepo.Create(c.Request.Context(), &check); err != nil {
+		if errors.Is(err, repository.ErrDuplicateName) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a check with this name already exists"})
+			return
+		}
+		log.Printf("ERROR: hc CreateCheck failed for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create check"})
+		return
+	}
+	setCreatedLocation(c, check.UUID) // Its update_url
+	c.JSON(http.StatusCreated, h.toHC(&check))
+}
+
+// PauseCheck pauses one of the caller's checks. Unlike on healthchecks.io, the
+// next ping doesn't resume it (see models.StatusPaused).
+// Method: POST /api/v1/hc/checks/{uuid}/pause
+func (h *HCHandler) PauseCheck(c *gin.Context) {
+	check, ok := h.ownedCheck(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	if err := h.CheckRepo.SetStatus(ctx, check.UUID, models.StatusPaused, models.EventSourceAPI); err != nil {
+		log.Printf("ERROR: hc PauseCheck failed for check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pause check"})
+		return
+	}
+	check.Status = models.StatusPaused
+	c.JSON(http.StatusOK, h.toHC(check))
+}
+
+// DeleteCheck deletes one of the caller's checks and returns it, as
+// healthchecks.io does.
+// Method: DELETE /api/v1/hc/checks/{uuid}
+func (h *HCHandler) DeleteCheck(c *gin.Context) {
+	check, ok := h.ownedCheck(c)
+	if !ok {
+		return
+	}
+	if err := h.CheckRepo.Delete(c.Request.Context(), check.ID, check.UserID); err != nil {
+		if errors.Is(err, repository.ErrCheckNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "check not found"})
+			return
+		}
+		log.Printf("ERROR: hc DeleteCheck failed for check %d: %v", check.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete check"})
+		return
+	}
+	c.JSON(http.StatusOK, h.toHC(check))
+}
+
+// ownedCheck loads the check named by :uuid, answering 404 (also for other
+// users' checks) and returning ok=false when the handler should stop.
+func (h *HCHandler) ownedCheck(c *gin.Context) (*models.Check, bool) {
+	userID, ok := hcUserID(c)
+	if !ok {
+		return nil, false
+	}
+	check, err := h.CheckRepo.FindByUUID(c.Request.Context(), c.Param("uuid"))
+	if err != nil || check.UserID != userID {
+		if err == nil || errors.Is(err, repository.ErrCheckNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "check not found"})
+			return nil, false
+		}
+		log.Printf("ERROR: hc failed to load check %s: %v", c.Param("uuid"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve check"})
+		return nil, false
+	}
+	return check, true
+}
+
+func hcUserID(c *gin.Context) (int64, bool) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/hc")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "authentication context error"})
+		return 0, false
+	}
+	return int64(userID), true
+}
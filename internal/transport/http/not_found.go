@@ -0,0 +1,22 @@
+package httptransport
+
+import (
+	"errors"
+	"net/http"
+
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// abortNotFound answers 404 when err is a repository not-found of any entity
+// (repository.ErrNotFound) and reports whether it did. what names the missing
+// thing in the body, e.g. "Check". Handlers that answer a particular entity's
+// not-found differently test its own sentinel first.
+func abortNotFound(c *gin.Context, err error, what string) bool {
+	if !errors.Is(err, repository.ErrNotFound) {
+		return false
+	}
+	c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": what + " not found"})
+	return true
+}
@@ -0,0 +1,33 @@
+package httptransport
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable is an update request field that can be cleared: omitted leaves the
+// setting as it is (Set false), null removes it (Set and Null), and any other
+// value replaces it. A plain pointer can't tell omitted from null.
+type Nullable[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		n.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
+
+// ptr returns the value, or nil when omitted or null, in the form the create
+// helpers take.
+func (n Nullable[T]) ptr() *T {
+	if !n.Set || n.Null {
+		return nil
+	}
+	return &n.Value
+}
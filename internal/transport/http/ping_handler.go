@@ -3,29 +3,145 @@ package httptransport
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/logging"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/pingsig"
 	"bitterlink/core/internal/repository"
+	"bitterlink/core/internal/spool"
+	"bitterlink/core/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 )
 
+// PingConfig holds tunables for the ping endpoint.
+type PingConfig struct {
+	// MaxPayloadBytes caps how much of a POST body is stored with the ping.
+	// Longer bodies are truncated, not rejected, so the heartbeat still counts.
+	MaxPayloadBytes int
+	// MaxBatchSize caps how many pings a single batch request may carry.
+	MaxBatchSize int
+	// Signer verifies signed ping URLs. nil disables signing: sig and exp are
+	// ignored and require_signed_pings can't be set.
+	Signer *pingsig.Signer
+	// RequireSignatures refuses unsigned pings to every check, not only to those
+	// with require_signed_pings.
+	RequireSignatures bool
+	// Spool, when set, accepts pings that failed on a transient database error
+	// and records them once the database is back. nil disables spooling.
+	Spool *spool.Spool
+}
+
 // PingHandler holds dependencies for ping routes
 type PingHandler struct {
 	CheckRepo repository.CheckRepository
+	Forwarder *forward.Forwarder // nil unless ping forwarding is enabled
+	Webhooks  *webhook.Sender    // nil unless account webhooks are enabled
+	Config    PingConfig
 }
 
-// NewPingHandler creates a new handler for ping operations
-func NewPingHandler(cr repository.CheckRepository) *PingHandler {
+// NewPingHandler creates a new handler for ping operations. fwd and hooks may be nil.
+func NewPingHandler(cr repository.CheckRepository, fwd *forward.Forwarder, hooks *webhook.Sender, cfg PingConfig) *PingHandler {
 	return &PingHandler{
 		CheckRepo: cr,
+		Forwarder: fwd,
+		Webhooks:  hooks,
+		Config:    cfg,
+	}
+}
+
+// enqueueForward hands a recorded ping to the forwarder, if there is one. Pings
+// that were themselves forwarded by an instance are not forwarded again.
+func (h *PingHandler) enqueueForward(c *gin.Context, p forward.Ping) {
+	if h.Forwarder == nil {
+		return
+	}
+	if c.GetHeader(forward.LoopHeader) != "" {
+		log.Printf("DEBUG: Not forwarding already-forwarded ping for UUID %s", p.UUID)
+		return
+	}
+	h.Forwarder.Enqueue(p)
+}
+
+// RunIDParam is the query parameter carrying a ping's run ID: ?rid=, the same
+// client-generated value on the start and completion pings of one execution.
+const RunIDParam = "rid"
+
+// SpooledHeader is set on the answer to a ping that was accepted into the
+// spool (PingConfig.Spool) because the database was unavailable.
+const SpooledHeader = "X-Bitterlink-Spooled"
+
+// RunIDIgnoredHeader is set on the answer to a ping whose rid was malformed.
+const RunIDIgnoredHeader = "X-Bitterlink-Rid-Ignored"
+
+// ridIgnoredKey marks in the gin context that the ping's rid was ignored, for
+// writePingResponse.
+const ridIgnoredKey = "ping_rid_ignored"
+
+// Client ping IDs: a client that retries a ping sends the same ID each time, in
+// PingIDHeader or ?pid=, and the retries are answered as duplicates (see
+// repository.ErrDuplicatePing) rather than recorded again.
+const (
+	PingIDHeader = "X-Ping-ID"
+	PingIDParam  = "pid"
+	// DuplicateHeader is set on the answer to a duplicate, which is otherwise
+	// the check's usual success answer
+	DuplicateHeader = "X-Bitterlink-Duplicate"
+	// PingIDIgnoredHeader is set on the answer to a ping whose ID was malformed
+	PingIDIgnoredHeader = "X-Bitterlink-Pid-Ignored"
+)
+
+// duplicateKey marks in the gin context that the ping was a duplicate, for
+// writePingResponse.
+const duplicateKey = "ping_duplicate"
+
+// clientPingID returns the ping's client ping ID, empty when it has none. Like
+// a malformed rid, a malformed ID is ignored rather than refusing the heartbeat,
+// which is then recorded without deduplication.
+func clientPingID(c *gin.Context) string {
+	id := c.GetHeader(PingIDHeader)
+	if id == "" {
+		id = c.Query(PingIDParam)
+	}
+	if id == "" || models.IsValidClientPingID(id) {
+		return id
 	}
+	log.Printf("WARN: Ignoring malformed client ping ID on ping for UUID %s (%d bytes)", c.Param("uuid"), len(id))
+	c.Header(PingIDIgnoredHeader, "malformed")
+	return ""
+}
+
+// runIDParam returns the ping's run ID, empty when it has none. A malformed one
+// (see models.IsValidRunID) is ignored rather than refusing the heartbeat: the
+// ping is recorded without it, and the answer says so.
+func runIDParam(c *gin.Context) string {
+	rid := c.Query(RunIDParam)
+	if rid == "" || models.IsValidRunID(rid) {
+		return rid
+	}
+	log.Printf("WARN: Ignoring malformed run ID on ping for UUID %s (%d bytes)", c.Param("uuid"), len(rid))
+	c.Header(RunIDIgnoredHeader, "malformed")
+	c.Set(ridIgnoredKey, true)
+	return ""
 }
 
 // HandlePing processes incoming pings for a check identified by UUID.
-// Method: GET or POST /ping/{uuid}
+// Method: GET or POST /ping/{uuid}, ?test=1 to only check the URL (see handleTestPing),
+// ?rid= to pair the start and completion pings of one run (see runIDParam),
+// X-Ping-ID or ?pid= to deduplicate retries (see clientPingID).
+// Browsers get an HTML page instead of JSON, see wantsPingPage.
 func (h *PingHandler) HandlePing(c *gin.Context) {
+	setNoCacheHeaders(c)
+
 	uuid := c.Param("uuid")
 	if uuid == "" {
 		// Although route matching usually prevents this, good to check.
@@ -36,6 +152,25 @@ func (h *PingHandler) HandlePing(c *gin.Context) {
 	// Optional: Validate UUID format if desired
 	// e.g., using a regex or a UUID library
 
+	// /ping/{uuid}/start and /ping/{uuid}/fail carry a signal; plain /ping/{uuid} is a success
+	kind := models.PingKindSuccess
+	if signal := c.Param("signal"); signal != "" {
+		if signal == models.PingKindSuccess || !models.IsValidPingKind(signal) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Unknown ping signal"})
+			return
+		}
+		kind = signal
+	}
+	if !h.verifySignature(c, uuid, kind) {
+		return
+	}
+	runID := runIDParam(c)
+	pingID := clientPingID(c)
+	if isTestPing(c) {
+		h.handleTestPing(c, uuid, kind)
+		return
+	}
+
 	// Capture client info (handle potential nulls for DB)
 	clientIP := sql.NullString{
 		String: c.ClientIP(),
@@ -45,27 +180,397 @@ func (h *PingHandler) HandlePing(c *gin.Context) {
 		String: c.Request.UserAgent(),
 		Valid:  c.Request.UserAgent() != "",
 	}
+	payload, err := h.readPayload(c)
+	if err != nil {
+		log.Printf("WARN: Failed to read ping body for UUID %s: %v", uuid, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
 	ctx := c.Request.Context() // Use request context
 
-	err := h.CheckRepo.RecordPing(ctx, uuid, clientIP, userAgent)
+	var response models.PingResponse
+	record := repository.PingRecord{
+		UUID:         uuid,
+		Kind:         kind,
+		SourceIP:     clientIP,
+		UserAgent:    userAgent,
+		Payload:      payload,
+		RunID:        runID,
+		ClientPingID: pingID,
+		Response:     &response,
+	}
+	spooled := false
+	if h.Config.Spool.Pending(uuid) {
+		// Earlier pings of this check wait in the spool; queue up behind them
+		err = h.Config.Spool.Append(record)
+		spooled = err == nil
+	} else {
+		err = h.CheckRepo.RecordPing(ctx, record)
+		if h.Config.Spool != nil && repository.IsTransient(err) {
+			if spoolErr := h.Config.Spool.Append(record); spoolErr != nil {
+				log.Printf("WARN: Failed to spool ping for UUID %s: %v", uuid, spoolErr)
+			} else {
+				log.Printf("WARN: Database unavailable, spooled '%s' ping for UUID %s: %v", kind, uuid, err)
+				err, spooled = nil, true
+			}
+		}
+	}
+	result := pingResult(err)
+	if spooled {
+		result = "spooled"
+	}
+	metrics.Incr(metrics.PingsIngested, "kind:"+kind, "result:"+result)
+	duplicate := errors.Is(err, repository.ErrDuplicatePing)
+	if duplicate {
+		err = nil // Answered as a success, below
+	}
 
 	if err != nil {
-		// Check for the specific "not found" error from the repository
-		if errors.Is(err, repository.ErrCheckNotFound) {
+		if isClientGone(err) {
+			// The pinger hung up; whether the ping was recorded is down to timing
+			abortClientGone(c, "ping", err)
+		} else if errors.Is(err, repository.ErrCheckNotFound) {
+			// Check for the specific "not found" error from the repository
 			log.Printf("WARN: Ping received for unknown/inactive UUID: %s", uuid)
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Check not found or inactive"})
+		} else if errors.Is(err, repository.ErrCheckInactive) {
+			// Rejected by INACTIVE_PING_POLICY=reject
+			log.Printf("INFO: Ping rejected for disabled/paused UUID: %s", uuid)
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Check is disabled or paused"})
 		} else {
 			// Log the underlying error details for server-side debugging
-			log.Printf("ERROR: Failed processing ping for UUID %s: %v", uuid, err)
+			logging.Errorf("Failed processing ping for UUID %s: %v", uuid, err)
 			// Return a generic server error to the client
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to process ping"})
 		}
 		return // Stop processing
 	}
+	if spooled {
+		// Accepted, not yet recorded: the check's custom response is unknown, and
+		// the forwarder and webhook would look the check up in the database too
+		c.Header(SpooledHeader, "true")
+		writePingResponse(c, models.PingResponse{})
+		return
+	}
+
+	if duplicate {
+		// Answered like the original, which was forwarded and announced already
+		log.Printf("DEBUG: Duplicate ping %s for UUID %s", pingID, uuid)
+		c.Header(DuplicateHeader, "true")
+		c.Set(duplicateKey, true)
+		writePingResponse(c, response)
+		return
+	}
+
+	// Success! Forwarding and the account webhook happen in the background and
+	// can't change the response.
+	h.enqueueForward(c, forward.Ping{UUID: uuid, Kind: kind, Method: c.Request.Method, Payload: payload})
+	h.Webhooks.Enqueue(webhook.Event{Type: webhook.EventPing, CheckUUID: uuid, PingKind: kind})
+
+	if wantsPingPage(c, response) {
+		writePingPage(c, kind, response)
+		return
+	}
+	writePingResponse(c, response)
+}
+
+// writePingResponse answers a recorded ping: a simple 'ok' by default, or the
+// check's custom status and plain-text body. Only successes are customised;
+// errors keep their usual JSON shape. The default answer also flags an ignored
+// rid and a duplicate, which custom answers only carry in RunIDIgnoredHeader
+// and DuplicateHeader.
+func writePingResponse(c *gin.Context, response models.PingResponse) {
+	code := http.StatusOK
+	if response.Code.Valid {
+		code = int(response.Code.Int32)
+	}
+	switch {
+	case code == http.StatusNoContent:
+		c.Status(code)
+	case response.Body.Valid:
+		c.Data(code, "text/plain; charset=utf-8", []byte(response.Body.String))
+	default:
+		body := gin.H{
+			"status": "ok",
+		}
+		if c.GetBool(ridIgnoredKey) {
+			body["rid_ignored"] = true
+		}
+		if c.GetBool(duplicateKey) {
+			body["duplicate"] = true
+		}
+		c.JSON(code, body)
+	}
+}
+
+// TestPingParam is the query parameter marking a test ping: ?test=1 (or true).
+const TestPingParam = "test"
+
+func isTestPing(c *gin.Context) bool {
+	test, err := strconv.ParseBool(c.Query(TestPingParam))
+	return err == nil && test
+}
+
+// handleTestPing answers a test ping, sent while setting up a cron to confirm its
+// URL: 404 for an unknown UUID, otherwise the standard success response.
+// Nothing is recorded or forwarded; last_ping_at, the status and the ping
+// history are untouched. The signature was already verified like for any ping,
+// but the InactivePingPolicy isn't applied, so a disabled check still answers
+// success. Only the check's existence is looked up (ExistsByUUID), so a custom
+// ping_response_code/body isn't applied here.
+func (h *PingHandler) handleTestPing(c *gin.Context, uuid, kind string) {
+	checkID, active, err := h.CheckRepo.ExistsByUUID(c.Request.Context(), uuid)
+	switch {
+	case errors.Is(err, repository.ErrCheckNotFound):
+		log.Printf("WARN: Test ping received for unknown UUID: %s", uuid)
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Check not found or inactive"})
+		return
+	case isClientGone(err):
+		abortClientGone(c, "test ping", err)
+		return
+	case err != nil:
+		log.Printf("ERROR: Failed to look up check %s for test ping: %v", uuid, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to process ping"})
+		return
+	}
+	log.Printf("INFO: Test '%s' ping for check ID %d (UUID: %s, active: %t), not recorded", kind, checkID, uuid, active)
+	writePingResponse(c, models.PingResponse{})
+}
+
+// verifySignature enforces signed ping URLs on the unauthenticated ping routes.
+// It returns false, having answered, when the ping must be refused. A present
+// signature is always verified; a missing one is only refused when the instance
+// or the check requires signatures.
+func (h *PingHandler) verifySignature(c *gin.Context, uuid, kind string) bool {
+	if h.Config.Signer == nil {
+		return true
+	}
+	err := h.Config.Signer.Verify(uuid, c.Query(pingsig.ParamSignature), c.Query(pingsig.ParamExpires), time.Now())
+	if errors.Is(err, pingsig.ErrMissing) && !h.Config.RequireSignatures {
+		// Only unsigned pings pay for this lookup, and only while signing is
+		// configured but not instance-wide
+		check, lookupErr := h.CheckRepo.FindByUUID(c.Request.Context(), uuid)
+		switch {
+		case errors.Is(lookupErr, repository.ErrCheckNotFound):
+			return true // RecordPing answers 404
+		case isClientGone(lookupErr):
+			abortClientGone(c, "ping", lookupErr)
+			return false
+		case lookupErr != nil:
+			log.Printf("ERROR: Failed to load check %s to verify ping signature: %v", uuid, lookupErr)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to process ping"})
+			return false
+		case !check.RequireSignedPings:
+			return true
+		}
+	}
+	if err == nil {
+		return true
+	}
+	log.Printf("WARN: Ping refused for UUID %s: %v", uuid, err)
+	metrics.Incr(metrics.PingsIngested, "kind:"+kind, "result:unauthorized")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	return false
+}
+
+// pingResult is the metrics result tag for a RecordPing error.
+func pingResult(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, repository.ErrCheckNotFound):
+		return "not_found"
+	case errors.Is(err, repository.ErrCheckInactive):
+		return "inactive"
+	case errors.Is(err, repository.ErrDuplicatePing):
+		return "duplicate"
+	case isClientGone(err):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+// setNoCacheHeaders stops CDNs and proxies from caching ping responses. A cached
+// "ok" would hide the fact that the pinger can no longer reach us.
+func setNoCacheHeaders(c *gin.Context) {
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	c.Header("Pragma", "no-cache")
+	c.Header("Expires", "0")
+}
+
+// readPayload reads the request body (POST pings only), truncated to MaxPayloadBytes.
+// Returns nil when there is no body to store. Non-empty bodies are measured for
+// the payload size metrics, see recordPayloadSize.
+func (h *PingHandler) readPayload(c *gin.Context) ([]byte, error) {
+	if c.Request.Method != http.MethodPost || c.Request.Body == nil || h.Config.MaxPayloadBytes <= 0 {
+		return nil, nil
+	}
+
+	// Read one byte past the limit so we can tell whether truncation happened
+	limit := int64(h.Config.MaxPayloadBytes)
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		log.Printf("WARN: Ping payload for UUID %s exceeds %d bytes, truncating", c.Param("uuid"), limit)
+		// The rest of the body is never read: its size comes from
+		// Content-Length, or is only known to be over the limit when chunked
+		size := max(c.Request.ContentLength, limit+1)
+		recordPayloadSize(size, true)
+		body = body[:limit]
+	} else if len(body) > 0 {
+		recordPayloadSize(int64(len(body)), false)
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+	return body, nil
+}
+
+// recordPayloadSize feeds the payload size histogram, and the truncation
+// counter, for one ping body of size bytes as received. Nothing identifying the
+// check or sender is attached; the metrics are only meant for tuning
+// PING_MAX_PAYLOAD_BYTES and retention.
+func recordPayloadSize(size int64, truncated bool) {
+	metrics.Histogram(metrics.PingPayloadBytes, float64(size))
+	if truncated {
+		metrics.Incr(metrics.PingPayloadsTruncated)
+	}
+}
+
+// BatchPingItem is one heartbeat within a batch ping request.
+type BatchPingItem struct {
+	UUID    string `json:"uuid" binding:"required"`
+	Signal  string `json:"signal"` // "", "success", "start" or "fail"
+	Payload string `json:"payload"`
+	RunID   string `json:"rid"` // Optional, as ?rid= on single pings
+	PingID  string `json:"pid"` // Optional, as X-Ping-ID on single pings
+}
+
+// BatchPingResult reports the outcome of one BatchPingItem, in request order.
+type BatchPingResult struct {
+	UUID   string `json:"uuid"`
+	Status string `json:"status"` // "ok", "duplicate", "not_found", "inactive" or "invalid_signal"
+	// RunIDIgnored is set when the item's rid was malformed and the ping was
+	// recorded without it.
+	RunIDIgnored bool `json:"rid_ignored,omitempty"`
+	// PingIDIgnored likewise, for its pid.
+	PingIDIgnored bool `json:"pid_ignored,omitempty"`
+}
+
+// HandlePingBatch records several heartbeats from one agent in a single transaction.
+// Only the authenticated user's checks are matched; other UUIDs report not_found.
+// Method: POST /api/v1/pings/batch
+func (h *PingHandler) HandlePingBatch(c *gin.Context) {
+	setNoCacheHeaders(c)
+
+	var items []BatchPingItem
+	if err := c.ShouldBindJSON(&items); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if len(items) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Batch must contain at least one ping"})
+		return
+	}
+	if h.Config.MaxBatchSize > 0 && len(items) > h.Config.MaxBatchSize {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("Batch must not contain more than %d pings", h.Config.MaxBatchSize),
+		})
+		return
+	}
+
+	userIDtmp, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		log.Println("ERROR: UserID not found in context for protected route /api/v1/pings/batch")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication context error"})
+		return
+	}
+	userID := int64(userIDtmp)
+
+	clientIP := sql.NullString{String: c.ClientIP(), Valid: c.ClientIP() != ""}
+	userAgent := sql.NullString{String: c.Request.UserAgent(), Valid: c.Request.UserAgent() != ""}
+
+	// Validate signals up front; only valid items are sent to the repository
+	results := make([]BatchPingResult, len(items))
+	records := make([]repository.PingRecord, 0, len(items))
+	recordIndex := make([]int, 0, len(items)) // records[i] belongs to results[recordIndex[i]]
+	for i, item := range items {
+		results[i].UUID = item.UUID
+		kind := item.Signal
+		if kind == "" {
+			kind = models.PingKindSuccess
+		}
+		if !models.IsValidPingKind(kind) {
+			results[i].Status = "invalid_signal"
+			continue
+		}
+
+		runID := item.RunID
+		if runID != "" && !models.IsValidRunID(runID) {
+			results[i].RunIDIgnored = true
+			runID = ""
+		}
+		pingID := item.PingID
+		if pingID != "" && !models.IsValidClientPingID(pingID) {
+			results[i].PingIDIgnored = true
+			pingID = ""
+		}
+
+		var payload []byte
+		if item.Payload != "" {
+			payload = []byte(item.Payload)
+			truncated := h.Config.MaxPayloadBytes > 0 && len(payload) > h.Config.MaxPayloadBytes
+			recordPayloadSize(int64(len(payload)), truncated)
+			if truncated {
+				payload = payload[:h.Config.MaxPayloadBytes]
+			}
+		}
+		records = append(records, repository.PingRecord{
+			UUID:         item.UUID,
+			Kind:         kind,
+			SourceIP:     clientIP,
+			UserAgent:    userAgent,
+			Payload:      payload,
+			RunID:        runID,
+			ClientPingID: pingID,
+		})
+		recordIndex = append(recordIndex, i)
+	}
+
+	if len(records) > 0 {
+		recordErrs, err := h.CheckRepo.RecordPingsBatch(c.Request.Context(), userID, records)
+		if err != nil {
+			log.Printf("ERROR: Failed processing ping batch of %d for user %d: %v", len(records), userID, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to process ping batch"})
+			return
+		}
+		for i, recordErr := range recordErrs {
+			switch {
+			case recordErr == nil:
+				results[recordIndex[i]].Status = "ok"
+				metrics.Incr(metrics.PingsIngested, "kind:"+records[i].Kind, "result:ok")
+				method := http.MethodGet // Batch items arrive together; forward each like a single ping
+				if records[i].Payload != nil {
+					method = http.MethodPost
+				}
+				h.enqueueForward(c, forward.Ping{UUID: records[i].UUID, Kind: records[i].Kind, Method: method, Payload: records[i].Payload})
+				h.Webhooks.Enqueue(webhook.Event{Type: webhook.EventPing, CheckUUID: records[i].UUID, PingKind: records[i].Kind})
+			case errors.Is(recordErr, repository.ErrDuplicatePing):
+				results[recordIndex[i]].Status = "duplicate"
+				metrics.Incr(metrics.PingsIngested, "kind:"+records[i].Kind, "result:duplicate")
+			case errors.Is(recordErr, repository.ErrCheckInactive):
+				results[recordIndex[i]].Status = "inactive"
+				metrics.Incr(metrics.PingsIngested, "kind:"+records[i].Kind, "result:inactive")
+			default:
+				results[recordIndex[i]].Status = "not_found"
+				metrics.Incr(metrics.PingsIngested, "kind:"+records[i].Kind, "result:not_found")
+			}
+		}
+	}
 
-	// Success!
-	// Return a simple 'ok' response.
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-	})
+	c.JSON(http.StatusOK, gin.H{"results": results})
 }
@@ -0,0 +1,306 @@
+package httptransport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dedupRepo records pings like RecordPing does with a client ping ID: the
+// first one per ID is recorded, later ones are duplicates.
+type dedupRepo struct {
+	repository.CheckRepository
+
+	mu       sync.Mutex
+	seen     map[string]bool
+	recorded int
+}
+
+func (r *dedupRepo) RecordPing(_ context.Context, ping repository.PingRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ping.ClientPingID != "" && r.seen[ping.ClientPingID] {
+		return repository.ErrDuplicatePing
+	}
+	r.seen[ping.ClientPingID] = true
+	r.recorded++
+	return nil
+}
+
+func newPingTestRouter(repo repository.CheckRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ping/:uuid", NewPingHandler(repo, nil, nil, PingConfig{}).HandlePing)
+	return router
+}
+
+// pingBody is the default success answer.
+type pingBody struct {
+	Status    string `json:"status"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+func sendPing(t *testing.T, router http.Handler, pingID string) (*httptest.ResponseRecorder, pingBody) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/ping/uuid-7", nil)
+	req.Header.Set(PingIDHeader, pingID)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var body pingBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Errorf("decoding ping answer %q: %v", rec.Body, err)
+	}
+	return rec, body
+}
+
+func TestHandlePingDuplicate(t *testing.T) {
+	repo := &dedupRepo{seen: map[string]bool{}}
+	router := newPingTestRouter(repo)
+
+	first, body := sendPing(t, router, "retry-1")
+	if first.Code != http.StatusOK || body.Status != "ok" || body.Duplicate || first.Header().Get(DuplicateHeader) != "" {
+		t.Errorf("first ping = %d %+v (header %q), want a plain ok", first.Code, body, first.Header().Get(DuplicateHeader))
+	}
+	retry, body := sendPing(t, router, "retry-1")
+	if retry.Code != http.StatusOK || body.Status != "ok" || !body.Duplicate || retry.Header().Get(DuplicateHeader) != "true" {
+		t.Errorf("retry = %d %+v (header %q), want ok with duplicate: true", retry.Code, body, retry.Header().Get(DuplicateHeader))
+	}
+	if repo.recorded != 1 {
+		t.Errorf("recorded %d pings, want 1", repo.recorded)
+	}
+}
+
+// Concurrent retries are all answered with success, only one of them as the
+// original.
+func TestHandlePingConcurrentDuplicates(t *testing.T) {
+	repo := &dedupRepo{seen: map[string]bool{}}
+	router := newPingTestRouter(repo)
+
+	const submissions = 8
+	var wg sync.WaitGroup
+	bodies := make([]pingBody, submissions)
+	codes := make([]int, submissions)
+	for i := range submissions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec, body := sendPing(t, router, "retry-1")
+			codes[i], bodies[i] = rec.Code, body
+		}()
+	}
+	wg.Wait()
+
+	originals := 0
+	for i := range submissions {
+		if codes[i] != http.StatusOK {
+			t.Errorf("submission %d = %d, want 200", i, codes[i])
+		}
+		if !bodies[i].Duplicate {
+			originals++
+		}
+	}
+	if originals != 1 || repo.recorded != 1 {
+		t.Errorf("%d answers without duplicate, %d recorded; want 1 and 1", originals, repo.recorded)
+	}
+}
+
+// A malformed ID is ignored, so the ping is recorded without deduplication.
+func TestHandlePingMalformedID(t *testing.T) {
+	repo := &dedupRepo{seen: map[string]bool{}}
+	router := newPingTestRouter(repo)
+
+	rec, body := sendPing(t, router, "bad id with spaces")
+	if rec.Code != http.StatusOK || body.Duplicate || rec.Header().Get(PingIDIgnoredHeader) != "malformed" {
+		t.Errorf("ping = %d %+v (ignored header %q), want ok with the ID ignored", rec.Code, body, rec.Header().Get(PingIDIgnoredHeader))
+	}
+}
+
+// namedCheckRepo records pings to a check called name, filling in the
+// response details as recordPingTx does.
+type namedCheckRepo struct {
+	repository.CheckRepository
+
+	name     string
+	recorded int
+}
+
+func (r *namedCheckRepo) RecordPing(_ context.Context, ping repository.PingRecord) error {
+	r.recorded++
+	if ping.Response != nil {
+		*ping.Response = models.PingResponse{CheckName: r.name, ExpectedInterval: 3600}
+	}
+	return nil
+}
+
+func TestHandlePingNegotiation(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		wantHTML bool
+	}{
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", wantHTML: true},
+		{name: "html only", accept: "text/html", wantHTML: true},
+		{name: "curl", accept: "*/*"},
+		{name: "json", accept: "application/json"},
+		{name: "no accept header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &namedCheckRepo{name: "nightly backup"}
+			router := newPingTestRouter(repo)
+
+			req := httptest.NewRequest(http.MethodGet, "/ping/uuid-7", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK || repo.recorded != 1 {
+				t.Fatalf("ping = %d with %d recorded, want 200 and 1", rec.Code, repo.recorded)
+			}
+			contentType := rec.Header().Get("Content-Type")
+			if tt.wantHTML {
+				if !strings.HasPrefix(contentType, "text/html") || !strings.Contains(rec.Body.String(), "<strong>nightly backup</strong>") {
+					t.Errorf("answer = %s %q, want the HTML page naming the check", contentType, rec.Body)
+				}
+				return
+			}
+			var body pingBody
+			if !strings.HasPrefix(contentType, "application/json") || json.Unmarshal(rec.Body.Bytes(), &body) != nil || body.Status != "ok" {
+				t.Errorf("answer = %s %q, want the JSON ok", contentType, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandlePingPageEscapesName(t *testing.T) {
+	repo := &namedCheckRepo{name: "<script>alert(1)</script>"}
+	router := newPingTestRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping/uuid-7", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	page := rec.Body.String()
+	if strings.Contains(page, "<script>") || !strings.Contains(page, "&lt;script&gt;alert(1)&lt;/script&gt;") {
+		t.Errorf("page = %q, want the check name escaped", page)
+	}
+}
+
+// recordingMetrics keeps the histogram samples and counts it is given.
+type recordingMetrics struct {
+	metrics.Nop
+
+	mu         sync.Mutex
+	histograms map[string][]float64
+	counts     map[string]int64
+}
+
+func (m *recordingMetrics) Count(name string, value int64, tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[name] += value
+}
+
+func (m *recordingMetrics) Histogram(name string, value float64, tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.histograms[name] = append(m.histograms[name], value)
+}
+
+// payloadRepo keeps the payload of the last recorded ping.
+type payloadRepo struct {
+	repository.CheckRepository
+	payload []byte
+}
+
+func (r *payloadRepo) RecordPing(_ context.Context, ping repository.PingRecord) error {
+	r.payload = ping.Payload
+	return nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+// The payload metrics come from the one limited read that also produces the
+// stored payload: the body is never read past the limit, nor twice.
+func TestHandlePingPayloadMetrics(t *testing.T) {
+	const limit = 100
+	tests := []struct {
+		name          string
+		body          int
+		chunked       bool
+		wantSize      []float64
+		wantTruncated int64
+		wantStored    int
+		wantRead      int
+	}{
+		{name: "empty", body: 0},
+		{name: "within the limit", body: 40, wantSize: []float64{40}, wantStored: 40, wantRead: 40},
+		{name: "at the limit", body: limit, wantSize: []float64{limit}, wantStored: limit, wantRead: limit},
+		// Only limit+1 bytes are read; the size is Content-Length's
+		{name: "truncated", body: 250, wantSize: []float64{250}, wantTruncated: 1, wantStored: limit, wantRead: limit + 1},
+		// Without a Content-Length the size is only known to be over the limit
+		{name: "truncated, chunked", body: 250, chunked: true, wantSize: []float64{limit + 1}, wantTruncated: 1, wantStored: limit, wantRead: limit + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingMetrics{histograms: map[string][]float64{}, counts: map[string]int64{}}
+			metrics.SetDefault(recorder)
+			t.Cleanup(func() { metrics.SetDefault(nil) })
+
+			repo := &payloadRepo{}
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/ping/:uuid", NewPingHandler(repo, nil, nil, PingConfig{MaxPayloadBytes: limit}).HandlePing)
+
+			body := &countingReader{r: strings.NewReader(strings.Repeat("x", tt.body))}
+			req := httptest.NewRequest(http.MethodPost, "/ping/uuid-7", body)
+			req.ContentLength = int64(tt.body)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST = %d: %s", rec.Code, rec.Body)
+			}
+
+			if got := recorder.histograms[metrics.PingPayloadBytes]; !slices.Equal(got, tt.wantSize) {
+				t.Errorf("%s samples = %v, want %v", metrics.PingPayloadBytes, got, tt.wantSize)
+			}
+			if got := recorder.counts[metrics.PingPayloadsTruncated]; got != tt.wantTruncated {
+				t.Errorf("%s = %d, want %d", metrics.PingPayloadsTruncated, got, tt.wantTruncated)
+			}
+			if len(repo.payload) != tt.wantStored {
+				t.Errorf("stored payload of %d bytes, want %d", len(repo.payload), tt.wantStored)
+			}
+			if body.read != tt.wantRead {
+				t.Errorf("read %d bytes of the body, want %d", body.read, tt.wantRead)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package httptransport
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"bitterlink/core/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// People paste ping URLs into a browser to try them. A GET ping whose Accept
+// header prefers HTML gets a short page instead of the JSON meant for scripts;
+// the ping itself is recorded exactly as any other.
+
+//go:embed templates/ping_received.html
+var pingPageSource string
+
+// pingPage is parsed with html/template, which escapes the check name.
+var pingPage = template.Must(template.New("ping_received").Parse(pingPageSource))
+
+// pingPageData is what pingPage shows.
+type pingPageData struct {
+	CheckName  string
+	Kind       string // models.PingKind*
+	ReceivedAt time.Time
+	NextPingBy time.Time // Successes only
+}
+
+// wantsPingPage reports whether a recorded ping should be answered with
+// pingPage: a GET from a client that lists text/html before JSON in Accept (as
+// browsers do), to a check without a custom ping response. curl sends */*,
+// which gets JSON, as does a missing Accept header.
+func wantsPingPage(c *gin.Context, response models.PingResponse) bool {
+	if c.Request.Method != http.MethodGet || response.Code.Valid || response.Body.Valid {
+		return false
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
+
+// writePingPage answers a recorded ping with pingPage.
+func writePingPage(c *gin.Context, kind string, response models.PingResponse) {
+	now := time.Now().UTC().Truncate(time.Second)
+	data := pingPageData{
+		CheckName:  response.CheckName,
+		Kind:       kind,
+		ReceivedAt: now,
+		NextPingBy: now.Add(time.Duration(response.ExpectedInterval) * time.Second),
+	}
+	var page bytes.Buffer
+	if err := pingPage.Execute(&page, data); err != nil {
+		// The ping is recorded; fall back to the plain answer
+		log.Printf("ERROR: Failed to render ping page: %v", err)
+		writePingResponse(c, response)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
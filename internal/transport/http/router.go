@@ -1,23 +1,48 @@
 package httptransport
 
 import (
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/logging"
 	"bitterlink/core/internal/middleware"
 	"bitterlink/core/internal/repository"
+	gqltransport "bitterlink/core/internal/transport/graphql"
+	"bitterlink/core/internal/version"
 	"database/sql"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RegisterRoutes sets up all the application routes
+// RegisterRoutes sets up all the application routes.
+// limiter may be nil, in which case the API is not rate limited. trustedHeader
+// is nil unless gateway header authentication is enabled; API keys are always accepted.
+// securityHeaders is nil when SECURITY_HEADERS is off, actionHandler when action
+// links are. pingLimiter, nil when off, limits the ping routes per source IP
+// before anything else happens, authentication included.
 func RegisterRoutes(
 	router *gin.Engine,
 	pingHandler *PingHandler,
 	checkHandler *CheckHandler,
 	dbPool *sql.DB,
 	repo repository.CheckRepository,
+	limiter middleware.RateLimiter,
+	trustedHeader *middleware.TrustedHeaderConfig,
+	hcHandler *HCHandler,
+	adminHandler *AdminHandler,
+	accountHandler *AccountHandler,
+	securityHeaders *middleware.SecurityHeadersConfig,
+	actionHandler *ActionHandler,
+	channelHandler *ChannelHandler,
+	pingLimiter *middleware.IPLimiter,
 ) {
+	router.Use(middleware.MetricsMiddleware())
+	if securityHeaders != nil {
+		router.Use(middleware.SecurityHeadersMiddleware(*securityHeaders))
+	}
+
 	// --- Public Routes ---
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -26,21 +51,196 @@ func RegisterRoutes(
 		})
 	})
 
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":      "ok",
-			"server_time": time.Now().UTC().Format(time.RFC3339Nano),
-		})
+	RegisterHealthRoutes(router)
+
+	router.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, version.Get())
 	})
 
+	// Everything below queries the database, so fails fast while it is unreachable
+	dbCircuit := middleware.DBCircuitMiddleware()
+
 	// --- API v1 Routes ---
-	apiV1 := router.Group("/api/v1")
+	apiV1 := router.Group("/api/v1", dbCircuit)
+
+	auth := middleware.APIKeyAuthMiddleware(dbPool)
+	if trustedHeader != nil {
+		auth = middleware.TrustedHeaderAuthMiddleware(*trustedHeader, auth)
+	}
+	apiV1.Use(auth)
+	if limiter != nil {
+		apiV1.Use(middleware.RateLimitMiddleware(limiter)) // After auth, so buckets are per user
+	}
+
+	// Ping routes: the same, behind the per-IP limiter
+	pings := router.Group("/api/v1")
+	if pingLimiter != nil {
+		pings.Use(middleware.IPRateLimitMiddleware(pingLimiter))
+	}
+	pings.Use(dbCircuit, auth)
+	if limiter != nil {
+		pings.Use(middleware.RateLimitMiddleware(limiter))
+	}
+	{
+		pings.GET("/ping/:uuid", pingHandler.HandlePing)
+		pings.POST("/ping/:uuid", pingHandler.HandlePing)        // POST bodies are stored as the ping payload
+		pings.GET("/ping/:uuid/:signal", pingHandler.HandlePing) // start / fail signals
+		pings.POST("/ping/:uuid/:signal", pingHandler.HandlePing)
+		pings.POST("/pings/batch", pingHandler.HandlePingBatch)
+	}
 
-	apiV1.Use(middleware.APIKeyAuthMiddleware(dbPool))
 	{
 		// Check management endpoints
-		apiV1.GET("/ping/:uuid", pingHandler.HandlePing)
 		apiV1.POST("/checks", checkHandler.CreateCheck)
 		apiV1.GET("/checks", checkHandler.GetChecks)
+		apiV1.POST("/checks/pause-all", checkHandler.PauseAll) // Every check of the caller, in one transaction
+		apiV1.POST("/checks/resume-all", checkHandler.ResumeAll)
+		apiV1.POST("/checks/bulk-action", checkHandler.BulkAction) // Filtered, with dry_run
+		apiV1.GET("/checks/active", checkHandler.GetActiveChecks)  // Only those the worker evaluates
+		// Every /checks/:id route takes the check's numeric ID
+		apiV1.GET("/checks/:id", checkHandler.GetCheck)
+		apiV1.PATCH("/checks/:id", checkHandler.UpdateCheck) // Re-evaluates shortened deadlines
+		apiV1.PUT("/checks/:id", checkHandler.UpdateCheck)   // Same partial update, for clients that only PUT
+		apiV1.DELETE("/checks/:id", checkHandler.DeleteCheck)
+		apiV1.GET("/checks/:id/pings", checkHandler.GetCheckPings)
+		apiV1.GET("/checks/:id/events", checkHandler.GetCheckEvents)
+		apiV1.GET("/events", checkHandler.GetEventFeed)
+		apiV1.GET("/checks/:id/dependencies", checkHandler.GetCheckDependencies) // Suppress alerts while a dependency is down
+		apiV1.POST("/checks/:id/dependencies", checkHandler.AddCheckDependency)
+		apiV1.DELETE("/checks/:id/dependencies/:dependency", checkHandler.RemoveCheckDependency)
+		apiV1.POST("/graphql", gqltransport.NewHandler(repo).ServeGraphQL) // Read-only dashboard queries
+
+		// Account data export, also needs the account password (PasswordHeader)
+		apiV1.GET("/account/export", accountHandler.ExportAccount)
+		apiV1.POST("/account/export", accountHandler.RequestExport) // Built in the background
+		apiV1.GET("/account/export/:id", accountHandler.GetExport)
+
+		// Account-wide event webhook, every event of every check
+		apiV1.GET("/account/webhook", accountHandler.GetWebhook)
+		apiV1.PUT("/account/webhook", accountHandler.SetWebhook)
+		apiV1.DELETE("/account/webhook", accountHandler.DeleteWebhook)
+
+		// Notification channels and their verification before first use.
+		// /channels is the older name, kept as an alias.
+		for _, prefix := range []string{"/notification-channels", "/channels"} {
+			apiV1.GET(prefix, channelHandler.GetChannels)
+			apiV1.POST(prefix, channelHandler.CreateChannel) // Pending verification until verified below
+			apiV1.GET(prefix+"/:id", channelHandler.GetChannel)
+			apiV1.PATCH(prefix+"/:id", channelHandler.UpdateChannel) // A new destination must be verified again
+			apiV1.DELETE(prefix+"/:id", channelHandler.DeleteChannel)
+			apiV1.POST(prefix+"/:id/verify", channelHandler.VerifyChannel) // Resend or re-test, rate limited per channel
+		}
+	}
+
+	// --- Operator endpoints, admin-scoped API keys only ---
+	admin := apiV1.Group("/admin", middleware.RequireAdminScope())
+	{
+		admin.GET("/stats", adminHandler.GetStats)
+		admin.GET("/orphaned-pings", adminHandler.GetOrphanedPings) // Pings of checks that no longer exist
+		admin.POST("/orphaned-pings/cleanup", adminHandler.CleanupOrphanedPings)
+		admin.GET("/notification-blackout", adminHandler.GetNotificationBlackout) // Global kill-switch, see internal/blackout
+		admin.PUT("/notification-blackout", adminHandler.StartNotificationBlackout)
+		admin.DELETE("/notification-blackout", adminHandler.EndNotificationBlackout)
+	}
+
+	// --- Per-check Prometheus gauges, scraped with an API key ---
+	checkMetrics := router.Group("/metrics", dbCircuit, auth)
+	if limiter != nil {
+		checkMetrics.Use(middleware.RateLimitMiddleware(limiter))
+	}
+	{
+		checkMetrics.GET("/checks", checkHandler.GetCheckMetrics)
+	}
+
+	// --- Action links from alerts, authorized by their signed token alone ---
+	if actionHandler != nil {
+		router.GET("/actions/:token", dbCircuit, actionHandler.ShowAction) // Confirmation page only
+		router.POST("/actions/:token", dbCircuit, actionHandler.ApplyAction)
+	}
+
+	// Email channel confirmation links, authorized by their token
+	router.GET("/channels/confirm/:id/:token", dbCircuit, channelHandler.ConfirmChannel)
+
+	// --- healthchecks.io-compatible API ---
+	// Its own group: it authenticates with X-Api-Key, not the Bearer middleware above.
+	hc := router.Group("/api/v1/hc")
+	hc.Use(dbCircuit, middleware.HCAPIKeyMiddleware(dbPool))
+	if limiter != nil {
+		hc.Use(middleware.RateLimitMiddleware(limiter))
+	}
+	{
+		hc.GET("/checks/", hcHandler.ListChecks)
+		hc.POST("/checks/", hcHandler.CreateCheck)
+		hc.POST("/checks/:uuid/pause", hcHandler.PauseCheck)
+		hc.DELETE("/checks/:uuid", hcHandler.DeleteCheck)
 	}
 }
+
+// RegisterHealthRoutes sets up the unauthenticated probe endpoints. It is the
+// only route set served by worker-role instances.
+//
+// A failing log writer (e.g. a full disk) reports "degraded" but keeps the 200:
+// restarting the instance wouldn't free the disk, and it can still serve pings.
+// The same goes for database clock skew beyond the warning threshold, and for an
+// open database circuit breaker: the database is what needs fixing.
+// /health/ready answers 503 while the breaker is open, so a load balancer can
+// route around this instance; with one database, every instance answers alike.
+// It reports the database clock skew as /health does, without failing on it.
+func RegisterHealthRoutes(router *gin.Engine) {
+	router.GET("/health", func(c *gin.Context) {
+		status, logWriter := "ok", "ok"
+		if !logging.Healthy() {
+			status, logWriter = "degraded", "failing"
+		}
+		checks := gin.H{
+			"log_writer": logWriter,
+		}
+		body := gin.H{
+			"server_time": time.Now().UTC().Format(time.RFC3339Nano),
+			"version":     version.Version, // Lets dashboards spot mixed-version fleets
+			"checks":      checks,
+		}
+		if lastSuccess := logging.LastSuccess(); !lastSuccess.IsZero() {
+			body["log_writer_last_success"] = lastSuccess.UTC().Format(time.RFC3339Nano)
+		}
+		if skew, measuredAt, exceeded := db.Skew(); !measuredAt.IsZero() {
+			checks["db_clock"] = "ok"
+			if exceeded {
+				status, checks["db_clock"] = "degraded", "skewed"
+			}
+			body["db_clock_skew_ms"] = skew.Milliseconds() // Database clock minus ours
+			body["db_clock_measured_at"] = measuredAt.UTC().Format(time.RFC3339Nano)
+		}
+		state, openedAt := db.CircuitState()
+		checks["db_circuit"] = state
+		if state != db.CircuitClosed {
+			status = "degraded"
+			body["db_circuit_opened_at"] = openedAt.UTC().Format(time.RFC3339Nano)
+		}
+		body["status"] = status
+		c.JSON(http.StatusOK, body)
+	})
+
+	router.GET("/health/ready", func(c *gin.Context) {
+		state, openedAt := db.CircuitState()
+		ready, retryAfter := db.CircuitReady()
+		body := gin.H{"status": "ready", "version": version.Version, "db_circuit": state}
+		// Skew is reported, not acted on: every instance shares the database clock
+		if skew, measuredAt, exceeded := db.Skew(); !measuredAt.IsZero() {
+			body["db_clock"] = "ok"
+			if exceeded {
+				body["db_clock"] = "skewed"
+			}
+			body["db_clock_skew_ms"] = skew.Milliseconds()
+			body["db_clock_measured_at"] = measuredAt.UTC().Format(time.RFC3339Nano)
+		}
+		if ready {
+			c.JSON(http.StatusOK, body)
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		body["status"] = "unavailable"
+		body["db_circuit_opened_at"] = openedAt.UTC().Format(time.RFC3339Nano)
+		c.JSON(http.StatusServiceUnavailable, body)
+	})
+}
@@ -0,0 +1,98 @@
+package httptransport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/version"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// A ROLE=worker instance registers only the health routes: probes answer, the
+// API doesn't exist.
+func TestWorkerRoleRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterHealthRoutes(router)
+
+	if got := serve(router, http.MethodGet, "/health", nil); got.Code != http.StatusOK {
+		t.Errorf("GET /health = %d, want 200", got.Code)
+	}
+	for _, path := range []string{"/api/v1/checks", "/ping/some-uuid", "/"} {
+		if got := serve(router, http.MethodGet, path, nil); got.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, got.Code)
+		}
+	}
+}
+
+func TestReadyReportsVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterHealthRoutes(router)
+
+	got := serve(router, http.MethodGet, "/health/ready", nil)
+	if got.Code != http.StatusOK {
+		t.Fatalf("GET /health/ready = %d, want 200: %s", got.Code, got.Body)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body["version"] != version.Version {
+		t.Errorf("version = %v, want %q", body["version"], version.Version)
+	}
+}
+
+// /health/ready reports a skewed database clock but stays ready.
+func TestReadyReportsClockSkew(t *testing.T) {
+	dbPool, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer dbPool.Close()
+	ahead := float64(time.Now().Add(10*time.Second).UnixMicro()) / 1e6
+	mock.ExpectQuery(`SELECT UNIX_TIMESTAMP\(NOW\(6\)\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"ts"}).AddRow(ahead))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.MonitorSkew(ctx, dbPool, db.SkewConfig{WarnThreshold: 2 * time.Second})
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, measuredAt, _ := db.Skew(); !measuredAt.IsZero() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no skew measured")
+		}
+	}
+	cancel()
+	<-done
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterHealthRoutes(router)
+	got := serve(router, http.MethodGet, "/health/ready", nil)
+	if got.Code != http.StatusOK {
+		t.Fatalf("GET /health/ready = %d, want 200: %s", got.Code, got.Body)
+	}
+	var body struct {
+		DBClock       string  `json:"db_clock"`
+		DBClockSkewMS float64 `json:"db_clock_skew_ms"`
+		MeasuredAt    string  `json:"db_clock_measured_at"`
+	}
+	if err := json.Unmarshal(got.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.DBClock != "skewed" || body.DBClockSkewMS < 9000 || body.DBClockSkewMS > 11000 || body.MeasuredAt == "" {
+		t.Errorf("body = %s, want a skewed clock about 10000ms ahead", got.Body)
+	}
+}
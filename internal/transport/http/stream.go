@@ -0,0 +1,124 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamFlushEvery is how many array elements are written between flushes.
+const streamFlushEvery = 100
+
+// streamErrorTrailer is set when a streamed response failed part-way.
+const streamErrorTrailer = "X-Stream-Error"
+
+// openStreams counts the streamed responses in progress, see OpenStreams.
+var openStreams atomic.Int64
+
+// OpenStreams returns how many streamed responses (large check lists and
+// account exports) are being written. These are the long-lived connections a
+// shutdown waits for; the value is a counter read, fit for a metrics gauge.
+func OpenStreams() int64 {
+	return openStreams.Load()
+}
+
+// streamJSONArray writes a 200 response whose body is a JSON array, encoding
+// elements one at a time as each produces them instead of marshalling a slice.
+//
+// Once the first byte is out the status can no longer change, so a failure
+// mid-stream is reported like this: the array is left unclosed, making the body
+// invalid JSON that no client can mistake for a complete (shorter) list, and the
+// X-Stream-Error trailer carries a generic reason. The server log has the details.
+func streamJSONArray(c *gin.Context, what string, each func(emit func(any) error) error) {
+	openStreams.Add(1)
+	defer openStreams.Add(-1)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Trailer", streamErrorTrailer)
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	enc := json.NewEncoder(w)
+	written := 0
+	emit := func(v any) error {
+		sep := ","
+		if written == 0 {
+			sep = "["
+		}
+		if _, err := w.WriteString(sep); err != nil {
+			return err
+		}
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		written++
+		if written%streamFlushEvery == 0 {
+			w.Flush()
+		}
+		return nil
+	}
+
+	if err := each(emit); err != nil {
+		if isClientGone(err) {
+			// No one left to read the trailer
+			log.Printf("DEBUG: Client went away while streaming %s after %d items: %v", what, written, err)
+			return
+		}
+		log.Printf("ERROR: Streaming %s failed after %d items: %v", what, written, err)
+		w.Header().Set(streamErrorTrailer, "Failed to retrieve "+what)
+		return
+	}
+	closing := "]"
+	if written == 0 {
+		closing = "[]"
+	}
+	if _, err := w.WriteString(closing + "\n"); err != nil {
+		log.Printf("WARN: Failed to finish streaming %s: %v", what, err)
+	}
+}
+
+// streamXMLList is streamJSONArray for XML clients: a 200 response whose body
+// is a root element holding the elements each produces. A failure mid-stream
+// leaves the root element unclosed, so the document is not well-formed, and
+// sets the same trailer.
+func streamXMLList(c *gin.Context, what, root string, each func(emit func(any) error) error) {
+	openStreams.Add(1)
+	defer openStreams.Add(-1)
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Header("Trailer", streamErrorTrailer)
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if _, err := w.WriteString(xml.Header + "<" + root + ">"); err != nil {
+		log.Printf("WARN: Failed to start streaming %s: %v", what, err)
+		return
+	}
+	enc := xml.NewEncoder(w)
+	written := 0
+	emit := func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		written++
+		if written%streamFlushEvery == 0 {
+			w.Flush()
+		}
+		return nil
+	}
+
+	if err := each(emit); err != nil {
+		if isClientGone(err) {
+			log.Printf("DEBUG: Client went away while streaming %s after %d items: %v", what, written, err)
+			return
+		}
+		log.Printf("ERROR: Streaming %s failed after %d items: %v", what, written, err)
+		w.Header().Set(streamErrorTrailer, "Failed to retrieve "+what)
+		return
+	}
+	if _, err := w.WriteString("</" + root + ">\n"); err != nil {
+		log.Printf("WARN: Failed to finish streaming %s: %v", what, err)
+	}
+}
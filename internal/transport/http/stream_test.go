@@ -0,0 +1,124 @@
+package httptransport
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// benchListChecks is about the size of the accounts the streaming was added for.
+const benchListChecks = 20_000
+
+// generatedCheckRepo makes up count checks as they are asked for, the way rows
+// come off a cursor: ListByUserID builds the whole slice, EachByUserID one
+// check at a time.
+type generatedCheckRepo struct {
+	repository.CheckRepository
+	count int
+}
+
+func (r generatedCheckRepo) check(i int) models.Check {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Second)
+	return models.Check{
+		ID:               int64(i + 1),
+		UUID:             fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+		UserID:           7,
+		Name:             fmt.Sprintf("Check %d", i),
+		Description:      sql.NullString{String: "Nightly database backup", Valid: true},
+		ExpectedInterval: 3600,
+		GracePeriod:      300,
+		Status:           models.StatusUp,
+		IsEnabled:        true,
+		LastPingAt:       sql.NullTime{Time: at, Valid: true},
+		CreatedAt:        at,
+		UpdatedAt:        at,
+	}
+}
+
+func (r generatedCheckRepo) CountByUserID(context.Context, int64) (int, error) {
+	return r.count, nil
+}
+
+func (r generatedCheckRepo) ListByUserID(context.Context, int64) ([]models.Check, error) {
+	checks := make([]models.Check, 0, r.count)
+	for i := range r.count {
+		checks = append(checks, r.check(i))
+	}
+	return checks, nil
+}
+
+func (r generatedCheckRepo) EachByUserID(_ context.Context, _ int64, fn func(models.Check) error) error {
+	for i := range r.count {
+		if err := fn(r.check(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardResponse is a ResponseWriter that drops the body, standing in for a
+// client reading it, so the benchmark measures the server's memory only. It
+// keeps the largest single write: what the handler held in memory at once.
+type discardResponse struct {
+	header   http.Header
+	written  int64
+	maxWrite int
+}
+
+func (w *discardResponse) Header() http.Header { return w.header }
+func (w *discardResponse) WriteHeader(int)     {}
+func (w *discardResponse) Flush()              {}
+func (w *discardResponse) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	w.maxWrite = max(w.maxWrite, len(p))
+	return len(p), nil
+}
+
+// BenchmarkGetChecks compares GET /checks for 20k checks buffered (the whole
+// slice loaded, then marshalled into one body) with streamed (encoded as each
+// row is read). B/op counts every allocation, short-lived ones included;
+// max-write-B is the largest body chunk held at once, which streaming bounds.
+func BenchmarkGetChecks(b *testing.B) {
+	for _, bm := range []struct {
+		name      string
+		threshold int
+	}{
+		{name: "buffered", threshold: 0},
+		{name: "streamed", threshold: 1000},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			log.SetOutput(io.Discard) // The INFO line per request
+			b.Cleanup(func() { log.SetOutput(os.Stderr) })
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/api/v1/checks", func(c *gin.Context) {
+				c.Set(middleware.UserIDKey, 7)
+			}, NewCheckHandler(generatedCheckRepo{count: benchListChecks}, CheckConfig{StreamListThreshold: bm.threshold}).GetChecks)
+
+			b.ReportAllocs()
+			maxWrite := 0
+			for range b.N {
+				w := &discardResponse{header: http.Header{}}
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/checks", nil))
+				if w.written == 0 {
+					b.Fatal("empty response")
+				}
+				maxWrite = max(maxWrite, w.maxWrite)
+			}
+			b.ReportMetric(float64(maxWrite), "max-write-B")
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package httptransport
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"slices"
+	"time"
+
+	"bitterlink/core/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The read endpoints for checks (GET /checks and GET /checks/{id}) can answer in
+// XML for clients whose Accept header prefers application/xml (or text/xml),
+// JSON otherwise. Only successful responses are negotiated: errors, and every
+// write endpoint, stay JSON.
+//
+// models.Check marshals its sql.Null* fields as structs, which is tolerable in
+// JSON but not in XML, so the XML goes through the DTOs below. NULL columns are
+// left out rather than written as empty elements.
+
+// wantsXML reports whether the client prefers XML to JSON and XML responses are
+// on (CheckConfig.XMLResponses). A missing Accept header or */* gets JSON.
+func (h *CheckHandler) wantsXML(c *gin.Context) bool {
+	if !h.Config.XMLResponses {
+		return false
+	}
+	format := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEXML, gin.MIMEXML2)
+	return format == gin.MIMEXML || format == gin.MIMEXML2
+}
+
+// checkXML is a check in XML responses, element for element like the JSON.
+type checkXML struct {
+	XMLName               xml.Name           `xml:"check"`
+	ID                    int64              `xml:"id"`
+	UserID                int64              `xml:"user_id"`
+	UUID                  string             `xml:"uuid"`
+	Name                  string             `xml:"name"`
+	Description           *string            `xml:"description,omitempty"`
+	ExpectedInterval      uint32             `xml:"expected_interval"`
+	GracePeriod           uint32             `xml:"grace_period"`
+	LastPingAt            *time.Time         `xml:"last_ping_at,omitempty"`
+	Status                string             `xml:"status"`
+	IsEnabled             bool               `xml:"is_enabled"`
+	NotifyLate            bool               `xml:"notify_late"`
+	Severity              string             `xml:"severity"`
+	RecoveryStabilization uint32             `xml:"recovery_stabilization"`
+	Color                 *string            `xml:"color,omitempty"`
+	Icon                  *string            `xml:"icon,omitempty"`
+	Metadata              []metadataEntryXML `xml:"metadata>entry,omitempty"` // Sorted by key
+	ForwardURL            *string            `xml:"forward_url,omitempty"`
+	ForwardFailures       uint32             `xml:"forward_failures"`
+	ForwardLastError      *string            `xml:"forward_last_error,omitempty"`
+	ForwardFailedAt       *time.Time         `xml:"forward_failed_at,omitempty"`
+	PingsHistoryLimit     *int32             `xml:"pings_history_limit,omitempty"`
+	RequireSignedPings    bool               `xml:"require_signed_pings"`
+	PingResponseCode      *int32             `xml:"ping_response_code,omitempty"`
+	PingResponseBody      *string            `xml:"ping_response_body,omitempty"`
+	MaxDuration           *int32             `xml:"max_duration,omitempty"`
+	WarmupPings           uint32             `xml:"warmup_pings"`
+	WarmupSuccesses       uint32             `xml:"warmup_successes"`
+	MutedUntil            *time.Time         `xml:"muted_until,omitempty"`
+	TotalPings            uint64             `xml:"total_pings"`
+	PingsThisWeek         uint32             `xml:"pings_this_week"`
+	LastFailureAt         *time.Time         `xml:"last_failure_at,omitempty"`
+	CreatedAt             time.Time          `xml:"created_at"`
+	UpdatedAt             time.Time          `xml:"updated_at"`
+	LastPing              *pingXML           `xml:"last_ping,omitempty"` // With ?include=last_ping only
+}
+
+// pingXML is a ping in XML responses. Payloads are never included.
+type pingXML struct {
+	ID         int64     `xml:"id"`
+	Kind       string    `xml:"kind"`
+	ReceivedAt time.Time `xml:"received_at"`
+	SourceIP   *string   `xml:"source_ip,omitempty"`
+	UserAgent  *string   `xml:"user_agent,omitempty"`
+	DurationMs *int64    `xml:"duration_ms,omitempty"`
+	RunID      *string   `xml:"rid,omitempty"`
+	CreatedAt  time.Time `xml:"created_at"`
+}
+
+// checkListXML is the GET /checks XML response.
+type checkListXML struct {
+	XMLName xml.Name   `xml:"checks"`
+	Checks  []checkXML `xml:"check"`
+}
+
+// checkPageXML is a page of GET /checks?page=P&per_page=PP in XML, the
+// envelope's meta as attributes.
+type checkPageXML struct {
+	XMLName xml.Name   `xml:"checks"`
+	Total   int64      `xml:"total,attr"`
+	Page    int64      `xml:"page,attr"`
+	PerPage int64      `xml:"per_page,attr"`
+	Checks  []checkXML `xml:"check"`
+}
+
+func newCheckXML(check models.Check) checkXML {
+	return checkXML{
+		ID:                    check.ID,
+		UserID:                check.UserID,
+		UUID:                  check.UUID,
+		Name:                  check.Name,
+		Description:           xmlString(check.Description),
+		ExpectedInterval:      check.ExpectedInterval,
+		GracePeriod:           check.GracePeriod,
+		LastPingAt:            xmlTime(check.LastPingAt),
+		Status:                check.Status,
+		IsEnabled:             check.IsEnabled,
+		NotifyLate:            check.NotifyLate,
+		Severity:              check.Severity,
+		RecoveryStabilization: check.RecoveryStabilization,
+		Color:                 xmlString(check.Color),
+		Icon:                  xmlString(check.Icon),
+		Metadata:              xmlMetadata(check.Metadata),
+		ForwardURL:            xmlString(check.ForwardURL),
+		ForwardFailures:       check.ForwardFailures,
+		ForwardLastError:      xmlString(check.ForwardLastError),
+		ForwardFailedAt:       xmlTime(check.ForwardFailedAt),
+		PingsHistoryLimit:     xmlInt32(check.PingsHistoryLimit),
+		RequireSignedPings:    check.RequireSignedPings,
+		PingResponseCode:      xmlInt32(check.PingResponseCode),
+		PingResponseBody:      xmlString(check.PingResponseBody),
+		MaxDuration:           xmlInt32(check.MaxDuration),
+		WarmupPings:           check.WarmupPings,
+		WarmupSuccesses:       check.WarmupSuccesses,
+		MutedUntil:            xmlTime(check.MutedUntil),
+		TotalPings:            check.TotalPings,
+		PingsThisWeek:         check.PingsThisWeek,
+		LastFailureAt:         xmlTime(check.LastFailureAt),
+		CreatedAt:             check.CreatedAt,
+		UpdatedAt:             check.UpdatedAt,
+	}
+}
+
+func newPingXML(ping models.Ping) *pingXML {
+	p := &pingXML{
+		ID:         ping.ID,
+		Kind:       ping.Kind,
+		ReceivedAt: ping.ReceivedAt,
+		SourceIP:   xmlString(ping.SourceIP),
+		UserAgent:  xmlString(ping.UserAgent),
+		RunID:      xmlString(ping.RunID),
+		CreatedAt:  ping.CreatedAt,
+	}
+	if ping.DurationMs.Valid {
+		p.DurationMs = &ping.DurationMs.Int64
+	}
+	return p
+}
+
+// metadataEntryXML is one metadata entry, <entry key="team">payments</entry>,
+// as a map has no XML form.
+type metadataEntryXML struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func xmlMetadata(m models.CheckMetadata) []metadataEntryXML {
+	if len(m) == 0 {
+		return nil // Left out, like the other NULL columns
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	entries := make([]metadataEntryXML, len(keys))
+	for i, key := range keys {
+		entries[i] = metadataEntryXML{Key: key, Value: m[key]}
+	}
+	return entries
+}
+
+func xmlString(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func xmlTime(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func xmlInt32(v sql.NullInt32) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
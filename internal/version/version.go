@@ -0,0 +1,45 @@
+// Package version exposes build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X bitterlink/core/internal/version.Version=v1.2.3 \
+//	  -X bitterlink/core/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X bitterlink/core/internal/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Set via -ldflags; the defaults identify a local/untagged build.
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info is the build metadata as returned by GET /version.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	AppEnv    string `json:"app_env"`
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		AppEnv:    os.Getenv("APP_ENV"),
+	}
+}
+
+// String renders the build metadata as a single log-friendly line.
+func (i Info) String() string {
+	return fmt.Sprintf("version=%s commit=%s build_date=%s go=%s app_env=%s",
+		i.Version, i.Commit, i.BuildDate, i.GoVersion, i.AppEnv)
+}
@@ -0,0 +1,346 @@
+// Package webhook delivers every event of an account's checks to the account's
+// own webhook (users.webhook_url), for users who feed them into their own
+// systems. It is in addition to the per-check notifications, never instead.
+//
+// Delivery is asynchronous and best effort, like ping forwarding: events are
+// queued without blocking the ping or the worker, retried a few times, and
+// dropped when the queue is full. A webhook that keeps failing trips a circuit
+// breaker: after BreakerThreshold consecutive failed deliveries its events are
+// dropped for BreakerCooldown, then one is let through to probe it. The failure
+// count lives on the user row, so every instance sees the same breaker.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/version"
+)
+
+// EventHeader carries the event type on every delivery.
+const EventHeader = "X-Bitterlink-Event"
+
+// Event types. Besides EventPing they are the notify.Kind* values.
+const (
+	EventPing = "ping"
+	EventLate = notify.KindLate
+	EventDown = notify.KindDown
+	EventUp   = notify.KindUp
+	EventSlow = notify.KindSlow
+	EventTest = "test" // Verifies a new 'webhook' notification channel, see TestChannel
+)
+
+// Event is one delivery, and the JSON payload POSTed to the webhook.
+type Event struct {
+	Type       string    `json:"event"` // Event* constants
+	CheckID    int64     `json:"check_id"`
+	CheckUUID  string    `json:"check_uuid"`
+	Severity   string    `json:"severity,omitempty"`  // The check's models.Severity*, notifications only
+	PingKind   string    `json:"ping_kind,omitempty"` // models.PingKind*, pings only
+	CycleID    string    `json:"cycle_id,omitempty"`  // Worker cycle, notifications only
+	MuteURL    string    `json:"mute_url,omitempty"`  // Signed link muting the check, late and down only
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Config configures the Sender. Zero values get the defaults noted.
+type Config struct {
+	Timeout          time.Duration // Per attempt, default 5s
+	MaxAttempts      int           // Including the first, default 3
+	RetryDelay       time.Duration // Before the second attempt, doubled after each, default 1s
+	QueueSize        int           // Pending events before new ones are dropped, default 1000
+	Workers          int           // Concurrent deliveries, default 4
+	BreakerThreshold int           // Consecutive failures that open the breaker, default 5
+	BreakerCooldown  time.Duration // How long an open breaker drops events, default 10m
+	// SelfHosts are this instance's host names; webhooks pointing at them are
+	// refused, as for forward URLs.
+	SelfHosts []string
+}
+
+// Store is the part of the user repository the Sender needs.
+type Store interface {
+	FindWebhookByCheckUUID(ctx context.Context, checkUUID string) (*models.UserWebhook, int64, error)
+	RecordWebhookResult(ctx context.Context, userID int64, deliveryErr error) error
+}
+
+// Sender delivers queued events to their account's webhook.
+type Sender struct {
+	store  Store
+	config Config
+	client *http.Client
+	queue  chan Event
+
+	dropMu  sync.Mutex
+	dropped int // Total events dropped on a full queue
+}
+
+// New creates a Sender. Call Start to run its workers.
+func New(store Store, cfg Config) *Sender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = 5
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 10 * time.Minute
+	}
+	s := &Sender{
+		store:  store,
+		config: cfg,
+		queue:  make(chan Event, cfg.QueueSize),
+	}
+	s.client = &http.Client{
+		Timeout: cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return errors.New("stopped after 3 redirects")
+			}
+			return forward.ValidateURL(req.URL.String(), cfg.SelfHosts)
+		},
+	}
+	return s
+}
+
+// Enqueue queues e without blocking; a full queue drops it. A nil Sender (the
+// feature is off) ignores it, so callers needn't check.
+func (s *Sender) Enqueue(e Event) {
+	if s == nil {
+		return
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	select {
+	case s.queue <- e:
+	default:
+		metrics.Incr(metrics.WebhookDeliveries, "event:"+e.Type, "outcome:dropped")
+		s.dropMu.Lock()
+		s.dropped++
+		dropped := s.dropped
+		s.dropMu.Unlock()
+		// Warn on the first drop and then every 100th, not once per event
+		if dropped == 1 || dropped%100 == 0 {
+			log.Printf("WARN: Webhook queue full (%d), dropped %d events so far", s.config.QueueSize, dropped)
+		}
+	}
+}
+
+// Start runs the workers until ctx is cancelled. Events still queued then are
+// dropped.
+func (s *Sender) Start(ctx context.Context) {
+	log.Printf("INFO: Account webhook sender started (%d workers, timeout %s, %d attempts)", s.config.Workers, s.config.Timeout, s.config.MaxAttempts)
+	var wg sync.WaitGroup
+	for i := 0; i < s.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case e := <-s.queue:
+					s.deliver(ctx, e)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if pending := len(s.queue); pending > 0 {
+		log.Printf("WARN: Account webhook sender stopped with %d events still queued", pending)
+	}
+	log.Println("INFO: Account webhook sender stopped.")
+}
+
+// deliver looks up the webhook of e's account and, unless there is none or its
+// breaker is open, sends e there and records the outcome on the user.
+func (s *Sender) deliver(ctx context.Context, e Event) {
+	// 1. Only accounts with a webhook get events
+	hook, checkID, err := s.store.FindWebhookByCheckUUID(ctx, e.CheckUUID)
+	if err != nil {
+		log.Printf("WARN: Webhook sender could not load the webhook for check %s: %v", e.CheckUUID, err)
+		return
+	}
+	if hook == nil {
+		return
+	}
+	if e.CheckID == 0 {
+		e.CheckID = checkID // Pings are only known by UUID
+	}
+	if s.breakerOpen(hook) {
+		metrics.Incr(metrics.WebhookDeliveries, "event:"+e.Type, "outcome:breaker_open")
+		return
+	}
+
+	// 2. Send, retrying transient failures
+	sendErr := s.send(ctx, hook.URL, e)
+	if sendErr != nil {
+		if ctx.Err() != nil {
+			return // Shutting down, not the webhook's fault
+		}
+		log.Printf("WARN: Delivering '%s' event of check ID %d to the webhook of user %d failed: %v", e.Type, e.CheckID, hook.UserID, sendErr)
+		metrics.Incr(metrics.WebhookDeliveries, "event:"+e.Type, "outcome:error")
+	} else {
+		metrics.Incr(metrics.WebhookDeliveries, "event:"+e.Type, "outcome:ok")
+	}
+
+	// 3. Record the result, which is also the breaker's state
+	if sendErr == nil && hook.Failures == 0 {
+		return // Nothing to reset
+	}
+	if err := s.store.RecordWebhookResult(ctx, hook.UserID, sendErr); err != nil {
+		log.Printf("ERROR: Failed to record webhook result for user %d: %v", hook.UserID, err)
+	}
+}
+
+// breakerOpen reports whether hook has failed BreakerThreshold times in a row,
+// the last time less than BreakerCooldown ago. Once the cooldown has passed the
+// next event goes through: its success closes the breaker, its failure restarts
+// the cooldown.
+func (s *Sender) breakerOpen(hook *models.UserWebhook) bool {
+	if int(hook.Failures) < s.config.BreakerThreshold || !hook.FailedAt.Valid {
+		return false
+	}
+	return time.Since(hook.FailedAt.Time) < s.config.BreakerCooldown
+}
+
+// send POSTs e to webhookURL with up to MaxAttempts attempts. Network errors,
+// 429 and 5xx responses are retried; any other non-2xx is final.
+func (s *Sender) send(ctx context.Context, webhookURL string, e Event) error {
+	if err := forward.ValidateURL(webhookURL, s.config.SelfHosts); err != nil {
+		return err // Set directly in the database, or PUBLIC_BASE_URL changed since
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	delay := s.config.RetryDelay
+	var lastErr error
+	for attempt := 1; attempt <= s.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+		retry, err := s.attempt(ctx, webhookURL, e.Type, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// TestChannel verifies a new 'webhook' notification channel pointing at
+// channelURL with a single test event, which must be answered with a 2xx.
+// Unlike deliveries it is not retried: whoever asked for the test sees the
+// result and can ask again.
+func (s *Sender) TestChannel(ctx context.Context, channelURL string) error {
+	if err := forward.ValidateURL(channelURL, s.config.SelfHosts); err != nil {
+		return err
+	}
+	body, err := json.Marshal(Event{Type: EventTest, OccurredAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	_, err = s.attempt(ctx, channelURL, EventTest, body)
+	return err
+}
+
+// attempt makes one request. retry reports whether a later attempt may succeed.
+func (s *Sender) attempt(ctx context.Context, webhookURL, eventType string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bitterlink-webhook/"+version.Version)
+	req.Header.Set(EventHeader, eventType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, forward.ErrLoop) {
+			return false, err // Redirected back here
+		}
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+// Dispatcher passes notifications on to Next and, once Next accepted one, also
+// queues it as an event for the account's webhook.
+type Dispatcher struct {
+	Next   notify.Dispatcher
+	Sender *Sender
+	// MuteURL, when set, returns an action link muting the check (see
+	// internal/actionlink) for late and down events, "" if there is none
+	MuteURL func(checkUUID string) string
+}
+
+// Dispatch implements notify.Dispatcher. A notification Next failed is not
+// queued: the worker redelivers it, and the webhook gets it then.
+func (d Dispatcher) Dispatch(ctx context.Context, n notify.Notification) error {
+	if err := d.Next.Dispatch(ctx, n); err != nil {
+		return err
+	}
+	d.Sender.Enqueue(d.event(n))
+	return nil
+}
+
+// DispatchBatch implements notify.BatchDispatcher, passing the batch on to Next
+// as a batch and queueing the notifications it accepted.
+func (d Dispatcher) DispatchBatch(ctx context.Context, ns []notify.Notification) []error {
+	errs := notify.DispatchAll(ctx, d.Next, ns)
+	for i, n := range ns {
+		if errs[i] == nil {
+			d.Sender.Enqueue(d.event(n))
+		}
+	}
+	return errs
+}
+
+// event turns n into its webhook event.
+func (d Dispatcher) event(n notify.Notification) Event {
+	event := Event{Type: n.Kind, CheckID: n.CheckID, CheckUUID: n.CheckUUID, Severity: n.Severity, CycleID: n.CycleID}
+	if d.MuteURL != nil && (n.Kind == notify.KindLate || n.Kind == notify.KindDown) {
+		event.MuteURL = d.MuteURL(n.CheckUUID)
+	}
+	return event
+}
@@ -4,28 +4,104 @@ package worker
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/logging"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/repository"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
 )
 
 type Config struct {
 	PollInterval time.Duration
-	BatchSize int
+	BatchSize    int
+	// InstanceID identifies this worker in claimed_by. Generated when empty.
+	InstanceID string
+	// ClaimTimeout is how long a claim is honoured before another worker may take
+	// the check over (only used by the claim strategy, see lockStrategyClaim).
+	ClaimTimeout time.Duration
+	// SkewMargin, when set, returns extra time added to every deadline (expected
+	// interval and grace period) as a safety margin, normally the measured
+	// database clock skew. nil adds nothing.
+	SkewMargin func() time.Duration
+	// RedeliverAfter is how long a 'down' notification may stay pending (set but
+	// not confirmed dispatched) before another tick redelivers it. Defaults to two
+	// poll intervals, at least a minute, so an in-flight dispatch isn't doubled.
+	RedeliverAfter time.Duration
+	// Outbox records 'down' notifications in the notification_outbox table in
+	// the transaction that marks the check down, instead of dispatching them
+	// after the commit. An OutboxConsumer must run to deliver them. Either way a
+	// committed 'down' has a durable record of its notification; the outbox
+	// moves delivery out of the checker, so a slow or failing dispatcher can't
+	// hold up detection.
+	Outbox bool
+	// SuppressDependents skips the 'late' and 'down' notifications of a check
+	// while one of its dependencies (see repository.AddDependency) is down: the
+	// status still changes, and a notification_suppressed event names the
+	// dependency. A suppressed 'down' is sent after all if the check is still
+	// down once no dependency is, see processSuppressed.
+	SuppressDependents bool
 }
 
+// Locking strategies for picking a batch of timed-out checks.
+const (
+	// lockStrategySkipLocked locks candidate rows with FOR UPDATE SKIP LOCKED
+	// (MySQL 8.0+, MariaDB 10.6+).
+	lockStrategySkipLocked = "skip_locked"
+	// lockStrategyClaim first stamps a batch with claimed_by/claimed_at in an
+	// autocommitted UPDATE, then locks only the rows it claimed. For servers
+	// that reject SKIP LOCKED.
+	lockStrategyClaim = "claim"
+)
+
+// mysqlParseError is ER_PARSE_ERROR, what servers without SKIP LOCKED return.
+const mysqlParseError = 1064
+
 type TimeoutChecker struct {
-	dbPool *sql.DB
-	config Config
-	// Add a message queue producer here later for notifications
-	// notificationDispatcher NotificationDispatcher // Example interface
+	dbPool     *sql.DB
+	dispatcher notify.Dispatcher
+	config     Config
+	// mu serializes ticks with EvaluateCheck: both stamp claims with the one
+	// InstanceID, and release all of them
+	mu sync.Mutex
+	// lockStrategy is detected on the first tick; empty until then
+	lockStrategy string
+	// lastRun is when processTimeouts last completed without error (UnixNano),
+	// read by the heartbeat from another goroutine
+	lastRun atomic.Int64
 }
 
-// NewTimeoutChecker creates a new checker instance.
-func NewTimeoutChecker(db *sql.DB, cfg Config) *TimeoutChecker {
+// NewTimeoutChecker creates a new checker instance. A nil dispatcher only logs
+// notifications.
+func NewTimeoutChecker(db *sql.DB, dispatcher notify.Dispatcher, cfg Config) *TimeoutChecker {
+	if dispatcher == nil {
+		dispatcher = notify.LogDispatcher{}
+	}
+	if cfg.InstanceID == "" {
+		hostname, _ := os.Hostname()
+		cfg.InstanceID = fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+	}
+	if cfg.ClaimTimeout <= 0 {
+		cfg.ClaimTimeout = 5 * time.Minute
+	}
+	if cfg.RedeliverAfter <= 0 {
+		cfg.RedeliverAfter = max(2*cfg.PollInterval, time.Minute)
+	}
 	return &TimeoutChecker{
-		dbPool: db,
-		config: cfg,
+		dbPool:     db,
+		dispatcher: dispatcher,
+		config:     cfg,
 	}
 }
 
@@ -39,12 +115,18 @@ func (tc *TimeoutChecker) Start(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			// Time to check for timeouts
+			// Time to check for timeouts, unless the database is known to be down
+			if ready, _ := db.CircuitReady(); !ready {
+				log.Println("DEBUG: TimeoutChecker tick skipped, database circuit breaker open")
+				continue
+			}
 			log.Println("DEBUG: TimeoutChecker tick: processing timeouts...")
 			err := tc.processTimeouts(ctx)
 			if err != nil {
 				// Log the error but continue running
-				log.Printf("ERROR: Error processing timeouts: %v", err)
+				logging.Errorf("Error processing timeouts: %v", err)
+			} else {
+				tc.lastRun.Store(time.Now().UnixNano())
 			}
 		case <-ctx.Done():
 			// Context was cancelled (e.g., shutdown signal)
@@ -54,84 +136,417 @@ func (tc *TimeoutChecker) Start(ctx context.Context) {
 	}
 }
 
+// skewMarginSeconds is Config.SkewMargin rounded up to whole seconds.
+func (tc *TimeoutChecker) skewMarginSeconds() int {
+	if tc.config.SkewMargin == nil {
+		return 0
+	}
+	margin := tc.config.SkewMargin().Abs()
+	return int((margin + time.Second - 1) / time.Second)
+}
+
+// LastRun returns when the checker last completed a cycle without error, or the
+// zero time if it hasn't yet.
+func (tc *TimeoutChecker) LastRun() time.Time {
+	nanos := tc.lastRun.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// NotificationBacklog returns the dispatcher's undelivered notification count.
+// ok is false for dispatchers that deliver synchronously and have no backlog.
+func (tc *TimeoutChecker) NotificationBacklog() (backlog int, ok bool) {
+	reporter, ok := tc.dispatcher.(notify.BacklogReporter)
+	if !ok {
+		return 0, false
+	}
+	return reporter.Backlog(), true
+}
+
+// lateCondition selects 'up' checks past their expected interval but still within
+// their grace period. They move to 'late' and, if they opted in, get a warning.
+// Only checks with is_enabled = TRUE are evaluated (see models.Check.IsMonitored),
+// so paused, new and disabled checks never escalate.
+// Using UTC_TIMESTAMP() for database time comparison is generally safer
+//
+// Both conditions are format strings: %[1]d is the skew margin in seconds, see
+// stage.where.
+const lateCondition = `
+            status = 'up'
+            AND is_enabled = TRUE
+            AND deleted_at IS NULL
+            AND last_ping_at < (UTC_TIMESTAMP() - INTERVAL (expected_interval + %[1]d) SECOND)
+            AND last_ping_at >= (UTC_TIMESTAMP() - INTERVAL (expected_interval + grace_period + %[1]d) SECOND)`
+
+// timedOutCondition selects 'up' or 'late' checks whose last ping is older than
+// interval + grace. They move to 'down' and always alert.
+const timedOutCondition = `
+            status IN ('up', 'late')
+            AND is_enabled = TRUE
+            AND deleted_at IS NULL
+            AND last_ping_at < (UTC_TIMESTAMP() - INTERVAL (expected_interval + grace_period + %[1]d) SECOND)`
+
+// runOverdueCondition selects 'up' or 'late' checks with a run that started
+// (start ping) more than max_duration ago and hasn't finished. A job that hangs
+// may keep its last success recent enough for the other stages, so this takes
+// the check down on its own. The success that finally closes the run brings it
+// back up and records the slow run, without a separate "ran slow" alert.
+const runOverdueCondition = `
+            status IN ('up', 'late')
+            AND is_enabled = TRUE
+            AND deleted_at IS NULL
+            AND max_duration IS NOT NULL
+            AND last_start_at < (UTC_TIMESTAMP() - INTERVAL (max_duration + %[1]d) SECOND)`
+
+// stage is one escalation step evaluated on every tick.
+type stage struct {
+	toStatus  string // status the selected checks move to
+	condition string // WHERE fragment selecting the checks to move, see where
+	notify    string // notify.Kind* to send
+	optIn     bool   // only notify checks with notify_late set
+	outbox    bool   // record the notification as pending until dispatched, see pending.go
+}
+
+// where returns the stage's WHERE fragment with every deadline pushed back by
+// marginSeconds (0 outside of Config.SkewMargin).
+func (st stage) where(marginSeconds int) string {
+	return fmt.Sprintf(st.condition, marginSeconds)
+}
+
+// stages run in order each tick: grace-period warnings, then hard timeouts, then
+// runs still going past their max_duration.
+var stages = []stage{
+	{toStatus: models.StatusLate, condition: lateCondition, notify: notify.KindLate, optIn: true},
+	{toStatus: models.StatusDown, condition: timedOutCondition, notify: notify.KindDown, outbox: true},
+	{toStatus: models.StatusDown, condition: runOverdueCondition, notify: notify.KindDown, outbox: true},
+}
+
+// processTimeouts runs every escalation stage once, releases the suppressed
+// 'down' notifications whose dependency recovered, redelivers 'down'
+// notifications left pending, then sends the recovery and "ran slow"
+// notifications that are due. Recoveries go after the stages so a check that went
+// down again in this tick has already had its pending recovery cancelled, and a
+// redelivered outage alert goes out before its recovery.
 func (tc *TimeoutChecker) processTimeouts(ctx context.Context) error {
-	// 1. Begin Transaction
-	tx, err := tc.dbPool.BeginTx(ctx, nil) // Use default isolation level 
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	for _, st := range stages {
+		if err := tc.processStage(ctx, st, 0); err != nil {
+			return fmt.Errorf("moving checks to '%s': %w", st.toStatus, err)
+		}
+	}
+	if err := tc.processSuppressed(ctx); err != nil {
+		return fmt.Errorf("re-evaluating suppressed notifications: %w", err)
+	}
+	if err := tc.processPendingNotifications(ctx); err != nil {
+		return fmt.Errorf("redelivering pending notifications: %w", err)
+	}
+	if err := tc.processRecoveries(ctx); err != nil {
+		return fmt.Errorf("sending recovery notifications: %w", err)
+	}
+	if err := tc.processSlowRuns(ctx); err != nil {
+		return fmt.Errorf("sending slow run notifications: %w", err)
+	}
+	return nil
+}
+
+// EvaluateCheck runs the escalation stages for checkID alone, straight away,
+// e.g. after its interval or grace period was shortened: a check that is now
+// past its deadline goes late or down (and notifies) without waiting for the
+// next tick. Recoveries and redeliveries are left to the tick.
+func (tc *TimeoutChecker) EvaluateCheck(ctx context.Context, checkID int64) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	for _, st := range stages {
+		if err := tc.processStage(ctx, st, checkID); err != nil {
+			return fmt.Errorf("moving check ID %d to '%s': %w", checkID, st.toStatus, err)
+		}
+	}
+	return nil
+}
+
+// lockedCheck is a row selected for a status change.
+type lockedCheck struct {
+	id         int64
+	uuid       string
+	severity   string
+	status     string
+	notifyLate bool
+	muted      bool // muted_until is still ahead, see muteNotification
+}
+
+// processStage moves one batch of checks matching st.condition to st.toStatus.
+// A non-zero checkID restricts it to that check.
+func (tc *TimeoutChecker) processStage(ctx context.Context, st stage, checkID int64) error {
+	condition := st.where(tc.skewMarginSeconds())
+	if checkID != 0 {
+		condition += fmt.Sprintf(" AND id = %d", checkID)
+	}
+
+	// 1. Cheap non-locking probe first, so idle polls (the common case)
+	// don't open and commit an empty transaction every tick.
+	var hasCandidates bool
+	probeQuery := `SELECT EXISTS (SELECT 1 FROM checks WHERE` + condition + `)`
+	if err := tc.dbPool.QueryRowContext(ctx, probeQuery).Scan(&hasCandidates); err != nil {
+		return fmt.Errorf("failed to probe for timed-out checks: %w", err)
+	}
+	if !hasCandidates {
+		return nil
+	}
+
+	if tc.lockStrategy == "" {
+		strategy, err := tc.detectLockStrategy(ctx)
+		if err != nil {
+			return err
+		}
+		tc.lockStrategy = strategy
+		log.Printf("INFO: TimeoutChecker %s using lock strategy %q", tc.config.InstanceID, strategy)
+	}
+	if tc.lockStrategy == lockStrategyClaim {
+		claimed, err := tc.claimBatch(ctx, condition)
+		if err != nil {
+			return err
+		}
+		if claimed == 0 {
+			// Everything eligible is claimed by other workers
+			return nil
+		}
+	}
+
+	// 2. Begin Transaction
+	tx, err := tc.dbPool.BeginTx(ctx, nil) // Use default isolation level
 	if err != nil {
 		return fmt.Errorf("failed to begin transation: %w", err)
 	}
 	defer tx.Rollback()
 
-	// 2. Execute Query to Find and Lock Timed-out Checks
-	// Using UTC_TIMESTAMP() for database time comparison is generally safer
+	// 3. Execute Query to Find and Lock Timed-out Checks
 	query := `
-        SELECT id, uuid -- Select minimal info needed to process/notify
+        SELECT id, uuid, severity, status, notify_late, muted_until > UTC_TIMESTAMP() -- Select minimal info needed to process/notify
         FROM checks
-        WHERE
-            status = 'up'
-            AND is_enabled = TRUE
-            AND deleted_at IS NULL
-            AND last_ping_at < (UTC_TIMESTAMP() - INTERVAL (expected_interval + grace_period) SECOND)
+        WHERE` + condition + `
         ORDER BY last_ping_at ASC -- Process oldest first
         LIMIT ? -- Use configured batch size
         FOR UPDATE SKIP LOCKED` // The key part for concurrency
+	args := []any{tc.config.BatchSize}
+	if tc.lockStrategy == lockStrategyClaim {
+		// Only our own claims; re-checking the condition drops checks pinged since
+		query = `
+        SELECT id, uuid, severity, status, notify_late, muted_until > UTC_TIMESTAMP()
+        FROM checks
+        WHERE claimed_by = ? AND` + condition + `
+        ORDER BY last_ping_at ASC
+        FOR UPDATE`
+		args = []any{tc.config.InstanceID}
+	}
 
-	rows, err := tx.QueryContext(ctx, query, tc.config.BatchSize)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to query timed-out checks: %w", err)
 	}
 	defer rows.Close()
 
-	var checkIDsToProcess []int64
+	var checksToProcess []lockedCheck
 	var timedOutChecksInfo []string // for logging
 
-	// 3. Collect IDs of checks to process
+	// 4. Collect the checks to process
 	for rows.Next() {
-		var id int64
-		var uuid string
-		if err := rows.Scan(&id, &uuid); err != nil {
+		var check lockedCheck
+		var muted sql.NullBool // NULL when never muted
+		if err := rows.Scan(&check.id, &check.uuid, &check.severity, &check.status, &check.notifyLate, &muted); err != nil {
 			// Log error but potentially continue processing others found so far?
 			// For simplicity, let's return error and rollback the whole batch on scan failure.
-			return fmt.Errorf("failed to scan check row: %w", err) 
+			return fmt.Errorf("failed to scan check row: %w", err)
 		}
-		checkIDsToProcess = append(checkIDsToProcess, id)
-		timedOutChecksInfo = append(timedOutChecksInfo, fmt.Sprintf("%d (%s)", id, uuid))
+		check.muted = muted.Bool
+		checksToProcess = append(checksToProcess, check)
+		timedOutChecksInfo = append(timedOutChecksInfo, fmt.Sprintf("%d (%s)", check.id, check.uuid))
 	}
 	if err = rows.Err(); err != nil {
-		return fmt.Errorf("row iteration failed: %w", err) 
+		return fmt.Errorf("row iteration failed: %w", err)
 	}
 
-	// If no rows found, commit the empty transaction and exit successfully
-	if len(checkIDsToProcess) == 0 {
-		return tx.Commit() // Commit needed even if empty to finish tx
+	// The probe can race with another worker locking the same rows. Nothing was
+	// written, so a rollback is enough to release the (empty) transaction.
+	if len(checksToProcess) == 0 {
+		if tc.lockStrategy == lockStrategyClaim {
+			// Our claims went stale before we got to them; let them go
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return tc.releaseClaims(ctx, tc.dbPool)
+		}
+		return tx.Rollback()
 	}
 
-	log.Printf("INFO: Found %d timed-out checks to process: %v", len(checkIDsToProcess), timedOutChecksInfo)
+	// Every event and notification produced by this batch carries the cycle ID,
+	// so an alert can be traced back to the detection run that raised it.
+	cycleID := uuid.NewString()
+	log.Printf("INFO: Cycle %s found %d checks to mark %s: %v", cycleID, len(checksToProcess), st.toStatus, timedOutChecksInfo)
 
-	// 4. Process Locked Rows (Update Status & Dispatch Notifications)
-	updateQuery := `UPDATE checks SET status = 'down', updated_at = UTC_TIMESTAMP() WHERE id = ?`
-	for _, checkID := range checkIDsToProcess {
+	// 5. Process Locked Rows (Update Status & queue Notifications)
+	// Going down cancels a pending recovery notification (see processRecoveries)
+	updateQuery := `
+        UPDATE checks
+        SET status = ?, updated_at = UTC_TIMESTAMP(),
+            recovery_pending_since = IF(? = 'down', NULL, recovery_pending_since)
+        WHERE id = ?`
+	var toNotify []lockedCheck
+	for _, check := range checksToProcess {
 		// Update status within the same transaction
-		_, updateErr := tx.ExecContext(ctx, updateQuery, checkID)
+		_, updateErr := tx.ExecContext(ctx, updateQuery, st.toStatus, st.toStatus, check.id)
 		if updateErr != nil {
 			// Rollback will happen via defer
-			return fmt.Errorf("failed to update status for check ID %d: %w", checkID, updateErr)
+			return fmt.Errorf("failed to update status for check ID %d: %w", check.id, updateErr)
+		}
+		event := repository.StatusChangedEvent(check.id, check.status, st.toStatus, models.EventSourceWorker)
+		event.CycleID = sql.NullString{String: cycleID, Valid: true}
+		if err := repository.InsertEvent(ctx, tx, event); err != nil {
+			return fmt.Errorf("failed to record event for check ID %d: %w", check.id, err)
+		}
+		log.Printf("DEBUG: Marked check ID %d as %s.", check.id, st.toStatus)
+
+		if st.optIn && !check.notifyLate {
+			continue
+		}
+		toNotify = append(toNotify, check)
+	}
+
+	// 6. Skip the notifications of checks with a dependency down, now that the
+	// dependencies in this batch are down too, and of muted checks
+	var downDependencies map[int64]int64
+	if tc.config.SuppressDependents {
+		ids := make([]int64, len(toNotify))
+		for i, check := range toNotify {
+			ids[i] = check.id
+		}
+		if downDependencies, err = findDownDependencies(ctx, tx, ids); err != nil {
+			return err
+		}
+	}
+	var notifications []notify.Notification
+	for _, check := range toNotify {
+		n := notify.Notification{
+			Kind:      st.notify,
+			CheckID:   check.id,
+			CheckUUID: check.uuid,
+			Severity:  check.severity,
+			CycleID:   cycleID,
+		}
+		if dependencyID, ok := downDependencies[check.id]; ok {
+			if err := suppressNotification(ctx, tx, n, dependencyID); err != nil {
+				return err
+			}
+			continue
+		}
+		if check.muted {
+			if err := muteNotification(ctx, tx, n); err != nil {
+				return err
+			}
+			continue
+		}
+		if st.outbox && tc.config.Outbox {
+			// Handed to the OutboxConsumer atomically with the status change
+			if err := enqueueOutbox(ctx, tx, n); err != nil {
+				return err
+			}
+			continue
 		}
-		log.Printf("DEBUG: Marked check ID %d as down.", checkID)
+		if st.outbox {
+			// Committed with the status change, cleared once dispatched
+			if err := markNotificationPending(ctx, tx, check.id, cycleID); err != nil {
+				return err
+			}
+		}
+		notifications = append(notifications, n)
+	}
 
-		// !!! TODO: Dispatch notification task HERE !!!
-		// Example: tc.notificationDispatcher.DispatchDownNotification(ctx, checkID)
-		// This should ideally send a message (with checkID/UUID/UserID)
-		// to a message queue (like RabbitMQ/Redis) for a separate worker
-		// to handle the actual sending of email/Slack/webhook.
-		log.Printf("INFO: Dispatched 'down' notification task for check ID %d", checkID)
+	// 7. Release our claims together with the status changes
+	if tc.lockStrategy == lockStrategyClaim {
+		if err := tc.releaseClaims(ctx, tx); err != nil {
+			return err
+		}
 	}
 
-	// 5. Commit Transaction
+	// 8. Commit Transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("INFO: Successfully processed batch of %d timed-out checks.", len(checkIDsToProcess))
+	log.Printf("INFO: Successfully processed batch of %d checks (now %s).", len(checksToProcess), st.toStatus)
+	metrics.Default().Count(metrics.WorkerStatusChanges, int64(len(checksToProcess)), "to_status:"+st.toStatus)
+
+	// 9. Dispatch only once the status changes are committed, so a rolled-back
+	// batch never alerts. A failed dispatch doesn't undo the status change; for
+	// outbox stages it leaves the notification pending for redelivery. The
+	// batch's notifications go out together, for dispatchers that combine them.
+	dispatchErrs := notify.DispatchAll(ctx, tc.dispatcher, notifications)
+	for i, n := range notifications {
+		if err := dispatchErrs[i]; err != nil {
+			logging.Errorf("Failed to dispatch '%s' notification for check ID %d (cycle %s): %v", n.Kind, n.CheckID, n.CycleID, err)
+			metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:error")
+			continue
+		}
+		metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:ok")
+		if st.outbox {
+			tc.clearNotificationPending(ctx, n)
+		}
+	}
+	return nil
+}
+
+// detectLockStrategy probes whether the server accepts FOR UPDATE SKIP LOCKED.
+func (tc *TimeoutChecker) detectLockStrategy(ctx context.Context) (string, error) {
+	tx, err := tc.dbPool.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin lock strategy probe: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM checks LIMIT 0 FOR UPDATE SKIP LOCKED`)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlParseError {
+			log.Println("WARN: Database does not support SKIP LOCKED, falling back to claim-based locking.")
+			return lockStrategyClaim, nil
+		}
+		return "", fmt.Errorf("failed to probe for SKIP LOCKED support: %w", err)
+	}
+	rows.Close()
+	return lockStrategySkipLocked, nil
+}
+
+// claimBatch stamps up to BatchSize checks matching condition with this worker's instance
+// ID, taking over claims older than ClaimTimeout (left by a crashed worker). The
+// UPDATE is autocommitted, so other workers see the claims straight away.
+// Returns the number of checks claimed.
+func (tc *TimeoutChecker) claimBatch(ctx context.Context, condition string) (int64, error) {
+	claimQuery := `
+        UPDATE checks
+        SET claimed_by = ?, claimed_at = UTC_TIMESTAMP()
+        WHERE` + condition + `
+            AND (claimed_by IS NULL OR claimed_by = ? OR claimed_at < (UTC_TIMESTAMP() - INTERVAL ? SECOND))
+        ORDER BY last_ping_at ASC
+        LIMIT ?`
+	result, err := tc.dbPool.ExecContext(ctx, claimQuery,
+		tc.config.InstanceID, tc.config.InstanceID, int64(tc.config.ClaimTimeout.Seconds()), tc.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim timed-out checks: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count claimed checks: %w", err)
+	}
+	return claimed, nil
+}
+
+// releaseClaims clears every claim held by this worker.
+func (tc *TimeoutChecker) releaseClaims(ctx context.Context, exec repository.Execer) error {
+	releaseQuery := `UPDATE checks SET claimed_by = NULL, claimed_at = NULL WHERE claimed_by = ?`
+	if _, err := exec.ExecContext(ctx, releaseQuery, tc.config.InstanceID); err != nil {
+		return fmt.Errorf("failed to release claims: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,113 @@
+//go:build integration
+
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"bitterlink/core/internal/notify"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// These tests need a migrated database, named by INTEGRATION_DB_DSN (a
+// go-sql-driver DSN with parseTime=true). Run them with -tags integration.
+
+// countingDispatcher counts the notifications per check.
+type countingDispatcher struct {
+	mu     sync.Mutex
+	counts map[int64]int
+}
+
+func (d *countingDispatcher) Dispatch(_ context.Context, n notify.Notification) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.counts[n.CheckID]++
+	return nil
+}
+
+func openIntegrationDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("INTEGRATION_DB_DSN")
+	if dsn == "" {
+		t.Skip("INTEGRATION_DB_DSN not set")
+	}
+	pool, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+// seedOverdueChecks creates a user with n enabled 'up' checks well past their
+// grace period, removed again when the test ends.
+func seedOverdueChecks(t *testing.T, pool *sql.DB, n int) []int64 {
+	t.Helper()
+	ctx := context.Background()
+	email := fmt.Sprintf("worker-test-%s@example.com", uuid.NewString()[:8])
+	result, err := pool.ExecContext(ctx, `INSERT INTO users (name, email, password_hash, created_at, updated_at)
+		VALUES ('worker test', ?, '-', UTC_TIMESTAMP(), UTC_TIMESTAMP())`, email)
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	userID, _ := result.LastInsertId()
+	t.Cleanup(func() {
+		pool.Exec(`DELETE FROM check_events WHERE check_id IN (SELECT id FROM checks WHERE user_id = ?)`, userID)
+		pool.Exec(`DELETE FROM checks WHERE user_id = ?`, userID)
+		pool.Exec(`DELETE FROM users WHERE id = ?`, userID)
+	})
+
+	ids := make([]int64, n)
+	for i := range ids {
+		result, err := pool.ExecContext(ctx, `INSERT INTO checks (user_id, uuid, name, expected_interval, grace_period, last_ping_at, status, is_enabled, created_at, updated_at)
+			VALUES (?, ?, ?, 60, 0, UTC_TIMESTAMP() - INTERVAL 1 HOUR, 'up', TRUE, UTC_TIMESTAMP(), UTC_TIMESTAMP())`,
+			userID, uuid.NewString(), fmt.Sprintf("overdue %d", i))
+		if err != nil {
+			t.Fatalf("creating check: %v", err)
+		}
+		ids[i], _ = result.LastInsertId()
+	}
+	return ids
+}
+
+// Two workers racing over the same overdue checks take each one down, and
+// notify it, exactly once, whichever locking strategy they use.
+func TestTwoWorkersNeverDoubleNotify(t *testing.T) {
+	for _, strategy := range []string{lockStrategySkipLocked, lockStrategyClaim} {
+		t.Run(strategy, func(t *testing.T) {
+			pool := openIntegrationDB(t)
+			ids := seedOverdueChecks(t, pool, 40)
+			dispatcher := &countingDispatcher{counts: map[int64]int{}}
+
+			var wg sync.WaitGroup
+			for _, instance := range []string{"worker-a", "worker-b"} {
+				tc := NewTimeoutChecker(pool, dispatcher, Config{BatchSize: 5, InstanceID: instance})
+				tc.lockStrategy = strategy
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range len(ids) {
+						if err := tc.processTimeouts(context.Background()); err != nil {
+							t.Errorf("%s: processTimeouts: %v", instance, err)
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			for _, id := range ids {
+				if got := dispatcher.counts[id]; got != 1 {
+					t.Errorf("check %d notified %d times, want 1", id, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+// newMockChecker returns a TimeoutChecker over sqlmock, failing the test on
+// unmet expectations.
+func newMockChecker(t *testing.T, cfg Config) (*TimeoutChecker, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unmet expectations: %v", err)
+		}
+		db.Close()
+	})
+	return NewTimeoutChecker(db, nil, cfg), mock
+}
+
+func TestDetectLockStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		probe   error
+		want    string
+		wantErr bool
+	}{
+		{name: "supported", want: lockStrategySkipLocked},
+		{name: "syntax error", probe: &mysql.MySQLError{Number: mysqlParseError, Message: "You have an error in your SQL syntax"}, want: lockStrategyClaim},
+		{name: "other error", probe: errors.New("connection refused"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, mock := newMockChecker(t, Config{})
+			mock.ExpectBegin()
+			probe := mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM checks LIMIT 0 FOR UPDATE SKIP LOCKED"))
+			if tt.probe != nil {
+				probe.WillReturnError(tt.probe)
+			} else {
+				probe.WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+			mock.ExpectRollback()
+
+			got, err := tc.detectLockStrategy(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("detectLockStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("detectLockStrategy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimBatch(t *testing.T) {
+	tc, mock := newMockChecker(t, Config{InstanceID: "worker-a", BatchSize: 25, ClaimTimeout: 2 * time.Minute})
+	mock.ExpectExec(regexp.QuoteMeta("SET claimed_by = ?, claimed_at = UTC_TIMESTAMP()")).
+		WithArgs("worker-a", "worker-a", int64(120), 25).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	claimed, err := tc.claimBatch(context.Background(), " status = 'up'")
+	if err != nil || claimed != 3 {
+		t.Fatalf("claimBatch = %d, %v; want 3, nil", claimed, err)
+	}
+}
+
+func TestReleaseClaims(t *testing.T) {
+	tc, mock := newMockChecker(t, Config{InstanceID: "worker-a"})
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE checks SET claimed_by = NULL, claimed_at = NULL WHERE claimed_by = ?")).
+		WithArgs("worker-a").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	if err := tc.releaseClaims(context.Background(), tc.dbPool); err != nil {
+		t.Fatalf("releaseClaims = %v", err)
+	}
+}
+
+// An idle tick: every probe finds nothing, so no transaction is opened and
+// nothing is committed. sqlmock fails any Begin or Commit not expected here.
+func TestProcessTimeoutsNoRows(t *testing.T) {
+	tc, mock := newMockChecker(t, Config{BatchSize: 10})
+	for range stages {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM checks WHERE")).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE notification_suppressed_by IS NOT NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "severity", "notification_suppressed_by"}))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE notification_pending = TRUE")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "severity", "notification_cycle_id", "notification_pending_at"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, uuid, severity, recovery_pending_since")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "severity", "recovery_pending_since"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, uuid, severity, slow_pending_since")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "uuid", "severity", "slow_pending_since"}))
+
+	if err := tc.processTimeouts(context.Background()); err != nil {
+		t.Fatalf("processTimeouts = %v", err)
+	}
+	if tc.lockStrategy != "" {
+		t.Errorf("lock strategy detected as %q on an idle tick, want no detection", tc.lockStrategy)
+	}
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/repository"
+)
+
+// CounterReconcilerConfig configures the CounterReconciler. Zero values get the
+// defaults noted.
+type CounterReconcilerConfig struct {
+	Interval  time.Duration // Between passes over all checks, default 1h
+	BatchSize int           // Checks locked and recomputed together, default 100
+	Pause     time.Duration // Between batches, to limit replication lag, default 100ms
+}
+
+// CounterReconciler recomputes the checks' denormalized ping counters
+// (total_pings, pings_this_week, last_failure_at) from the pings table. RecordPing
+// keeps them current between passes, except that pings_this_week only goes down
+// here, so it may include up to one interval's worth of pings older than a week.
+type CounterReconciler struct {
+	repo   repository.CheckRepository
+	config CounterReconcilerConfig
+}
+
+// NewCounterReconciler creates a reconciler. Call Start to run it.
+func NewCounterReconciler(repo repository.CheckRepository, cfg CounterReconcilerConfig) *CounterReconciler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Pause <= 0 {
+		cfg.Pause = 100 * time.Millisecond
+	}
+	return &CounterReconciler{repo: repo, config: cfg}
+}
+
+// Start runs a pass every interval until ctx is cancelled, the first straight away.
+func (cr *CounterReconciler) Start(ctx context.Context) {
+	log.Printf("INFO: Ping counter reconciler started (interval %s, batch size %d)", cr.config.Interval, cr.config.BatchSize)
+	ticker := time.NewTicker(cr.config.Interval)
+	defer ticker.Stop()
+	for {
+		cr.reconcileAll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("INFO: Ping counter reconciler stopping due to context cancellation.")
+			return
+		}
+	}
+}
+
+// reconcileAll walks every live check in ID order, a batch at a time. An error
+// ends the pass; the next one starts over.
+func (cr *CounterReconciler) reconcileAll(ctx context.Context) {
+	var afterID, total int64
+	for {
+		lastID, corrected, err := cr.repo.ReconcilePingCounters(ctx, afterID, cr.config.BatchSize)
+		total += corrected
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("ERROR: Ping counter reconciler failed after check ID %d: %v", afterID, err)
+			}
+			return
+		}
+		if lastID == 0 {
+			break
+		}
+		afterID = lastID
+		select {
+		case <-time.After(cr.config.Pause):
+		case <-ctx.Done():
+			return
+		}
+	}
+	if total > 0 {
+		log.Printf("INFO: Ping counter reconciler corrected the counters of %d checks", total)
+	}
+}
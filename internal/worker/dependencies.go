@@ -0,0 +1,232 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"bitterlink/core/internal/logging"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// downDependency selects the lowest ID among the down dependencies of the check
+// in the enclosing FROM checks, or NULL when none is down. A disabled or
+// deleted dependency doesn't count: nothing is watching it any more.
+const downDependency = `(
+            SELECT MIN(p.id)
+            FROM check_dependencies d
+            JOIN checks p ON p.id = d.depends_on_check_id
+            WHERE d.check_id = checks.id AND p.status = 'down' AND p.is_enabled = TRUE AND p.deleted_at IS NULL)`
+
+// findDownDependencies returns, for each of ids that has a down dependency, the
+// lowest such dependency's ID. It runs in the stage's transaction after the
+// status updates, so a dependency that went down in the same batch counts.
+func findDownDependencies(ctx context.Context, tx *sql.Tx, ids []int64) (map[int64]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `
+        SELECT d.check_id, MIN(p.id)
+        FROM check_dependencies d
+        JOIN checks p ON p.id = d.depends_on_check_id
+        WHERE d.check_id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + `)
+            AND p.status = 'down' AND p.is_enabled = TRUE AND p.deleted_at IS NULL
+        GROUP BY d.check_id`
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query down dependencies: %w", err)
+	}
+	defer rows.Close()
+	down := make(map[int64]int64)
+	for rows.Next() {
+		var checkID, dependencyID int64
+		if err := rows.Scan(&checkID, &dependencyID); err != nil {
+			return nil, fmt.Errorf("failed to scan down dependency: %w", err)
+		}
+		down[checkID] = dependencyID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return down, nil
+}
+
+// suppressNotification records in tx that n was not sent because dependencyID
+// is down. A suppressed 'down' notification is remembered on the check
+// (notification_suppressed_by) until processSuppressed releases it or the check
+// recovers; a suppressed 'late' warning is only recorded.
+func suppressNotification(ctx context.Context, tx *sql.Tx, n notify.Notification, dependencyID int64) error {
+	if n.Kind == notify.KindDown {
+		query := `UPDATE checks SET notification_suppressed_by = ? WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, dependencyID, n.CheckID); err != nil {
+			return fmt.Errorf("failed to suppress notification for check ID %d: %w", n.CheckID, err)
+		}
+	}
+	event := models.CheckEvent{
+		CheckID:        n.CheckID,
+		Type:           models.EventNotificationSuppressed,
+		ToStatus:       sql.NullString{String: n.Kind, Valid: true},
+		Source:         models.EventSourceWorker,
+		CycleID:        sql.NullString{String: n.CycleID, Valid: true},
+		RelatedCheckID: sql.NullInt64{Int64: dependencyID, Valid: true},
+	}
+	if err := repository.InsertEvent(ctx, tx, event); err != nil {
+		return fmt.Errorf("failed to record suppression for check ID %d: %w", n.CheckID, err)
+	}
+	log.Printf("INFO: Suppressed '%s' notification for check ID %d: dependency check ID %d is down (cycle %s)", n.Kind, n.CheckID, dependencyID, n.CycleID)
+	metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:suppressed")
+	return nil
+}
+
+// suppressedCheck is a check whose 'down' notification is suppressed by a
+// dependency that no longer is down.
+type suppressedCheck struct {
+	id           int64
+	uuid         string
+	severity     string
+	suppressedBy int64
+}
+
+// processSuppressed re-evaluates one batch of suppressed 'down' notifications
+// whose dependency recovered, was removed, disabled or deleted. A check that is
+// still down with another dependency down stays suppressed, now by that one; a
+// check that is still down otherwise gets its notification after all, through
+// the same path as a fresh outage (pending flag or outbox); a check that left
+// 'down' meanwhile (paused, say) is just cleared. With SuppressDependents off
+// every suppressed notification is released.
+func (tc *TimeoutChecker) processSuppressed(ctx context.Context) error {
+	// 1. Find the checks whose suppression no longer holds
+	query := `
+        SELECT id, uuid, severity, notification_suppressed_by
+        FROM checks
+        WHERE notification_suppressed_by IS NOT NULL
+            AND deleted_at IS NULL
+            AND (? = FALSE OR status <> 'down' OR NOT EXISTS (
+                SELECT 1
+                FROM check_dependencies d
+                JOIN checks p ON p.id = d.depends_on_check_id
+                WHERE d.check_id = checks.id AND p.id = checks.notification_suppressed_by
+                    AND p.status = 'down' AND p.is_enabled = TRUE AND p.deleted_at IS NULL))
+        ORDER BY id ASC
+        LIMIT ?`
+	rows, err := tc.dbPool.QueryContext(ctx, query, tc.config.SuppressDependents, tc.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to query suppressed notifications: %w", err)
+	}
+	var due []suppressedCheck
+	for rows.Next() {
+		var s suppressedCheck
+		if err := rows.Scan(&s.id, &s.uuid, &s.severity, &s.suppressedBy); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan suppressed notification: %w", err)
+		}
+		due = append(due, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration failed: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	cycleID := uuid.NewString()
+	log.Printf("INFO: Cycle %s re-evaluating %d suppressed notifications", cycleID, len(due))
+
+	// 2. Decide each check in its own transaction, then dispatch what was released
+	for _, s := range due {
+		n, err := tc.reevaluateSuppressed(ctx, s, cycleID)
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			continue
+		}
+		if err := tc.dispatcher.Dispatch(ctx, *n); err != nil {
+			logging.Errorf("Failed to dispatch '%s' notification for check ID %d (cycle %s): %v", n.Kind, n.CheckID, n.CycleID, err)
+			metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:error")
+			continue
+		}
+		metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:ok")
+		tc.clearNotificationPending(ctx, *n)
+	}
+	return nil
+}
+
+// reevaluateSuppressed decides one check of processSuppressed. It returns the
+// released notification when the caller must dispatch it, nil otherwise
+// (nothing released, already handled by another worker, or handed to the
+// outbox).
+func (tc *TimeoutChecker) reevaluateSuppressed(ctx context.Context, s suppressedCheck, cycleID string) (*notify.Notification, error) {
+	tx, err := tc.dbPool.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 1. Lock the check, unless another worker got to it first
+	var status string
+	var otherDown sql.NullInt64
+	var muted sql.NullBool
+	lockQuery := `SELECT status, ` + downDependency + `, muted_until > UTC_TIMESTAMP() FROM checks WHERE id = ? AND notification_suppressed_by = ? FOR UPDATE`
+	err = tx.QueryRowContext(ctx, lockQuery, s.id, s.suppressedBy).Scan(&status, &otherDown, &muted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock suppressed check ID %d: %w", s.id, err)
+	}
+
+	n := notify.Notification{Kind: notify.KindDown, CheckID: s.id, CheckUUID: s.uuid, Severity: s.severity, CycleID: cycleID}
+	var released *notify.Notification
+	release := false
+	switch {
+	case status != models.StatusDown:
+		// Left 'down' some other way; nothing to send
+		_, err = tx.ExecContext(ctx, `UPDATE checks SET notification_suppressed_by = NULL WHERE id = ?`, s.id)
+	case tc.config.SuppressDependents && otherDown.Int64 == s.suppressedBy:
+		return nil, nil // Went down again since we looked, still suppressed
+	case tc.config.SuppressDependents && otherDown.Valid:
+		err = suppressNotification(ctx, tx, n, otherDown.Int64)
+	case muted.Bool:
+		// Muted since it was suppressed; the mute has the last word
+		if _, err = tx.ExecContext(ctx, `UPDATE checks SET notification_suppressed_by = NULL WHERE id = ?`, s.id); err == nil {
+			err = muteNotification(ctx, tx, n)
+		}
+	default:
+		release = true
+		if _, err = tx.ExecContext(ctx, `UPDATE checks SET notification_suppressed_by = NULL WHERE id = ?`, s.id); err != nil {
+			break
+		}
+		if tc.config.Outbox {
+			err = enqueueOutbox(ctx, tx, n)
+			break
+		}
+		if err = markNotificationPending(ctx, tx, s.id, cycleID); err == nil {
+			released = &n
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-evaluate suppressed check ID %d: %w", s.id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if release {
+		log.Printf("INFO: Released suppressed 'down' notification for check ID %d: dependency check ID %d is no longer down", s.id, s.suppressedBy)
+	}
+	return released, nil
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bitterlink/core/internal/version"
+)
+
+// HeartbeatConfig configures the self-monitoring Heartbeat.
+type HeartbeatConfig struct {
+	// URL is pinged while this instance is healthy, e.g. a check on another
+	// Bitterlink instance or on healthchecks.io.
+	URL      string
+	Interval time.Duration // Between pings while healthy, default 60s
+	Timeout  time.Duration // Per ping request, default 10s
+	// MaxCheckerAge is how long ago the TimeoutChecker may have last completed a
+	// cycle. Defaults to three of its poll intervals.
+	MaxCheckerAge time.Duration
+	// MaxBacklog is the most undelivered notifications tolerated. Only applies to
+	// dispatchers that queue (notify.BacklogReporter).
+	MaxBacklog int
+}
+
+// heartbeatMinRetry is the first retry delay after a failed ping, doubled on
+// each further failure up to the normal interval.
+const heartbeatMinRetry = 5 * time.Second
+
+// Heartbeat is a dead man's switch for the service itself: it pings an external
+// monitor only while its health criteria pass, so that monitor alerts when this
+// instance is down, stuck or degraded. The criteria are:
+//   - the database answers a ping;
+//   - the TimeoutChecker completed a cycle within MaxCheckerAge;
+//   - the notification backlog, if the dispatcher has one, is within MaxBacklog.
+//
+// An unhealthy instance simply stops pinging; it doesn't report the failure.
+type Heartbeat struct {
+	dbPool  *sql.DB
+	checker *TimeoutChecker
+	config  HeartbeatConfig
+	client  *http.Client
+}
+
+// NewHeartbeat creates a heartbeat reporting on checker, which must be the
+// TimeoutChecker running in this process.
+func NewHeartbeat(db *sql.DB, checker *TimeoutChecker, cfg HeartbeatConfig) *Heartbeat {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxCheckerAge <= 0 {
+		cfg.MaxCheckerAge = 3 * checker.config.PollInterval
+	}
+	return &Heartbeat{
+		dbPool:  db,
+		checker: checker,
+		config:  cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Start pings until ctx is cancelled. The first ping waits one interval, giving
+// the TimeoutChecker time to complete its first cycle.
+func (h *Heartbeat) Start(ctx context.Context) {
+	log.Printf("INFO: Self-monitoring heartbeat active: pinging %s every %s while healthy (checker max age %s, max backlog %d)",
+		redactHeartbeatURL(h.config.URL), h.config.Interval, h.config.MaxCheckerAge, h.config.MaxBacklog)
+
+	delay := h.config.Interval
+	failures := 0
+	for {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			log.Println("INFO: Heartbeat stopping due to context cancellation.")
+			return
+		}
+
+		// 1. Unhealthy: skip this beat, which is what makes the monitor fire
+		if problems := h.healthProblems(ctx); len(problems) > 0 {
+			log.Printf("WARN: Heartbeat skipped, instance unhealthy: %s", strings.Join(problems, "; "))
+			delay, failures = h.config.Interval, 0
+			continue
+		}
+
+		// 2. Healthy: ping, retrying a failed send sooner with backoff
+		if err := h.send(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			if failures == 1 {
+				delay = min(heartbeatMinRetry, h.config.Interval)
+			} else {
+				delay = min(delay*2, h.config.Interval)
+			}
+			log.Printf("WARN: Heartbeat ping failed (%d in a row), retrying in %s: %v", failures, delay, err)
+			continue
+		}
+		if failures > 0 {
+			log.Printf("INFO: Heartbeat ping succeeded after %d failures", failures)
+		}
+		delay, failures = h.config.Interval, 0
+	}
+}
+
+// healthProblems evaluates the health criteria, returning one message per
+// failing criterion.
+func (h *Heartbeat) healthProblems(ctx context.Context) []string {
+	var problems []string
+
+	pingCtx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+	if err := h.dbPool.PingContext(pingCtx); err != nil {
+		problems = append(problems, fmt.Sprintf("database unreachable: %v", err))
+	}
+
+	if lastRun := h.checker.LastRun(); lastRun.IsZero() {
+		problems = append(problems, "timeout checker has not completed a cycle yet")
+	} else if age := time.Since(lastRun); age > h.config.MaxCheckerAge {
+		problems = append(problems, fmt.Sprintf("timeout checker last completed %s ago", age.Round(time.Second)))
+	}
+
+	if backlog, ok := h.checker.NotificationBacklog(); ok && backlog > h.config.MaxBacklog {
+		problems = append(problems, fmt.Sprintf("notification backlog %d exceeds %d", backlog, h.config.MaxBacklog))
+	}
+	return problems
+}
+
+// send makes one GET to the heartbeat URL; any 2xx counts as delivered.
+func (h *Heartbeat) send(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("building heartbeat request: %w", err)
+	}
+	req.Header.Set("User-Agent", "bitterlink-heartbeat/"+version.Version)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("heartbeat URL returned %s", resp.Status)
+	}
+	return nil
+}
+
+// redactHeartbeatURL keeps the scheme and host for logging. The path of a ping
+// URL usually is the check's secret UUID, so it is elided.
+func redactHeartbeatURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "(invalid URL)"
+	}
+	return u.Scheme + "://" + u.Host + "/..."
+}
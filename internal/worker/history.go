@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/repository"
+)
+
+// HistoryTrimmerConfig configures the HistoryTrimmer.
+type HistoryTrimmerConfig struct {
+	Interval  time.Duration   // Between passes over all limited checks, default 5m
+	BatchSize int             // Pings per DELETE, default 1000
+	Pause     time.Duration   // Between batches, to limit replication lag, default 100ms
+	Throttle  *db.LagThrottle // Also waits for lagging replicas between batches, nil for none
+}
+
+// HistoryTrimmer enforces per-check pings_history_limit. Trimming happens off
+// the ping path, so a check may briefly hold up to one interval's worth of pings
+// over its limit. Time-based pruning (core prune) still runs as a backstop.
+type HistoryTrimmer struct {
+	repo   repository.CheckRepository
+	config HistoryTrimmerConfig
+}
+
+// NewHistoryTrimmer creates a trimmer. Call Start to run it.
+func NewHistoryTrimmer(repo repository.CheckRepository, cfg HistoryTrimmerConfig) *HistoryTrimmer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.Pause <= 0 {
+		cfg.Pause = 100 * time.Millisecond
+	}
+	return &HistoryTrimmer{repo: repo, config: cfg}
+}
+
+// Start runs a pass every interval until ctx is cancelled, the first straight away.
+func (t *HistoryTrimmer) Start(ctx context.Context) {
+	log.Printf("INFO: Ping history trimmer started (interval %s, batch size %d)", t.config.Interval, t.config.BatchSize)
+	ticker := time.NewTicker(t.config.Interval)
+	defer ticker.Stop()
+	for {
+		t.trimAll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("INFO: Ping history trimmer stopping due to context cancellation.")
+			return
+		}
+	}
+}
+
+// trimAll trims every check with a history limit. A failure on one check is
+// logged and the pass moves on to the next.
+func (t *HistoryTrimmer) trimAll(ctx context.Context) {
+	limits, err := t.repo.ListHistoryLimits(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("ERROR: Ping history trimmer failed to list limits: %v", err)
+		}
+		return
+	}
+	var total int64
+	for _, limit := range limits {
+		deleted, err := t.trim(ctx, limit)
+		total += deleted
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("ERROR: Ping history trimmer failed for check ID %d: %v", limit.CheckID, err)
+		}
+	}
+	if total > 0 {
+		log.Printf("INFO: Ping history trimmer deleted %d pings over %d limited checks", total, len(limits))
+	}
+}
+
+// trim deletes one check's excess pings in batches, pausing between them.
+func (t *HistoryTrimmer) trim(ctx context.Context, limit repository.HistoryLimit) (int64, error) {
+	var total int64
+	for {
+		deleted, err := t.repo.TrimPingHistory(ctx, limit.CheckID, limit.Keep, t.config.BatchSize)
+		total += deleted
+		if err != nil || deleted < int64(t.config.BatchSize) {
+			return total, err
+		}
+		select {
+		case <-time.After(t.config.Pause):
+		case <-ctx.Done():
+			return total, nil
+		}
+		if t.config.Throttle.Wait(ctx) != nil {
+			return total, nil
+		}
+	}
+}
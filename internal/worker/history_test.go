@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"bitterlink/core/internal/repository"
+)
+
+// historyRepo holds one check's ping count and history limit, trimming and
+// updating them as the MySQL repository would.
+type historyRepo struct {
+	repository.CheckRepository
+
+	checkID int64
+	limit   sql.NullInt32
+	pings   int
+	batches []int64 // Deleted by each TrimPingHistory call
+}
+
+func (r *historyRepo) Update(_ context.Context, id int64, update repository.CheckUpdate) error {
+	if update.PingsHistoryLimit != nil {
+		r.limit = *update.PingsHistoryLimit
+	}
+	return nil
+}
+
+func (r *historyRepo) ListHistoryLimits(context.Context) ([]repository.HistoryLimit, error) {
+	if !r.limit.Valid {
+		return nil, nil
+	}
+	return []repository.HistoryLimit{{CheckID: r.checkID, Keep: int(r.limit.Int32)}}, nil
+}
+
+func (r *historyRepo) TrimPingHistory(_ context.Context, checkID int64, keep, limit int) (int64, error) {
+	deleted := int64(min(max(r.pings-keep, 0), limit))
+	r.pings -= int(deleted)
+	r.batches = append(r.batches, deleted)
+	return deleted, nil
+}
+
+// Lowering a check's limit from 10000 to 100 trims the 9900 pings now over it
+// in batches of 1000 on the next pass.
+func TestHistoryTrimmerLoweredLimit(t *testing.T) {
+	repo := &historyRepo{checkID: 42, limit: sql.NullInt32{Int32: 10000, Valid: true}, pings: 10000}
+	trimmer := NewHistoryTrimmer(repo, HistoryTrimmerConfig{BatchSize: 1000, Pause: time.Microsecond})
+	ctx := context.Background()
+
+	trimmer.trimAll(ctx)
+	if repo.pings != 10000 || len(repo.batches) != 1 || repo.batches[0] != 0 {
+		t.Fatalf("within the limit: %d pings left after batches %v, want 10000 after one empty batch", repo.pings, repo.batches)
+	}
+
+	lowered := sql.NullInt32{Int32: 100, Valid: true}
+	if err := repo.Update(ctx, 42, repository.CheckUpdate{PingsHistoryLimit: &lowered}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	repo.batches = nil
+	trimmer.trimAll(ctx)
+
+	if repo.pings != 100 {
+		t.Errorf("%d pings left, want 100", repo.pings)
+	}
+	want := []int64{1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 900}
+	if len(repo.batches) != len(want) {
+		t.Fatalf("batches = %v, want %v", repo.batches, want)
+	}
+	for i := range want {
+		if repo.batches[i] != want[i] {
+			t.Errorf("batches = %v, want %v", repo.batches, want)
+			break
+		}
+	}
+
+	// Removing the limit leaves the history alone
+	if err := repo.Update(ctx, 42, repository.CheckUpdate{PingsHistoryLimit: &sql.NullInt32{}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	repo.batches = nil
+	trimmer.trimAll(ctx)
+	if len(repo.batches) != 0 {
+		t.Errorf("trimmed %v without a limit", repo.batches)
+	}
+}
@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/repository"
+)
+
+// muteNotification records in tx that n was not sent because its check is
+// muted (checks.muted_until, set from an action link). Unlike a suppressed
+// notification, a muted one is not sent later: the mute was asked for knowing
+// the check was failing. Recoveries aren't muted, so the next notification
+// after a muted down is its 'up'.
+func muteNotification(ctx context.Context, tx *sql.Tx, n notify.Notification) error {
+	event := models.CheckEvent{
+		CheckID:  n.CheckID,
+		Type:     models.EventNotificationMuted,
+		ToStatus: sql.NullString{String: n.Kind, Valid: true},
+		Source:   models.EventSourceWorker,
+		CycleID:  sql.NullString{String: n.CycleID, Valid: true},
+	}
+	if err := repository.InsertEvent(ctx, tx, event); err != nil {
+		return fmt.Errorf("failed to record muted notification for check ID %d: %w", n.CheckID, err)
+	}
+	log.Printf("INFO: Muted '%s' notification for check ID %d (cycle %s)", n.Kind, n.CheckID, n.CycleID)
+	metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:muted")
+	return nil
+}
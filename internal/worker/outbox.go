@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"bitterlink/core/internal/logging"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/repository"
+)
+
+// maxOutboxErrorLen is the size of notification_outbox.last_error.
+const maxOutboxErrorLen = 255
+
+// enqueueOutbox records n in the notification outbox within tx, the transaction
+// that changes the check's status, so the two commit or roll back together.
+func enqueueOutbox(ctx context.Context, tx repository.Execer, n notify.Notification) error {
+	query := `
+        INSERT INTO notification_outbox (check_id, kind, cycle_id, next_attempt_at, created_at)
+        VALUES (?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`
+	if _, err := tx.ExecContext(ctx, query, n.CheckID, n.Kind, n.CycleID); err != nil {
+		return fmt.Errorf("failed to enqueue '%s' notification for check ID %d: %w", n.Kind, n.CheckID, err)
+	}
+	return nil
+}
+
+// OutboxConsumerConfig configures the OutboxConsumer. Zero values get the defaults noted.
+type OutboxConsumerConfig struct {
+	PollInterval time.Duration // Between polls, default 5s
+	BatchSize    int           // Rows per poll, default 50
+	// RetryAfter is how long a taken row is leased to its consumer, and so when
+	// a failed or interrupted delivery is tried again. Default 1m.
+	RetryAfter time.Duration
+}
+
+// OutboxConsumer delivers the notifications the TimeoutChecker records in the
+// outbox (Config.Outbox), decoupled from the transactions that created them.
+// Delivery is at-least-once: a crash between a dispatch and deleting its row
+// sends the notification again after RetryAfter. Any number of consumers may
+// run; a row is taken by moving its next_attempt_at forward only if it still
+// holds the value read, as in processPendingNotifications.
+type OutboxConsumer struct {
+	dbPool     *sql.DB
+	dispatcher notify.Dispatcher
+	config     OutboxConsumerConfig
+	backlog    atomic.Int64 // Rows left after the last poll, see Backlog
+}
+
+// NewOutboxConsumer creates a consumer. A nil dispatcher only logs notifications.
+// Call Start to run it.
+func NewOutboxConsumer(db *sql.DB, dispatcher notify.Dispatcher, cfg OutboxConsumerConfig) *OutboxConsumer {
+	if dispatcher == nil {
+		dispatcher = notify.LogDispatcher{}
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = time.Minute
+	}
+	return &OutboxConsumer{dbPool: db, dispatcher: dispatcher, config: cfg}
+}
+
+// Start polls the outbox every interval until ctx is cancelled.
+func (oc *OutboxConsumer) Start(ctx context.Context) {
+	log.Printf("INFO: Notification outbox consumer started (poll interval %s, retry after %s)", oc.config.PollInterval, oc.config.RetryAfter)
+	ticker := time.NewTicker(oc.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Keep going while batches come back full
+			for {
+				delivered, err := oc.processBatch(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						logging.Errorf("Notification outbox consumer failed: %v", err)
+					}
+					break
+				}
+				if delivered < oc.config.BatchSize {
+					break
+				}
+			}
+			oc.countBacklog(ctx)
+		case <-ctx.Done():
+			log.Println("INFO: Notification outbox consumer stopping due to context cancellation.")
+			return
+		}
+	}
+}
+
+// Backlog returns how many rows the outbox held after the last poll, due or
+// waiting for a retry. It is counted once per poll, so reading it for a metrics
+// gauge costs no query.
+func (oc *OutboxConsumer) Backlog() int64 {
+	return oc.backlog.Load()
+}
+
+// countBacklog refreshes Backlog. On failure the previous count stays.
+func (oc *OutboxConsumer) countBacklog(ctx context.Context) {
+	var backlog int64
+	if err := oc.dbPool.QueryRowContext(ctx, `SELECT COUNT(*) FROM notification_outbox`).Scan(&backlog); err != nil {
+		if ctx.Err() == nil {
+			log.Printf("WARN: Failed to count the notification outbox backlog: %v", err)
+		}
+		return
+	}
+	oc.backlog.Store(backlog)
+}
+
+// outboxRow is a notification waiting in the outbox.
+type outboxRow struct {
+	id            int64
+	n             notify.Notification
+	attempts      int
+	nextAttemptAt time.Time
+}
+
+// processBatch takes and delivers one batch of due rows and returns how many
+// rows it read.
+func (oc *OutboxConsumer) processBatch(ctx context.Context) (int, error) {
+	// 1. Find the due rows, oldest first. A deleted check's notification is
+	// dropped with the row below instead of being delivered.
+	query := `
+        SELECT o.id, o.check_id, COALESCE(c.uuid, ''), COALESCE(c.severity, ''), c.deleted_at IS NULL, o.kind, o.cycle_id, o.attempts, o.next_attempt_at
+        FROM notification_outbox o
+        LEFT JOIN checks c ON c.id = o.check_id
+        WHERE o.next_attempt_at <= UTC_TIMESTAMP()
+        ORDER BY o.id ASC
+        LIMIT ?`
+	rows, err := oc.dbPool.QueryContext(ctx, query, oc.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the notification outbox: %w", err)
+	}
+	var due []outboxRow
+	var gone []int64 // Rows of checks that no longer exist
+	for rows.Next() {
+		var row outboxRow
+		var live sql.NullBool
+		if err := rows.Scan(&row.id, &row.n.CheckID, &row.n.CheckUUID, &row.n.Severity, &live, &row.n.Kind, &row.n.CycleID, &row.attempts, &row.nextAttemptAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		if !live.Bool {
+			gone = append(gone, row.id)
+			continue
+		}
+		due = append(due, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("row iteration failed: %w", err)
+	}
+	for _, id := range gone {
+		if err := oc.deleteRow(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	// 2. Take each row by leasing it, then dispatch
+	takeQuery := `
+        UPDATE notification_outbox
+        SET next_attempt_at = UTC_TIMESTAMP() + INTERVAL ? SECOND, attempts = attempts + 1
+        WHERE id = ? AND next_attempt_at = ?`
+	lease := int64(oc.config.RetryAfter.Seconds())
+	for _, row := range due {
+		result, err := oc.dbPool.ExecContext(ctx, takeQuery, lease, row.id, row.nextAttemptAt)
+		if err != nil {
+			return 0, fmt.Errorf("failed to take outbox row %d: %w", row.id, err)
+		}
+		if taken, err := result.RowsAffected(); err != nil || taken != 1 {
+			continue // Another consumer took it
+		}
+
+		n := row.n
+		if err := oc.dispatcher.Dispatch(ctx, n); err != nil {
+			logging.Errorf("Failed to dispatch '%s' notification for check ID %d (cycle %s, attempt %d): %v", n.Kind, n.CheckID, n.CycleID, row.attempts+1, err)
+			metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:error")
+			oc.recordFailure(ctx, row.id, err)
+			continue
+		}
+		metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:ok")
+		if err := oc.deleteRow(ctx, row.id); err != nil {
+			// The lease runs out and the notification goes out again
+			log.Printf("WARN: Failed to delete delivered outbox row %d, it may be sent again: %v", row.id, err)
+		}
+	}
+	return len(due) + len(gone), nil
+}
+
+func (oc *OutboxConsumer) deleteRow(ctx context.Context, id int64) error {
+	if _, err := oc.dbPool.ExecContext(ctx, `DELETE FROM notification_outbox WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete outbox row %d: %w", id, err)
+	}
+	return nil
+}
+
+// recordFailure stores why the row's delivery failed. The lease taken for it
+// already schedules the retry.
+func (oc *OutboxConsumer) recordFailure(ctx context.Context, id int64, dispatchErr error) {
+	message := dispatchErr.Error()
+	if len(message) > maxOutboxErrorLen {
+		message = message[:maxOutboxErrorLen]
+	}
+	if _, err := oc.dbPool.ExecContext(ctx, `UPDATE notification_outbox SET last_error = ? WHERE id = ?`, message, id); err != nil {
+		log.Printf("WARN: Failed to record outbox delivery failure for row %d: %v", id, err)
+	}
+}
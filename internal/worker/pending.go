@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/repository"
+)
+
+// pendingNotification is a 'down' notification that was recorded but never
+// confirmed dispatched: the process died between commit and dispatch, or the
+// dispatch failed.
+type pendingNotification struct {
+	id        int64
+	uuid      string
+	severity  string
+	cycleID   string
+	pendingAt time.Time
+}
+
+// markNotificationPending records a 'down' notification for checkID in tx, the
+// transaction that marks the check down.
+func markNotificationPending(ctx context.Context, tx repository.Execer, checkID int64, cycleID string) error {
+	query := `
+        UPDATE checks
+        SET notification_pending = TRUE, notification_pending_at = UTC_TIMESTAMP(), notification_cycle_id = ?
+        WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, cycleID, checkID); err != nil {
+		return fmt.Errorf("failed to mark notification pending for check ID %d: %w", checkID, err)
+	}
+	return nil
+}
+
+// clearNotificationPending confirms n was dispatched. It only clears the flag
+// if it still belongs to n's cycle, not a newer outage's. A failure here is
+// logged only: the worst case is one redelivered notification.
+func (tc *TimeoutChecker) clearNotificationPending(ctx context.Context, n notify.Notification) {
+	query := `
+        UPDATE checks
+        SET notification_pending = FALSE, notification_pending_at = NULL, notification_cycle_id = NULL
+        WHERE id = ? AND notification_pending = TRUE AND notification_cycle_id = ?`
+	if _, err := tc.dbPool.ExecContext(ctx, query, n.CheckID, n.CycleID); err != nil {
+		log.Printf("WARN: Failed to clear pending notification for check ID %d (cycle %s), it may be sent again: %v", n.CheckID, n.CycleID, err)
+	}
+}
+
+// processPendingNotifications redelivers one batch of 'down' notifications that
+// have been pending for longer than RedeliverAfter, including those left behind
+// by a crashed process. Delivery is at-least-once: a crash between a successful
+// dispatch and clearing the flag sends the notification again.
+//
+// As in processRecoveries nothing is locked. Each notification is taken by
+// bumping notification_pending_at only if it still holds the value we read, so
+// concurrent workers don't both redeliver it, and a taken notification that
+// fails again is retried RedeliverAfter later.
+func (tc *TimeoutChecker) processPendingNotifications(ctx context.Context) error {
+	// 1. Find the stale pending notifications
+	query := `
+        SELECT id, uuid, severity, notification_cycle_id, notification_pending_at
+        FROM checks
+        WHERE notification_pending = TRUE
+            AND deleted_at IS NULL
+            AND notification_pending_at < (UTC_TIMESTAMP() - INTERVAL ? SECOND)
+        ORDER BY notification_pending_at ASC
+        LIMIT ?`
+	rows, err := tc.dbPool.QueryContext(ctx, query, int64(tc.config.RedeliverAfter.Seconds()), tc.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to query pending notifications: %w", err)
+	}
+	var pending []pendingNotification
+	for rows.Next() {
+		var p pendingNotification
+		if err := rows.Scan(&p.id, &p.uuid, &p.severity, &p.cycleID, &p.pendingAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan pending notification: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration failed: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	log.Printf("WARN: Found %d 'down' notifications pending longer than %s, redelivering", len(pending), tc.config.RedeliverAfter)
+
+	// 2. Take each one, then dispatch it with its original cycle ID
+	takeQuery := `
+        UPDATE checks SET notification_pending_at = UTC_TIMESTAMP()
+        WHERE id = ? AND notification_pending = TRUE AND notification_pending_at = ?`
+	for _, p := range pending {
+		result, err := tc.dbPool.ExecContext(ctx, takeQuery, p.id, p.pendingAt)
+		if err != nil {
+			return fmt.Errorf("failed to take pending notification of check ID %d: %w", p.id, err)
+		}
+		if taken, err := result.RowsAffected(); err != nil || taken != 1 {
+			// Another worker took it, or it was cleared since we read it
+			continue
+		}
+
+		n := notify.Notification{Kind: notify.KindDown, CheckID: p.id, CheckUUID: p.uuid, Severity: p.severity, CycleID: p.cycleID}
+		if err := tc.dispatcher.Dispatch(ctx, n); err != nil {
+			log.Printf("ERROR: Failed to redeliver '%s' notification for check ID %d (cycle %s): %v", n.Kind, n.CheckID, n.CycleID, err)
+			metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:error")
+			continue
+		}
+		log.Printf("INFO: Redelivered '%s' notification for check ID %d (cycle %s)", n.Kind, n.CheckID, n.CycleID)
+		metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:ok")
+		tc.clearNotificationPending(ctx, n)
+	}
+	return nil
+}
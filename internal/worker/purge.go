@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/repository"
+)
+
+// PurgerConfig configures the Purger. Zero values get the defaults noted.
+type PurgerConfig struct {
+	Retention     time.Duration   // How long soft-deleted checks are kept, default 30 days
+	Interval      time.Duration   // Between runs, default 1h
+	BatchSize     int             // Checks per cascade transaction, default 10
+	PingBatchSize int             // Pings per DELETE while emptying a check's history, default 5000
+	Pause         time.Duration   // Between batches, to limit replication lag, default 500ms
+	Throttle      *db.LagThrottle // Also waits for lagging replicas between batches, nil for none
+}
+
+// Purger permanently removes checks that have been soft-deleted for longer than
+// the retention window, with everything that references them. It is the
+// background counterpart of `core prune --purge-deleted-checks-older-than` and
+// deletes through the same cascade (repository.PurgeDeletedChecks).
+//
+// Live checks (deleted_at IS NULL) are never selected. Account exports only
+// read live checks, so they can't be holding on to anything purged here.
+type Purger struct {
+	repo   repository.CheckRepository
+	config PurgerConfig
+}
+
+// NewPurger creates a purger. Call Start to run it.
+func NewPurger(repo repository.CheckRepository, cfg PurgerConfig) *Purger {
+	if cfg.Retention <= 0 {
+		cfg.Retention = 30 * 24 * time.Hour
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.PingBatchSize <= 0 {
+		cfg.PingBatchSize = 5000
+	}
+	if cfg.Pause <= 0 {
+		cfg.Pause = 500 * time.Millisecond
+	}
+	return &Purger{repo: repo, config: cfg}
+}
+
+// Start runs a purge every interval until ctx is cancelled, the first straight away.
+func (p *Purger) Start(ctx context.Context) {
+	log.Printf("INFO: Deleted check purger started (retention %s, interval %s)", p.config.Retention, p.config.Interval)
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		p.purge(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("INFO: Deleted check purger stopping due to context cancellation.")
+			return
+		}
+	}
+}
+
+// purge runs batches until no check is past the retention window, logging the
+// totals. An error ends the run; the next one picks up where it stopped.
+func (p *Purger) purge(ctx context.Context) {
+	cutoff := time.Now().UTC().Add(-p.config.Retention)
+	var checks, pings int64
+	defer func() {
+		if checks > 0 || pings > 0 {
+			log.Printf("INFO: Purged %d checks soft-deleted before %s (%d pings)", checks, cutoff.Format(time.RFC3339), pings)
+		}
+	}()
+
+	for {
+		// 1. Empty each check's ping history first, in bounded batches
+		ids, err := p.repo.ListPurgeableCheckIDs(ctx, cutoff, p.config.BatchSize)
+		if err != nil {
+			p.logError(ctx, "list deleted checks", err)
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+		for _, id := range ids {
+			deleted, err := p.deletePings(ctx, id, cutoff)
+			pings += deleted
+			if err != nil {
+				p.logError(ctx, "delete pings", err)
+				return
+			}
+		}
+
+		// 2. Then cascade-delete the checks themselves in one transaction
+		purged, err := p.repo.PurgeDeletedChecks(ctx, cutoff, len(ids))
+		checks += purged
+		if err != nil {
+			p.logError(ctx, "purge checks", err)
+			return
+		}
+		if len(ids) < p.config.BatchSize || !p.sleep(ctx) {
+			return
+		}
+	}
+}
+
+// deletePings deletes one check's pings batch by batch.
+func (p *Purger) deletePings(ctx context.Context, checkID int64, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		deleted, err := p.repo.DeletePingsOfPurgeableCheck(ctx, checkID, cutoff, p.config.PingBatchSize)
+		total += deleted
+		if err != nil || deleted < int64(p.config.PingBatchSize) {
+			return total, err
+		}
+		if !p.sleep(ctx) {
+			return total, ctx.Err()
+		}
+	}
+}
+
+// sleep pauses between batches, and for lagging replicas, returning false when
+// ctx is cancelled.
+func (p *Purger) sleep(ctx context.Context) bool {
+	select {
+	case <-time.After(p.config.Pause):
+		return p.config.Throttle.Wait(ctx) == nil
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *Purger) logError(ctx context.Context, what string, err error) {
+	if ctx.Err() != nil {
+		return // Shutting down
+	}
+	log.Printf("ERROR: Deleted check purger failed to %s: %v", what, err)
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/notify"
+
+	"github.com/google/uuid"
+)
+
+// recoveryCondition selects checks whose recovery notification is due: a ping
+// brought them back from 'down' (stamping recovery_pending_since) and they have
+// been up for their whole recovery_stabilization since. A check that went down
+// again in between had the stamp cleared, so its brief recovery never notifies.
+const recoveryCondition = `
+            status = 'up'
+            AND is_enabled = TRUE
+            AND deleted_at IS NULL
+            AND recovery_pending_since IS NOT NULL
+            AND recovery_pending_since <= (UTC_TIMESTAMP() - INTERVAL recovery_stabilization SECOND)`
+
+// pendingRecovery is a check whose recovery notification is due.
+type pendingRecovery struct {
+	id           int64
+	uuid         string
+	severity     string
+	pendingSince time.Time
+}
+
+// processRecoveries sends one batch of due recovery notifications.
+//
+// Unlike the escalation stages nothing is locked: each check is taken with a
+// conditional UPDATE that clears recovery_pending_since only if it still holds
+// the value we read and the check is still up. Only the worker whose UPDATE
+// affects the row dispatches, so concurrent workers never double-notify, and a
+// check that went down after we read it is skipped.
+func (tc *TimeoutChecker) processRecoveries(ctx context.Context) error {
+	// 1. Find the due checks
+	query := `
+        SELECT id, uuid, severity, recovery_pending_since
+        FROM checks
+        WHERE` + recoveryCondition + `
+        ORDER BY recovery_pending_since ASC
+        LIMIT ?`
+	rows, err := tc.dbPool.QueryContext(ctx, query, tc.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to query pending recoveries: %w", err)
+	}
+	var due []pendingRecovery
+	for rows.Next() {
+		var r pendingRecovery
+		if err := rows.Scan(&r.id, &r.uuid, &r.severity, &r.pendingSince); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan pending recovery: %w", err)
+		}
+		due = append(due, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration failed: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	cycleID := uuid.NewString()
+	log.Printf("INFO: Cycle %s found %d recovery notifications due", cycleID, len(due))
+
+	// 2. Take each check, re-checking it is still up
+	takeQuery := `
+        UPDATE checks SET recovery_pending_since = NULL
+        WHERE id = ? AND recovery_pending_since = ? AND status = 'up'`
+	var notifications []notify.Notification
+	for _, r := range due {
+		result, err := tc.dbPool.ExecContext(ctx, takeQuery, r.id, r.pendingSince)
+		if err != nil {
+			return fmt.Errorf("failed to take recovery of check ID %d: %w", r.id, err)
+		}
+		if taken, err := result.RowsAffected(); err != nil || taken != 1 {
+			// Another worker sent it, or the check changed since we read it
+			continue
+		}
+		notifications = append(notifications, notify.Notification{Kind: notify.KindUp, CheckID: r.id, CheckUUID: r.uuid, Severity: r.severity, CycleID: cycleID})
+	}
+
+	// 3. Dispatch the ones we took, together
+	dispatchErrs := notify.DispatchAll(ctx, tc.dispatcher, notifications)
+	for i, n := range notifications {
+		if err := dispatchErrs[i]; err != nil {
+			log.Printf("ERROR: Failed to dispatch '%s' notification for check ID %d (cycle %s): %v", n.Kind, n.CheckID, n.CycleID, err)
+			metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:error")
+			continue
+		}
+		metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:ok")
+	}
+	return nil
+}
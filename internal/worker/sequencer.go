@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/repository"
+)
+
+// EventSequencerConfig configures the EventSequencer. Zero values get the
+// defaults noted.
+type EventSequencerConfig struct {
+	PollInterval time.Duration // Between passes when caught up, default 1s
+	BatchSize    int           // Events numbered per transaction, default 500
+}
+
+// EventSequencer numbers committed check events for the event feed (GET
+// /api/v1/events), see repository.SequenceEvents. Events show up in the feed
+// only once numbered, so its poll interval is the feed's delay. Several
+// instances can run at once; the sequence lock serializes them.
+type EventSequencer struct {
+	repo   repository.CheckRepository
+	config EventSequencerConfig
+}
+
+// NewEventSequencer creates a sequencer. Call Start to run it.
+func NewEventSequencer(repo repository.CheckRepository, cfg EventSequencerConfig) *EventSequencer {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &EventSequencer{repo: repo, config: cfg}
+}
+
+// Start sequences events until ctx is cancelled; a full batch is followed by
+// the next one straight away.
+func (s *EventSequencer) Start(ctx context.Context) {
+	log.Printf("INFO: Event sequencer started (poll interval %s, batch size %d)", s.config.PollInterval, s.config.BatchSize)
+	for {
+		sequenced, err := s.repo.SequenceEvents(ctx, s.config.BatchSize)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("ERROR: Event sequencer failed: %v", err)
+		}
+		if err != nil || sequenced < s.config.BatchSize {
+			select {
+			case <-time.After(s.config.PollInterval):
+			case <-ctx.Done():
+				log.Println("INFO: Event sequencer stopping due to context cancellation.")
+				return
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
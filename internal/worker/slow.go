@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/notify"
+
+	"github.com/google/uuid"
+)
+
+// slowCondition selects checks with a "ran slow" notification waiting: a success
+// ping closed a run that took longer than max_duration and was the first of a
+// streak (see repository.recordPingTx), stamping slow_pending_since.
+const slowCondition = `
+            slow_pending_since IS NOT NULL
+            AND is_enabled = TRUE
+            AND deleted_at IS NULL`
+
+// pendingSlowRun is a check whose "ran slow" notification is due.
+type pendingSlowRun struct {
+	id           int64
+	uuid         string
+	severity     string
+	pendingSince time.Time
+}
+
+// processSlowRuns sends one batch of "ran slow" notifications. Checks are taken as
+// in processRecoveries: a conditional UPDATE clears slow_pending_since only if it
+// still holds the value we read, and only the worker it succeeds for dispatches.
+// A disabled check's notification waits for it to be enabled again, like its
+// other alerts would.
+func (tc *TimeoutChecker) processSlowRuns(ctx context.Context) error {
+	// 1. Find the due checks
+	query := `
+        SELECT id, uuid, severity, slow_pending_since
+        FROM checks
+        WHERE` + slowCondition + `
+        ORDER BY slow_pending_since ASC
+        LIMIT ?`
+	rows, err := tc.dbPool.QueryContext(ctx, query, tc.config.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to query pending slow runs: %w", err)
+	}
+	var due []pendingSlowRun
+	for rows.Next() {
+		var s pendingSlowRun
+		if err := rows.Scan(&s.id, &s.uuid, &s.severity, &s.pendingSince); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan pending slow run: %w", err)
+		}
+		due = append(due, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration failed: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	cycleID := uuid.NewString()
+	log.Printf("INFO: Cycle %s found %d slow run notifications due", cycleID, len(due))
+
+	// 2. Take each check, then dispatch
+	takeQuery := `UPDATE checks SET slow_pending_since = NULL WHERE id = ? AND slow_pending_since = ?`
+	for _, s := range due {
+		result, err := tc.dbPool.ExecContext(ctx, takeQuery, s.id, s.pendingSince)
+		if err != nil {
+			return fmt.Errorf("failed to take slow run of check ID %d: %w", s.id, err)
+		}
+		if taken, err := result.RowsAffected(); err != nil || taken != 1 {
+			// Another worker sent it, or a newer slow run replaced it
+			continue
+		}
+
+		n := notify.Notification{Kind: notify.KindSlow, CheckID: s.id, CheckUUID: s.uuid, Severity: s.severity, CycleID: cycleID}
+		if err := tc.dispatcher.Dispatch(ctx, n); err != nil {
+			log.Printf("ERROR: Failed to dispatch '%s' notification for check ID %d (cycle %s): %v", n.Kind, n.CheckID, n.CycleID, err)
+			metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:error")
+			continue
+		}
+		metrics.Incr(metrics.NotificationsDispatched, "kind:"+n.Kind, "outcome:ok")
+	}
+	return nil
+}
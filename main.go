@@ -1,123 +1,125 @@
 package main
 
 import (
-	"context"
-	"errors"
+	"database/sql"
+	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"strconv"
-	"syscall"
+	"strings"
 	"time"
 
-	"bitterlink/core/internal/agency"
 	"bitterlink/core/internal/config"
 	"bitterlink/core/internal/db"
 	"bitterlink/core/internal/logging"
 	"bitterlink/core/internal/repository"
-	"bitterlink/core/internal/transport/http"
-	"bitterlink/core/internal/worker"
+	"bitterlink/core/internal/version"
 
-	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
 )
 
-func main() {
-	logging.SetupLogging()
-	config.LoadEnv()
-	log.Println("INFO: Starting application...")
+const usage = `Usage: core [command] [flags]
 
-	databasePool, err := db.ConnectDB()
-	if err != nil {
-		log.Fatalf("FATAL: Database initialization failed: %v", err)
-	}
-	log.Println("INFO: Database connection ready.")
+Commands:
+  serve        Run the HTTP server and background workers (default; ROLE=api|worker|all)
+  checks       Inspect and force check states (checks list | checks set-status)
+  seed         Generate development data (refuses to run when APP_ENV=production)
+  export       Write a JSON dump of one account (--user) or all accounts (--all)
+  import       Restore a JSON dump into empty accounts
+  prune        Delete old pings and purge soft-deleted checks in rate-limited batches
+  healthcheck  Probe the running server's /health (for container health checks)
+`
 
-	// --- Timeout Checker Worker ---
-	// Configuration (Read from Env Vars or defaults)
-	pollIntervalSeconds, _ := strconv.Atoi(os.Getenv("CHECKER_POLL_INTERVAL_SECONDS"))
-	if pollIntervalSeconds <= 0 {
-		pollIntervalSeconds = 30
-	}
-	batchSize, _ := strconv.Atoi(os.Getenv("CHECKER_BATCH_SIZE"))
-	if batchSize <= 0 {
-		batchSize = 10
-	}
-	checkerConfig := worker.Config{
-		PollInterval: time.Duration(pollIntervalSeconds) * time.Second,
-		BatchSize:    batchSize,
+func main() {
+	command := "serve"
+	args := []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
 	}
-	timeoutChecker := worker.NewTimeoutChecker(databasePool, checkerConfig)
-
-	// Create a context that can be cancelled for graceful shutdown
-	// Link it to SIGINT/SIGTERM signals
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	// Start the checker worker in a separate goroutine
-	// Pass the cancellable context
-	go timeoutChecker.Start(ctx)
 
-	// Create repository instances
-	checkRepo := repository.NewMySQLCheckRepository(databasePool)
-	// userRepo := repository.NewMySQLUserRepository(dbPool) // etc.
-
-	// Create handler instances, injecting dependencies
-	pingHandler := httptransport.NewPingHandler(checkRepo)
-	checkHandler := httptransport.NewCheckHandler(checkRepo)
-	// checkHandler := httptransport.NewCheckHandler(checkRepo) // For API CRUD
+	switch command {
+	case "serve":
+		runServe()
+	case "checks":
+		os.Exit(runChecksCommand(args))
+	case "seed":
+		os.Exit(runSeedCommand(args))
+	case "export":
+		os.Exit(runExportCommand(args))
+	case "import":
+		os.Exit(runImportCommand(args))
+	case "prune":
+		os.Exit(runPruneCommand(args))
+	case "healthcheck":
+		os.Exit(runHealthcheckCommand(args))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
 
-	router := gin.Default()
+// bootstrap performs the setup shared by every command: logging, configuration
+// and the database pool.
+func bootstrap() (*sql.DB, error) {
+	logging.SetupLogging()
+	config.LoadEnv()
+	log.Printf("INFO: Starting application (%s)...", version.Get())
 
-	httptransport.RegisterRoutes(router, pingHandler, checkHandler, databasePool, checkRepo)
-	log.Println("INFO: HTTP routes registered.")
+	db.ConfigureCircuit(db.CircuitConfig{
+		Threshold:     config.GetInt("DB_CIRCUIT_FAILURES", 5),
+		ProbeInterval: config.GetDuration("DB_CIRCUIT_PROBE_INTERVAL", 5*time.Second),
+	})
 
-	srvPort := os.Getenv("SERVER_PORT")
-	if srvPort == "" {
-		srvPort = "8080"
+	databasePool, err := db.ConnectDB()
+	if err != nil {
+		return nil, err
 	}
+	log.Println("INFO: Database connection ready.")
+	return databasePool, nil
+}
 
-	if !agency.IsNumeric(srvPort) {
-		log.Printf("ERROR: Server port: %s is not numeric.\n", srvPort)
+// newCheckRepository builds the check repository from configuration.
+func newCheckRepository(databasePool *sql.DB) repository.CheckRepository {
+	inactivePingPolicy := config.GetString("INACTIVE_PING_POLICY", repository.InactivePingRecordNoStatus)
+	if !repository.IsValidInactivePingPolicy(inactivePingPolicy) {
+		log.Printf("WARN: Unknown INACTIVE_PING_POLICY %q, using %q", inactivePingPolicy, repository.InactivePingRecordNoStatus)
+		inactivePingPolicy = repository.InactivePingRecordNoStatus
 	}
-
-	srv := &http.Server{
-		Addr:    ":" + srvPort,
-		Handler: router,
-		// Add Read/Write timeouts for production readiness
-		// ReadTimeout: 5 * time.Second,
-		// WriteTimeout: 10 * time.Second,
-		// IdleTimeout: 120 * time.Second,
+	repoConfig := repository.Config{
+		CompressPayloads:       config.GetBool("PING_PAYLOAD_COMPRESSION", false),
+		CompressThresholdBytes: config.GetInt("PING_PAYLOAD_COMPRESSION_THRESHOLD_BYTES", 1024),
+		InactivePingPolicy:     inactivePingPolicy,
+		PingFastPath:           config.GetBool("PING_FAST_PATH", false),
 	}
+	return repository.NewMySQLCheckRepository(databasePool, repoConfig)
+}
 
-	go func() {
-		log.Printf("INFO: Starting HTTP server on port :%s", srvPort)
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("FATAL: listen: %s\n", err)
+// connectReplicas opens the read replicas listed in REPLICA_DSNS (full
+// go-sql-driver DSNs, comma separated), which batched deletes wait for while
+// they lag. An unreachable replica is left out with a warning: pruning goes on
+// without watching it rather than not at all.
+func connectReplicas() []*sql.DB {
+	var replicas []*sql.DB
+	for _, dsn := range strings.Split(os.Getenv("REPLICA_DSNS"), ",") {
+		if dsn = strings.TrimSpace(dsn); dsn == "" {
+			continue
 		}
-	}()
-
-	// --- Graceful Shutdown ---
-	// Wait for interrupt signal (captured by signal.NotifyContext)
-	<-ctx.Done()
-
-	stop()
-	log.Println("INFO: Shutting down server and workers...")
-
-	// Create a deadline context for the shutdown process.
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second) // Increased timeout slightly
-	defer cancel()
-
-	// Attempt to gracefully shut down the HTTP server
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("WARN: Server shutdown failed: %v", err)
-	} else {
-		log.Println("INFO: Server gracefully stopped.")
+		replica, err := db.ConnectReplicaDB(dsn)
+		if err != nil {
+			log.Printf("WARN: Not watching replication lag of a replica: %v", err)
+			continue
+		}
+		replicas = append(replicas, replica)
 	}
+	return replicas
+}
 
-	// At this point, the context passed to timeoutChecker.Start() is cancelled,
-	// so its loop should exit cleanly. You might add a WaitGroup if you
-	// need to explicitly wait for background workers like the checker to finish.
-	log.Println("INFO: Application exited.")
+// lagThrottleConfig reads how far behind replicas may fall during batched
+// deletes (REPLICATION_MAX_LAG) and how often that is checked.
+func lagThrottleConfig() db.LagThrottleConfig {
+	return db.LagThrottleConfig{
+		MaxLag:        config.GetDuration("REPLICATION_MAX_LAG", 10*time.Second),
+		CheckInterval: config.GetDuration("REPLICATION_LAG_CHECK_INTERVAL", 5*time.Second),
+	}
 }
@@ -0,0 +1,5 @@
+-- Ping payloads may now be stored gzipped (see PING_PAYLOAD_COMPRESSION).
+-- payload becomes binary-safe and payload_compressed records how each row was stored.
+ALTER TABLE pings
+    MODIFY COLUMN payload MEDIUMBLOB NULL,
+    ADD COLUMN payload_compressed BOOLEAN NOT NULL DEFAULT FALSE AFTER payload;
@@ -0,0 +1,11 @@
+-- History of status changes and other notable events per check.
+CREATE TABLE check_events (
+    id          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    check_id    BIGINT UNSIGNED NOT NULL,
+    event_type  VARCHAR(32)     NOT NULL, -- e.g. 'status_changed'
+    from_status VARCHAR(16)     NULL,
+    to_status   VARCHAR(16)     NULL,
+    source      VARCHAR(32)     NOT NULL, -- who caused it: 'ping', 'worker', 'api', 'cli'
+    created_at  TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_check_events_check_id (check_id, id)
+);
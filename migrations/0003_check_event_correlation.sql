@@ -0,0 +1,5 @@
+-- Link each event to what triggered it: the ping that caused a recovery,
+-- or the worker detection cycle that marked the check down.
+ALTER TABLE check_events
+    ADD COLUMN ping_id  BIGINT UNSIGNED NULL AFTER source,
+    ADD COLUMN cycle_id VARCHAR(36)     NULL AFTER ping_id;
@@ -0,0 +1,3 @@
+-- Pings can now carry a signal: a plain success, a job start, or a failure report.
+ALTER TABLE pings
+    ADD COLUMN kind ENUM('success', 'start', 'fail') NOT NULL DEFAULT 'success' AFTER check_id;
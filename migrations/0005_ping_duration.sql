@@ -0,0 +1,6 @@
+-- Track when a job run started so the completing ping can record how long it took.
+ALTER TABLE checks
+    ADD COLUMN last_start_at TIMESTAMP NULL DEFAULT NULL AFTER last_ping_at;
+
+ALTER TABLE pings
+    ADD COLUMN duration_ms INT UNSIGNED NULL DEFAULT NULL AFTER user_agent;
@@ -0,0 +1,6 @@
+-- Claim columns for the worker's fallback locking strategy, used on servers
+-- without FOR UPDATE SKIP LOCKED (MySQL 5.7, older MariaDB).
+ALTER TABLE checks
+    ADD COLUMN claimed_by VARCHAR(64) NULL DEFAULT NULL,
+    ADD COLUMN claimed_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_checks_claimed_by (claimed_by);
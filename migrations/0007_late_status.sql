@@ -0,0 +1,5 @@
+-- Two-stage alerting: 'late' sits between 'up' and 'down' while a check is
+-- inside its grace period. The warning notification for it is opt-in.
+ALTER TABLE checks
+    MODIFY COLUMN status ENUM('new', 'up', 'late', 'down', 'paused') NOT NULL DEFAULT 'new',
+    ADD COLUMN notify_late BOOLEAN NOT NULL DEFAULT FALSE AFTER is_enabled;
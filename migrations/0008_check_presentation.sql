@@ -0,0 +1,5 @@
+-- Dashboard-only presentation metadata. Neither column affects monitoring.
+-- color is stored as lowercase #rrggbb; icon is one of models.CheckIcons.
+ALTER TABLE checks
+    ADD COLUMN color CHAR(7) NULL DEFAULT NULL AFTER notify_late,
+    ADD COLUMN icon VARCHAR(32) NULL DEFAULT NULL AFTER color;
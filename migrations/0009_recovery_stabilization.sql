@@ -0,0 +1,8 @@
+-- Deferred recovery notifications. A ping that brings a check back from 'down'
+-- stamps recovery_pending_since; the worker sends the recovery notification once
+-- the check has stayed 'up' for recovery_stabilization seconds (0 = next tick).
+-- Going down again clears the stamp, so a brief recovery never alerts.
+ALTER TABLE checks
+    ADD COLUMN recovery_stabilization INT UNSIGNED NOT NULL DEFAULT 0 AFTER notify_late,
+    ADD COLUMN recovery_pending_since DATETIME NULL DEFAULT NULL AFTER recovery_stabilization,
+    ADD INDEX idx_checks_recovery_pending (recovery_pending_since);
@@ -0,0 +1,5 @@
+-- API key scopes. Every existing key keeps ordinary per-user access; operators
+-- grant instance-wide endpoints (/api/v1/admin) by setting scope = 'admin':
+--   UPDATE api_keys SET scope = 'admin' WHERE id = ...;
+ALTER TABLE api_keys
+    ADD COLUMN scope VARCHAR(16) NOT NULL DEFAULT 'user' AFTER label;
@@ -0,0 +1,8 @@
+-- Per-check ping forwarding. Pings to a check with forward_url set are mirrored
+-- to that URL after they are recorded. forward_failures counts consecutive
+-- failed forwards (reset by the next success) so a dead downstream is visible.
+ALTER TABLE checks
+    ADD COLUMN forward_url VARCHAR(2048) NULL DEFAULT NULL AFTER icon,
+    ADD COLUMN forward_failures INT UNSIGNED NOT NULL DEFAULT 0 AFTER forward_url,
+    ADD COLUMN forward_last_error VARCHAR(255) NULL DEFAULT NULL AFTER forward_failures,
+    ADD COLUMN forward_failed_at DATETIME NULL DEFAULT NULL AFTER forward_last_error;
@@ -0,0 +1,6 @@
+-- Per-check ping retention by count. When pings_history_limit is set, the
+-- history trimmer deletes the check's pings beyond the newest N, in batches.
+-- Time-based pruning (core prune --pings-older-than) still applies to all checks.
+ALTER TABLE checks
+    ADD COLUMN pings_history_limit INT UNSIGNED NULL DEFAULT NULL AFTER forward_failed_at,
+    ADD INDEX idx_checks_pings_history_limit (pings_history_limit);
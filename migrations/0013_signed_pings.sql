@@ -0,0 +1,6 @@
+-- Per-check opt-in to signed ping URLs. Pings to a check with
+-- require_signed_pings set are refused unless they carry a valid, unexpired
+-- HMAC signature (see internal/pingsig). PING_SIGNATURES_REQUIRED applies the
+-- same to every check on the instance.
+ALTER TABLE checks
+    ADD COLUMN require_signed_pings BOOLEAN NOT NULL DEFAULT FALSE AFTER pings_history_limit;
@@ -0,0 +1,9 @@
+-- Outbox flag for 'down' notifications. The worker sets notification_pending in
+-- the same transaction that marks a check down and clears it only once the
+-- notification was dispatched, so one that was lost to a crash (or a failed
+-- dispatch) is redelivered on a later tick: at-least-once delivery.
+ALTER TABLE checks
+    ADD COLUMN notification_pending BOOLEAN NOT NULL DEFAULT FALSE AFTER recovery_pending_since,
+    ADD COLUMN notification_pending_at DATETIME NULL DEFAULT NULL AFTER notification_pending,
+    ADD COLUMN notification_cycle_id VARCHAR(36) NULL DEFAULT NULL AFTER notification_pending_at,
+    ADD INDEX idx_checks_notification_pending (notification_pending, notification_pending_at);
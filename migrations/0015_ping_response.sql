@@ -0,0 +1,6 @@
+-- Per-check response to successful pings, for pingers that expect a particular
+-- status or body. NULL keeps the default: 200 with {"status":"ok"}. Errors are
+-- never customised.
+ALTER TABLE checks
+    ADD COLUMN ping_response_code SMALLINT UNSIGNED NULL DEFAULT NULL AFTER require_signed_pings,
+    ADD COLUMN ping_response_body VARCHAR(255) NULL DEFAULT NULL AFTER ping_response_code;
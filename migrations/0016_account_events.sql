@@ -0,0 +1,12 @@
+-- Account-level history for actions that span many checks, e.g. pausing every
+-- check at once. Each affected check still gets its own check_events row; this
+-- is the single entry saying who did it and how many checks it touched.
+CREATE TABLE account_events (
+    id              BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id         BIGINT UNSIGNED NOT NULL,
+    event_type      VARCHAR(32)     NOT NULL, -- e.g. 'checks_paused'
+    source          VARCHAR(32)     NOT NULL, -- same values as check_events.source
+    checks_affected INT UNSIGNED    NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_account_events_user_id (user_id, id)
+);
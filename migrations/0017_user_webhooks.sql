@@ -0,0 +1,9 @@
+-- Per-user "firehose" webhook: every event of every check of the account (pings,
+-- late, down, up) is POSTed to webhook_url when it is set. webhook_failures
+-- counts consecutive failed deliveries (reset by the next success) and trips the
+-- circuit breaker, see internal/webhook.
+ALTER TABLE users
+    ADD COLUMN webhook_url VARCHAR(2048) NULL DEFAULT NULL,
+    ADD COLUMN webhook_failures INT UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN webhook_last_error VARCHAR(255) NULL DEFAULT NULL,
+    ADD COLUMN webhook_failed_at DATETIME NULL DEFAULT NULL;
@@ -0,0 +1,11 @@
+-- Slow-run alerting. A success ping that closes a start ping more than
+-- max_duration seconds after it records a 'slow' event and, once per incident,
+-- a "ran slow" notification; a start ping left open for longer than that takes
+-- the check down. slow_since is when the current streak of slow runs began (a run
+-- within max_duration ends it) and slow_pending_since, like
+-- recovery_pending_since, stamps a notification the worker has yet to send.
+ALTER TABLE checks
+    ADD COLUMN max_duration INT UNSIGNED NULL DEFAULT NULL AFTER ping_response_body,
+    ADD COLUMN slow_since DATETIME NULL DEFAULT NULL AFTER max_duration,
+    ADD COLUMN slow_pending_since DATETIME NULL DEFAULT NULL AFTER slow_since,
+    ADD INDEX idx_checks_slow_pending (slow_pending_since);
@@ -0,0 +1,16 @@
+-- Durable notification outbox, used when CHECKER_NOTIFICATION_OUTBOX is on. The
+-- worker inserts a row in the same transaction that marks a check down, so a
+-- committed status change always has its notification on record; the outbox
+-- consumer delivers rows and deletes them once dispatched. next_attempt_at is
+-- both the retry schedule and the lease of a consumer working on the row.
+CREATE TABLE notification_outbox (
+    id              BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    check_id        BIGINT UNSIGNED NOT NULL,
+    kind            VARCHAR(16)     NOT NULL, -- notify.Kind*
+    cycle_id        VARCHAR(36)     NOT NULL,
+    attempts        INT UNSIGNED    NOT NULL DEFAULT 0,
+    last_error      VARCHAR(255)    NULL DEFAULT NULL,
+    next_attempt_at DATETIME        NOT NULL,
+    created_at      TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_notification_outbox_next_attempt (next_attempt_at)
+);
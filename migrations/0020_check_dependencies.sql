@@ -0,0 +1,21 @@
+-- Check dependencies: check_id depends on depends_on_check_id, e.g. every check
+-- of a host depends on the host's own check. While a dependency is down the
+-- worker still changes the dependent's status but suppresses its notification,
+-- recording the dependency in notification_suppressed_by until it recovers.
+-- The graph is kept free of cycles by the repository, on write.
+CREATE TABLE check_dependencies (
+    check_id            BIGINT UNSIGNED NOT NULL,
+    depends_on_check_id BIGINT UNSIGNED NOT NULL,
+    created_at          TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (check_id, depends_on_check_id),
+    INDEX idx_check_dependencies_depends_on (depends_on_check_id)
+);
+
+ALTER TABLE checks
+    ADD COLUMN notification_suppressed_by BIGINT UNSIGNED NULL DEFAULT NULL AFTER notification_cycle_id,
+    ADD INDEX idx_checks_notification_suppressed_by (notification_suppressed_by);
+
+-- The other check an event refers to, e.g. the dependency that suppressed a
+-- notification_suppressed event's notification.
+ALTER TABLE check_events
+    ADD COLUMN related_check_id BIGINT UNSIGNED NULL DEFAULT NULL AFTER cycle_id;
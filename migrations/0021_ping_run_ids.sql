@@ -0,0 +1,7 @@
+-- Run IDs: a client-generated ID (a ULID or UUID, say) sent as ?rid= with the
+-- start, success and fail pings of one execution. Completions are paired with
+-- the start of the same run, so overlapping runs of a check each get their own
+-- duration; pings without one pair with the check's open run as before.
+ALTER TABLE pings
+    ADD COLUMN run_id VARCHAR(64) NULL DEFAULT NULL AFTER duration_ms,
+    ADD INDEX idx_pings_check_run (check_id, run_id);
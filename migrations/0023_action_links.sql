@@ -0,0 +1,17 @@
+-- Signed action links in alerts (internal/actionlink), e.g. "mute this check for
+-- 4 hours". muted_until silences the check's late and down notifications until
+-- then; the status still changes and the worker records a notification_muted
+-- event instead of alerting.
+ALTER TABLE checks
+    ADD COLUMN muted_until DATETIME NULL DEFAULT NULL AFTER slow_pending_since;
+
+-- Nonces of the action links already used, for actions that may only be applied
+-- once (a mute extends the deadline each time). Rows can go once expires_at, the
+-- link's own expiry, has passed: the link is refused as expired from then on.
+CREATE TABLE used_action_tokens (
+    nonce      VARCHAR(32)     NOT NULL PRIMARY KEY,
+    check_id   BIGINT UNSIGNED NOT NULL,
+    used_at    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME        NOT NULL,
+    INDEX idx_used_action_tokens_expires_at (expires_at)
+);
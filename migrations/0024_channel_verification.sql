@@ -0,0 +1,14 @@
+-- Notification channels must be verified before they get alerts: email channels
+-- by the link in a confirmation message, URL channels (webhook, alertmanager) by
+-- a test delivery answered with a 2xx. Delivery skips channels with
+-- is_verified = FALSE. verification_token is the pending email link's secret,
+-- verification_sent_at the last attempt, which rate limits retries.
+--
+-- Channels that exist now have been in use unverified; they are grandfathered
+-- in, and only new channels start unverified.
+UPDATE notification_channels SET is_verified = TRUE, verification_token = NULL;
+
+ALTER TABLE notification_channels
+    MODIFY COLUMN is_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    MODIFY COLUMN verification_token VARCHAR(64) NULL DEFAULT NULL,
+    ADD COLUMN verification_sent_at DATETIME NULL DEFAULT NULL AFTER verification_token;
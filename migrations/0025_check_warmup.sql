@@ -0,0 +1,7 @@
+-- Warmup for new checks: a 'new' check only goes 'up' after warmup_pings
+-- successful pings in a row (1, the default, is the first ping as before).
+-- warmup_successes counts them meanwhile; a fail ping during the warmup starts
+-- the count over and leaves the check 'new', which the worker doesn't alert on.
+ALTER TABLE checks
+    ADD COLUMN warmup_pings SMALLINT UNSIGNED NOT NULL DEFAULT 1 AFTER max_duration,
+    ADD COLUMN warmup_successes SMALLINT UNSIGNED NOT NULL DEFAULT 0 AFTER warmup_pings;
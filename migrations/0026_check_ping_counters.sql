@@ -0,0 +1,9 @@
+-- Denormalized ping counters, so check lists don't aggregate pings per row.
+-- RecordPing increments them with each ping; the ping counter reconciler
+-- (worker.CounterReconciler) recomputes them from the pings table, correcting
+-- the drift left by pruning, trimming and pings_this_week's rolling window.
+-- Existing checks start at zero until its first pass.
+ALTER TABLE checks
+    ADD COLUMN total_pings BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER muted_until,
+    ADD COLUMN pings_this_week INT UNSIGNED NOT NULL DEFAULT 0 AFTER total_pings,
+    ADD COLUMN last_failure_at DATETIME NULL AFTER pings_this_week;
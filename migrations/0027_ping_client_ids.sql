@@ -0,0 +1,7 @@
+-- Client ping IDs: an agent that retries a ping sends the same X-Ping-ID (or
+-- ?pid=) each time, and the retries are answered as duplicates instead of
+-- being recorded again. IDs are only remembered as long as their ping is kept,
+-- so retention bounds the dedupe window. Several NULLs don't conflict.
+ALTER TABLE pings
+    ADD COLUMN client_ping_id VARCHAR(64) NULL DEFAULT NULL AFTER run_id,
+    ADD UNIQUE INDEX uq_pings_check_client_ping_id (check_id, client_ping_id);
@@ -0,0 +1,20 @@
+-- Global notification kill-switch: while a blackout has no ended_at, the
+-- worker sends no notifications at all, for any check. Statuses still change
+-- and are recorded as events. The events from the blackout are the ones with
+-- IDs after first_event_id and up to last_event_id, which is what the summary
+-- sent at the end (when send_summary is set) reports.
+CREATE TABLE notification_blackouts (
+    id              BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    reason          VARCHAR(255)    NULL,
+    started_by      BIGINT UNSIGNED NOT NULL, -- Admin user
+    started_at      DATETIME        NOT NULL,
+    first_event_id  BIGINT UNSIGNED NOT NULL, -- Last check_events ID before it started
+    ended_by        BIGINT UNSIGNED NULL,
+    ended_at        DATETIME        NULL,
+    last_event_id   BIGINT UNSIGNED NULL,
+    send_summary    BOOLEAN         NOT NULL DEFAULT FALSE,
+    summary_sent_at DATETIME        NULL,
+    -- 1 while active, NULL otherwise: the unique key allows one active blackout
+    active          TINYINT GENERATED ALWAYS AS (IF(ended_at IS NULL, 1, NULL)) STORED,
+    UNIQUE INDEX uq_notification_blackouts_active (active)
+);
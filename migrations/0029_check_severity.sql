@@ -0,0 +1,10 @@
+-- Per-check severity, sent with every notification and webhook event of the
+-- check (the Alertmanager severity label among them). Ordered as the ENUM is,
+-- info < warning < critical, which is how channel routing compares them.
+-- A check-channel association only delivers checks at or above its
+-- min_severity; the default 'info' keeps delivering everything, as before.
+ALTER TABLE checks
+    ADD COLUMN severity ENUM('info', 'warning', 'critical') NOT NULL DEFAULT 'warning' AFTER notify_late;
+
+ALTER TABLE check_notification_channel
+    ADD COLUMN min_severity ENUM('info', 'warning', 'critical') NOT NULL DEFAULT 'info';
@@ -0,0 +1,7 @@
+-- Free-form key-value metadata on checks (team=payments, runbook=...), for
+-- the owner's own tooling: a flat JSON object of strings, NULL when unset. The
+-- API validates its size and keys, see models.CheckMetadata. Filtering by a
+-- key reads it with JSON_EXTRACT; accounts are small enough that this needs no
+-- index.
+ALTER TABLE checks
+    ADD COLUMN metadata JSON NULL AFTER icon;
@@ -0,0 +1,20 @@
+-- The event feed's cursor. check_events IDs are handed out at insert but
+-- become visible at commit, so they don't give commit order: a consumer that
+-- has seen ID 12 can still miss 11 when it commits later. feed_seq is assigned
+-- after commit instead, by the worker (worker.EventSequencer), one batch at a
+-- time under the lock on event_feed_sequence, so a sequence number becomes
+-- visible only after every lower one has. NULL until the event is sequenced.
+ALTER TABLE check_events
+    ADD COLUMN feed_seq BIGINT UNSIGNED NULL AFTER related_check_id,
+    ADD UNIQUE INDEX uq_check_events_feed_seq (feed_seq);
+
+-- Existing events keep their place: earlier feed cursors were event IDs
+UPDATE check_events SET feed_seq = id;
+
+-- A single row holding the last feed_seq handed out
+CREATE TABLE event_feed_sequence (
+    id       TINYINT UNSIGNED PRIMARY KEY,
+    last_seq BIGINT UNSIGNED  NOT NULL
+);
+INSERT INTO event_feed_sequence (id, last_seq)
+SELECT 1, COALESCE(MAX(id), 0) FROM check_events;
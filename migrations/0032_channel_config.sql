@@ -0,0 +1,13 @@
+-- Notification channels keep their type-specific settings in config, a flat
+-- JSON object of strings: {"address": ...} for email, {"url": ...} for webhook
+-- and alertmanager, {"webhook_url": ...} for slack (see models.ChannelConfig).
+-- value stays as the channel's destination, copied from config on every save,
+-- because delivery and verification look it up there.
+ALTER TABLE notification_channels
+    ADD COLUMN config JSON NULL AFTER value;
+
+UPDATE notification_channels
+SET config = CASE type
+    WHEN 'email' THEN JSON_OBJECT('address', value)
+    ELSE JSON_OBJECT('url', value)
+END;
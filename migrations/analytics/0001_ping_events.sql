@@ -0,0 +1,13 @@
+-- Analytics database only (ANALYTICS_DB_DSN), not the primary. One row per
+-- recorded ping, appended best effort by analytics.MySQLSink for long-term
+-- aggregation. Rows carry the check UUID rather than an ID so they stay
+-- meaningful without joins against the primary.
+CREATE TABLE IF NOT EXISTS ping_events (
+    id            BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    check_uuid    CHAR(36)     NOT NULL,
+    kind          VARCHAR(16)  NOT NULL,
+    received_at   DATETIME(3)  NOT NULL,
+    payload_bytes INT UNSIGNED NOT NULL DEFAULT 0,
+    INDEX idx_ping_events_received_at (received_at),
+    INDEX idx_ping_events_check (check_uuid, received_at)
+);
@@ -0,0 +1,688 @@
+// CheckService exposes check management and ping recording over gRPC. It mirrors
+// the HTTP API under /api/v1 and is authenticated with the same API keys, sent
+// as "authorization: Bearer <key>" metadata.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: checks/v1/checks.proto
+
+package checksv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Check struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Uuid  string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name  string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Unset when the check has no description.
+	Description      *wrapperspb.StringValue `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	ExpectedInterval uint32                  `protobuf:"varint,5,opt,name=expected_interval,json=expectedInterval,proto3" json:"expected_interval,omitempty"` // seconds
+	GracePeriod      uint32                  `protobuf:"varint,6,opt,name=grace_period,json=gracePeriod,proto3" json:"grace_period,omitempty"`                // seconds
+	// Unset until the first ping.
+	LastPingAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_ping_at,json=lastPingAt,proto3" json:"last_ping_at,omitempty"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"` // new, up, late, down or paused
+	IsEnabled     bool                   `protobuf:"varint,9,opt,name=is_enabled,json=isEnabled,proto3" json:"is_enabled,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	NotifyLate    bool                   `protobuf:"varint,12,opt,name=notify_late,json=notifyLate,proto3" json:"notify_late,omitempty"` // also warn when the check goes late
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Check) Reset() {
+	*x = Check{}
+	mi := &file_checks_v1_checks_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Check) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Check) ProtoMessage() {}
+
+func (x *Check) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Check.ProtoReflect.Descriptor instead.
+func (*Check) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Check) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Check) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Check) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Check) GetDescription() *wrapperspb.StringValue {
+	if x != nil {
+		return x.Description
+	}
+	return nil
+}
+
+func (x *Check) GetExpectedInterval() uint32 {
+	if x != nil {
+		return x.ExpectedInterval
+	}
+	return 0
+}
+
+func (x *Check) GetGracePeriod() uint32 {
+	if x != nil {
+		return x.GracePeriod
+	}
+	return 0
+}
+
+func (x *Check) GetLastPingAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastPingAt
+	}
+	return nil
+}
+
+func (x *Check) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Check) GetIsEnabled() bool {
+	if x != nil {
+		return x.IsEnabled
+	}
+	return false
+}
+
+func (x *Check) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Check) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Check) GetNotifyLate() bool {
+	if x != nil {
+		return x.NotifyLate
+	}
+	return false
+}
+
+type CreateCheckRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description      *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	ExpectedInterval uint32                 `protobuf:"varint,3,opt,name=expected_interval,json=expectedInterval,proto3" json:"expected_interval,omitempty"` // seconds, required
+	GracePeriod      *uint32                `protobuf:"varint,4,opt,name=grace_period,json=gracePeriod,proto3,oneof" json:"grace_period,omitempty"`
+	IsEnabled        *bool                  `protobuf:"varint,5,opt,name=is_enabled,json=isEnabled,proto3,oneof" json:"is_enabled,omitempty"` // defaults to true
+	Status           *string                `protobuf:"bytes,6,opt,name=status,proto3,oneof" json:"status,omitempty"`                         // new (default) or paused
+	NotifyLate       bool                   `protobuf:"varint,7,opt,name=notify_late,json=notifyLate,proto3" json:"notify_late,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CreateCheckRequest) Reset() {
+	*x = CreateCheckRequest{}
+	mi := &file_checks_v1_checks_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCheckRequest) ProtoMessage() {}
+
+func (x *CreateCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCheckRequest.ProtoReflect.Descriptor instead.
+func (*CreateCheckRequest) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateCheckRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCheckRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *CreateCheckRequest) GetExpectedInterval() uint32 {
+	if x != nil {
+		return x.ExpectedInterval
+	}
+	return 0
+}
+
+func (x *CreateCheckRequest) GetGracePeriod() uint32 {
+	if x != nil && x.GracePeriod != nil {
+		return *x.GracePeriod
+	}
+	return 0
+}
+
+func (x *CreateCheckRequest) GetIsEnabled() bool {
+	if x != nil && x.IsEnabled != nil {
+		return *x.IsEnabled
+	}
+	return false
+}
+
+func (x *CreateCheckRequest) GetStatus() string {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return ""
+}
+
+func (x *CreateCheckRequest) GetNotifyLate() bool {
+	if x != nil {
+		return x.NotifyLate
+	}
+	return false
+}
+
+type GetCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCheckRequest) Reset() {
+	*x = GetCheckRequest{}
+	mi := &file_checks_v1_checks_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCheckRequest) ProtoMessage() {}
+
+func (x *GetCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCheckRequest.ProtoReflect.Descriptor instead.
+func (*GetCheckRequest) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetCheckRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListChecksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChecksRequest) Reset() {
+	*x = ListChecksRequest{}
+	mi := &file_checks_v1_checks_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChecksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChecksRequest) ProtoMessage() {}
+
+func (x *ListChecksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChecksRequest.ProtoReflect.Descriptor instead.
+func (*ListChecksRequest) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{3}
+}
+
+type ListChecksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Checks        []*Check               `protobuf:"bytes,1,rep,name=checks,proto3" json:"checks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChecksResponse) Reset() {
+	*x = ListChecksResponse{}
+	mi := &file_checks_v1_checks_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChecksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChecksResponse) ProtoMessage() {}
+
+func (x *ListChecksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChecksResponse.ProtoReflect.Descriptor instead.
+func (*ListChecksResponse) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListChecksResponse) GetChecks() []*Check {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+type DeleteCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCheckRequest) Reset() {
+	*x = DeleteCheckRequest{}
+	mi := &file_checks_v1_checks_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCheckRequest) ProtoMessage() {}
+
+func (x *DeleteCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCheckRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCheckRequest) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteCheckRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCheckResponse) Reset() {
+	*x = DeleteCheckResponse{}
+	mi := &file_checks_v1_checks_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCheckResponse) ProtoMessage() {}
+
+func (x *DeleteCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCheckResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCheckResponse) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{6}
+}
+
+type RecordPingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`       // success (default), start or fail
+	Payload       []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"` // optional, truncated to the server's payload limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordPingRequest) Reset() {
+	*x = RecordPingRequest{}
+	mi := &file_checks_v1_checks_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordPingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordPingRequest) ProtoMessage() {}
+
+func (x *RecordPingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordPingRequest.ProtoReflect.Descriptor instead.
+func (*RecordPingRequest) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecordPingRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *RecordPingRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RecordPingRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type RecordPingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordPingResponse) Reset() {
+	*x = RecordPingResponse{}
+	mi := &file_checks_v1_checks_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordPingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordPingResponse) ProtoMessage() {}
+
+func (x *RecordPingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_checks_v1_checks_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordPingResponse.ProtoReflect.Descriptor instead.
+func (*RecordPingResponse) Descriptor() ([]byte, []int) {
+	return file_checks_v1_checks_proto_rawDescGZIP(), []int{8}
+}
+
+var File_checks_v1_checks_proto protoreflect.FileDescriptor
+
+const file_checks_v1_checks_proto_rawDesc = "" +
+	"\n" +
+	"\x16checks/v1/checks.proto\x12\x14bitterlink.checks.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/wrappers.proto\"\xdb\x03\n" +
+	"\x05Check\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04uuid\x18\x02 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12>\n" +
+	"\vdescription\x18\x04 \x01(\v2\x1c.google.protobuf.StringValueR\vdescription\x12+\n" +
+	"\x11expected_interval\x18\x05 \x01(\rR\x10expectedInterval\x12!\n" +
+	"\fgrace_period\x18\x06 \x01(\rR\vgracePeriod\x12<\n" +
+	"\flast_ping_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastPingAt\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"is_enabled\x18\t \x01(\bR\tisEnabled\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1f\n" +
+	"\vnotify_late\x18\f \x01(\bR\n" +
+	"notifyLate\"\xc1\x02\n" +
+	"\x12CreateCheckRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
+	"\vdescription\x18\x02 \x01(\tH\x00R\vdescription\x88\x01\x01\x12+\n" +
+	"\x11expected_interval\x18\x03 \x01(\rR\x10expectedInterval\x12&\n" +
+	"\fgrace_period\x18\x04 \x01(\rH\x01R\vgracePeriod\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"is_enabled\x18\x05 \x01(\bH\x02R\tisEnabled\x88\x01\x01\x12\x1b\n" +
+	"\x06status\x18\x06 \x01(\tH\x03R\x06status\x88\x01\x01\x12\x1f\n" +
+	"\vnotify_late\x18\a \x01(\bR\n" +
+	"notifyLateB\x0e\n" +
+	"\f_descriptionB\x0f\n" +
+	"\r_grace_periodB\r\n" +
+	"\v_is_enabledB\t\n" +
+	"\a_status\"!\n" +
+	"\x0fGetCheckRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x13\n" +
+	"\x11ListChecksRequest\"I\n" +
+	"\x12ListChecksResponse\x123\n" +
+	"\x06checks\x18\x01 \x03(\v2\x1b.bitterlink.checks.v1.CheckR\x06checks\"$\n" +
+	"\x12DeleteCheckRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x15\n" +
+	"\x13DeleteCheckResponse\"U\n" +
+	"\x11RecordPingRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\"\x14\n" +
+	"\x12RecordPingResponse2\xda\x03\n" +
+	"\fCheckService\x12T\n" +
+	"\vCreateCheck\x12(.bitterlink.checks.v1.CreateCheckRequest\x1a\x1b.bitterlink.checks.v1.Check\x12N\n" +
+	"\bGetCheck\x12%.bitterlink.checks.v1.GetCheckRequest\x1a\x1b.bitterlink.checks.v1.Check\x12_\n" +
+	"\n" +
+	"ListChecks\x12'.bitterlink.checks.v1.ListChecksRequest\x1a(.bitterlink.checks.v1.ListChecksResponse\x12b\n" +
+	"\vDeleteCheck\x12(.bitterlink.checks.v1.DeleteCheckRequest\x1a).bitterlink.checks.v1.DeleteCheckResponse\x12_\n" +
+	"\n" +
+	"RecordPing\x12'.bitterlink.checks.v1.RecordPingRequest\x1a(.bitterlink.checks.v1.RecordPingResponseB*Z(bitterlink/core/proto/checks/v1;checksv1b\x06proto3"
+
+var (
+	file_checks_v1_checks_proto_rawDescOnce sync.Once
+	file_checks_v1_checks_proto_rawDescData []byte
+)
+
+func file_checks_v1_checks_proto_rawDescGZIP() []byte {
+	file_checks_v1_checks_proto_rawDescOnce.Do(func() {
+		file_checks_v1_checks_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_checks_v1_checks_proto_rawDesc), len(file_checks_v1_checks_proto_rawDesc)))
+	})
+	return file_checks_v1_checks_proto_rawDescData
+}
+
+var file_checks_v1_checks_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_checks_v1_checks_proto_goTypes = []any{
+	(*Check)(nil),                  // 0: bitterlink.checks.v1.Check
+	(*CreateCheckRequest)(nil),     // 1: bitterlink.checks.v1.CreateCheckRequest
+	(*GetCheckRequest)(nil),        // 2: bitterlink.checks.v1.GetCheckRequest
+	(*ListChecksRequest)(nil),      // 3: bitterlink.checks.v1.ListChecksRequest
+	(*ListChecksResponse)(nil),     // 4: bitterlink.checks.v1.ListChecksResponse
+	(*DeleteCheckRequest)(nil),     // 5: bitterlink.checks.v1.DeleteCheckRequest
+	(*DeleteCheckResponse)(nil),    // 6: bitterlink.checks.v1.DeleteCheckResponse
+	(*RecordPingRequest)(nil),      // 7: bitterlink.checks.v1.RecordPingRequest
+	(*RecordPingResponse)(nil),     // 8: bitterlink.checks.v1.RecordPingResponse
+	(*wrapperspb.StringValue)(nil), // 9: google.protobuf.StringValue
+	(*timestamppb.Timestamp)(nil),  // 10: google.protobuf.Timestamp
+}
+var file_checks_v1_checks_proto_depIdxs = []int32{
+	9,  // 0: bitterlink.checks.v1.Check.description:type_name -> google.protobuf.StringValue
+	10, // 1: bitterlink.checks.v1.Check.last_ping_at:type_name -> google.protobuf.Timestamp
+	10, // 2: bitterlink.checks.v1.Check.created_at:type_name -> google.protobuf.Timestamp
+	10, // 3: bitterlink.checks.v1.Check.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: bitterlink.checks.v1.ListChecksResponse.checks:type_name -> bitterlink.checks.v1.Check
+	1,  // 5: bitterlink.checks.v1.CheckService.CreateCheck:input_type -> bitterlink.checks.v1.CreateCheckRequest
+	2,  // 6: bitterlink.checks.v1.CheckService.GetCheck:input_type -> bitterlink.checks.v1.GetCheckRequest
+	3,  // 7: bitterlink.checks.v1.CheckService.ListChecks:input_type -> bitterlink.checks.v1.ListChecksRequest
+	5,  // 8: bitterlink.checks.v1.CheckService.DeleteCheck:input_type -> bitterlink.checks.v1.DeleteCheckRequest
+	7,  // 9: bitterlink.checks.v1.CheckService.RecordPing:input_type -> bitterlink.checks.v1.RecordPingRequest
+	0,  // 10: bitterlink.checks.v1.CheckService.CreateCheck:output_type -> bitterlink.checks.v1.Check
+	0,  // 11: bitterlink.checks.v1.CheckService.GetCheck:output_type -> bitterlink.checks.v1.Check
+	4,  // 12: bitterlink.checks.v1.CheckService.ListChecks:output_type -> bitterlink.checks.v1.ListChecksResponse
+	6,  // 13: bitterlink.checks.v1.CheckService.DeleteCheck:output_type -> bitterlink.checks.v1.DeleteCheckResponse
+	8,  // 14: bitterlink.checks.v1.CheckService.RecordPing:output_type -> bitterlink.checks.v1.RecordPingResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_checks_v1_checks_proto_init() }
+func file_checks_v1_checks_proto_init() {
+	if File_checks_v1_checks_proto != nil {
+		return
+	}
+	file_checks_v1_checks_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_checks_v1_checks_proto_rawDesc), len(file_checks_v1_checks_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_checks_v1_checks_proto_goTypes,
+		DependencyIndexes: file_checks_v1_checks_proto_depIdxs,
+		MessageInfos:      file_checks_v1_checks_proto_msgTypes,
+	}.Build()
+	File_checks_v1_checks_proto = out.File
+	file_checks_v1_checks_proto_goTypes = nil
+	file_checks_v1_checks_proto_depIdxs = nil
+}
@@ -0,0 +1,4 @@
+package checksv1
+
+// Regenerate with protoc, protoc-gen-go and protoc-gen-go-grpc on PATH.
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative checks/v1/checks.proto
@@ -0,0 +1,1040 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"bitterlink/core/internal/actionlink"
+	"bitterlink/core/internal/agency"
+	"bitterlink/core/internal/alertmanager"
+	"bitterlink/core/internal/analytics"
+	"bitterlink/core/internal/blackout"
+	"bitterlink/core/internal/config"
+	"bitterlink/core/internal/db"
+	"bitterlink/core/internal/export"
+	"bitterlink/core/internal/forward"
+	"bitterlink/core/internal/logging"
+	"bitterlink/core/internal/metrics"
+	"bitterlink/core/internal/middleware"
+	"bitterlink/core/internal/models"
+	"bitterlink/core/internal/notify"
+	"bitterlink/core/internal/pingsig"
+	"bitterlink/core/internal/repository"
+	"bitterlink/core/internal/slack"
+	"bitterlink/core/internal/spool"
+	grpctransport "bitterlink/core/internal/transport/grpc"
+	"bitterlink/core/internal/transport/http"
+	"bitterlink/core/internal/version"
+	"bitterlink/core/internal/webhook"
+	"bitterlink/core/internal/worker"
+	checksv1 "bitterlink/core/proto/checks/v1"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+)
+
+// Process roles, selected with ROLE. Ping ingestion and the background workers
+// can be scaled separately by running some instances as "api" and others as "worker".
+//
+// Splitting is safe because the two sides only share state through MySQL:
+//   - the TimeoutChecker claims checks with FOR UPDATE SKIP LOCKED, so any number
+//     of worker instances can poll concurrently;
+//   - API key auth looks keys up in the database on every request; there is no
+//     in-process key cache yet. One added later is per-instance and must tolerate
+//     keys revoked by another instance;
+//   - API rate limit counters are per-instance unless RATE_LIMIT_BACKEND=redis.
+const (
+	roleAll    = "all"    // HTTP API and workers in one process (default)
+	roleAPI    = "api"    // HTTP API only
+	roleWorker = "worker" // Workers plus a health-only listener
+)
+
+// processRole returns the configured ROLE, defaulting to "all".
+func processRole() (string, error) {
+	role := strings.ToLower(config.GetString("ROLE", roleAll))
+	switch role {
+	case roleAll, roleAPI, roleWorker:
+		return role, nil
+	default:
+		return "", fmt.Errorf("invalid ROLE %q (want %s, %s or %s)", role, roleAPI, roleWorker, roleAll)
+	}
+}
+
+// runServe runs the HTTP server and, depending on ROLE, the background workers
+// until SIGINT/SIGTERM.
+func runServe() {
+	databasePool, err := bootstrap()
+	if err != nil {
+		log.Fatalf("FATAL: Database initialization failed: %v", err)
+	}
+	role, err := processRole()
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	runsAPI := role != roleWorker
+	runsWorkers := role != roleAPI
+
+	metricsBackend, err := newMetrics(databasePool)
+	if err != nil {
+		log.Fatalf("FATAL: Metrics initialization failed: %v", err)
+	}
+	metrics.SetDefault(metricsBackend)
+	defer metricsBackend.Close() // Flushes buffered metrics on the way out
+	// Prometheus is scraped on a listener of its own, kept off the public API;
+	// every role serves it, workers' metrics included
+	var metricsServer *http.Server
+	if prometheus, ok := metricsBackend.(*metrics.Prometheus); ok {
+		metricsServer = servePrometheus(prometheus, config.GetString("PROMETHEUS_LISTEN_ADDR", ":9464"))
+	}
+
+	// Create a context that can be cancelled for graceful shutdown
+	// Link it to SIGINT/SIGTERM signals
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Collapse repeated ERROR lines, e.g. one per request while the database is away
+	go logging.StartDedup(ctx, time.Duration(config.GetInt("LOG_DEDUP_WINDOW_SECONDS", 60))*time.Second)
+
+	// Runtime, process and pool gauges; skipped entirely when metrics are off, and
+	// sampled on scrape instead for Prometheus
+	_, off := metricsBackend.(metrics.Nop)
+	if _, scraped := metricsBackend.(*metrics.Prometheus); !off && !scraped {
+		runtimeInterval := time.Duration(config.GetInt("METRICS_RUNTIME_INTERVAL_SECONDS", 10)) * time.Second
+		go metrics.CollectRuntime(ctx, runtimeInterval, databasePool)
+	}
+
+	// Database clock skew: at startup, then hourly; visible on /health and as a gauge
+	go db.MonitorSkew(ctx, databasePool, db.SkewConfig{
+		Interval:      time.Duration(config.GetInt("CLOCK_SKEW_PROBE_INTERVAL_SECONDS", 3600)) * time.Second,
+		WarnThreshold: time.Duration(config.GetInt("CLOCK_SKEW_WARN_MS", 2000)) * time.Millisecond,
+	})
+	metrics.RegisterGaugeFunc(metrics.DBClockSkew, func() float64 {
+		skew, _, _ := db.Skew()
+		return float64(skew.Milliseconds())
+	})
+
+	// Database circuit breaker: probed while open; visible on /health/ready and as a gauge
+	go db.MonitorCircuit(ctx, databasePool)
+	metrics.RegisterGaugeFunc(metrics.DBCircuitOpen, func() float64 {
+		if state, _ := db.CircuitState(); state != db.CircuitClosed {
+			return 1
+		}
+		return 0
+	})
+
+	// --- Timeout Checker Worker ---
+	// Configuration (Read from Env Vars or defaults)
+	pollIntervalSeconds, _ := strconv.Atoi(os.Getenv("CHECKER_POLL_INTERVAL_SECONDS"))
+	if pollIntervalSeconds <= 0 {
+		pollIntervalSeconds = 30
+	}
+	batchSize, _ := strconv.Atoi(os.Getenv("CHECKER_BATCH_SIZE"))
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+	checkerConfig := worker.Config{
+		PollInterval: time.Duration(pollIntervalSeconds) * time.Second,
+		BatchSize:    batchSize,
+		InstanceID:   os.Getenv("CHECKER_INSTANCE_ID"), // generated from the hostname when unset
+		ClaimTimeout: time.Duration(config.GetInt("CHECKER_CLAIM_TIMEOUT_SECONDS", 300)) * time.Second,
+		// 0 = two poll intervals, at least a minute
+		RedeliverAfter: time.Duration(config.GetInt("CHECKER_REDELIVER_AFTER_SECONDS", 0)) * time.Second,
+		Outbox:         config.GetBool("CHECKER_NOTIFICATION_OUTBOX", false),
+		// Dependencies are declared per check, so honouring them is the default
+		SuppressDependents: config.GetBool("CHECKER_SUPPRESS_DEPENDENT_ALERTS", true),
+	}
+	if config.GetBool("CHECKER_SKEW_GRACE", false) {
+		// Give checks the measured skew on top of their grace period
+		checkerConfig.SkewMargin = func() time.Duration {
+			skew, _, _ := db.Skew()
+			return skew
+		}
+	}
+
+	// workers is waited on during shutdown so in-flight cycles can finish
+	var workers sync.WaitGroup
+
+	// Action links are signed by the workers, in alerts, and applied through the API
+	actionSigner, err := actionLinkSigner()
+	if err != nil {
+		log.Fatalf("FATAL: Action link configuration invalid: %v", err)
+	}
+
+	// Channel types switched off here can't be created, and existing channels of
+	// those types get no alerts
+	channelTypes, err := enabledChannelTypes()
+	if err != nil {
+		log.Fatalf("FATAL: Channel type configuration invalid: %v", err)
+	}
+
+	// Account webhooks get events from both sides: pings from the API, late/down/up
+	// notifications from the checker. Each user still opts in by setting a URL.
+	var webhooks *webhook.Sender
+	if config.GetBool("USER_WEBHOOKS", false) {
+		webhooks = webhook.New(repository.NewMySQLUserRepository(databasePool), webhook.Config{
+			Timeout:          time.Duration(config.GetInt("USER_WEBHOOK_TIMEOUT_SECONDS", 5)) * time.Second,
+			MaxAttempts:      config.GetInt("USER_WEBHOOK_ATTEMPTS", 3),
+			QueueSize:        config.GetInt("USER_WEBHOOK_QUEUE_SIZE", 1000),
+			Workers:          config.GetInt("USER_WEBHOOK_WORKERS", 4),
+			BreakerThreshold: config.GetInt("USER_WEBHOOK_BREAKER_FAILURES", 5),
+			BreakerCooldown:  time.Duration(config.GetInt("USER_WEBHOOK_BREAKER_COOLDOWN_SECONDS", 600)) * time.Second,
+			SelfHosts:        forwardSelfHosts(publicBaseURL()),
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			webhooks.Start(ctx)
+		}()
+	}
+
+	// timeoutChecker stays nil unless this instance runs the workers
+	var timeoutChecker *worker.TimeoutChecker
+	if runsWorkers {
+		var dispatcher notify.Dispatcher = notify.LogDispatcher{}
+		if config.GetBool("ALERTMANAGER_CHANNELS", false) {
+			// Checks' 'alertmanager' channels get firing and resolved alerts
+			amConfig := alertmanager.Config{
+				Timeout:   config.GetDuration("ALERTMANAGER_TIMEOUT", 10*time.Second),
+				SelfHosts: forwardSelfHosts(publicBaseURL()),
+				Disabled:  !slices.Contains(channelTypes, models.ChannelAlertmanager),
+			}
+			if amConfig.Disabled {
+				log.Printf("WARN: ALERTMANAGER_CHANNELS is set but NOTIFY_CHANNEL_TYPES leaves out %s; its channels are skipped", models.ChannelAlertmanager)
+			}
+			dispatcher = alertmanager.New(dispatcher, repository.NewMySQLChannelRepository(databasePool), amConfig)
+		}
+		if webhooks != nil {
+			webhookDispatcher := webhook.Dispatcher{Next: dispatcher, Sender: webhooks}
+			if actionSigner != nil {
+				// Late and down events carry a link muting the check
+				ttl := config.GetDuration("ACTION_LINK_TTL", 7*24*time.Hour)
+				baseURL := publicBaseURL()
+				webhookDispatcher.MuteURL = func(checkUUID string) string {
+					link, err := actionSigner.URL(baseURL, checkUUID, actionlink.ActionMute, time.Now().Add(ttl))
+					if err != nil {
+						log.Printf("WARN: Failed to sign mute link for check %s: %v", checkUUID, err)
+						return ""
+					}
+					return link
+				}
+			}
+			dispatcher = webhookDispatcher
+		}
+		// Outermost, so the admin kill-switch holds back every notification
+		blackoutDispatcher := blackout.New(dispatcher, newCheckRepository(databasePool), blackout.Config{
+			RefreshInterval: config.GetDuration("NOTIFICATION_BLACKOUT_REFRESH_INTERVAL", 10*time.Second),
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			blackoutDispatcher.Start(ctx)
+		}()
+		dispatcher = blackoutDispatcher
+		timeoutChecker = worker.NewTimeoutChecker(databasePool, dispatcher, checkerConfig)
+		if checkerConfig.Outbox {
+			outboxConsumer := worker.NewOutboxConsumer(databasePool, dispatcher, worker.OutboxConsumerConfig{
+				PollInterval: time.Duration(config.GetInt("NOTIFICATION_OUTBOX_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+				BatchSize:    config.GetInt("NOTIFICATION_OUTBOX_BATCH_SIZE", 50),
+				RetryAfter:   time.Duration(config.GetInt("NOTIFICATION_OUTBOX_RETRY_AFTER_SECONDS", 60)) * time.Second,
+			})
+			metrics.RegisterGaugeFunc(metrics.OutboxBacklog, func() float64 {
+				return float64(outboxConsumer.Backlog())
+			})
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				outboxConsumer.Start(ctx)
+			}()
+		}
+		// Start the checker worker in a separate goroutine
+		// Pass the cancellable context
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			timeoutChecker.Start(ctx)
+		}()
+
+		// Self-monitoring: only worker roles run it, as it vouches for the checker
+		if heartbeatURL := os.Getenv("HEARTBEAT_URL"); heartbeatURL != "" {
+			heartbeat := worker.NewHeartbeat(databasePool, timeoutChecker, worker.HeartbeatConfig{
+				URL:           heartbeatURL,
+				Interval:      time.Duration(config.GetInt("HEARTBEAT_INTERVAL_SECONDS", 60)) * time.Second,
+				MaxCheckerAge: time.Duration(config.GetInt("HEARTBEAT_CHECKER_MAX_AGE_SECONDS", 0)) * time.Second, // 0 = 3 poll intervals
+				MaxBacklog:    config.GetInt("HEARTBEAT_MAX_NOTIFICATION_BACKLOG", 100),
+			})
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				heartbeat.Start(ctx)
+			}()
+		}
+
+		// Batched deletes below wait for lagging replicas, when REPLICA_DSNS lists any
+		replicas := connectReplicas()
+
+		// Permanently remove checks soft-deleted longer ago than the retention window
+		if days := config.GetInt("PURGE_DELETED_CHECKS_AFTER_DAYS", 30); days > 0 {
+			purger := worker.NewPurger(newCheckRepository(databasePool), worker.PurgerConfig{
+				Retention:     time.Duration(days) * 24 * time.Hour,
+				Interval:      time.Duration(config.GetInt("PURGE_INTERVAL_SECONDS", 3600)) * time.Second,
+				BatchSize:     config.GetInt("PURGE_BATCH_SIZE", 10),
+				PingBatchSize: config.GetInt("PURGE_PING_BATCH_SIZE", 5000),
+				Pause:         config.GetDuration("PURGE_BATCH_PAUSE", 500*time.Millisecond),
+				Throttle:      db.NewLagThrottle(replicas, lagThrottleConfig()),
+			})
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				purger.Start(ctx)
+			}()
+		}
+
+		// Per-check keep-last-N retention (checks.pings_history_limit)
+		historyTrimmer := worker.NewHistoryTrimmer(newCheckRepository(databasePool), worker.HistoryTrimmerConfig{
+			Interval:  time.Duration(config.GetInt("PINGS_HISTORY_TRIM_INTERVAL_SECONDS", 300)) * time.Second,
+			BatchSize: config.GetInt("PINGS_HISTORY_TRIM_BATCH_SIZE", 1000),
+			Pause:     config.GetDuration("PINGS_HISTORY_TRIM_PAUSE", 100*time.Millisecond),
+			Throttle:  db.NewLagThrottle(replicas, lagThrottleConfig()),
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			historyTrimmer.Start(ctx)
+		}()
+
+		// Numbers committed events for the event feed, which shows nothing
+		// without it
+		eventSequencer := worker.NewEventSequencer(newCheckRepository(databasePool), worker.EventSequencerConfig{
+			PollInterval: config.GetDuration("EVENT_FEED_SEQUENCE_INTERVAL", time.Second),
+			BatchSize:    config.GetInt("EVENT_FEED_SEQUENCE_BATCH_SIZE", 500),
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			eventSequencer.Start(ctx)
+		}()
+
+		// Denormalized ping counters (checks.total_pings etc.), corrected for
+		// pruning and the rolling week
+		counterReconciler := worker.NewCounterReconciler(newCheckRepository(databasePool), worker.CounterReconcilerConfig{
+			Interval:  time.Duration(config.GetInt("PING_COUNTERS_RECONCILE_INTERVAL_SECONDS", 3600)) * time.Second,
+			BatchSize: config.GetInt("PING_COUNTERS_RECONCILE_BATCH_SIZE", 100),
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			counterReconciler.Start(ctx)
+		}()
+	} else {
+		log.Println("INFO: ROLE=api, background workers not started.")
+	}
+
+	router := gin.Default()
+	// grpcServer stays nil unless GRPC_PORT is set (API roles only)
+	var grpcServer *grpc.Server
+
+	if runsAPI {
+		// Create repository instances
+		checkRepo := newCheckRepository(databasePool)
+		if sink := newAnalyticsSink(ctx, &workers); sink != nil {
+			checkRepo = analytics.WrapCheckRepository(checkRepo, sink)
+		}
+		// userRepo := repository.NewMySQLUserRepository(dbPool) // etc.
+
+		// Create handler instances, injecting dependencies
+		pingConfig := httptransport.PingConfig{
+			MaxPayloadBytes: config.GetInt("PING_MAX_PAYLOAD_BYTES", 10000),
+			MaxBatchSize:    config.GetInt("PING_BATCH_MAX_SIZE", 100),
+		}
+		signer, err := pingSigner()
+		if err != nil {
+			log.Fatalf("FATAL: Ping signing configuration invalid: %v", err)
+		}
+		pingConfig.Signer = signer
+		pingConfig.RequireSignatures = signer != nil && config.GetBool("PING_SIGNATURES_REQUIRED", false)
+		if path := config.GetString("PING_SPOOL_PATH", ""); path != "" {
+			pingSpool, err := spool.New(checkRepo, spool.Config{
+				Path:           path,
+				MaxBytes:       int64(config.GetInt("PING_SPOOL_MAX_BYTES", 64<<20)),
+				ReplayInterval: config.GetDuration("PING_SPOOL_REPLAY_INTERVAL", 5*time.Second),
+			})
+			if err != nil {
+				log.Fatalf("FATAL: Ping spool initialization failed: %v", err)
+			}
+			pingConfig.Spool = pingSpool
+			metrics.RegisterGaugeFunc(metrics.PingSpoolDepth, func() float64 {
+				return float64(pingSpool.Depth())
+			})
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				pingSpool.Start(ctx)
+			}()
+		}
+		publicBaseURL := publicBaseURL()
+		selfHosts := forwardSelfHosts(publicBaseURL)
+		var forwarder *forward.Forwarder
+		if config.GetBool("PING_FORWARDING", false) {
+			forwarder = forward.New(checkRepo, forward.Config{
+				Timeout:     time.Duration(config.GetInt("PING_FORWARD_TIMEOUT_SECONDS", 5)) * time.Second,
+				MaxAttempts: config.GetInt("PING_FORWARD_ATTEMPTS", 3),
+				QueueSize:   config.GetInt("PING_FORWARD_QUEUE_SIZE", 1000),
+				Workers:     config.GetInt("PING_FORWARD_WORKERS", 4),
+				SelfHosts:   selfHosts,
+			})
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				forwarder.Start(ctx)
+			}()
+		}
+		pingHandler := httptransport.NewPingHandler(checkRepo, forwarder, webhooks, pingConfig)
+		checkConfig := httptransport.CheckConfig{
+			Bounds:              timingBounds(),
+			StreamListThreshold: config.GetInt("CHECKS_STREAM_THRESHOLD", 1000),
+			ForwardSelfHosts:    selfHosts,
+			Descriptions: models.DescriptionRules{
+				MaxLength: config.GetInt("DESCRIPTION_MAX_LENGTH", 2000),
+				StripHTML: config.GetBool("DESCRIPTION_STRIP_HTML", false),
+			},
+			Signer:           signer,
+			SignedURLTTL:     time.Duration(config.GetInt("PING_SIGNED_URL_TTL_SECONDS", 90*24*60*60)) * time.Second, // 90 days
+			PublicBaseURL:    publicBaseURL,
+			MaxBulkChecks:    config.GetInt("BULK_ACTION_MAX_CHECKS", 1000),
+			MetricsMaxChecks: config.GetInt("CHECK_METRICS_MAX_CHECKS", 1000),
+			XMLResponses:     config.GetBool("API_XML_RESPONSES", true),
+		}
+		if timeoutChecker != nil {
+			// Shortened deadlines take effect at once, not on the next tick
+			checkConfig.Evaluator = timeoutChecker
+		}
+		checkHandler := httptransport.NewCheckHandler(checkRepo, checkConfig)
+		go auditTimingBounds(ctx, checkRepo, checkConfig.Bounds)
+
+		limiter, err := newRateLimiter()
+		if err != nil {
+			log.Fatalf("FATAL: Rate limiter initialization failed: %v", err)
+		}
+		trustedHeader, err := trustedHeaderConfig()
+		if err != nil {
+			log.Fatalf("FATAL: Trusted header auth configuration invalid: %v", err)
+		}
+		hcHandler := httptransport.NewHCHandler(checkRepo, httptransport.HCConfig{
+			BaseURL:      publicBaseURL,
+			Bounds:       checkConfig.Bounds,
+			Descriptions: checkConfig.Descriptions,
+		})
+		adminHandler := httptransport.NewAdminHandler(checkRepo, httptransport.AdminConfig{
+			PingCountTTL:     time.Duration(config.GetInt("ADMIN_STATS_CACHE_SECONDS", 300)) * time.Second,
+			OrphanBatchSize:  config.GetInt("ADMIN_ORPHAN_BATCH_SIZE", 1000),
+			OrphanMaxDelete:  config.GetInt("ADMIN_ORPHAN_MAX_DELETE", 100000),
+			OrphanBatchPause: config.GetDuration("ADMIN_ORPHAN_BATCH_PAUSE", 100*time.Millisecond),
+		})
+		exportJobs := export.NewJobManager(export.JobsConfig{
+			Dir:       os.Getenv("ACCOUNT_EXPORT_DIR"), // <tmp>/bitterlink-exports when unset
+			TTL:       time.Duration(config.GetInt("ACCOUNT_EXPORT_TTL_HOURS", 24)) * time.Hour,
+			Workers:   config.GetInt("ACCOUNT_EXPORT_WORKERS", 1),
+			QueueSize: config.GetInt("ACCOUNT_EXPORT_QUEUE_SIZE", 10),
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			exportJobs.Start(ctx)
+		}()
+		accountHandler := httptransport.NewAccountHandler(
+			repository.NewMySQLUserRepository(databasePool),
+			checkRepo,
+			repository.NewMySQLAPIKeyRepository(databasePool),
+			exportJobs,
+			httptransport.AccountConfig{
+				PingHistory:      time.Duration(config.GetInt("ACCOUNT_EXPORT_PING_DAYS", 90)) * 24 * time.Hour,
+				Webhooks:         webhooks != nil,
+				WebhookSelfHosts: selfHosts,
+			},
+		)
+		// Channels are verified before first use: URL channels by a test delivery
+		// (email channels can't be, this instance sends no email)
+		channelRepo := repository.NewMySQLChannelRepository(databasePool)
+		channelHandler := httptransport.NewChannelHandler(channelRepo, httptransport.ChannelConfig{
+			Testers: map[string]httptransport.ChannelTester{
+				models.ChannelAlertmanager: alertmanager.New(nil, channelRepo, alertmanager.Config{
+					Timeout:   config.GetDuration("ALERTMANAGER_TIMEOUT", 10*time.Second),
+					SelfHosts: selfHosts,
+				}),
+				models.ChannelWebhook: webhook.New(nil, webhook.Config{
+					Timeout:   time.Duration(config.GetInt("USER_WEBHOOK_TIMEOUT_SECONDS", 5)) * time.Second,
+					SelfHosts: selfHosts,
+				}),
+				models.ChannelSlack: slack.New(slack.Config{
+					Timeout:   config.GetDuration("SLACK_TIMEOUT", 10*time.Second),
+					SelfHosts: selfHosts,
+				}),
+			},
+			PublicBaseURL:  publicBaseURL,
+			VerifyInterval: config.GetDuration("CHANNEL_VERIFY_INTERVAL", time.Minute),
+			ConfirmTTL:     config.GetDuration("CHANNEL_CONFIRM_TTL", 24*time.Hour),
+			SelfHosts:      selfHosts,
+			EnabledTypes:   channelTypes,
+		})
+		var actionHandler *httptransport.ActionHandler
+		if actionSigner != nil {
+			actionHandler = httptransport.NewActionHandler(checkRepo, httptransport.ActionConfig{Signer: actionSigner})
+		}
+		metrics.RegisterGaugeFunc(metrics.HTTPStreamsOpen, func() float64 {
+			return float64(httptransport.OpenStreams())
+		})
+		httptransport.RegisterRoutes(router, pingHandler, checkHandler, databasePool, checkRepo, limiter, trustedHeader, hcHandler, adminHandler, accountHandler, securityHeadersConfig(), actionHandler, channelHandler, pingIPLimiter())
+		log.Println("INFO: HTTP routes registered.")
+
+		// --- Optional gRPC API ---
+		if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+			grpcConfig := grpctransport.Config{
+				Bounds:          checkConfig.Bounds,
+				MaxPayloadBytes: pingConfig.MaxPayloadBytes,
+				Descriptions:    checkConfig.Descriptions,
+			}
+			grpcServer = grpc.NewServer(grpc.UnaryInterceptor(grpctransport.APIKeyAuthInterceptor(databasePool)))
+			checksv1.RegisterCheckServiceServer(grpcServer, grpctransport.NewServer(checkRepo, forwarder, webhooks, grpcConfig))
+			go serveGRPC(grpcServer, grpcPort)
+		}
+	} else {
+		// Workers still listen so orchestrators can probe them, but expose no API
+		httptransport.RegisterHealthRoutes(router)
+		log.Println("INFO: ROLE=worker, only health routes registered.")
+	}
+
+	srvPort := serverPort()
+
+	if !agency.IsNumeric(srvPort) {
+		log.Printf("ERROR: Server port: %s is not numeric.\n", srvPort)
+	}
+
+	srv := &http.Server{
+		Addr:    ":" + srvPort,
+		Handler: router,
+		// Add Read/Write timeouts for production readiness
+		// ReadTimeout: 5 * time.Second,
+		// WriteTimeout: 10 * time.Second,
+		// IdleTimeout: 120 * time.Second,
+	}
+
+	drainTimeout := shutdownTimeout()
+	logStartupSummary(srv.Addr, role, checkerConfig, drainTimeout)
+
+	// SERVER_SOCKET switches the listener from TCP to a unix socket (e.g. behind a local proxy)
+	socketPath := os.Getenv("SERVER_SOCKET")
+
+	go func() {
+		var err error
+		if socketPath != "" {
+			log.Printf("INFO: Starting HTTP server on unix socket %s", socketPath)
+			err = serveUnix(srv, socketPath)
+		} else {
+			log.Printf("INFO: Starting HTTP server on port :%s", srvPort)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("FATAL: listen: %s\n", err)
+		}
+	}()
+
+	// --- Graceful Shutdown ---
+	// Wait for interrupt signal (captured by signal.NotifyContext)
+	<-ctx.Done()
+
+	stop()
+	log.Println("INFO: Shutting down server and workers...")
+
+	// Create a deadline context for the shutdown process, shared by the HTTP and
+	// gRPC servers and the worker drain.
+	log.Printf("INFO: Waiting up to %s for requests and workers to finish", drainTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	// Attempt to gracefully shut down the HTTP server
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("WARN: Server shutdown failed: %v", err)
+	} else {
+		log.Println("INFO: Server gracefully stopped.")
+	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+	if metricsServer != nil {
+		metricsServer.Shutdown(shutdownCtx)
+	}
+
+	// The context passed to the workers is cancelled; wait for them to return,
+	// but don't outlive the shutdown deadline. A no-op when none were started.
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		log.Println("WARN: Workers did not stop before the shutdown deadline.")
+	}
+	log.Println("INFO: Application exited.")
+}
+
+// defaultShutdownTimeout is how long shutdown waits by default, see shutdownTimeout.
+const defaultShutdownTimeout = 10 * time.Second
+
+// shutdownTimeout is SHUTDOWN_TIMEOUT ("30s", or plain seconds): how long a
+// shutdown waits for in-flight requests (SSE streams and exports included) and
+// the background workers before exiting anyway.
+func shutdownTimeout() time.Duration {
+	timeout := config.GetDuration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+	if timeout <= 0 {
+		log.Printf("WARN: SHUTDOWN_TIMEOUT must be positive, using default %s", defaultShutdownTimeout)
+		return defaultShutdownTimeout
+	}
+	return timeout
+}
+
+// logStartupSummary emits a single line describing the effective runtime config,
+// so it's obvious which settings an instance is running with. No secrets.
+func logStartupSummary(addr, role string, checkerConfig worker.Config, shutdownTimeout time.Duration) {
+	var features []string
+	if config.GetBool("PING_PAYLOAD_COMPRESSION", false) {
+		features = append(features, "payload_compression")
+	}
+	if config.GetBool("PING_FAST_PATH", false) {
+		features = append(features, "ping_fast_path")
+	}
+	if role != roleWorker && os.Getenv("GRPC_PORT") != "" {
+		features = append(features, "grpc")
+	}
+	if backend := strings.ToLower(config.GetString("METRICS_BACKEND", metricsNone)); backend != metricsNone {
+		features = append(features, "metrics_"+backend)
+	}
+	if role != roleWorker && config.GetFloat("PING_RATE_LIMIT_PER_SECOND", 10) > 0 {
+		features = append(features, "ping_ip_limit")
+	}
+	if role != roleWorker && config.GetBool("TRUSTED_HEADER_AUTH", false) {
+		features = append(features, "trusted_header_auth")
+	}
+	if role != roleAPI && config.GetBool("CHECKER_SKEW_GRACE", false) {
+		features = append(features, "skew_grace")
+	}
+	if days := config.GetInt("PURGE_DELETED_CHECKS_AFTER_DAYS", 30); role != roleAPI && days > 0 {
+		features = append(features, "purge_deleted_"+strconv.Itoa(days)+"d")
+	}
+	if role != roleAPI && os.Getenv("REPLICA_DSNS") != "" {
+		features = append(features, "replica_lag_throttle")
+	}
+	if role != roleAPI && os.Getenv("HEARTBEAT_URL") != "" {
+		features = append(features, "heartbeat")
+	}
+	if role != roleWorker && os.Getenv("ANALYTICS_DB_DSN") != "" {
+		features = append(features, "analytics_db")
+	}
+	if role != roleWorker && os.Getenv("PING_SIGNING_SECRET") != "" {
+		if config.GetBool("PING_SIGNATURES_REQUIRED", false) {
+			features = append(features, "signed_pings_required")
+		} else {
+			features = append(features, "signed_pings")
+		}
+	}
+	if os.Getenv("ACTION_LINK_SECRET") != "" {
+		features = append(features, "action_links")
+	}
+	if role != roleWorker && os.Getenv("PING_SPOOL_PATH") != "" {
+		features = append(features, "ping_spool")
+	}
+	if role != roleWorker && config.GetBool("PING_FORWARDING", false) {
+		features = append(features, "ping_forwarding")
+	}
+	if role != roleAPI && config.GetBool("ALERTMANAGER_CHANNELS", false) {
+		features = append(features, "alertmanager_channels")
+	}
+	if config.GetBool("USER_WEBHOOKS", false) {
+		features = append(features, "user_webhooks")
+	}
+	if role != roleAPI && config.GetBool("CHECKER_NOTIFICATION_OUTBOX", false) {
+		features = append(features, "notification_outbox")
+	}
+	if role != roleWorker && config.GetInt("RATE_LIMIT_REQUESTS", 0) > 0 {
+		features = append(features, "rate_limit_"+strings.ToLower(config.GetString("RATE_LIMIT_BACKEND", rateLimitMemory)))
+	}
+	if len(features) == 0 {
+		features = append(features, "none")
+	}
+
+	fields := []string{
+		"role=" + role,
+		"addr=" + addr,
+		"db=" + db.Target(),
+		"poll_interval=" + checkerConfig.PollInterval.String(),
+		"batch_size=" + strconv.Itoa(checkerConfig.BatchSize),
+		"shutdown_timeout=" + shutdownTimeout.String(),
+		"gin_mode=" + gin.Mode(),
+		"features=" + strings.Join(features, ","),
+		version.Get().String(),
+	}
+	log.Printf("INFO: Startup summary: %s", strings.Join(fields, " "))
+}
+
+// Rate limit backends, selected with RATE_LIMIT_BACKEND.
+const (
+	rateLimitMemory = "memory" // Per-instance counters (default, single instance only)
+	rateLimitRedis  = "redis"  // Shared counters in REDIS_URL, for multiple instances
+)
+
+// newRateLimiter builds the API rate limiter from configuration. It returns nil
+// (no limiting) unless RATE_LIMIT_REQUESTS is set.
+func newRateLimiter() (middleware.RateLimiter, error) {
+	limit := config.GetInt("RATE_LIMIT_REQUESTS", 0)
+	if limit <= 0 {
+		return nil, nil
+	}
+	window := time.Duration(config.GetInt("RATE_LIMIT_WINDOW_SECONDS", 60)) * time.Second
+	if window <= 0 {
+		return nil, fmt.Errorf("RATE_LIMIT_WINDOW_SECONDS must be positive")
+	}
+
+	switch backend := strings.ToLower(config.GetString("RATE_LIMIT_BACKEND", rateLimitMemory)); backend {
+	case rateLimitMemory:
+		return middleware.NewMemoryRateLimiter(limit, window), nil
+	case rateLimitRedis:
+		options, err := redis.ParseURL(config.GetString("REDIS_URL", "redis://localhost:6379/0"))
+		if err != nil {
+			return nil, fmt.Errorf("parsing REDIS_URL: %w", err)
+		}
+		client := redis.NewClient(options)
+		// Not fatal if Redis is down right now: the middleware lets requests through
+		// while the limiter errors, and the client reconnects on its own.
+		pingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(pingCtx).Err(); err != nil {
+			log.Printf("WARN: Redis at %s not reachable yet, rate limiting is inactive until it is: %v", options.Addr, err)
+		}
+		return middleware.NewRedisRateLimiter(client, limit, window), nil
+	default:
+		return nil, fmt.Errorf("invalid RATE_LIMIT_BACKEND %q (want %s or %s)", backend, rateLimitMemory, rateLimitRedis)
+	}
+}
+
+// pingIPLimiter returns the per-IP limiter of the ping routes, or nil when
+// PING_RATE_LIMIT_PER_SECOND is 0. The defaults are generous, as one NAT
+// gateway may front many jobs.
+func pingIPLimiter() *middleware.IPLimiter {
+	rate := config.GetFloat("PING_RATE_LIMIT_PER_SECOND", 10)
+	if rate <= 0 {
+		return nil
+	}
+	return middleware.NewIPLimiter(middleware.IPLimiterConfig{
+		Rate:   rate,
+		Burst:  config.GetInt("PING_RATE_LIMIT_BURST", 300),
+		MaxIPs: config.GetInt("PING_RATE_LIMIT_MAX_IPS", 100000),
+	})
+}
+
+// trustedHeaderConfig returns the gateway header auth settings, or nil unless
+// TRUSTED_HEADER_AUTH is on. Enabling it without TRUSTED_PROXIES is an error
+// rather than "trust nobody", so a typo can't silently leave it half-configured.
+func trustedHeaderConfig() (*middleware.TrustedHeaderConfig, error) {
+	if !config.GetBool("TRUSTED_HEADER_AUTH", false) {
+		return nil, nil
+	}
+	proxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, err
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("TRUSTED_HEADER_AUTH is set but TRUSTED_PROXIES is empty")
+	}
+	return &middleware.TrustedHeaderConfig{
+		Header:         config.GetString("TRUSTED_HEADER_NAME", "X-User-ID"),
+		TrustedProxies: proxies,
+	}, nil
+}
+
+// enabledChannelTypes returns the notification channel types enabled on this
+// instance: NOTIFY_CHANNEL_TYPES, a comma-separated list, or every type when
+// unset. An unknown type is an error.
+func enabledChannelTypes() ([]string, error) {
+	all := append(slices.Clone(models.ChannelTypes), models.ChannelAlertmanager)
+	list := config.GetString("NOTIFY_CHANNEL_TYPES", "")
+	if strings.TrimSpace(list) == "" {
+		return all, nil
+	}
+	var types []string
+	for _, t := range strings.Split(list, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(all, t) {
+			return nil, fmt.Errorf("NOTIFY_CHANNEL_TYPES: unknown channel type %q (known: %s)", t, strings.Join(all, ", "))
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("NOTIFY_CHANNEL_TYPES lists no channel types")
+	}
+	return types, nil
+}
+
+// publicBaseURL is the URL this instance is reached at (PUBLIC_BASE_URL).
+func publicBaseURL() string {
+	return config.GetString("PUBLIC_BASE_URL", "http://localhost:"+serverPort())
+}
+
+// timingBounds returns the instance's limits on expected_interval and
+// grace_period (see models.TimingBounds). A maximum of 0 means no maximum.
+func timingBounds() models.TimingBounds {
+	return models.TimingBounds{
+		MinExpectedInterval: uint32(config.GetInt("MIN_EXPECTED_INTERVAL_SECONDS", 60)),
+		MaxExpectedInterval: uint32(config.GetInt("MAX_EXPECTED_INTERVAL_SECONDS", 30*24*60*60)), // 30 days
+		MinGracePeriod:      uint32(config.GetInt("MIN_GRACE_PERIOD_SECONDS", 0)),
+		MaxGracePeriod:      uint32(config.GetInt("MAX_GRACE_PERIOD_SECONDS", 30*24*60*60)), // 30 days
+	}
+}
+
+// auditTimingBounds reports existing checks outside bounds at startup. They
+// were created under older or looser limits and keep working unchanged; only
+// new checks are held to the bounds.
+func auditTimingBounds(ctx context.Context, checkRepo repository.CheckRepository, bounds models.TimingBounds) {
+	const sample = 20
+	checks, total, err := checkRepo.ListChecksOutsideBounds(ctx, bounds, sample)
+	if err != nil {
+		log.Printf("WARN: Could not audit checks against the interval bounds: %v", err)
+		return
+	}
+	if total == 0 {
+		return
+	}
+	described := make([]string, len(checks))
+	for i, check := range checks {
+		described[i] = fmt.Sprintf("%d (interval %ds, grace %ds)", check.ID, check.ExpectedInterval, check.GracePeriod)
+	}
+	log.Printf("WARN: %d existing checks are outside the configured interval/grace bounds and were left unchanged; first %d by ID: %s",
+		total, len(checks), strings.Join(described, ", "))
+}
+
+// securityHeadersConfig returns the security header settings, or nil when
+// SECURITY_HEADERS is off. HSTS is only sent over TLS (see
+// middleware.SecurityHeadersMiddleware); HSTS_MAX_AGE_SECONDS=0 disables it.
+func securityHeadersConfig() *middleware.SecurityHeadersConfig {
+	if !config.GetBool("SECURITY_HEADERS", true) {
+		return nil
+	}
+	return &middleware.SecurityHeadersConfig{
+		HSTSMaxAge:          time.Duration(config.GetInt("HSTS_MAX_AGE_SECONDS", 365*24*60*60)) * time.Second, // 1 year
+		TrustForwardedProto: config.GetBool("HSTS_TRUST_FORWARDED_PROTO", false),
+		FrameOptions:        config.GetString("X_FRAME_OPTIONS", "DENY"),
+		ReferrerPolicy:      config.GetString("REFERRER_POLICY", "no-referrer"),
+	}
+}
+
+// newAnalyticsSink connects the optional analytics database (ANALYTICS_DB_DSN)
+// and starts a sink copying ping events to it, joined to workers for shutdown.
+// Returns nil when it isn't configured, or can't be reached at startup: the
+// analytics copy is optional and never a reason not to serve pings.
+func newAnalyticsSink(ctx context.Context, workers *sync.WaitGroup) analytics.MetricsSink {
+	dsn := os.Getenv("ANALYTICS_DB_DSN")
+	if dsn == "" {
+		return nil
+	}
+	analyticsDB, err := db.ConnectAnalyticsDB(dsn)
+	if err != nil {
+		log.Printf("WARN: Analytics database unavailable, ping events will not be copied: %v", err)
+		return nil
+	}
+	sink := analytics.NewMySQLSink(analyticsDB, analytics.MySQLSinkConfig{
+		BufferSize:    config.GetInt("ANALYTICS_BUFFER_SIZE", 10000),
+		BatchSize:     config.GetInt("ANALYTICS_BATCH_SIZE", 500),
+		FlushInterval: time.Duration(config.GetInt("ANALYTICS_FLUSH_INTERVAL_SECONDS", 5)) * time.Second,
+	})
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		sink.Start(ctx)
+		analyticsDB.Close() // After the final flush
+	}()
+	return sink
+}
+
+// pingSigner builds the ping URL signer from PING_SIGNING_SECRET. It returns nil
+// when signing is off.
+func pingSigner() (*pingsig.Signer, error) {
+	secret := os.Getenv("PING_SIGNING_SECRET")
+	if secret == "" {
+		if config.GetBool("PING_SIGNATURES_REQUIRED", false) {
+			return nil, errors.New("PING_SIGNATURES_REQUIRED is set but PING_SIGNING_SECRET is empty")
+		}
+		return nil, nil
+	}
+	if len(secret) < 32 {
+		return nil, errors.New("PING_SIGNING_SECRET must be at least 32 characters")
+	}
+	return pingsig.NewSigner(secret)
+}
+
+// actionLinkSigner builds the action link signer from ACTION_LINK_SECRET. It
+// returns nil when action links are off.
+func actionLinkSigner() (*actionlink.Signer, error) {
+	secret := os.Getenv("ACTION_LINK_SECRET")
+	if secret == "" {
+		return nil, nil
+	}
+	if len(secret) < 32 {
+		return nil, errors.New("ACTION_LINK_SECRET must be at least 32 characters")
+	}
+	return actionlink.NewSigner(secret)
+}
+
+// forwardSelfHosts lists the names this instance answers to, which a check's
+// forward_url must not point at: the PUBLIC_BASE_URL host and the loopback
+// addresses on the listening port.
+func forwardSelfHosts(publicBaseURL string) []string {
+	port := serverPort()
+	hosts := []string{
+		net.JoinHostPort("localhost", port),
+		net.JoinHostPort("127.0.0.1", port),
+		net.JoinHostPort("::1", port),
+	}
+	if u, err := url.Parse(publicBaseURL); err == nil && u.Hostname() != "" {
+		// The whole host, on any port: behind a proxy the public port differs from ours
+		hosts = append(hosts, u.Hostname())
+	}
+	return hosts
+}
+
+// Metrics backends, selected with METRICS_BACKEND.
+const (
+	metricsNone       = "none"       // Discard metrics (default)
+	metricsStatsD     = "statsd"     // StatsD/DogStatsD over UDP
+	metricsPrometheus = "prometheus" // Scraped from /metrics on PROMETHEUS_LISTEN_ADDR
+)
+
+// newMetrics builds the metrics backend from configuration. dbPool's stats are
+// exported by the Prometheus backend; push backends get them from CollectRuntime.
+func newMetrics(dbPool *sql.DB) (metrics.Metrics, error) {
+	switch backend := strings.ToLower(config.GetString("METRICS_BACKEND", metricsNone)); backend {
+	case metricsNone:
+		return metrics.Nop{}, nil
+	case metricsStatsD:
+		statsdConfig := metrics.StatsDConfig{
+			Addr:        net.JoinHostPort(config.GetString("STATSD_HOST", "127.0.0.1"), config.GetString("STATSD_PORT", "8125")),
+			Prefix:      config.GetString("STATSD_PREFIX", "bitterlink."),
+			SampleRates: map[string]float64{},
+		}
+		if tags := config.GetString("STATSD_TAGS", ""); tags != "" {
+			statsdConfig.Tags = strings.Split(tags, ",") // e.g. "env:prod,service:core"
+		}
+		// Request and ping counters are the hot ones; sample them on busy installs
+		if rate := config.GetFloat("STATSD_HOT_SAMPLE_RATE", 1); rate > 0 && rate < 1 {
+			for _, name := range []string{metrics.HTTPRequests, metrics.HTTPRequestDuration, metrics.PingsIngested} {
+				statsdConfig.SampleRates[name] = rate
+			}
+		}
+		return metrics.NewStatsD(statsdConfig)
+	case metricsPrometheus:
+		return metrics.NewPrometheus(metrics.PrometheusConfig{
+			Namespace: config.GetString("PROMETHEUS_NAMESPACE", "bitterlink"),
+			Buckets: map[string][]float64{
+				metrics.PingPayloadBytes: payloadBuckets(config.GetInt("PING_MAX_PAYLOAD_BYTES", 10000)),
+			},
+			DBPool: dbPool,
+		}), nil
+	default:
+		return nil, fmt.Errorf("invalid METRICS_BACKEND %q (want %s, %s or %s)", backend, metricsNone, metricsStatsD, metricsPrometheus)
+	}
+}
+
+// payloadBuckets are the ping payload size buckets for a cap of maxBytes:
+// powers of 4 from 64 bytes up to the cap, then the cap itself, so the +Inf
+// bucket holds exactly the truncated payloads.
+func payloadBuckets(maxBytes int) []float64 {
+	var buckets []float64
+	for bound := 64; bound < maxBytes; bound *= 4 {
+		buckets = append(buckets, float64(bound))
+	}
+	if maxBytes > 0 {
+		buckets = append(buckets, float64(maxBytes))
+	}
+	return buckets
+}
+
+// servePrometheus serves p at /metrics on addr in the background.
+func servePrometheus(p *metrics.Prometheus, addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", p)
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		log.Printf("INFO: Serving Prometheus metrics on %s/metrics", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("FATAL: Prometheus metrics listen: %s\n", err)
+		}
+	}()
+	return srv
+}
+
+// serveGRPC serves the gRPC API on port until the server is stopped.
+func serveGRPC(grpcServer *grpc.Server, port string) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("FATAL: gRPC listen: %s\n", err)
+	}
+	log.Printf("INFO: Starting gRPC server on port :%s", port)
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatalf("FATAL: gRPC serve: %s\n", err)
+	}
+}
+
+// stopGRPC drains in-flight RPCs, forcing the stop once ctx expires.
+func stopGRPC(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		log.Println("INFO: gRPC server gracefully stopped.")
+	case <-ctx.Done():
+		grpcServer.Stop()
+		log.Println("WARN: gRPC server shutdown deadline exceeded, connections closed.")
+	}
+}
+
+// serveUnix serves srv on a unix socket, replacing a stale socket file left by a previous run.
+func serveUnix(srv *http.Server, socketPath string) error {
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(listener)
+}
+
+// serverPort returns the configured HTTP port (SERVER_PORT, default 8080).
+func serverPort() string {
+	if port := os.Getenv("SERVER_PORT"); port != "" {
+		return port
+	}
+	return "8080"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProcessRole(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{env: "", want: roleAll},
+		{env: "all", want: roleAll},
+		{env: "api", want: roleAPI},
+		{env: "Worker", want: roleWorker},
+		{env: "scheduler", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("ROLE", tt.env)
+			got, err := processRole()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processRole() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("processRole() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnabledChannelTypes(t *testing.T) {
+	tests := []struct {
+		env     string
+		want    []string
+		wantErr bool
+	}{
+		{env: "", want: []string{"webhook", "email", "slack", "alertmanager"}},
+		{env: "email", want: []string{"email"}},
+		{env: " Slack, webhook ,slack", want: []string{"slack", "webhook"}},
+		{env: "email,sms", wantErr: true},
+		{env: ",", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("NOTIFY_CHANNEL_TYPES", tt.env)
+			got, err := enabledChannelTypes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("enabledChannelTypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("enabledChannelTypes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadBuckets(t *testing.T) {
+	tests := []struct {
+		max  int
+		want []float64
+	}{
+		{max: 10000, want: []float64{64, 256, 1024, 4096, 10000}},
+		{max: 1024, want: []float64{64, 256, 1024}},
+		{max: 50, want: []float64{50}},
+		{max: 0, want: nil},
+	}
+	for _, tt := range tests {
+		if got := payloadBuckets(tt.max); !slices.Equal(got, tt.want) {
+			t.Errorf("payloadBuckets(%d) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}